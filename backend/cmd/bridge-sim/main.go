@@ -0,0 +1,95 @@
+// Command bridge-sim connects to a CPD-Nexus backend as a simulated IoT bridge so the
+// GET_ATTENDANCE and REGISTER_USER / UPDATE_USER flows can be exercised without hardware.
+//
+//	go run ./cmd/bridge-sim -url http://localhost:3000 -user-id <tenant> -token <bridge token> -scenario scenario.json
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"cpd-nexus/internal/bridge/bridgesim"
+	"cpd-nexus/internal/pkg/logger"
+)
+
+func main() {
+	baseURL := flag.String("url", "http://localhost:3000", "backend base URL")
+	userID := flag.String("user-id", "", "tenant user_id the bridge belongs to")
+	token := flag.String("token", "", "bridge auth token from the admin dashboard")
+	scenarioFile := flag.String("scenario", "", "JSON scenario file; defaults accept every command and generate a 08:00-17:30 shift")
+	heartbeat := flag.Duration("heartbeat", time.Minute, "interval between DEVICE_HEARTBEAT messages; 0 disables them")
+	reconnect := flag.Duration("reconnect", 5*time.Second, "delay before reconnecting after the connection drops; 0 exits instead")
+	flag.Parse()
+
+	if *userID == "" || *token == "" {
+		logger.Fatalf("-user-id and -token are required")
+	}
+
+	scenario := bridgesim.DefaultScenario()
+	if *scenarioFile != "" {
+		sc, err := bridgesim.LoadScenario(*scenarioFile)
+		if err != nil {
+			logger.Fatalf("%v", err)
+		}
+		scenario = sc
+	}
+
+	sim, err := bridgesim.New(*baseURL, *userID, *token, scenario)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		if err := sim.Connect(ctx); err != nil {
+			logger.Errorf("[BridgeSim] %v", err)
+		} else {
+			logger.Infof("[BridgeSim] Connected to %s as %s", *baseURL, *userID)
+			connCtx, cancel := context.WithCancel(ctx)
+			runHeartbeat(connCtx, sim, *heartbeat)
+			if err := sim.Run(connCtx); err != nil {
+				logger.Errorf("[BridgeSim] %v", err)
+			}
+			cancel()
+			sim.Close()
+		}
+
+		if ctx.Err() != nil || *reconnect <= 0 {
+			logger.Infof("[BridgeSim] Stopped. %d workers on roster, %d commands received", len(sim.Roster()), len(sim.Received()))
+			return
+		}
+
+		select {
+		case <-time.After(*reconnect):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runHeartbeat sends device heartbeats in the background until ctx ends or a write fails.
+func runHeartbeat(ctx context.Context, sim *bridgesim.Sim, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := sim.SendHeartbeat(); err != nil {
+				return
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
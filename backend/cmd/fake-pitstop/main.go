@@ -0,0 +1,128 @@
+// Command fake-pitstop runs a local stand-in for the SGTradeX Pitstop API so submissions can be
+// exercised end-to-end without the UAT host. Point the backend at it with PITSTOP_URL.
+//
+// Besides the Pitstop endpoints it exposes a small control API:
+//
+//	GET    /_fake/batches  recorded push requests
+//	DELETE /_fake/batches  clear recorded requests and scripted failures
+//	POST   /_fake/script   {"fail": [429, 500], "latency_ms": 200, "reject_person_ids": ["G1234567P"], "reject_status": 422}
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/adapters/external/sgbuildex/pitstoptest"
+	"cpd-nexus/internal/pkg/logger"
+)
+
+type script struct {
+	Fail            []int    `json:"fail"`
+	LatencyMs       int      `json:"latency_ms"`
+	RejectPersonIDs []string `json:"reject_person_ids"`
+	RejectStatus    int      `json:"reject_status"`
+}
+
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	apiKey := flag.String("api-key", os.Getenv("SGTRADEX_API_KEY"), "expected SGTRADEX-API-KEY header; empty accepts any request")
+	configFile := flag.String("config", "", "JSON file with the produces list served from /api/v1/config")
+	fail := flag.String("fail", "", "comma-separated HTTP statuses returned by the next pushes, e.g. 429,429,500")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	rejectIDs := flag.String("reject-person-ids", "", "comma-separated person_id_no values to reject (partial rejection)")
+	rejectStatus := flag.Int("reject-status", http.StatusUnprocessableEntity, "HTTP status returned for a batch with rejected items; 4xx/5xx refuses the whole batch, 2xx accepts the rest")
+	flag.Parse()
+
+	fake := pitstoptest.New(*apiKey)
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			logger.Fatalf("Failed to read config file: %v", err)
+		}
+		var produces []pitstoptest.Produce
+		if err := json.Unmarshal(data, &produces); err != nil {
+			logger.Fatalf("Failed to parse config file: %v", err)
+		}
+		fake.SetProduces(produces)
+	}
+
+	initial := script{LatencyMs: int(latency.Milliseconds()), RejectStatus: *rejectStatus}
+	for _, s := range splitList(*fail) {
+		status, err := strconv.Atoi(s)
+		if err != nil {
+			logger.Fatalf("Invalid -fail status %q", s)
+		}
+		initial.Fail = append(initial.Fail, status)
+	}
+	initial.RejectPersonIDs = splitList(*rejectIDs)
+	apply(fake, initial)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_fake/batches", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": fake.Batches()})
+		case http.MethodDelete:
+			fake.Reset()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/_fake/script", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var s script
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		apply(fake, s)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.Handle("/", logRequests(fake))
+
+	logger.Infof("[FakePitstop] Listening on %s (api key check: %t)", *addr, *apiKey != "")
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logger.Fatalf("Fake Pitstop server failed: %v", err)
+	}
+}
+
+// apply adds the scripted failures to the fake. Latency and rejection replace the current settings.
+func apply(fake *pitstoptest.Fake, s script) {
+	for _, status := range s.Fail {
+		fake.FailNext(status, 1)
+	}
+	fake.SetLatency(time.Duration(s.LatencyMs) * time.Millisecond)
+	if len(s.RejectPersonIDs) > 0 {
+		fake.RejectItems(s.RejectStatus, pitstoptest.RejectPersonIDs(s.RejectPersonIDs...))
+	} else {
+		fake.RejectItems(s.RejectStatus, nil)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Infof("[FakePitstop] %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
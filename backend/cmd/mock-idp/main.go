@@ -0,0 +1,68 @@
+// Command mock-idp runs a local OpenID Connect provider so single sign-on can be exercised without a
+// corporate IdP. Register it as an organisation's provider with the printed issuer and client ID.
+//
+// There is no login page: /authorize signs in the scripted user straight away. Change the user with
+//
+//	POST /_mock/user   {"sub": "user-2", "email": "sam@example.com", "email_verified": true, "groups": ["admins"]}
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"strings"
+
+	"cpd-nexus/internal/adapters/external/oidc/oidctest"
+	"cpd-nexus/internal/pkg/logger"
+)
+
+func main() {
+	addr := flag.String("addr", ":8091", "listen address")
+	issuer := flag.String("issuer", "http://localhost:8091", "issuer URL, as reachable by the backend")
+	clientID := flag.String("client-id", "cpd-nexus", "client ID of the relying party")
+	clientSecret := flag.String("client-secret", "", "client secret; empty registers a public client")
+	redirectURIs := flag.String("redirect-uris", "http://localhost:3000/api/auth/oidc/callback", "comma-separated allowed redirect URIs")
+	email := flag.String("email", "jane@example.com", "e-mail address of the signed-in user")
+	flag.Parse()
+
+	idp, err := oidctest.New(strings.TrimSuffix(*issuer, "/"))
+	if err != nil {
+		logger.Fatalf("Failed to create provider: %v", err)
+	}
+	var uris []string
+	for _, u := range strings.Split(*redirectURIs, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			uris = append(uris, u)
+		}
+	}
+	idp.AddClient(oidctest.Client{ID: *clientID, Secret: *clientSecret, RedirectURIs: uris})
+	idp.SetUser(map[string]interface{}{"sub": *email, "email": *email, "email_verified": true, "name": *email})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/_mock/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var claims map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&claims); err != nil || claims["sub"] == nil {
+			http.Error(w, "body must be a JSON object with a sub claim", http.StatusBadRequest)
+			return
+		}
+		idp.SetUser(claims)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.Handle("/", logRequests(idp))
+
+	logger.Infof("[MockIdP] Listening on %s (issuer %s, client %s)", *addr, idp.Issuer(), *clientID)
+	if err := http.ListenAndServe(*addr, mux); err != nil {
+		logger.Fatalf("Mock IdP failed: %v", err)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger.Infof("[MockIdP] %s %s", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"cpd-nexus/internal/adapters/external/oidc"
 	"cpd-nexus/internal/adapters/external/sgbuildex"
 	"cpd-nexus/internal/adapters/repository/mysql"
 	"cpd-nexus/internal/api"
@@ -22,18 +23,25 @@ import (
 	"cpd-nexus/internal/core/services"
 	"cpd-nexus/internal/pkg/config"
 	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/timeutil"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
+
+	// Embedded tz database, so BUSINESS_TIMEZONE resolves on hosts without one
+	_ "time/tzdata"
 )
 
 func main() {
 	cfg := config.LoadConfig()
 	logger.Infof("--- CPD Nexus Unified Backend Starting ---")
 
-	// --- 0. Configure JWT middleware ---
+	// --- 0. Configure JWT middleware and the business timezone ---
 	middleware.SetJWTSecret(cfg.JWTSecret)
+	middleware.SetTrustedProxies(cfg.TrustedProxies)
+	timeutil.SetBusinessLocation(cfg.BusinessLocation)
+	logger.Infof("Business timezone: %s (UTC%s)", cfg.BusinessLocation, timeutil.MySQLOffset(cfg.BusinessLocation))
 
 	// --- 1. DB Connection ---
 	db, err := sql.Open("mysql", cfg.DBDSN)
@@ -56,11 +64,24 @@ func main() {
 	// --- 2. Shared Initialization ---
 	// Repositories
 	attendanceRepo := mysql.NewAttendanceRepository(db)
+	punchRepo := mysql.NewPunchRepository(db)
+	shiftRepo := mysql.NewShiftRepository(db)
+	correctionRepo := mysql.NewCorrectionRepository(db)
 	workerRepo := mysql.NewWorkerRepository(db)
 	deviceRepo := mysql.NewDeviceRepository(db)
 	settingsRepo := mysql.NewMySQLSettingsRepository(db)
-	submissionRepo := mysql.NewSubmissionRepository(db)
+	retryPolicy := domain.DefaultSubmissionRetryPolicy
+	retryPolicy.MaxAttempts = cfg.SubmissionMaxAttempts
+	retryPolicy.BaseDelay = time.Duration(cfg.SubmissionRetryBaseMinutes) * time.Minute
+	submissionRepo := mysql.NewSubmissionRepository(db, retryPolicy)
 	userRepo := mysql.NewUserRepository(db)
+	memberRepo := mysql.NewMemberRepository(db)
+	sessionRepo := mysql.NewSessionRepository(db)
+	throttleRepo := mysql.NewLoginThrottleRepository(db)
+	mfaRepo := mysql.NewMFARepository(db)
+	apiKeyRepo := mysql.NewAPIKeyRepository(db)
+	oidcRepo := mysql.NewOIDCRepository(db)
+	impersonationRepo := mysql.NewImpersonationRepository(db)
 	siteRepo := mysql.NewSiteRepository(db)
 	projectRepo := mysql.NewProjectRepository(db)
 	analyticsRepo := mysql.NewAnalyticsRepository(db)
@@ -70,12 +91,59 @@ func main() {
 	analyticsService := services.NewAnalyticsService(analyticsRepo)
 	analyticsService.SetUserRepo(userRepo)
 	workerService := services.NewWorkerService(workerRepo, analyticsService)
-	attendanceService := services.NewAttendanceService(attendanceRepo, workerRepo, deviceRepo, analyticsService)
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret, analyticsService)
-	userService := services.NewUserService(userRepo, analyticsService, cfg.DefaultUserPassword)
+	attendanceService := services.NewAttendanceService(attendanceRepo, punchRepo, shiftRepo, workerRepo, deviceRepo, settingsRepo, analyticsService)
+	passwords := services.PasswordConfig{
+		Policy: domain.PasswordPolicy{
+			MinLength:     cfg.PasswordMinLength,
+			RequireUpper:  cfg.PasswordRequireUpper,
+			RequireLower:  cfg.PasswordRequireLower,
+			RequireDigit:  cfg.PasswordRequireDigit,
+			RequireSymbol: cfg.PasswordRequireSymbol,
+		},
+		DefaultPassword: cfg.DefaultUserPassword,
+		ResetTTL:        time.Duration(cfg.PasswordResetTTLMinutes) * time.Minute,
+	}
+	authService := services.NewAuthService(memberRepo, sessionRepo, throttleRepo, mfaRepo, services.AuthConfig{
+		JWTSecret:  cfg.JWTSecret,
+		AccessTTL:  time.Duration(cfg.AccessTokenTTLMinutes) * time.Minute,
+		RefreshTTL: time.Duration(cfg.RefreshTokenTTLHours) * time.Hour,
+		Passwords:  passwords,
+		Throttle: domain.LoginThrottlePolicy{
+			MaxAccountFailures: cfg.LoginMaxAccountFailures,
+			MaxIPFailures:      cfg.LoginMaxIPFailures,
+			Window:             time.Duration(cfg.LoginFailureWindowMinutes) * time.Minute,
+			BaseDelay:          time.Duration(cfg.LoginDelayBaseSeconds) * time.Second,
+			MaxDelay:           time.Duration(cfg.LoginDelayMaxSeconds) * time.Second,
+			LockoutDuration:    time.Duration(cfg.LoginLockoutMinutes) * time.Minute,
+		},
+		MFA: services.MFAConfig{
+			Issuer:      cfg.MFAIssuer,
+			RequiredFor: cfg.MFARequiredUserTypes,
+			SecretKey:   cfg.MFASecretKey,
+		},
+	}, analyticsService)
+	userService := services.NewUserService(userRepo, memberRepo, analyticsService, passwords)
+	memberService := services.NewMemberService(memberRepo, sessionRepo, throttleRepo, analyticsService, passwords)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, analyticsService)
+	impersonationService := services.NewImpersonationService(impersonationRepo, userRepo, memberRepo, services.ImpersonationConfig{
+		JWTSecret:  cfg.JWTSecret,
+		DefaultTTL: time.Duration(cfg.ImpersonationTTLMinutes) * time.Minute,
+		MaxTTL:     time.Duration(cfg.ImpersonationMaxTTLMinutes) * time.Minute,
+	}, analyticsService)
+	sealKey := cfg.MFASecretKey
+	if sealKey == "" {
+		sealKey = cfg.JWTSecret
+	}
+	oidcService := services.NewOIDCService(oidcRepo, memberRepo, authService, oidc.NewClient(), services.OIDCConfig{
+		RedirectURL: cfg.OIDCRedirectURL,
+		SecretKey:   sealKey,
+	}, analyticsService)
 	siteService := services.NewSiteService(siteRepo, analyticsService)
 	projectService := services.NewProjectService(projectRepo, workerRepo, analyticsService)
 	deviceService := services.NewDeviceService(deviceRepo, analyticsService)
+	shiftService := services.NewShiftService(shiftRepo, siteRepo, projectRepo, analyticsService)
+	correctionService := services.NewCorrectionService(correctionRepo, attendanceRepo, analyticsService)
+	attendanceEntryService := services.NewAttendanceEntryService(attendanceRepo, workerRepo, projectRepo, shiftRepo, settingsRepo, analyticsService)
 	var settingsService ports.SettingsService
 
 	// Internal client for external fetch
@@ -84,35 +152,59 @@ func main() {
 
 	// Handlers
 	routerCfg := api.RouterConfig{
-		AuthHandler:        apiHandlers.NewAuthHandler(authService, userService),
-		WorkersHandler:     apiHandlers.NewWorkersHandler(workerService),
-		ProjectsHandler:    apiHandlers.NewProjectsHandler(projectService),
-		SitesHandler:       apiHandlers.NewSitesHandler(siteService),
-		DevicesHandler:     apiHandlers.NewDevicesHandler(deviceService),
-		UsersHandler:       apiHandlers.NewUsersHandler(userService),
-		AssignmentsHandler: apiHandlers.NewAssignmentsHandler(workerService, deviceService, projectService),
-		AnalyticsHandler:   apiHandlers.NewAnalyticsHandler(analyticsService),
-		AttendanceHandler:  apiHandlers.NewAttendanceHandler(attendanceService),
-		PitstopHandler:     apiHandlers.NewPitstopHandler(pitstopService),
-		UserRepo:           userRepo,
+		AuthHandler:          apiHandlers.NewAuthHandler(authService),
+		WorkersHandler:       apiHandlers.NewWorkersHandler(workerService),
+		ProjectsHandler:      apiHandlers.NewProjectsHandler(projectService),
+		SitesHandler:         apiHandlers.NewSitesHandler(siteService),
+		DevicesHandler:       apiHandlers.NewDevicesHandler(deviceService),
+		UsersHandler:         apiHandlers.NewUsersHandler(userService),
+		AssignmentsHandler:   apiHandlers.NewAssignmentsHandler(workerService, deviceService, projectService),
+		AnalyticsHandler:     apiHandlers.NewAnalyticsHandler(analyticsService),
+		AttendanceHandler:    apiHandlers.NewAttendanceHandler(attendanceService),
+		PitstopHandler:       apiHandlers.NewPitstopHandler(pitstopService),
+		MembersHandler:       apiHandlers.NewMembersHandler(memberService),
+		APIKeysHandler:       apiHandlers.NewAPIKeysHandler(apiKeyService),
+		OIDCHandler:          apiHandlers.NewOIDCHandler(oidcService, cfg.OIDCPostLoginURL),
+		ImpersonationHandler: apiHandlers.NewImpersonationHandler(impersonationService),
+		ShiftsHandler:        apiHandlers.NewShiftsHandler(shiftService),
+		CorrectionsHandler:   apiHandlers.NewCorrectionsHandler(correctionService),
+		UserRepo:             userRepo,
+		MemberRepo:           memberRepo,
+		SessionRepo:          sessionRepo,
+		APIKeyRepo:           apiKeyRepo,
+		ImpersonationRepo:    impersonationRepo,
+
+		// Manual entry and timesheet import
+		AttendanceEntryHandler: apiHandlers.NewAttendanceEntryHandler(attendanceEntryService),
 		// SettingsHandler will be added later after Schedulers are ready
 	}
 
 	// Bridge Integration
 	bridgeRepo := mysql.NewBridgeRepository(db)
 	requestMgr := bridge.NewRequestManager(bridgeRepo)
+	requestMgr.SetHeartbeat(time.Duration(cfg.BridgePingIntervalSeconds)*time.Second, time.Duration(cfg.BridgePongTimeoutSeconds)*time.Second)
+	// No transport survives a restart, so clear any connection state left over from the previous run
+	if err := bridgeRepo.ResetBridgeConnections(context.Background()); err != nil {
+		logger.Errorf("Failed to reset bridge connection state: %v", err)
+	}
 	userSyncBuilder := bridgeHandlers.NewUserSyncBuilder(workerService, workerRepo, deviceRepo)
 	routerCfg.BridgeHandler = apiHandlers.NewBridgeHandler(requestMgr, userRepo); routerCfg.BridgeSyncHandler = apiHandlers.NewBridgeSyncHandler(userSyncBuilder, requestMgr, bridgeRepo)
+	routerCfg.BridgeOutboxHandler = apiHandlers.NewBridgeOutboxHandler(bridgeRepo)
 
 	attendanceHandler := bridgeHandlers.NewAttendanceHandler(attendanceService)
 	requestMgr.RegisterHandler("GET_ATTENDANCE_RESPONSE", attendanceHandler)
 
-	userSyncResponseHandler := bridgeHandlers.NewUserSyncResponseHandler(workerRepo, bridgeRepo)
+	userSyncResponseHandler := bridgeHandlers.NewUserSyncResponseHandler(workerRepo)
 	requestMgr.RegisterHandler("REGISTER_USER_RESPONSE", userSyncResponseHandler)
 	requestMgr.RegisterHandler("UPDATE_USER_RESPONSE", userSyncResponseHandler)
 
-	// Context for graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
+	deviceStatusHandler := bridgeHandlers.NewDeviceStatusHandler(deviceService)
+	requestMgr.RegisterHandler("DEVICE_STATUS", deviceStatusHandler)
+	requestMgr.RegisterHandler("DEVICE_HEARTBEAT", deviceStatusHandler)
+
+	// Context for graceful shutdown. The schedulers, retry loop and sweepers act for no login,
+	// so it marks them as system callers.
+	ctx, cancel := context.WithCancel(ports.WithSystemCaller(context.Background()))
 	defer cancel()
 
 	// Task 1: Attendance Sync (Bridge -> Nexus)
@@ -156,9 +248,11 @@ func main() {
 	server := startAPI(cfg, routerCfg)
 
 	// --- 5. Component D: Core Loops ---
-	go startBridge(ctx, cfg, bridgeRepo, requestMgr, userSyncBuilder)
+	go startBridge(ctx, cfg, bridgeRepo, requestMgr, userSyncBuilder, deviceService)
 	go attendanceSyncScheduler.Start(ctx)
 	go cpdSubmissionScheduler.Start(ctx)
+	go startSubmissionRetries(ctx, pitstopService, time.Duration(cfg.SubmissionRetryIntervalMinutes)*time.Minute)
+	go startAuthPurge(ctx, sessionRepo, throttleRepo, oidcRepo, time.Hour)
 
 	logger.Infof("[System] Schedulers and API services fully operational")
 
@@ -208,7 +302,10 @@ func startAPI(cfg *config.Config, routerCfg api.RouterConfig) *http.Server {
 	return server
 }
 
-func startBridge(ctx context.Context, _ *config.Config, _ ports.BridgeRepository, requestMgr *bridge.RequestManager, userSyncBuilder *bridgeHandlers.UserSyncBuilder) {
+func startBridge(ctx context.Context, cfg *config.Config, _ ports.BridgeRepository, requestMgr *bridge.RequestManager, userSyncBuilder *bridgeHandlers.UserSyncBuilder, deviceService ports.DeviceService) {
+	// 0. Expire bridge requests that never received a response and record them as timed out
+	go requestMgr.StartTimeoutSweeper(ctx, 10*time.Second)
+
 	// 1. Worker Sync Background Ticker (Every 10 seconds as per user requirement)
 	// This queues sync commands for any bridges that are currently connected.
 	go func() {
@@ -225,4 +322,71 @@ func startBridge(ctx context.Context, _ *config.Config, _ ports.BridgeRepository
 			}
 		}
 	}()
+
+	// 2. Device Liveness Sweeper: flip devices to offline once their heartbeat goes silent
+	go func() {
+		silence := time.Duration(cfg.DeviceOfflineAfterSeconds) * time.Second
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				n, err := deviceService.MarkSilentDevicesOffline(ctx, silence)
+				if err != nil {
+					logger.Infof("[DeviceSweeper] Offline sweep failed: %v", err)
+				} else if n > 0 {
+					logger.Infof("[DeviceSweeper] Marked %d silent devices offline", n)
+				}
+			}
+		}
+	}()
+}
+
+// startSubmissionRetries resubmits transiently failed CPD records once their backoff has elapsed,
+// so retries do not have to wait for the next daily submission cycle.
+func startSubmissionRetries(ctx context.Context, pitstopService *services.PitstopService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := pitstopService.RetryFailedSubmissions(ctx)
+			if err != nil {
+				logger.Errorf("[CPDRetry] Retry cycle failed: %v", err)
+			} else if n > 0 {
+				logger.Infof("[CPDRetry] Retried %d failed submissions", n)
+			}
+		}
+	}
+}
+
+// startAuthPurge deletes expired refresh sessions and revocation entries, which can no longer be presented,
+// login failure counters that have run out, and abandoned single sign-on attempts.
+func startAuthPurge(ctx context.Context, sessions ports.SessionRepository, throttles ports.LoginThrottleRepository, oidcStates ports.OIDCRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := sessions.PurgeExpired(ctx, time.Now())
+			if err != nil {
+				logger.Errorf("[Sessions] Purge failed: %v", err)
+			} else if n > 0 {
+				logger.Infof("[Sessions] Purged %d expired session records", n)
+			}
+			// Counters are only consulted within their window, so a day is ample
+			if _, err := throttles.PurgeStale(ctx, time.Now().Add(-24*time.Hour)); err != nil {
+				logger.Errorf("[Sessions] Login throttle purge failed: %v", err)
+			}
+			if _, err := oidcStates.PurgeStates(ctx, time.Now()); err != nil {
+				logger.Errorf("[Sessions] SSO login state purge failed: %v", err)
+			}
+		}
+	}
 }
@@ -0,0 +1,268 @@
+// Package oidc is a minimal OpenID Connect relying party: provider discovery, the authorization
+// code flow with PKCE, and RS256 ID token verification against the provider's JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// metadataTTL is how long discovery documents and signing keys are cached
+const metadataTTL = time.Hour
+
+// Metadata is the part of the discovery document the client uses
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type cachedMetadata struct {
+	meta    *Metadata
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+}
+
+// Client implements ports.OIDCClient. It is safe for concurrent use.
+type Client struct {
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	cache map[string]*cachedMetadata // by issuer
+}
+
+func NewClient() *Client {
+	return &Client{HTTPClient: &http.Client{Timeout: 15 * time.Second}, cache: map[string]*cachedMetadata{}}
+}
+
+var _ ports.OIDCClient = (*Client)(nil)
+
+// AuthorizationURL builds the authorization request with an S256 code challenge.
+func (c *Client) AuthorizationURL(ctx context.Context, issuer string, req domain.OIDCAuthRequest) (string, error) {
+	meta, err := c.metadata(ctx, issuer)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", req.ClientID)
+	q.Set("redirect_uri", req.RedirectURI)
+	q.Set("scope", strings.Join(req.Scopes, " "))
+	q.Set("state", req.State)
+	q.Set("nonce", req.Nonce)
+	q.Set("code_challenge", req.CodeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Exchange redeems the code at the token endpoint and verifies the returned ID token: signature,
+// issuer, audience, expiry and nonce.
+func (c *Client) Exchange(ctx context.Context, issuer string, req domain.OIDCTokenRequest) (map[string]interface{}, error) {
+	meta, err := c.metadata(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {req.Code},
+		"redirect_uri":  {req.RedirectURI},
+		"client_id":     {req.ClientID},
+		"code_verifier": {req.CodeVerifier},
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	httpReq.Header.Set("Accept", "application/json")
+	if req.ClientSecret != "" {
+		httpReq.SetBasicAuth(url.QueryEscape(req.ClientID), url.QueryEscape(req.ClientSecret))
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var tokens struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil || tokens.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+	return c.VerifyIDToken(ctx, issuer, tokens.IDToken, req.ClientID, req.Nonce)
+}
+
+// VerifyIDToken checks an ID token issued to clientID and returns its claims.
+func (c *Client) VerifyIDToken(ctx context.Context, issuer, raw, clientID, nonce string) (map[string]interface{}, error) {
+	meta, err := c.metadata(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return c.signingKey(ctx, meta.Issuer, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(meta.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+	if sub, _ := claims["sub"].(string); sub == "" {
+		return nil, errors.New("invalid id_token: missing sub")
+	}
+	return claims, nil
+}
+
+// metadata returns the cached discovery document, fetching it when missing or stale.
+func (c *Client) metadata(ctx context.Context, issuer string) (*Metadata, error) {
+	c.mu.Lock()
+	entry := c.cache[issuer]
+	c.mu.Unlock()
+	if entry != nil && time.Since(entry.fetched) < metadataTTL {
+		return entry.meta, nil
+	}
+
+	var meta Metadata
+	if err := c.getJSON(ctx, strings.TrimSuffix(issuer, "/")+"/.well-known/openid-configuration", &meta); err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	if meta.Issuer != issuer {
+		return nil, fmt.Errorf("discovery document is for issuer %q, expected %q", meta.Issuer, issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("discovery document is incomplete")
+	}
+
+	c.mu.Lock()
+	c.cache[issuer] = &cachedMetadata{meta: &meta, fetched: time.Now()}
+	c.mu.Unlock()
+	return &meta, nil
+}
+
+// signingKey looks a key up by kid, refetching the JWKS once when the kid is unknown (key rotation).
+func (c *Client) signingKey(ctx context.Context, issuer, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	entry := c.cache[issuer]
+	var keys map[string]*rsa.PublicKey
+	if entry != nil {
+		keys = entry.keys
+	}
+	c.mu.Unlock()
+	if entry == nil {
+		return nil, errors.New("unknown issuer")
+	}
+
+	if key := pickKey(keys, kid); key != nil {
+		return key, nil
+	}
+	keys, err := c.fetchKeys(ctx, entry.meta.JWKSURI)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	entry.keys = keys
+	c.mu.Unlock()
+
+	if key := pickKey(keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key %q", kid)
+}
+
+// pickKey finds the key with kid; tokens without a kid may use the only key there is
+func pickKey(keys map[string]*rsa.PublicKey, kid string) *rsa.PublicKey {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func (c *Client) fetchKeys(ctx context.Context, jwksURI string) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := c.getJSON(ctx, jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
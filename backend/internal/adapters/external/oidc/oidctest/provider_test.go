@@ -0,0 +1,122 @@
+package oidctest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/adapters/external/oidc"
+	"cpd-nexus/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirect = "https://nexus.example.com/api/auth/oidc/callback"
+
+func startProvider(t *testing.T, secret string) *Provider {
+	t.Helper()
+	idp, err := Start()
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+	idp.AddClient(Client{ID: "nexus", Secret: secret, RedirectURIs: []string{testRedirect}})
+	return idp
+}
+
+func authRequest(verifier string) domain.OIDCAuthRequest {
+	sum := sha256.Sum256([]byte(verifier))
+	return domain.OIDCAuthRequest{
+		ClientID:      "nexus",
+		RedirectURI:   testRedirect,
+		Scopes:        domain.DefaultOIDCScopes,
+		State:         "state-1",
+		Nonce:         "nonce-1",
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+	}
+}
+
+func TestClient_AuthorizationCodeFlow(t *testing.T) {
+	idp := startProvider(t, "s3cret")
+	idp.SetUser(map[string]interface{}{"sub": "abc", "email": "jane@example.com", "groups": []string{"admins"}})
+	client := oidc.NewClient()
+	ctx := context.Background()
+
+	authURL, err := client.AuthorizationURL(ctx, idp.Issuer(), authRequest("verifier-1"))
+	require.NoError(t, err)
+	code, state, err := idp.Authorize(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := client.Exchange(ctx, idp.Issuer(), domain.OIDCTokenRequest{
+		ClientID: "nexus", ClientSecret: "s3cret", Code: code, CodeVerifier: "verifier-1", RedirectURI: testRedirect, Nonce: "nonce-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", claims["sub"])
+	assert.Equal(t, "jane@example.com", claims["email"])
+
+	// Codes are single-use
+	_, err = client.Exchange(ctx, idp.Issuer(), domain.OIDCTokenRequest{
+		ClientID: "nexus", ClientSecret: "s3cret", Code: code, CodeVerifier: "verifier-1", RedirectURI: testRedirect, Nonce: "nonce-1",
+	})
+	assert.Error(t, err)
+}
+
+func TestClient_ExchangeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		req  func(code string) domain.OIDCTokenRequest
+	}{
+		{"wrong PKCE verifier", func(code string) domain.OIDCTokenRequest {
+			return domain.OIDCTokenRequest{ClientID: "nexus", Code: code, CodeVerifier: "other", RedirectURI: testRedirect, Nonce: "nonce-1"}
+		}},
+		{"nonce mismatch", func(code string) domain.OIDCTokenRequest {
+			return domain.OIDCTokenRequest{ClientID: "nexus", Code: code, CodeVerifier: "verifier-1", RedirectURI: testRedirect, Nonce: "nonce-2"}
+		}},
+		{"different redirect URI", func(code string) domain.OIDCTokenRequest {
+			return domain.OIDCTokenRequest{ClientID: "nexus", Code: code, CodeVerifier: "verifier-1", RedirectURI: "https://evil.example.com/cb", Nonce: "nonce-1"}
+		}},
+		{"wrong client secret", func(code string) domain.OIDCTokenRequest {
+			return domain.OIDCTokenRequest{ClientID: "nexus", ClientSecret: "guess", Code: code, CodeVerifier: "verifier-1", RedirectURI: testRedirect, Nonce: "nonce-1"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idp := startProvider(t, "") // public client
+			client := oidc.NewClient()
+			authURL, err := client.AuthorizationURL(context.Background(), idp.Issuer(), authRequest("verifier-1"))
+			require.NoError(t, err)
+			code, _, err := idp.Authorize(authURL)
+			require.NoError(t, err)
+
+			_, err = client.Exchange(context.Background(), idp.Issuer(), tt.req(code))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestClient_RejectsTokensFromOtherKeys(t *testing.T) {
+	idp := startProvider(t, "")
+	other, err := New(idp.Issuer())
+	require.NoError(t, err)
+
+	// A token signed by a different key with a matching kid must fail signature verification
+	forged, err := other.Sign(map[string]interface{}{
+		"iss": idp.Issuer(), "aud": "nexus", "sub": "abc", "nonce": "nonce-1", "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+	genuine, err := idp.Sign(map[string]interface{}{
+		"iss": idp.Issuer(), "aud": "nexus", "sub": "abc", "nonce": "nonce-1", "exp": time.Now().Add(time.Minute).Unix(),
+	})
+	require.NoError(t, err)
+
+	client := oidc.NewClient()
+	_, err = client.VerifyIDToken(context.Background(), idp.Issuer(), genuine, "nexus", "nonce-1")
+	assert.NoError(t, err)
+	_, err = client.VerifyIDToken(context.Background(), idp.Issuer(), forged, "nexus", "nonce-1")
+	assert.Error(t, err)
+	_, err = client.VerifyIDToken(context.Background(), idp.Issuer(), genuine, "someone-else", "nonce-1")
+	assert.Error(t, err, "audience must be our client ID")
+}
@@ -26,7 +26,8 @@ func (c *Client) SubmitManpowerUtilization(ctx context.Context, repo ports.Submi
 				pJSON, _ := json.Marshal(dummyResult.Payloads[0])
 				failedPayload = string(pJSON)
 			}
-			repo.UpdateAttendanceStatus(ctx, row.AttendanceID, "failed", "", errMsg)
+			// Missing mandatory fields will fail the same way every time, so the row is not retried
+			repo.RecordAttendanceFailure(ctx, row.AttendanceID, "", errMsg, false)
 			repo.LogSubmission(ctx, "manpower_utilization", row.AttendanceID, "failed", failedPayload, errMsg)
 			failedCount++
 		}
@@ -40,6 +41,93 @@ func (c *Client) SubmitManpowerUtilization(ctx context.Context, repo ports.Submi
 	return submittedCount, failedCount, err
 }
 
+// PreviewManpowerUtilization implements ports.ExternalSubmitter.
+// It runs the same mapping and batching as SubmitManpowerUtilization but sends nothing and writes nothing.
+func (c *Client) PreviewManpowerUtilization(ctx context.Context, settings *domain.SystemSettings, rows []domain.AttendanceRow) (*ports.SubmissionPreview, error) {
+	muResult := MapAttendanceToManpower(rows)
+	maxItems, maxBytes := batchLimits(settings)
+
+	preview := &ports.SubmissionPreview{
+		DataElementID:    "manpower_utilization",
+		TotalRows:        len(rows),
+		ValidRows:        len(muResult.Payloads),
+		MaxItemsPerBatch: maxItems,
+		MaxPayloadBytes:  maxBytes,
+		Batches:          []ports.SubmissionPreviewBatch{},
+		Failures:         []ports.SubmissionPreviewFailure{},
+		Skipped:          []ports.SubmissionPreviewFailure{},
+	}
+
+	// Keep failures in extraction order rather than map order
+	for _, row := range rows {
+		if errMsg, exists := muResult.Failures[row.AttendanceID]; exists {
+			preview.Failures = append(preview.Failures, ports.SubmissionPreviewFailure{AttendanceID: row.AttendanceID, Error: errMsg})
+		}
+	}
+
+	wrappers := make([]ManpowerUtilizationWrapper, len(muResult.Payloads))
+	byID := make(map[string]ManpowerUtilizationWrapper, len(muResult.Payloads))
+	for i, p := range muResult.Payloads {
+		wrappers[i] = ManpowerUtilizationWrapper{ManpowerUtilization: p}
+		byID[wrappers[i].GetInternalID()] = wrappers[i]
+	}
+
+	batches, skipped := BuildBatches(ctx, settings, wrappers)
+	for _, sk := range skipped {
+		preview.Skipped = append(preview.Skipped, ports.SubmissionPreviewFailure{AttendanceID: sk.ID, Error: sk.Reason})
+	}
+
+	for i, b := range batches {
+		reqJSON, err := json.Marshal(b.Request)
+		if err != nil {
+			return nil, err
+		}
+
+		pb := ports.SubmissionPreviewBatch{
+			Index:         i + 1,
+			ItemCount:     len(b.IDs),
+			SizeBytes:     b.SizeBytes,
+			AttendanceIDs: b.IDs,
+			Groups:        []ports.SubmissionPreviewGroup{},
+			OnBehalfOf:    []string{},
+			Request:       reqJSON,
+		}
+		for _, ob := range b.Request.OnBehalfOf {
+			pb.OnBehalfOf = append(pb.OnBehalfOf, ob.ID)
+		}
+
+		// Group rows by the participant and on-behalf-of entity they are sent under
+		groupIdx := make(map[string]int)
+		for _, id := range b.IDs {
+			req, err := byID[id].ToPushRequest(ctx)
+			if err != nil || len(req.Participants) == 0 {
+				continue
+			}
+			part := req.Participants[0]
+			g := ports.SubmissionPreviewGroup{ParticipantID: part.ID, ParticipantName: part.Name}
+			if part.Meta != nil {
+				g.DataRefID = part.Meta.DataRefID
+			}
+			if len(req.OnBehalfOf) > 0 {
+				g.OnBehalfOfID = req.OnBehalfOf[0].ID
+			}
+
+			key := g.ParticipantID + "|" + g.DataRefID + "|" + g.OnBehalfOfID
+			idx, ok := groupIdx[key]
+			if !ok {
+				idx = len(pb.Groups)
+				groupIdx[key] = idx
+				pb.Groups = append(pb.Groups, g)
+			}
+			pb.Groups[idx].AttendanceIDs = append(pb.Groups[idx].AttendanceIDs, id)
+		}
+
+		preview.Batches = append(preview.Batches, pb)
+	}
+
+	return preview, nil
+}
+
 // FetchPitstopConfig implements ports.ExternalSubmitter — wraps the concrete FetchConfig method
 // and converts the response to the ports-level type (no concrete adapter types escape the boundary).
 func (c *Client) FetchPitstopConfig(ctx context.Context) (*ports.PitstopConfigResponse, error) {
@@ -3,6 +3,7 @@ package sgbuildex
 import (
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/timeutil"
 	"cpd-nexus/internal/pkg/validation"
 	"fmt"
 	"strings"
@@ -103,7 +104,7 @@ func MapAttendanceToManpower(rows []domain.AttendanceRow) MapResult {
 			InternalRegulatorName:           r.RegulatorName,
 			InternalOnBehalfOfID:            r.OnBehalfOfID,
 			SubmissionEntity:                ptrIntOrDefault(r.SubmissionEntity, 1),
-			SubmissionMonth:                 r.SubmissionDate.Format("2006-01"),
+			SubmissionMonth:                 r.SubmissionDate.Format("2006-01"), // a DATE, already in business terms: no zone conversion
 			PersonIDNo:                      Ptr(strings.ToUpper(strings.TrimSpace(r.WorkerFIN))),
 			PersonIDAndWorkPassType:         Ptr(strings.ToUpper(strings.TrimSpace(r.WorkerWorkPassType))),
 			PersonNationality:               Ptr(strings.ToUpper(strings.TrimSpace(r.WorkerNationality))),
@@ -113,10 +114,10 @@ func MapAttendanceToManpower(rows []domain.AttendanceRow) MapResult {
 			PersonEmployerCompanyTrade:      parseTrades(r.EmployerTrade),
 			PersonEmployerClientCompanyName: Ptr(r.EmployerClientName),
 			PersonEmployerClientCompanyUEN:  Ptr(validation.SanitizeUEN(r.EmployerClientUEN)),
-			PersonAttendanceDate:            r.TimeIn.Format("2006-01-02"),
+			PersonAttendanceDate:            r.SubmissionDate.Format("2006-01-02"), // the business date: a night shift counts towards the day it started
 			PersonAttendanceDetails: []payloads.AttendanceDetail{
 				{
-					TimeIn:  r.TimeIn.Format(time.RFC3339),
+					TimeIn:  timeutil.InBusiness(r.TimeIn).Format(time.RFC3339),
 					TimeOut: FormatOptionalTime(r.TimeOut),
 				},
 			},
@@ -196,3 +196,69 @@ func TestMapAttendanceToManpower(t *testing.T) {
 	assert.Equal(t, "Fabricator", *payload1.OffsiteFabricatorCompanyName)
 	assert.Nil(t, payload1.ProjectReferenceNumber) // Should be nil for Entity 2
 }
+
+func TestMapAttendanceToManpower_OvernightShiftUsesBusinessDate(t *testing.T) {
+	timeIn := time.Date(2026, 3, 31, 20, 0, 0, 0, time.UTC)
+	timeOut := time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC)
+	row := domain.AttendanceRow{
+		AttendanceID:       "ATT-1",
+		RegulatorID:        "REG-1",
+		OnBehalfOfID:       "OB-1",
+		SubmissionEntity:   1,
+		SubmissionDate:     time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		WorkerFIN:          "G1234567P",
+		WorkerWorkPassType: "WP",
+		WorkerTrade:        "2.3",
+		EmployerName:       "Valid Employer",
+		EmployerUEN:        "11111111A",
+		TimeIn:             timeIn,
+		TimeOut:            &timeOut,
+		ProjectRef:         "REF-1",
+		ProjectTitle:       "Title 1",
+		ProjectLocation:    "Loc 1",
+	}
+
+	result := MapAttendanceToManpower([]domain.AttendanceRow{row})
+
+	if assert.Len(t, result.Payloads, 1) {
+		assert.Equal(t, "2026-03-31", result.Payloads[0].PersonAttendanceDate)
+		assert.Equal(t, "2026-03", result.Payloads[0].SubmissionMonth)
+	}
+}
+
+func TestMapAttendanceToManpower_BusinessTimezoneDayBoundary(t *testing.T) {
+	sgt, err := time.LoadLocation("Asia/Singapore")
+	if err != nil {
+		t.Skip("tz database not available")
+	}
+	// 16:30 UTC on 31 March is 00:30 on 1 April in Singapore: the attendance belongs to April
+	timeIn := time.Date(2026, 3, 31, 16, 30, 0, 0, time.UTC)
+	timeOut := time.Date(2026, 4, 1, 1, 0, 0, 0, time.UTC)
+	row := domain.AttendanceRow{
+		AttendanceID:       "ATT-1",
+		RegulatorID:        "REG-1",
+		OnBehalfOfID:       "OB-1",
+		SubmissionEntity:   1,
+		SubmissionDate:     time.Date(2026, 4, 1, 0, 0, 0, 0, sgt),
+		WorkerFIN:          "G1234567P",
+		WorkerWorkPassType: "WP",
+		WorkerTrade:        "2.3",
+		EmployerName:       "Valid Employer",
+		EmployerUEN:        "11111111A",
+		TimeIn:             timeIn,
+		TimeOut:            &timeOut,
+		ProjectRef:         "REF-1",
+		ProjectTitle:       "Title 1",
+		ProjectLocation:    "Loc 1",
+	}
+
+	result := MapAttendanceToManpower([]domain.AttendanceRow{row})
+
+	if assert.Len(t, result.Payloads, 1) {
+		p := result.Payloads[0]
+		assert.Equal(t, "2026-04-01", p.PersonAttendanceDate)
+		assert.Equal(t, "2026-04", p.SubmissionMonth)
+		assert.Equal(t, "2026-04-01T00:30:00+08:00", p.PersonAttendanceDetails[0].TimeIn)
+		assert.Equal(t, "2026-04-01T09:00:00+08:00", *p.PersonAttendanceDetails[0].TimeOut)
+	}
+}
@@ -0,0 +1,92 @@
+package pitstoptest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/adapters/external/sgbuildex"
+)
+
+var submissionMonthPattern = regexp.MustCompile(`^\d{4}-(0[1-9]|1[0-2])$`)
+
+// manpowerRequired lists the manpower_utilization fields every item must carry
+var manpowerRequired = []string{
+	"submission_month",
+	"person_id_no",
+	"person_id_and_work_pass_type",
+	"person_trade",
+	"person_employer_company_name",
+	"person_employer_company_unique_entity_number",
+	"person_attendance_date",
+}
+
+// validatePush checks the envelope and, for known data elements, each payload item.
+// It returns one message per problem found.
+func validatePush(dataElementID string, participants []sgbuildex.ParticipantWrapper, payload []map[string]any) []string {
+	var errs []string
+
+	if len(participants) == 0 {
+		errs = append(errs, "participants: at least one participant is required")
+	}
+	for i, p := range participants {
+		if strings.TrimSpace(p.ID) == "" {
+			errs = append(errs, fmt.Sprintf("participants[%d].id: required", i))
+		}
+	}
+	if len(payload) == 0 {
+		errs = append(errs, "payload: at least one item is required")
+	}
+
+	if dataElementID == "manpower_utilization" {
+		for i, item := range payload {
+			for _, msg := range validateManpowerItem(item) {
+				errs = append(errs, fmt.Sprintf("payload[%d].%s", i, msg))
+			}
+		}
+	}
+	return errs
+}
+
+func validateManpowerItem(item map[string]any) []string {
+	var errs []string
+
+	for _, field := range manpowerRequired {
+		if s, _ := item[field].(string); strings.TrimSpace(s) == "" {
+			errs = append(errs, field+": required")
+		}
+	}
+
+	if s, ok := item["submission_month"].(string); ok && s != "" && !submissionMonthPattern.MatchString(s) {
+		errs = append(errs, "submission_month: must be YYYY-MM")
+	}
+	if s, ok := item["person_attendance_date"].(string); ok && s != "" {
+		if _, err := time.Parse("2006-01-02", s); err != nil {
+			errs = append(errs, "person_attendance_date: must be YYYY-MM-DD")
+		}
+	}
+
+	if entity, ok := item["submission_entity"].(float64); ok && entity != 1 && entity != 2 {
+		errs = append(errs, "submission_entity: must be 1 or 2")
+	}
+
+	details, _ := item["person_attendance_details"].([]any)
+	if len(details) == 0 {
+		errs = append(errs, "person_attendance_details: at least one entry is required")
+	}
+	for i, d := range details {
+		entry, _ := d.(map[string]any)
+		timeIn, _ := entry["time_in"].(string)
+		if _, err := time.Parse(time.RFC3339, timeIn); err != nil {
+			errs = append(errs, fmt.Sprintf("person_attendance_details[%d].time_in: must be RFC3339", i))
+		}
+		if timeOut, ok := entry["time_out"].(string); ok {
+			if _, err := time.Parse(time.RFC3339, timeOut); err != nil {
+				errs = append(errs, fmt.Sprintf("person_attendance_details[%d].time_out: must be RFC3339", i))
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,352 @@
+// Package pitstoptest provides an in-process stand-in for the SGTradeX Pitstop API.
+// It serves /api/v1/config and /api/v1/data/push/{dataElementID}, validates the API key
+// and payload schema, records every batch it receives and can be scripted to fail.
+package pitstoptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"cpd-nexus/internal/adapters/external/sgbuildex"
+)
+
+const (
+	configPath = "/api/v1/config"
+	pushPrefix = "/api/v1/data/push/"
+)
+
+// OnBehalfOf is an entity a regulator accepts submissions on behalf of
+type OnBehalfOf struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Regulator is a recipient of a data element
+type Regulator struct {
+	ID         string       `json:"id"`
+	Name       string       `json:"name"`
+	OnBehalfOf []OnBehalfOf `json:"on_behalf_of"`
+}
+
+// Produce is a data element the caller is allowed to push, with its recipients
+type Produce struct {
+	ID   string      `json:"id"`
+	Name string      `json:"name"`
+	To   []Regulator `json:"to"`
+}
+
+// Rejection is a single payload item refused by the fake
+type Rejection struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+// Batch is a push request as received by the fake, with the outcome it was given
+type Batch struct {
+	DataElementID string                         `json:"data_element_id"`
+	ReceivedAt    time.Time                      `json:"received_at"`
+	StatusCode    int                            `json:"status_code"`
+	Participants  []sgbuildex.ParticipantWrapper `json:"participants"`
+	OnBehalfOf    []sgbuildex.OnBehalfWrapper    `json:"on_behalf_of,omitempty"`
+	Payload       []map[string]any               `json:"payload"`
+	Rejected      []Rejection                    `json:"rejected,omitempty"`
+}
+
+// RejectFunc decides whether a payload item is rejected; it returns a reason or "".
+type RejectFunc func(dataElementID string, item map[string]any) string
+
+// Fake is the Pitstop API handler and its recorded state. It is safe for concurrent use.
+type Fake struct {
+	mu            sync.Mutex
+	apiKey        string
+	produces      []Produce
+	batches       []Batch
+	failures      []int
+	latency       time.Duration
+	reject        RejectFunc
+	partialStatus int
+}
+
+// New creates a Fake that accepts the given API key. An empty key disables the key check.
+func New(apiKey string) *Fake {
+	return &Fake{
+		apiKey:        apiKey,
+		produces:      DefaultProduces(),
+		partialStatus: http.StatusUnprocessableEntity,
+	}
+}
+
+// DefaultProduces returns a config with manpower_utilization routed to BCA on behalf of one main contractor.
+func DefaultProduces() []Produce {
+	return []Produce{
+		{
+			ID:   "manpower_utilization",
+			Name: "Manpower Utilization",
+			To: []Regulator{
+				{
+					ID:         "REG-BCA",
+					Name:       "BCA",
+					OnBehalfOf: []OnBehalfOf{{ID: "200000000A", Name: "Fake Main Contractor Pte Ltd"}},
+				},
+			},
+		},
+	}
+}
+
+// SetProduces replaces the routing config served from /api/v1/config.
+func (f *Fake) SetProduces(produces []Produce) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.produces = produces
+}
+
+// FailNext makes the next n push requests return the given HTTP status (e.g. 429 or 500).
+// Calls queue up, so FailNext(429, 2) followed by FailNext(500, 1) fails three pushes in order.
+func (f *Fake) FailNext(status, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := 0; i < n; i++ {
+		f.failures = append(f.failures, status)
+	}
+}
+
+// SetLatency delays every response by d.
+func (f *Fake) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// RejectItems enables item rejection. Items for which fn returns a reason are refused and the
+// batch is answered with status (422 if status is 0). An error status (4xx/5xx) refuses the whole
+// batch, as the real API does; with a 2xx status the remaining items are accepted.
+func (f *Fake) RejectItems(status int, fn RejectFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if status == 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	f.reject = fn
+	f.partialStatus = status
+}
+
+// RejectPersonIDs is a RejectFunc that refuses items whose person_id_no is in ids.
+func RejectPersonIDs(ids ...string) RejectFunc {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return func(_ string, item map[string]any) string {
+		if id, _ := item["person_id_no"].(string); set[id] {
+			return fmt.Sprintf("person_id_no %s rejected", id)
+		}
+		return ""
+	}
+}
+
+// Batches returns a copy of every push request received so far, in arrival order.
+func (f *Fake) Batches() []Batch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]Batch, len(f.batches))
+	copy(out, f.batches)
+	return out
+}
+
+// AcceptedItems returns every payload item that was accepted, across all batches.
+func (f *Fake) AcceptedItems() []map[string]any {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []map[string]any
+	for _, b := range f.batches {
+		if b.StatusCode >= 400 {
+			continue
+		}
+		rejected := make(map[int]bool, len(b.Rejected))
+		for _, r := range b.Rejected {
+			rejected[r.Index] = true
+		}
+		for i, item := range b.Payload {
+			if !rejected[i] {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// Reset clears recorded batches and every scripted failure mode.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.batches = nil
+	f.failures = nil
+	f.latency = 0
+	f.reject = nil
+	f.partialStatus = http.StatusUnprocessableEntity
+}
+
+// ServeHTTP implements http.Handler.
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	latency := f.latency
+	f.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if !f.authorised(r) {
+		writeJSON(w, http.StatusUnauthorized, map[string]any{"status": "error", "message": "invalid or missing SGTRADEX-API-KEY"})
+		return
+	}
+
+	switch {
+	case r.URL.Path == configPath && r.Method == http.MethodGet:
+		f.handleConfig(w)
+	case strings.HasPrefix(r.URL.Path, pushPrefix) && r.Method == http.MethodPost:
+		f.handlePush(w, r, strings.TrimPrefix(r.URL.Path, pushPrefix))
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": "not found"})
+	}
+}
+
+func (f *Fake) authorised(r *http.Request) bool {
+	if f.apiKey == "" {
+		return true
+	}
+	return r.Header.Get("SGTRADEX-API-KEY") == f.apiKey
+}
+
+func (f *Fake) handleConfig(w http.ResponseWriter) {
+	f.mu.Lock()
+	produces := f.produces
+	f.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"data":      map[string]any{"produces": produces},
+	})
+}
+
+func (f *Fake) handlePush(w http.ResponseWriter, r *http.Request, dataElementID string) {
+	if !f.knownDataElement(dataElementID) {
+		writeJSON(w, http.StatusNotFound, map[string]any{"status": "error", "message": fmt.Sprintf("unknown data element %q", dataElementID)})
+		return
+	}
+
+	var req struct {
+		Participants []sgbuildex.ParticipantWrapper `json:"participants"`
+		Payload      []map[string]any               `json:"payload"`
+		OnBehalfOf   []sgbuildex.OnBehalfWrapper    `json:"on_behalf_of"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "invalid JSON: " + err.Error()})
+		return
+	}
+
+	batch := Batch{
+		DataElementID: dataElementID,
+		ReceivedAt:    time.Now(),
+		Participants:  req.Participants,
+		OnBehalfOf:    req.OnBehalfOf,
+		Payload:       req.Payload,
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Scripted failures take precedence so retry paths can be exercised with valid payloads
+	if len(f.failures) > 0 {
+		status := f.failures[0]
+		f.failures = f.failures[1:]
+		batch.StatusCode = status
+		f.batches = append(f.batches, batch)
+		if status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeJSON(w, status, map[string]any{"status": "error", "message": http.StatusText(status)})
+		return
+	}
+
+	if errs := validatePush(dataElementID, req.Participants, req.Payload); len(errs) > 0 {
+		batch.StatusCode = http.StatusBadRequest
+		f.batches = append(f.batches, batch)
+		writeJSON(w, http.StatusBadRequest, map[string]any{"status": "error", "message": "schema validation failed", "errors": errs})
+		return
+	}
+
+	if f.reject != nil {
+		for i, item := range req.Payload {
+			if reason := f.reject(dataElementID, item); reason != "" {
+				batch.Rejected = append(batch.Rejected, Rejection{Index: i, Reason: reason})
+			}
+		}
+	}
+
+	if len(batch.Rejected) > 0 {
+		batch.StatusCode = f.partialStatus
+		f.batches = append(f.batches, batch)
+		result, accepted := "partial", len(req.Payload)-len(batch.Rejected)
+		if f.partialStatus >= 400 {
+			// An error status refuses the batch, so none of its items are accepted
+			result, accepted = "error", 0
+		}
+		writeJSON(w, f.partialStatus, map[string]any{
+			"status":   result,
+			"accepted": accepted,
+			"rejected": batch.Rejected,
+		})
+		return
+	}
+
+	batch.StatusCode = http.StatusOK
+	f.batches = append(f.batches, batch)
+	writeJSON(w, http.StatusOK, map[string]any{"status": "success", "accepted": len(req.Payload)})
+}
+
+func (f *Fake) knownDataElement(id string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, p := range f.produces {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// Server is a Fake listening on a local httptest server.
+type Server struct {
+	*Fake
+	URL string
+
+	srv *httptest.Server
+}
+
+// NewServer starts a Fake on a random local port. Point sgbuildex.NewClient at Server.URL.
+func NewServer(apiKey string) *Server {
+	f := New(apiKey)
+	srv := httptest.NewServer(f)
+	return &Server{Fake: f, URL: srv.URL, srv: srv}
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
@@ -0,0 +1,236 @@
+package pitstoptest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/adapters/external/sgbuildex"
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSubmissionRepo captures the status the submitter writes back for each row.
+type recordingSubmissionRepo struct {
+	ports.SubmissionRepository
+
+	mu        sync.Mutex
+	submitted []string
+	failed    map[string]bool // attendance ID -> retryable
+}
+
+func (r *recordingSubmissionRepo) LogSubmission(ctx context.Context, dataElementID, internalID, status, payload, errorMessage string) error {
+	return nil
+}
+
+func (r *recordingSubmissionRepo) UpdateAttendanceStatus(ctx context.Context, attendanceID, status, responsePayload, errorMessage string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.submitted = append(r.submitted, attendanceID)
+	return nil
+}
+
+func (r *recordingSubmissionRepo) RecordAttendanceFailure(ctx context.Context, attendanceID, responsePayload, errorMessage string, retryable bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed == nil {
+		r.failed = make(map[string]bool)
+	}
+	r.failed[attendanceID] = retryable
+	return nil
+}
+
+func testRows() []domain.AttendanceRow {
+	now := time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC)
+	row := func(id, fin string) domain.AttendanceRow {
+		return domain.AttendanceRow{
+			AttendanceID:       id,
+			RegulatorID:        "REG-BCA",
+			RegulatorName:      "Other",
+			OnBehalfOfID:       "200000000A",
+			SubmissionEntity:   1,
+			SubmissionDate:     now,
+			WorkerFIN:          fin,
+			WorkerWorkPassType: "WP",
+			WorkerTrade:        "2.3",
+			EmployerName:       "Employer",
+			EmployerUEN:        "11111111A",
+			TimeIn:             now,
+			ProjectRef:         "REF-1",
+		}
+	}
+	return []domain.AttendanceRow{row("ATT-1", "G1234567P"), row("ATT-2", "G7654321Q")}
+}
+
+func newClient(url, apiKey string) *sgbuildex.Client {
+	c := sgbuildex.NewClient(url, url)
+	c.APIKey = apiKey
+	return c
+}
+
+func TestFetchConfig(t *testing.T) {
+	srv := NewServer("secret")
+	defer srv.Close()
+
+	resp, err := newClient(srv.URL, "secret").FetchPitstopConfig(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resp.Produces, 1)
+	assert.Equal(t, "manpower_utilization", resp.Produces[0].ID)
+	assert.Equal(t, "200000000A", resp.Produces[0].To[0].OnBehalfOf[0].ID)
+
+	_, err = newClient(srv.URL, "wrong").FetchPitstopConfig(context.Background())
+	assert.Error(t, err)
+}
+
+func TestSubmitManpowerUtilization(t *testing.T) {
+	tests := []struct {
+		name          string
+		script        func(f *Fake)
+		wantSubmitted []string
+		wantFailed    map[string]bool
+		wantStatus    int
+	}{
+		{
+			name:          "accepted",
+			script:        func(f *Fake) {},
+			wantSubmitted: []string{"ATT-1", "ATT-2"},
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "rate limited is retryable",
+			script:     func(f *Fake) { f.FailNext(http.StatusTooManyRequests, 1) },
+			wantFailed: map[string]bool{"ATT-1": true, "ATT-2": true},
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:       "server error is retryable",
+			script:     func(f *Fake) { f.FailNext(http.StatusInternalServerError, 1) },
+			wantFailed: map[string]bool{"ATT-1": true, "ATT-2": true},
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "partial rejection fails the batch permanently",
+			script:     func(f *Fake) { f.RejectItems(0, RejectPersonIDs("G7654321Q")) },
+			wantFailed: map[string]bool{"ATT-1": false, "ATT-2": false},
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer("secret")
+			defer srv.Close()
+			tt.script(srv.Fake)
+
+			repo := &recordingSubmissionRepo{}
+			settings := &domain.SystemSettings{MaxWorkersPerRequest: 10}
+			_, _, err := newClient(srv.URL, "secret").SubmitManpowerUtilization(context.Background(), repo, settings, testRows())
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantSubmitted, repo.submitted)
+			if tt.wantFailed != nil {
+				assert.Equal(t, tt.wantFailed, repo.failed)
+			}
+
+			batches := srv.Batches()
+			require.Len(t, batches, 1)
+			assert.Equal(t, tt.wantStatus, batches[0].StatusCode)
+			assert.Len(t, batches[0].Payload, 2)
+			assert.Len(t, srv.AcceptedItems(), len(tt.wantSubmitted))
+		})
+	}
+}
+
+func TestRejectItems_StatusMatchesItemResults(t *testing.T) {
+	var wrappers []sgbuildex.ManpowerUtilizationWrapper
+	for _, p := range sgbuildex.MapAttendanceToManpower(testRows()).Payloads {
+		wrappers = append(wrappers, sgbuildex.ManpowerUtilizationWrapper{ManpowerUtilization: p})
+	}
+	batches, _ := sgbuildex.BuildBatches(context.Background(), &domain.SystemSettings{}, wrappers)
+	require.Len(t, batches, 1)
+
+	push := func(t *testing.T, srv *Server) map[string]any {
+		resp, err := newClient(srv.URL, "secret").PostJSON("api/v1/data/push/manpower_utilization", batches[0].Request)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return body
+	}
+
+	t.Run("error status accepts nothing", func(t *testing.T) {
+		srv := NewServer("secret")
+		defer srv.Close()
+		srv.RejectItems(http.StatusUnprocessableEntity, RejectPersonIDs("G7654321Q"))
+
+		body := push(t, srv)
+		assert.Equal(t, "error", body["status"])
+		assert.EqualValues(t, 0, body["accepted"])
+		assert.Empty(t, srv.AcceptedItems())
+	})
+
+	t.Run("success status accepts the rest", func(t *testing.T) {
+		srv := NewServer("secret")
+		defer srv.Close()
+		srv.RejectItems(http.StatusMultiStatus, RejectPersonIDs("G7654321Q"))
+
+		body := push(t, srv)
+		assert.Equal(t, "partial", body["status"])
+		assert.EqualValues(t, 1, body["accepted"])
+		require.Len(t, srv.AcceptedItems(), 1)
+		assert.Equal(t, "G1234567P", srv.AcceptedItems()[0]["person_id_no"])
+	})
+}
+
+func TestPushValidation(t *testing.T) {
+	srv := NewServer("secret")
+	defer srv.Close()
+	c := newClient(srv.URL, "secret")
+
+	post := func(endpoint, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+endpoint, strings.NewReader(body))
+		req.Header.Set("SGTRADEX-API-KEY", c.APIKey)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusNotFound, post("/api/v1/data/push/unknown", `{}`))
+	assert.Equal(t, http.StatusBadRequest, post("/api/v1/data/push/manpower_utilization", `not json`))
+	assert.Equal(t, http.StatusBadRequest, post("/api/v1/data/push/manpower_utilization",
+		`{"participants":[{"id":"REG-BCA"}],"payload":[{"submission_month":"2026-3"}]}`))
+
+	resp, err := c.PostJSON("api/v1/data/push/manpower_utilization", map[string]any{})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	noKey := newClient(srv.URL, "")
+	resp, err = noKey.PostJSON("api/v1/data/push/manpower_utilization", map[string]any{})
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestLatency(t *testing.T) {
+	srv := NewServer("")
+	defer srv.Close()
+	srv.SetLatency(50 * time.Millisecond)
+
+	c := newClient(srv.URL, "")
+	c.HTTPClient.Timeout = 10 * time.Millisecond
+	_, err := c.FetchPitstopConfig(context.Background())
+	assert.Error(t, err, "request should time out behind the configured latency")
+
+	c.HTTPClient.Timeout = time.Second
+	_, err = c.FetchPitstopConfig(context.Background())
+	assert.NoError(t, err)
+}
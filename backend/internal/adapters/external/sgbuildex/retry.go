@@ -0,0 +1,17 @@
+package sgbuildex
+
+import "net/http"
+
+// isRetryableStatus reports whether an SGBuildex HTTP status indicates a transient failure.
+// Rate limiting, timeouts and server errors are retried; other 4xx responses mean the
+// payload itself was rejected and resubmitting it unchanged cannot succeed.
+func isRetryableStatus(code int) bool {
+	switch {
+	case code == http.StatusTooManyRequests, code == http.StatusRequestTimeout:
+		return true
+	case code >= 500:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,26 @@
+package sgbuildex
+
+import "testing"
+
+func TestIsRetryableStatus(t *testing.T) {
+	tests := []struct {
+		code     int
+		expected bool
+	}{
+		{408, true},
+		{429, true},
+		{500, true},
+		{502, true},
+		{503, true},
+		{400, false},
+		{401, false},
+		{404, false},
+		{422, false},
+	}
+
+	for _, tt := range tests {
+		if res := isRetryableStatus(tt.code); res != tt.expected {
+			t.Errorf("isRetryableStatus(%d) = %v; want %v", tt.code, res, tt.expected)
+		}
+	}
+}
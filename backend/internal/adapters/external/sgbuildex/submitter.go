@@ -19,16 +19,22 @@ type Submittable interface {
 	GetInternalID() string
 }
 
-// SubmitPayloads submissions any submittable payloads to SGBuildex in batches.
-// It respects the MaxWorkersPerRequest and MaxPayloadSizeKB settings.
-// Returns the total number of items successfully pushed (status='submitted').
-func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRepository, client *Client, settings *domain.SystemSettings, submittables []T) (int, error) {
-	totalSubmitted := 0
-	if len(submittables) == 0 {
-		return 0, nil
-	}
+// Batch is one push request assembled from submittables within the configured limits
+type Batch struct {
+	Request      *PushRequest
+	IDs          []string
+	ItemPayloads map[string]string // request payload per internal ID, for logging
+	SizeBytes    int
+}
 
-	dataElementID := submittables[0].DataElementID()
+// SkippedItem is a submittable that could not be placed into any batch
+type SkippedItem struct {
+	ID     string
+	Reason string
+}
+
+// batchLimits resolves the batch size and payload byte limits from settings, applying defaults.
+func batchLimits(settings *domain.SystemSettings) (int, int) {
 	maxBatchSize := settings.MaxWorkersPerRequest
 	if maxBatchSize <= 0 {
 		maxBatchSize = 100
@@ -37,6 +43,21 @@ func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRep
 	if limitBytes <= 0 {
 		limitBytes = 256 * 1024
 	}
+	return maxBatchSize, limitBytes
+}
+
+// BuildBatches groups submittables into push requests respecting MaxWorkersPerRequest and
+// MaxPayloadSizeKB. It performs no I/O, so it is shared by submission and dry-run preview.
+func BuildBatches[T Submittable](ctx context.Context, settings *domain.SystemSettings, submittables []T) ([]Batch, []SkippedItem) {
+	if len(submittables) == 0 {
+		return nil, nil
+	}
+
+	dataElementID := submittables[0].DataElementID()
+	maxBatchSize, limitBytes := batchLimits(settings)
+
+	var batches []Batch
+	var skipped []SkippedItem
 
 	totalItems := len(submittables)
 	for i := 0; i < totalItems; {
@@ -52,6 +73,7 @@ func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRep
 			req, err := s.ToPushRequest(ctx)
 			if err != nil {
 				logger.Infof("[SGBuildex] Failed to prepare %s for %s: %v", dataElementID, s.GetInternalID(), err)
+				skipped = append(skipped, SkippedItem{ID: s.GetInternalID(), Reason: err.Error()})
 				i++
 				continue
 			}
@@ -94,6 +116,8 @@ func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRep
 				if len(batchIDs) == 0 {
 					// Single item above limit - skip and log
 					logger.Infof("[SGBuildex] CRITICAL: Single item for %s is already above size limit (%d > %d bytes). Skipping.", s.GetInternalID(), len(jsonBytes), limitBytes)
+					skipped = append(skipped, SkippedItem{ID: s.GetInternalID(), Reason: fmt.Sprintf("item exceeds payload size limit (%d > %d bytes)", len(jsonBytes), limitBytes)})
+					delete(itemRequestPayloads, s.GetInternalID())
 					i++
 					continue
 				}
@@ -119,22 +143,53 @@ func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRep
 			Payload:      batchPayload,
 			OnBehalfOf:   batchOnBehalf,
 		}
-		fullJSON, _ := json.MarshalIndent(finalReq, "", "  ")
+		// Measure the body exactly as PostJSON will send it
+		fullJSON, _ := json.Marshal(finalReq)
+
+		batches = append(batches, Batch{
+			Request:      finalReq,
+			IDs:          batchIDs,
+			ItemPayloads: itemRequestPayloads,
+			SizeBytes:    len(fullJSON),
+		})
+	}
+
+	return batches, skipped
+}
+
+// SubmitPayloads submissions any submittable payloads to SGBuildex in batches.
+// It respects the MaxWorkersPerRequest and MaxPayloadSizeKB settings.
+// Returns the total number of items successfully pushed (status='submitted').
+func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRepository, client *Client, settings *domain.SystemSettings, submittables []T) (int, error) {
+	totalSubmitted := 0
+	if len(submittables) == 0 {
+		return 0, nil
+	}
+
+	dataElementID := submittables[0].DataElementID()
+	batches, _ := BuildBatches(ctx, settings, submittables)
+
+	for bi, batch := range batches {
+		batchIDs := batch.IDs
+		itemRequestPayloads := batch.ItemPayloads
 
-		logger.Infof("[SGBuildex] Submitting batch of %d items for %s (Size: %d bytes)", len(batchIDs), dataElementID, len(fullJSON))
+		logger.Infof("[SGBuildex] Submitting batch of %d items for %s (Size: %d bytes)", len(batchIDs), dataElementID, batch.SizeBytes)
 		// Log of full JSON payload removed to prevent PII leakage in application logs (#4)
 
 		// Execute submission in a closure to ensure `defer resp.Body.Close()` runs per iteration
 		func() {
-			resp, err := client.PostJSON(fmt.Sprintf("api/v1/data/push/%s", dataElementID), finalReq)
+			resp, err := client.PostJSON(fmt.Sprintf("api/v1/data/push/%s", dataElementID), batch.Request)
 
 			status := "submitted"
 			errorMessage := ""
+			retryable := false
 			var responsePayload string // NEW: capture response for DB storage
 
 			if err != nil {
+				// Network-level failures (DNS, connection reset, timeout) are transient
 				status = "failed"
 				errorMessage = err.Error()
+				retryable = true
 				logger.Infof("[SGBuildex] Batch submission failed: %v", err)
 			} else {
 				defer resp.Body.Close()
@@ -144,7 +199,8 @@ func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRep
 				if resp.StatusCode >= 400 {
 					status = "failed"
 					errorMessage = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, responsePayload)
-					logger.Infof("[SGBuildex] Batch submission returned error: %s", errorMessage)
+					retryable = isRetryableStatus(resp.StatusCode)
+					logger.Infof("[SGBuildex] Batch submission returned error (retryable: %t): %s", retryable, errorMessage)
 				} else {
 					totalSubmitted += len(batchIDs)
 				}
@@ -158,13 +214,17 @@ func SubmitPayloads[T Submittable](ctx context.Context, repo ports.SubmissionRep
 
 				// Store the general RESPONSE payload in the source table
 				if dataElementID == "manpower_utilization" {
-					repo.UpdateAttendanceStatus(ctx, id, status, responsePayload, errorMessage)
+					if status == "failed" {
+						repo.RecordAttendanceFailure(ctx, id, responsePayload, errorMessage, retryable)
+					} else {
+						repo.UpdateAttendanceStatus(ctx, id, status, responsePayload, errorMessage)
+					}
 				}
 			}
 		}()
 
 		// Rate limiting safety: if we have more batches, wait a bit
-		if i < totalItems && settings.MaxRequestsPerMinute > 0 {
+		if bi < len(batches)-1 && settings.MaxRequestsPerMinute > 0 {
 			sleepDuration := time.Minute / time.Duration(settings.MaxRequestsPerMinute)
 			time.Sleep(sleepDuration)
 		}
@@ -0,0 +1,122 @@
+package sgbuildex
+
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func previewRow(id, regulatorID, onBehalfOfID, projectRef string, now time.Time) domain.AttendanceRow {
+	return domain.AttendanceRow{
+		AttendanceID:       id,
+		RegulatorID:        regulatorID,
+		RegulatorName:      "Other",
+		OnBehalfOfID:       onBehalfOfID,
+		SubmissionEntity:   1,
+		SubmissionDate:     now,
+		WorkerFIN:          "G1234567P",
+		WorkerWorkPassType: "WP",
+		WorkerTrade:        "2.3",
+		EmployerName:       "Valid Employer",
+		EmployerUEN:        "11111111A",
+		TimeIn:             now,
+		ProjectRef:         projectRef,
+	}
+}
+
+func TestBuildBatches(t *testing.T) {
+	now := time.Now()
+	rows := make([]domain.AttendanceRow, 0, 5)
+	for i := 1; i <= 5; i++ {
+		rows = append(rows, previewRow(fmt.Sprintf("ATT-%d", i), "REG-1", "OB-1", "REF-1", now))
+	}
+	wrappers := make([]ManpowerUtilizationWrapper, 0, len(rows))
+	for _, p := range MapAttendanceToManpower(rows).Payloads {
+		wrappers = append(wrappers, ManpowerUtilizationWrapper{ManpowerUtilization: p})
+	}
+
+	tests := []struct {
+		name         string
+		settings     *domain.SystemSettings
+		batchSizes   []int
+		skippedCount int
+	}{
+		{"defaults fit everything in one batch", &domain.SystemSettings{}, []int{5}, 0},
+		{"count limit splits batches", &domain.SystemSettings{MaxWorkersPerRequest: 2}, []int{2, 2, 1}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batches, skipped := BuildBatches(context.Background(), tt.settings, wrappers)
+			var sizes []int
+			for _, b := range batches {
+				sizes = append(sizes, len(b.IDs))
+				body, err := json.Marshal(b.Request)
+				require.NoError(t, err)
+				assert.Equal(t, len(body), b.SizeBytes)
+				assert.Len(t, b.ItemPayloads, len(b.IDs))
+			}
+			assert.Equal(t, tt.batchSizes, sizes)
+			assert.Len(t, skipped, tt.skippedCount)
+		})
+	}
+}
+
+func TestPreviewManpowerUtilization_DoesNotCallPitstop(t *testing.T) {
+	called := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	rows := []domain.AttendanceRow{
+		previewRow("ATT-1", "REG-1", "OB-1", "REF-1", now),
+		{AttendanceID: "ATT-2"}, // missing mandatory fields
+		previewRow("ATT-3", "REG-1", "OB-2", "REF-1", now),
+		previewRow("ATT-4", "REG-1", "OB-1", "REF-1", now),
+	}
+
+	client := NewClient(srv.URL, srv.URL)
+	settings := &domain.SystemSettings{MaxWorkersPerRequest: 2}
+
+	preview, err := client.PreviewManpowerUtilization(context.Background(), settings, rows)
+	require.NoError(t, err)
+	assert.False(t, called, "preview must not call Pitstop")
+
+	assert.Equal(t, "manpower_utilization", preview.DataElementID)
+	assert.Equal(t, 4, preview.TotalRows)
+	assert.Equal(t, 3, preview.ValidRows)
+	assert.Equal(t, 2, preview.MaxItemsPerBatch)
+	assert.Equal(t, 256*1024, preview.MaxPayloadBytes)
+
+	require.Len(t, preview.Failures, 1)
+	assert.Equal(t, "ATT-2", preview.Failures[0].AttendanceID)
+	assert.Contains(t, preview.Failures[0].Error, "person_id_no")
+
+	require.Len(t, preview.Batches, 2)
+	first := preview.Batches[0]
+	assert.Equal(t, 1, first.Index)
+	assert.Equal(t, []string{"ATT-1", "ATT-3"}, first.AttendanceIDs)
+	assert.Equal(t, []string{"OB-1", "OB-2"}, first.OnBehalfOf)
+	require.Len(t, first.Groups, 2)
+	assert.Equal(t, "REG-1", first.Groups[0].ParticipantID)
+	assert.Equal(t, "REF-1", first.Groups[0].DataRefID)
+	assert.Equal(t, "OB-1", first.Groups[0].OnBehalfOfID)
+	assert.Equal(t, []string{"ATT-1"}, first.Groups[0].AttendanceIDs)
+	assert.Equal(t, "OB-2", first.Groups[1].OnBehalfOfID)
+	assert.True(t, strings.Contains(string(first.Request), `"participants"`))
+	assert.Greater(t, first.SizeBytes, 0)
+
+	assert.Equal(t, []string{"ATT-4"}, preview.Batches[1].AttendanceIDs)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"cpd-nexus/internal/pkg/timeutil"
 )
 
 // Ptr returns a pointer to the trimmed string, or nil if the string is empty or "null" (case-insensitive).
@@ -30,12 +32,12 @@ func ptrIntOrDefault(v int, def int) *int {
 	return &v
 }
 
-// FormatOptionalTime formats a nullable time pointer to RFC3339, returning nil if input is nil.
+// FormatOptionalTime formats a nullable time pointer to RFC3339 in the business timezone, returning nil if input is nil.
 func FormatOptionalTime(t *time.Time) *string {
 	if t == nil {
 		return nil
 	}
-	s := t.Format(time.RFC3339)
+	s := timeutil.InBusiness(*t).Format(time.RFC3339)
 	return &s
 }
 
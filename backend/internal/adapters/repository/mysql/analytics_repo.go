@@ -17,15 +17,10 @@ func NewAnalyticsRepository(db *sql.DB) ports.AnalyticsRepository {
 	return &AnalyticsRepository{db: db}
 }
 
+// GetDashboardStats counts the workers, sites, projects and devices of the tenants the caller may see.
+// userID narrows a vendor's view to one organisation; empty means the caller's whole scope.
 func (r *AnalyticsRepository) GetDashboardStats(ctx context.Context, userID string) (map[string]interface{}, error) {
-	isVendor := ports.IsVendor(ctx)
-	contextUserID := ports.GetUserID(ctx)
-
-	// Security/Scope logic
-	queryUserID := userID
-	if !isVendor {
-		queryUserID = contextUserID
-	}
+	cond, args := tenantCondition(ctx, "user_id", userID)
 
 	response := map[string]interface{}{
 		"total_workers":   0,
@@ -38,25 +33,14 @@ func (r *AnalyticsRepository) GetDashboardStats(ctx context.Context, userID stri
 	var totalWorkers, activeSites, activeProjects, totalDevices int
 	var err error
 
-	if queryUserID == "all" || queryUserID == "tenant-vendor-1" {
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM workers WHERE status = ?", domain.StatusActive).Scan(&totalWorkers)
-		if err != nil { return nil, err }
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sites").Scan(&activeSites)
-		if err != nil { return nil, err }
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM projects WHERE status= ?", domain.StatusActive).Scan(&activeProjects)
-		if err != nil { return nil, err }
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM devices").Scan(&totalDevices)
-		if err != nil { return nil, err }
-	} else {
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM workers WHERE status = ? AND user_id = ?", domain.StatusActive, queryUserID).Scan(&totalWorkers)
-		if err != nil { return nil, err }
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sites WHERE user_id = ?", queryUserID).Scan(&activeSites)
-		if err != nil { return nil, err }
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM projects WHERE status= ? AND user_id = ?", domain.StatusActive, queryUserID).Scan(&activeProjects)
-		if err != nil { return nil, err }
-		err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM devices WHERE user_id = ?", queryUserID).Scan(&totalDevices)
-		if err != nil { return nil, err }
-	}
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM workers WHERE status = ?"+cond, append([]interface{}{domain.StatusActive}, args...)...).Scan(&totalWorkers)
+	if err != nil { return nil, err }
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sites WHERE 1=1"+cond, args...).Scan(&activeSites)
+	if err != nil { return nil, err }
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM projects WHERE status= ?"+cond, append([]interface{}{domain.StatusActive}, args...)...).Scan(&activeProjects)
+	if err != nil { return nil, err }
+	err = r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM devices WHERE 1=1"+cond, args...).Scan(&totalDevices)
+	if err != nil { return nil, err }
 
 	response["total_workers"] = totalWorkers
 	response["active_sites"] = activeSites
@@ -66,25 +50,14 @@ func (r *AnalyticsRepository) GetDashboardStats(ctx context.Context, userID stri
 	return response, nil
 }
 
+// GetDetailedAnalytics returns distributions and trends for the tenants the caller may see (see GetDashboardStats).
 func (r *AnalyticsRepository) GetDetailedAnalytics(ctx context.Context, userID string) (map[string]interface{}, error) {
-	isVendor := ports.IsVendor(ctx)
-	contextUserID := ports.GetUserID(ctx)
-
-	queryUserID := userID
-	if !isVendor {
-		queryUserID = contextUserID
-	}
+	cond, args := tenantCondition(ctx, "user_id", userID)
 
 	response := make(map[string]interface{})
 
 	// 1. Worker Distribution by Trade
-	var tradeRows *sql.Rows
-	var err error
-	if queryUserID == "all" || queryUserID == "tenant-vendor-1" {
-		tradeRows, err = r.db.QueryContext(ctx, "SELECT person_trade, COUNT(*) FROM workers WHERE status = ? GROUP BY person_trade", domain.StatusActive)
-	} else {
-		tradeRows, err = r.db.QueryContext(ctx, "SELECT person_trade, COUNT(*) FROM workers WHERE status = ? AND user_id = ? GROUP BY person_trade", domain.StatusActive, queryUserID)
-	}
+	tradeRows, err := r.db.QueryContext(ctx, "SELECT person_trade, COUNT(*) FROM workers WHERE status = ?"+cond+" GROUP BY person_trade", append([]interface{}{domain.StatusActive}, args...)...)
 	if err == nil {
 		defer tradeRows.Close()
 		trades := make(map[string]int)
@@ -103,12 +76,7 @@ func (r *AnalyticsRepository) GetDetailedAnalytics(ctx context.Context, userID s
 	}
 
 	// 2. Worker Status Distribution
-	var statusRows *sql.Rows
-	if queryUserID == "all" || queryUserID == "tenant-vendor-1" {
-		statusRows, err = r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM workers GROUP BY status")
-	} else {
-		statusRows, err = r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM workers WHERE user_id = ? GROUP BY status", queryUserID)
-	}
+	statusRows, err := r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM workers WHERE 1=1"+cond+" GROUP BY status", args...)
 	if err == nil {
 		defer statusRows.Close()
 		statuses := make(map[string]int)
@@ -123,12 +91,7 @@ func (r *AnalyticsRepository) GetDetailedAnalytics(ctx context.Context, userID s
 	}
 
 	// 3. Device Status Distribution
-	var deviceRows *sql.Rows
-	if queryUserID == "all" || queryUserID == "tenant-vendor-1" {
-		deviceRows, err = r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM devices GROUP BY status")
-	} else {
-		deviceRows, err = r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM devices WHERE user_id = ? GROUP BY status", queryUserID)
-	}
+	deviceRows, err := r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM devices WHERE 1=1"+cond+" GROUP BY status", args...)
 	if err == nil {
 		defer deviceRows.Close()
 		dStatuses := make(map[string]int)
@@ -150,15 +113,9 @@ func (r *AnalyticsRepository) GetDetailedAnalytics(ctx context.Context, userID s
 		SELECT DAYNAME(created_at) as day_name, COUNT(*) 
 		FROM attendance 
 		WHERE created_at >= DATE_SUB(NOW(), INTERVAL 7 DAY)`
-	
-	var trendArgs []interface{}
-	if queryUserID != "all" && queryUserID != "tenant-vendor-1" && queryUserID != "" {
-		trendQuery += " AND user_id = ?"
-		trendArgs = append(trendArgs, queryUserID)
-	}
-	trendQuery += " GROUP BY day_name"
+	trendQuery += cond + " GROUP BY day_name"
 
-	trendRows, err := r.db.QueryContext(ctx, trendQuery, trendArgs...)
+	trendRows, err := r.db.QueryContext(ctx, trendQuery, args...)
 	if err == nil {
 		defer trendRows.Close()
 		for trendRows.Next() {
@@ -180,26 +137,15 @@ func (r *AnalyticsRepository) GetDetailedAnalytics(ctx context.Context, userID s
 	return response, nil
 }
 
+// GetActivityLog returns the latest activity of the tenants the caller may see (see GetDashboardStats).
 func (r *AnalyticsRepository) GetActivityLog(ctx context.Context, userID string, filters map[string]interface{}) ([]map[string]interface{}, error) {
 	var rows *sql.Rows
 	var err error
 
-	isVendor := ports.IsVendor(ctx)
-	contextUserID := ports.GetUserID(ctx)
-
-	queryUserID := userID
-	if !isVendor {
-		queryUserID = contextUserID
-	}
-
-	query := `SELECT id, user_id, user_name, action, target_type, target_id, details, created_at 
+	query := `SELECT id, user_id, member_id, impersonator_user_id, impersonation_id, user_name, action, target_type, target_id, details, created_at 
 			 FROM activity_logs WHERE 1=1`
-	var args []interface{}
-
-	if !isVendor || (queryUserID != "" && queryUserID != "all" && queryUserID != "tenant-vendor-1") {
-		query += ` AND user_id = ?`
-		args = append(args, queryUserID)
-	}
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query += cond
 
 	if action, ok := filters["action"].(string); ok && action != "" {
 		query += ` AND action = ?`
@@ -211,6 +157,16 @@ func (r *AnalyticsRepository) GetActivityLog(ctx context.Context, userID string,
 		args = append(args, targetType)
 	}
 
+	if memberID, ok := filters["member_id"].(string); ok && memberID != "" {
+		query += ` AND member_id = ?`
+		args = append(args, memberID)
+	}
+
+	if impersonationID, ok := filters["impersonation_id"].(string); ok && impersonationID != "" {
+		query += ` AND impersonation_id = ?`
+		args = append(args, impersonationID)
+	}
+
 	query += ` ORDER BY created_at DESC LIMIT 100`
 
 	rows, err = r.db.QueryContext(ctx, query, args...)
@@ -222,16 +178,17 @@ func (r *AnalyticsRepository) GetActivityLog(ctx context.Context, userID string,
 	logs := []map[string]interface{}{}
 	for rows.Next() {
 		var id int
-		var uid, uname, action, tType, tID, details sql.NullString
+		var uid, memberID, impersonatorID, impersonationID, uname, action, tType, tID, details sql.NullString
 		var createdAt sql.NullTime
 
-		if err := rows.Scan(&id, &uid, &uname, &action, &tType, &tID, &details, &createdAt); err != nil {
+		if err := rows.Scan(&id, &uid, &memberID, &impersonatorID, &impersonationID, &uname, &action, &tType, &tID, &details, &createdAt); err != nil {
 			return nil, err
 		}
 
 		log := map[string]interface{}{
 			"id":          id,
 			"user_id":     uid.String,
+			"member_id":   memberID.String,
 			"user_name":   uname.String,
 			"action":      action.String,
 			"target_type": tType.String,
@@ -240,6 +197,11 @@ func (r *AnalyticsRepository) GetActivityLog(ctx context.Context, userID string,
 			"time":        "Just now",
 		}
 
+		if impersonationID.Valid {
+			log["impersonator_user_id"] = impersonatorID.String
+			log["impersonation_id"] = impersonationID.String
+		}
+
 		if createdAt.Valid {
 			log["created_at"] = createdAt.Time
 			log["time"] = formatTimeAgo(createdAt.Time)
@@ -252,11 +214,17 @@ func (r *AnalyticsRepository) GetActivityLog(ctx context.Context, userID string,
 }
 
 func (r *AnalyticsRepository) LogActivity(ctx context.Context, log map[string]interface{}) error {
-	query := `INSERT INTO activity_logs (user_id, user_name, action, target_type, target_id, details, ip_address) 
-			  VALUES (?, ?, ?, ?, ?, ?, ?)`
+	query := `INSERT INTO activity_logs (user_id, member_id, impersonator_user_id, impersonation_id, user_name, action, target_type, target_id, details, ip_address) 
+			  VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
+	memberID, _ := log["member_id"].(string)
+	impersonatorID, _ := log["impersonator_user_id"].(string)
+	impersonationID, _ := log["impersonation_id"].(string)
 	_, err := r.db.ExecContext(ctx, query,
 		log["user_id"],
+		sql.NullString{String: memberID, Valid: memberID != ""},
+		sql.NullString{String: impersonatorID, Valid: impersonatorID != ""},
+		sql.NullString{String: impersonationID, Valid: impersonationID != ""},
 		log["user_name"],
 		log["action"],
 		log["target_type"],
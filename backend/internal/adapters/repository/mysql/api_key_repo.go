@@ -0,0 +1,133 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+)
+
+// apiKeyTouchInterval bounds how often last_used_at is written for a busy key
+const apiKeyTouchInterval = time.Minute
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) ports.APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyBaseSelect = `
+    SELECT
+        key_id, user_id, name, key_prefix, key_hash, scopes, expires_at, last_used_at,
+        last_used_ip, created_by, created_at, revoked_at
+    FROM api_keys`
+
+func (r *APIKeyRepository) Create(ctx context.Context, k *domain.APIKey) error {
+	scopes := make([]string, len(k.Scopes))
+	for i, s := range k.Scopes {
+		scopes[i] = string(s)
+	}
+
+	var expiresAt sql.NullTime
+	if k.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *k.ExpiresAt, Valid: true}
+	}
+
+	query := `
+		INSERT INTO api_keys (key_id, user_id, name, key_prefix, key_hash, scopes, expires_at, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		k.ID, k.UserID, k.Name, k.Prefix, k.KeyHash, strings.Join(scopes, ","), expiresAt,
+		sql.NullString{String: k.CreatedBy, Valid: k.CreatedBy != ""})
+	return err
+}
+
+func (r *APIKeyRepository) Get(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, apiKeyBaseSelect+" WHERE key_id = ?", id))
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, apiKeyBaseSelect+" WHERE key_hash = ?", keyHash))
+}
+
+func (r *APIKeyRepository) ListByUser(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, apiKeyBaseSelect+" WHERE user_id = ? ORDER BY created_at DESC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []domain.APIKey{}
+	for rows.Next() {
+		k, err := r.scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = ? WHERE key_id = ? AND revoked_at IS NULL", time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id, ip string, now time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE api_keys SET last_used_at = ?, last_used_ip = ?
+		WHERE key_id = ? AND (last_used_at IS NULL OR last_used_at < ? OR NOT (last_used_ip <=> ?))`,
+		now, sql.NullString{String: ip, Valid: ip != ""}, id, now.Add(-apiKeyTouchInterval), ip)
+	return err
+}
+
+func (r *APIKeyRepository) scanRow(scanner Scanner) (*domain.APIKey, error) {
+	var k domain.APIKey
+	var scopes string
+	var lastUsedIP, createdBy sql.NullString
+	var expiresAt, lastUsedAt, createdAt, revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &scopes, &expiresAt, &lastUsedAt,
+		&lastUsedIP, &createdBy, &createdAt, &revokedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k.Scopes = []domain.Permission{}
+	for _, s := range strings.Split(scopes, ",") {
+		if s != "" {
+			k.Scopes = append(k.Scopes, domain.Permission(s))
+		}
+	}
+	k.LastUsedIP = lastUsedIP.String
+	k.CreatedBy = createdBy.String
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if createdAt.Valid {
+		k.CreatedAt = createdAt.Time
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+	return &k, nil
+}
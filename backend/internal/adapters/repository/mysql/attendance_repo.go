@@ -3,6 +3,7 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -10,13 +11,14 @@ import (
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/timeutil"
 )
 
 // SQL fragments shared across extraction queries to avoid repetition.
 const (
 	attendanceSelectFields = `
 			a.attendance_id, a.device_id, a.worker_id, a.site_id, a.user_id,
-			a.time_in, a.time_out, a.direction, a.trade_code, a.status, a.submission_date,
+			a.time_in, a.time_out, a.direction, a.source, a.trade_code, a.status, a.submission_date,
 			s.site_name, s.location,
 			p.project_reference_number, p.project_title, p.project_location_description,
 			p.project_contract_number, p.project_contract_name, p.hdb_precinct_name,
@@ -44,26 +46,29 @@ func NewAttendanceRepository(db *sql.DB) ports.AttendanceRepository {
 	return &AttendanceRepository{db: db}
 }
 
-// Get retrieves a single attendance record by ID, scoped to the given user.
+// Get retrieves a single attendance record by ID within the caller's scope on userID.
 func (r *AttendanceRepository) Get(ctx context.Context, userID, id string) (*domain.Attendance, error) {
 	query := `
 		SELECT
 			a.attendance_id, a.device_id, a.worker_id, a.site_id, a.user_id,
-			a.time_in, a.time_out, a.direction, a.trade_code, a.status, a.submission_date,
+			a.time_in, a.time_out, a.open_until, a.direction, a.source, a.entered_by, a.entered_by_name,
+			a.flagged, a.trade_code, a.status, a.revision, a.submission_date,
 			w.name AS worker_name, s.site_name, a.created_at, a.updated_at
 		FROM attendance a
 		LEFT JOIN workers w ON a.worker_id = w.worker_id
 		LEFT JOIN sites s ON a.site_id = s.site_id
-		WHERE a.attendance_id = ? AND a.user_id = ?
-	`
+		WHERE a.attendance_id = ?`
+	cond, args := tenantCondition(ctx, "a.user_id", userID)
+	query += cond
 
 	var a domain.Attendance
-	var timeIn, timeOut sql.NullTime
-	var subDate, wName, sName sql.NullString
+	var timeIn, timeOut, openUntil sql.NullTime
+	var subDate, wName, sName, enteredBy, enteredByName sql.NullString
 
-	err := r.db.QueryRowContext(ctx, query, id, userID).Scan(
+	err := r.db.QueryRowContext(ctx, query, append([]interface{}{id}, args...)...).Scan(
 		&a.ID, &a.DeviceID, &a.WorkerID, &a.SiteID, &a.UserID,
-		&timeIn, &timeOut, &a.Direction, &a.TradeCode, &a.Status, &subDate,
+		&timeIn, &timeOut, &openUntil, &a.Direction, &a.Source, &enteredBy, &enteredByName,
+		&a.Flagged, &a.TradeCode, &a.Status, &a.Revision, &subDate,
 		&wName, &sName, &a.CreatedAt, &a.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -79,6 +84,9 @@ func (r *AttendanceRepository) Get(ctx context.Context, userID, id string) (*dom
 	if timeOut.Valid {
 		a.TimeOut = &timeOut.Time
 	}
+	if openUntil.Valid {
+		a.OpenUntil = &openUntil.Time
+	}
 	if subDate.Valid {
 		a.SubmissionDate = subDate.String
 	}
@@ -88,27 +96,27 @@ func (r *AttendanceRepository) Get(ctx context.Context, userID, id string) (*dom
 	if sName.Valid {
 		a.SiteName = sName.String
 	}
+	a.EnteredBy = enteredBy.String
+	a.EnteredByName = enteredByName.String
 
 	return &a, nil
 }
 
-// List retrieves attendance records filtered by optional siteID, workerID, and date, scoped to userID.
+// List retrieves attendance records filtered by optional siteID, workerID, and date, within the caller's scope on userID.
 func (r *AttendanceRepository) List(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Attendance, error) {
-	if userID == "" {
-		return nil, apperrors.NewPermissionDenied("user_id is required for multi-tenant isolation")
-	}
-
 	query := `
 		SELECT
 			a.attendance_id, a.device_id, a.worker_id, a.site_id, a.user_id,
-			a.time_in, a.time_out, a.direction, a.trade_code, a.status, a.submission_date,
+			a.time_in, a.time_out, a.open_until, a.direction, a.source, a.entered_by, a.entered_by_name,
+			a.flagged, a.trade_code, a.status, a.revision, a.submission_date,
 			w.name AS worker_name, s.site_name, a.created_at, a.updated_at
 		FROM attendance a
 		LEFT JOIN workers w ON a.worker_id = w.worker_id
 		LEFT JOIN sites s ON a.site_id = s.site_id
-		WHERE a.user_id = ?`
+		WHERE 1=1`
 
-	args := []interface{}{userID}
+	cond, args := tenantCondition(ctx, "a.user_id", userID)
+	query += cond
 
 	if siteID != "" {
 		query += " AND a.site_id = ?"
@@ -133,12 +141,13 @@ func (r *AttendanceRepository) List(ctx context.Context, userID, siteID, workerI
 	var records []domain.Attendance
 	for rows.Next() {
 		var a domain.Attendance
-		var timeIn, timeOut sql.NullTime
-		var subDate, wName, sName sql.NullString
+		var timeIn, timeOut, openUntil sql.NullTime
+		var subDate, wName, sName, enteredBy, enteredByName sql.NullString
 
 		if err := rows.Scan(
 			&a.ID, &a.DeviceID, &a.WorkerID, &a.SiteID, &a.UserID,
-			&timeIn, &timeOut, &a.Direction, &a.TradeCode, &a.Status, &subDate,
+			&timeIn, &timeOut, &openUntil, &a.Direction, &a.Source, &enteredBy, &enteredByName,
+			&a.Flagged, &a.TradeCode, &a.Status, &a.Revision, &subDate,
 			&wName, &sName, &a.CreatedAt, &a.UpdatedAt,
 		); err != nil {
 			return nil, err
@@ -150,6 +159,9 @@ func (r *AttendanceRepository) List(ctx context.Context, userID, siteID, workerI
 		if timeOut.Valid {
 			a.TimeOut = &timeOut.Time
 		}
+		if openUntil.Valid {
+			a.OpenUntil = &openUntil.Time
+		}
 		if subDate.Valid {
 			a.SubmissionDate = subDate.String
 		}
@@ -159,6 +171,8 @@ func (r *AttendanceRepository) List(ctx context.Context, userID, siteID, workerI
 		if sName.Valid {
 			a.SiteName = sName.String
 		}
+		a.EnteredBy = enteredBy.String
+		a.EnteredByName = enteredByName.String
 
 		records = append(records, a)
 	}
@@ -166,42 +180,174 @@ func (r *AttendanceRepository) List(ctx context.Context, userID, siteID, workerI
 	return records, rows.Err()
 }
 
-// Create inserts a new attendance record into the database.
-func (r *AttendanceRepository) Create(ctx context.Context, a *domain.Attendance) error {
+// Create numbers a record and inserts it, unless a record with the same (worker_id, time_in, device_id)
+// natural key already exists. The ID is only taken once the check has passed, and numbering and insert
+// share one transaction. Returns false when the record was already stored.
+func (r *AttendanceRepository) Create(ctx context.Context, a *domain.Attendance) (bool, error) {
+	var existingID string
+	err := r.db.QueryRowContext(ctx,
+		"SELECT attendance_id FROM attendance WHERE worker_id = ? AND time_in = ? AND device_id = ? LIMIT 1",
+		a.WorkerID, a.TimeIn, a.DeviceID).Scan(&existingID)
+	if err == nil {
+		return false, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	day, seq, err := lastAttendanceSeq(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	a.ID = fmt.Sprintf("ATT-%s-%04d", day, seq+1)
+	if err := insertAttendance(ctx, tx, a); err != nil {
+		// A concurrent fetch stored the same session after the check
+		if errors.Is(err, apperrors.ErrConflict) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// CreateBatch numbers the records and creates them in one transaction, so either all are stored or none.
+// The day's highest ID stays locked until the batch commits.
+func (r *AttendanceRepository) CreateBatch(ctx context.Context, records []*domain.Attendance) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	day, seq, err := lastAttendanceSeq(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, a := range records {
+		seq++
+		a.ID = fmt.Sprintf("ATT-%s-%04d", day, seq)
+		if err := insertAttendance(ctx, tx, a); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func insertAttendance(ctx context.Context, db execer, a *domain.Attendance) error {
 	query := `
 		INSERT INTO attendance (
 			attendance_id, device_id, worker_id, site_id, user_id,
-			time_in, time_out, direction, trade_code, status,
-			submission_date, response_payload, created_at, updated_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+			time_in, time_out, open_until, direction, source, entered_by, entered_by_name,
+			trade_code, status, submission_date, response_payload, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		a.ID, a.DeviceID, a.WorkerID, a.SiteID, a.UserID,
-		a.TimeIn, a.TimeOut, a.Direction, a.TradeCode, a.Status,
-		a.SubmissionDate, a.ResponsePayload,
+		a.TimeIn, a.TimeOut, a.OpenUntil, a.Direction, attendanceSource(a.Source),
+		sql.NullString{String: a.EnteredBy, Valid: a.EnteredBy != ""},
+		sql.NullString{String: a.EnteredByName, Valid: a.EnteredByName != ""},
+		a.TradeCode, a.Status, a.SubmissionDate, sql.NullString{String: a.ResponsePayload, Valid: a.ResponsePayload != ""},
 	)
+	if isDuplicateKeyError(err) {
+		return apperrors.NewConflict("another attendance record already exists for this worker, device and time_in")
+	}
 	return err
 }
 
-// Update modifies the TimeIn and TimeOut of an existing attendance record.
-func (r *AttendanceRepository) Update(ctx context.Context, userID, id string, timeIn, timeOut *time.Time) error {
+// ListByWorkerDates returns a worker's attendance records whose submission_date is one of dates,
+// within the caller's scope.
+func (r *AttendanceRepository) ListByWorkerDates(ctx context.Context, workerID string, dates []string) ([]domain.Attendance, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
 	query := `
-		UPDATE attendance 
-		SET time_in = ?, time_out = ?, updated_at = NOW() 
-		WHERE attendance_id = ? AND user_id = ?
-	`
-	res, err := r.db.ExecContext(ctx, query, timeIn, timeOut, id, userID)
+		SELECT attendance_id, device_id, worker_id, site_id, user_id,
+			time_in, time_out, open_until, direction, source, trade_code, status, revision, submission_date, created_at, updated_at
+		FROM attendance
+		WHERE worker_id = ? AND submission_date IN (` + strings.TrimSuffix(strings.Repeat("?,", len(dates)), ",") + `)`
+	args := []interface{}{workerID}
+	for _, d := range dates {
+		args = append(args, d)
+	}
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
+	query += cond + " ORDER BY time_in"
+	args = append(args, condArgs...)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.Attendance
+	for rows.Next() {
+		var a domain.Attendance
+		var siteID, userID sql.NullString
+		var timeIn, timeOut, openUntil, subDate sql.NullTime
+		if err := rows.Scan(
+			&a.ID, &a.DeviceID, &a.WorkerID, &siteID, &userID,
+			&timeIn, &timeOut, &openUntil, &a.Direction, &a.Source, &a.TradeCode, &a.Status, &a.Revision, &subDate, &a.CreatedAt, &a.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		a.SiteID = siteID.String
+		a.UserID = userID.String
+		if timeIn.Valid {
+			a.TimeIn = &timeIn.Time
+		}
+		if timeOut.Valid {
+			a.TimeOut = &timeOut.Time
+		}
+		if openUntil.Valid {
+			a.OpenUntil = &openUntil.Time
+		}
+		if subDate.Valid {
+			a.SubmissionDate = subDate.Time.Format("2006-01-02")
+		}
+		records = append(records, a)
+	}
+	return records, rows.Err()
+}
+
+// UpdateSession rewrites the time_out, open_until and direction of a derived session. Submitted,
+// corrected and manually entered rows are left untouched.
+func (r *AttendanceRepository) UpdateSession(ctx context.Context, id string, timeOut, openUntil *time.Time, direction string) error {
+	cond, args := tenantCondition(ctx, "user_id", "")
+	query := `
+		UPDATE attendance
+		SET time_out = ?, open_until = ?, direction = ?, updated_at = NOW()
+		WHERE attendance_id = ? AND status != ? AND revision = 0 AND source = 'device'` + cond
+	_, err := r.db.ExecContext(ctx, query, append([]interface{}{timeOut, openUntil, direction, id, domain.SubmissionStatusSubmitted}, args...)...)
+	return err
+}
+
+// Delete removes a device-derived attendance record that has been neither submitted nor corrected.
+func (r *AttendanceRepository) Delete(ctx context.Context, id string) (bool, error) {
+	cond, args := tenantCondition(ctx, "user_id", "")
+	res, err := r.db.ExecContext(ctx, "DELETE FROM attendance WHERE attendance_id = ? AND status != ? AND revision = 0 AND source = 'device'"+cond,
+		append([]interface{}{id, domain.SubmissionStatusSubmitted}, args...)...)
+	if err != nil {
+		return false, err
 	}
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
-		return err
+		return false, err
 	}
-	if rowsAffected == 0 {
-		return apperrors.NewNotFound("attendance", id)
+	return rowsAffected > 0, nil
+}
+
+// attendanceSource defaults an unset source to device, which all derived attendance is.
+func attendanceSource(source string) string {
+	if source == "" {
+		return domain.AttendanceSourceDevice
 	}
-	return nil
+	return source
 }
 
 // GetMaxID returns the highest attendance_id matching the given LIKE pattern.
@@ -218,28 +364,21 @@ func (r *AttendanceRepository) GetMaxID(ctx context.Context, pattern string) (st
 	return maxID.String, nil
 }
 
-// GenerateNextID atomically generates the next sequential attendance ID for today (e.g. ATT-20260319-0042).
-// Uses a DB transaction with a lock to guarantee no two IDs collide even under horizontal scaling.
-func (r *AttendanceRepository) GenerateNextID(ctx context.Context) (string, error) {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return "", fmt.Errorf("GenerateNextID: begin tx: %w", err)
-	}
-	defer tx.Rollback()
-
-	day := time.Now().Format("20060102")
+// lastAttendanceSeq returns the business day and the highest sequence number used on it, locking
+// the row(s) so concurrent instances serialize until tx ends.
+func lastAttendanceSeq(ctx context.Context, tx *sql.Tx) (string, int, error) {
+	day := timeutil.BusinessNow().Format("20060102")
 	pattern := "ATT-" + day + "-%"
 
 	var maxID sql.NullString
-	// Lock the row(s) so concurrent instances serialize here
-	err = tx.QueryRowContext(ctx, `
+	err := tx.QueryRowContext(ctx, `
 		SELECT MAX(attendance_id)
 		FROM attendance
 		WHERE attendance_id LIKE ?
 		FOR UPDATE
 	`, pattern).Scan(&maxID)
 	if err != nil {
-		return "", fmt.Errorf("GenerateNextID: query max: %w", err)
+		return "", 0, fmt.Errorf("query max: %w", err)
 	}
 
 	seq := 0
@@ -249,44 +388,142 @@ func (r *AttendanceRepository) GenerateNextID(ctx context.Context) (string, erro
 			fmt.Sscanf(parts[2], "%d", &seq)
 		}
 	}
-	seq++
-
-	newID := fmt.Sprintf("ATT-%s-%04d", day, seq)
-	if err := tx.Commit(); err != nil {
-		return "", fmt.Errorf("GenerateNextID: commit: %w", err)
-	}
-	return newID, nil
+	return day, seq, nil
 }
 
-// ExtractPendingAttendance returns all non-submitted attendance rows with full joined data for SGBuildex submission.
+// staleClaimCondition selects rows claimed by a submission run whose lease (next_retry_at) ran out
+// before it recorded an outcome.
+const staleClaimCondition = `(a.status = 'submitting' AND a.next_retry_at <= NOW())`
+
+// submittableCondition selects rows that are due for submission: never attempted, failed
+// transiently with their backoff elapsed, or left behind by a run that died. Dead-lettered rows are
+// excluded until requeued, and so are sessions still waiting for a time_out that can arrive before
+// open_until (a shift in progress).
+const submittableCondition = `(a.status = 'pending' OR (a.status = 'failed' AND (a.next_retry_at IS NULL OR a.next_retry_at <= NOW())) OR ` + staleClaimCondition + `)
+		AND (a.open_until IS NULL OR a.open_until <= NOW())`
+
+// ExtractPendingAttendance returns all attendance rows due for submission with full joined data for SGBuildex submission.
 func (r *AttendanceRepository) ExtractPendingAttendance(ctx context.Context) ([]domain.AttendanceRow, error) {
 	query := `SELECT ` + attendanceSelectFields + attendanceJoinBlock + `
-		WHERE a.status != 'submitted'
+		WHERE ` + submittableCondition + `
 		ORDER BY a.submission_date, a.attendance_id
 	`
 	return r.queryAttendanceRows(ctx, query)
 }
 
-// ExtractPendingAttendanceByProject returns non-submitted attendance rows for a specific project.
-// If userID is empty, it bypasses the user filter (for Admins).
-func (r *AttendanceRepository) ExtractPendingAttendanceByProject(ctx context.Context, userID, projectID string) ([]domain.AttendanceRow, error) {
+// ExtractDueRetries returns only failed rows whose backoff has elapsed, and rows whose claim expired.
+func (r *AttendanceRepository) ExtractDueRetries(ctx context.Context) ([]domain.AttendanceRow, error) {
 	query := `SELECT ` + attendanceSelectFields + attendanceJoinBlock + `
-		WHERE a.status != 'submitted' AND w.current_project_id = ?`
+		WHERE (a.status = 'failed' AND (a.next_retry_at IS NULL OR a.next_retry_at <= NOW())) OR ` + staleClaimCondition + `
+		ORDER BY a.next_retry_at, a.attendance_id
+	`
+	return r.queryAttendanceRows(ctx, query)
+}
 
-	args := []interface{}{projectID}
+// ExtractPendingAttendanceByProject returns attendance rows due for submission for a specific project,
+// limited to the caller's scope on userID.
+func (r *AttendanceRepository) ExtractPendingAttendanceByProject(ctx context.Context, userID, projectID string) ([]domain.AttendanceRow, error) {
+	cond, condArgs := tenantCondition(ctx, "a.user_id", userID)
+	query := `SELECT ` + attendanceSelectFields + attendanceJoinBlock + `
+		WHERE ` + submittableCondition + ` AND w.current_project_id = ?` + cond
 
-	if userID != "" {
-		query += ` AND a.user_id = ?`
-		args = append(args, userID)
-	}
+	args := append([]interface{}{projectID}, condArgs...)
 
 	query += ` ORDER BY a.submission_date, a.attendance_id`
 
 	return r.queryAttendanceRows(ctx, query, args...)
 }
 
-// ExtractProjectsWithPendingAttendance returns distinct projects that have attendance records not yet submitted.
-// If userID is empty, it bypasses the user filter (for Admins).
+// ClaimForSubmission locks the given rows, keeps those still due for submission and marks them
+// submitting until the claim lease runs out. A run that loses the race to another one finds the
+// rows already claimed once the lock is released and skips them.
+func (r *AttendanceRepository) ClaimForSubmission(ctx context.Context, attendanceIDs []string) ([]string, error) {
+	if len(attendanceIDs) == 0 {
+		return nil, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	placeholders, args := inPlaceholders(attendanceIDs)
+	rows, err := tx.QueryContext(ctx, `SELECT a.attendance_id FROM attendance a
+		WHERE a.attendance_id IN (`+placeholders+`) AND `+submittableCondition+`
+		FOR UPDATE`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var claimed []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		claimed = append(claimed, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	placeholders, args = inPlaceholders(claimed)
+	query := `UPDATE attendance SET status = ?, next_retry_at = ?, updated_at = ? WHERE attendance_id IN (` + placeholders + `)`
+	args = append([]interface{}{domain.SubmissionStatusSubmitting, now.Add(domain.SubmissionClaimLease), now}, args...)
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// ReleaseSubmissionClaims returns rows still marked submitting to pending, or to failed (due at
+// once) if they had failed before.
+func (r *AttendanceRepository) ReleaseSubmissionClaims(ctx context.Context, attendanceIDs []string) error {
+	if len(attendanceIDs) == 0 {
+		return nil
+	}
+
+	placeholders, args := inPlaceholders(attendanceIDs)
+	query := `
+		UPDATE attendance
+		SET status = CASE WHEN COALESCE(retry_count, 0) > 0 THEN ? ELSE ? END, next_retry_at = NULL, updated_at = ?
+		WHERE status = ? AND attendance_id IN (` + placeholders + `)`
+	args = append([]interface{}{domain.SubmissionStatusFailed, domain.SubmissionStatusPending, time.Now(), domain.SubmissionStatusSubmitting}, args...)
+	_, err := r.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// MarkFlagged records that the given rows were submitted under the "flag" manual attendance policy.
+func (r *AttendanceRepository) MarkFlagged(ctx context.Context, attendanceIDs []string) error {
+	if len(attendanceIDs) == 0 {
+		return nil
+	}
+
+	placeholders, args := inPlaceholders(attendanceIDs)
+	_, err := r.db.ExecContext(ctx, "UPDATE attendance SET flagged = 1 WHERE attendance_id IN ("+placeholders+")", args...)
+	return err
+}
+
+// inPlaceholders returns the placeholder list and arguments for an IN clause over ids.
+func inPlaceholders(ids []string) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(ids)), ","), args
+}
+
+// ExtractProjectsWithPendingAttendance returns distinct projects that have attendance records not yet submitted,
+// limited to the caller's scope on userID.
 func (r *AttendanceRepository) ExtractProjectsWithPendingAttendance(ctx context.Context, userID string) ([]domain.Project, error) {
 	query := `
 		SELECT DISTINCT
@@ -309,12 +546,9 @@ func (r *AttendanceRepository) ExtractProjectsWithPendingAttendance(ctx context.
 		AND p.status = ? AND a.status IN ('pending', 'failed')
 	`
 
-	args := []interface{}{domain.StatusActive}
-
-	if userID != "" {
-		query += ` AND p.user_id = ?`
-		args = append(args, userID)
-	}
+	cond, condArgs := tenantCondition(ctx, "p.user_id", userID)
+	query += cond
+	args := append([]interface{}{domain.StatusActive}, condArgs...)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -425,6 +659,7 @@ func (r *AttendanceRepository) scanAttendanceRow(rows *sql.Rows) (domain.Attenda
 		&res.TimeIn,
 		&timeOut,
 		&res.Direction,
+		&res.Source,
 		&res.TradeCode,
 		&res.Status,
 		&res.SubmissionDate,
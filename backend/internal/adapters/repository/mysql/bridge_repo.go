@@ -6,6 +6,8 @@ import (
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/logger"
+	"strings"
+	"time"
 )
 
 type BridgeRepository struct {
@@ -88,13 +90,15 @@ func (r *BridgeRepository) GetActiveBridges(ctx context.Context) ([]ports.Bridge
 	return configs, nil
 }
 
+// LogBridgeInteraction records a request and its response on one row per request ID. A response
+// that arrives after the request timed out stores its payload but keeps the 408 status.
 func (r *BridgeRepository) LogBridgeInteraction(ctx context.Context, userID, action, requestID string, requestPayload, responsePayload []byte, statusCode int) error {
 	query := `
 		INSERT INTO bridge_logs (user_id, action, request_id, request_payload, response_payload, status_code)
 		VALUES (?, ?, ?, ?, ?, ?)
 		ON DUPLICATE KEY UPDATE 
 			response_payload = IF(VALUES(response_payload) IS NOT NULL, VALUES(response_payload), response_payload),
-			status_code = IF(VALUES(status_code) IS NOT NULL, VALUES(status_code), status_code),
+			status_code = IF(status_code = 408 OR VALUES(status_code) IS NULL, status_code, VALUES(status_code)),
 			updated_at = CURRENT_TIMESTAMP`
 
 	var reqPl, respPl interface{}
@@ -118,3 +122,185 @@ func (r *BridgeRepository) LogBridgeInteraction(ctx context.Context, userID, act
 	}
 	return err
 }
+
+func (r *BridgeRepository) MarkBridgeConnected(ctx context.Context, userID, remoteAddr string, connectedAt time.Time) error {
+	query := `
+		INSERT INTO bridge_connections (user_id, status, remote_addr, connected_at, last_seen, disconnected_at)
+		VALUES (?, ?, ?, ?, ?, NULL)
+		ON DUPLICATE KEY UPDATE
+			status = VALUES(status),
+			remote_addr = VALUES(remote_addr),
+			connected_at = VALUES(connected_at),
+			last_seen = VALUES(last_seen),
+			disconnected_at = NULL`
+	_, err := r.db.ExecContext(ctx, query, userID, domain.BridgeConnected, remoteAddr, connectedAt, connectedAt)
+	return err
+}
+
+func (r *BridgeRepository) TouchBridgeConnection(ctx context.Context, userID string, lastSeen time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE bridge_connections SET last_seen = ? WHERE user_id = ? AND status = ?", lastSeen, userID, domain.BridgeConnected)
+	return err
+}
+
+func (r *BridgeRepository) MarkBridgeDisconnected(ctx context.Context, userID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE bridge_connections SET status = ?, disconnected_at = ? WHERE user_id = ?", domain.BridgeDisconnected, at, userID)
+	return err
+}
+
+// ResetBridgeConnections marks every bridge as disconnected. Called on startup since no
+// transport survives a server restart.
+func (r *BridgeRepository) ResetBridgeConnections(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE bridge_connections SET status = ?, disconnected_at = CURRENT_TIMESTAMP WHERE status = ?", domain.BridgeDisconnected, domain.BridgeConnected)
+	return err
+}
+
+const bridgeCommandSelect = `
+	SELECT id, user_id, action, request_id, worker_id, payload, status, attempts, last_error, expires_at, created_at, sent_at
+	FROM bridge_outbox`
+
+// EnqueueBridgeCommand stores an outbound command. When pending commands for the worker with an action
+// in replaces already exist, the oldest is overwritten with cmd so it keeps its queue position, and any
+// others are dropped.
+func (r *BridgeRepository) EnqueueBridgeCommand(ctx context.Context, cmd *domain.BridgeCommand, replaces []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var workerID interface{}
+	if cmd.WorkerID != "" {
+		workerID = cmd.WorkerID
+	}
+
+	if len(replaces) > 0 && cmd.WorkerID != "" {
+		query := "SELECT id FROM bridge_outbox WHERE user_id = ? AND worker_id = ? AND status = ? AND action IN (" +
+			strings.TrimSuffix(strings.Repeat("?,", len(replaces)), ",") + ") ORDER BY id FOR UPDATE"
+		args := []interface{}{cmd.UserID, cmd.WorkerID, domain.BridgeCommandPending}
+		for _, action := range replaces {
+			args = append(args, action)
+		}
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		var existing []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			existing = append(existing, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(existing) > 0 {
+			_, err = tx.ExecContext(ctx,
+				"UPDATE bridge_outbox SET action = ?, request_id = ?, payload = ?, expires_at = ? WHERE id = ?",
+				cmd.Action, cmd.RequestID, []byte(cmd.Payload), cmd.ExpiresAt, existing[0])
+			if err != nil {
+				return err
+			}
+			for _, id := range existing[1:] {
+				if _, err := tx.ExecContext(ctx, "DELETE FROM bridge_outbox WHERE id = ?", id); err != nil {
+					return err
+				}
+			}
+			cmd.ID = existing[0]
+			cmd.Status = domain.BridgeCommandPending
+			return tx.Commit()
+		}
+	}
+
+	res, err := tx.ExecContext(ctx,
+		"INSERT INTO bridge_outbox (user_id, action, request_id, worker_id, payload, status, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		cmd.UserID, cmd.Action, cmd.RequestID, workerID, []byte(cmd.Payload), domain.BridgeCommandPending, cmd.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	cmd.ID, _ = res.LastInsertId()
+	cmd.Status = domain.BridgeCommandPending
+	return tx.Commit()
+}
+
+// ListBridgeCommands returns a tenant's commands in queue order. Empty status returns all of them.
+func (r *BridgeRepository) ListBridgeCommands(ctx context.Context, userID, status string) ([]domain.BridgeCommand, error) {
+	query := bridgeCommandSelect + " WHERE user_id = ?"
+	args := []interface{}{userID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var cmds []domain.BridgeCommand
+	for rows.Next() {
+		var c domain.BridgeCommand
+		var workerID, lastError sql.NullString
+		var payload []byte
+		var sentAt sql.NullTime
+		if err := rows.Scan(&c.ID, &c.UserID, &c.Action, &c.RequestID, &workerID, &payload, &c.Status, &c.Attempts, &lastError, &c.ExpiresAt, &c.CreatedAt, &sentAt); err != nil {
+			return nil, err
+		}
+		c.WorkerID = workerID.String
+		c.LastError = lastError.String
+		c.Payload = payload
+		if sentAt.Valid {
+			c.SentAt = &sentAt.Time
+		}
+		cmds = append(cmds, c)
+	}
+	return cmds, rows.Err()
+}
+
+func (r *BridgeRepository) MarkBridgeCommandSent(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE bridge_outbox SET status = ?, attempts = attempts + 1, last_error = NULL, sent_at = NOW() WHERE id = ?",
+		domain.BridgeCommandSent, id)
+	return err
+}
+
+// MarkBridgeCommandAttempt records a failed delivery. The command is marked failed once maxAttempts is reached.
+func (r *BridgeRepository) MarkBridgeCommandAttempt(ctx context.Context, id int64, errMsg string, maxAttempts int) error {
+	query := `
+		UPDATE bridge_outbox 
+		SET attempts = attempts + 1, last_error = ?, status = IF(attempts >= ?, ?, status)
+		WHERE id = ?`
+	_, err := r.db.ExecContext(ctx, query, errMsg, maxAttempts, domain.BridgeCommandFailed, id)
+	return err
+}
+
+func (r *BridgeRepository) ExpireBridgeCommands(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE bridge_outbox SET status = ? WHERE status = ? AND expires_at < ?",
+		domain.BridgeCommandExpired, domain.BridgeCommandPending, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// PurgeBridgeCommands deletes a tenant's commands. Empty status purges the whole queue.
+func (r *BridgeRepository) PurgeBridgeCommands(ctx context.Context, userID, status string) (int64, error) {
+	query := "DELETE FROM bridge_outbox WHERE user_id = ?"
+	args := []interface{}{userID}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
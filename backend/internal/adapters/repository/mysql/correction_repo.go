@@ -0,0 +1,240 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+type CorrectionRepository struct {
+	db *sql.DB
+}
+
+func NewCorrectionRepository(db *sql.DB) ports.CorrectionRepository {
+	return &CorrectionRepository{db: db}
+}
+
+const correctionBaseSelect = `
+	SELECT correction_id, attendance_id, user_id, site_id, worker_id, kind, reason,
+		original_time_in, original_time_out, proposed_time_in, proposed_time_out, status,
+		requested_by, requested_by_name, reviewed_by, reviewed_by_name, review_note, reviewed_at, created_at
+	FROM attendance_corrections`
+
+func (r *CorrectionRepository) Create(ctx context.Context, c *domain.AttendanceCorrection) error {
+	query := `
+		INSERT INTO attendance_corrections
+			(correction_id, attendance_id, user_id, site_id, worker_id, kind, reason,
+			 original_time_in, original_time_out, proposed_time_in, proposed_time_out, status,
+			 requested_by, requested_by_name, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		c.ID, c.AttendanceID, c.UserID, c.SiteID, c.WorkerID, c.Kind, c.Reason,
+		c.OriginalTimeIn, c.OriginalTimeOut, c.ProposedTimeIn, c.ProposedTimeOut, c.Status,
+		c.RequestedBy, sql.NullString{String: c.RequestedByName, Valid: c.RequestedByName != ""}, c.CreatedAt,
+	)
+	return err
+}
+
+func (r *CorrectionRepository) Get(ctx context.Context, userID, id string) (*domain.AttendanceCorrection, error) {
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	corrections, err := r.query(ctx, correctionBaseSelect+" WHERE correction_id = ?"+cond, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(corrections) == 0 {
+		return nil, apperrors.NewNotFound("correction", id)
+	}
+	return &corrections[0], nil
+}
+
+func (r *CorrectionRepository) List(ctx context.Context, userID, attendanceID, status string) ([]domain.AttendanceCorrection, error) {
+	query := correctionBaseSelect + " WHERE 1=1"
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query += cond
+	if attendanceID != "" {
+		query += " AND attendance_id = ?"
+		args = append(args, attendanceID)
+	}
+	if status != "" {
+		query += " AND status = ?"
+		args = append(args, status)
+	}
+	query += " ORDER BY created_at DESC, correction_id"
+	return r.query(ctx, query, args...)
+}
+
+// Apply guards the attendance update on the revision, status and times the correction was reviewed
+// against, so a record changed by a concurrent approval or submission is never overwritten.
+func (r *CorrectionRepository) Apply(ctx context.Context, c *domain.AttendanceCorrection, rev *domain.AttendanceRevision) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// An amendment queues the record for submission again with a fresh retry budget
+	set := "time_in = ?, time_out = ?, open_until = NULL, revision = revision + 1, updated_at = NOW()"
+	setArgs := []interface{}{rev.TimeIn, rev.TimeOut}
+	if c.Kind == domain.CorrectionKindAmendment {
+		set += ", status = ?, retry_count = 0, next_retry_at = NULL, error_message = NULL"
+		setArgs = append(setArgs, domain.SubmissionStatusPending)
+	}
+	cond, args := tenantCondition(ctx, "user_id", c.UserID)
+	res, err := tx.ExecContext(ctx, `
+		UPDATE attendance SET `+set+`
+		WHERE attendance_id = ? AND revision = ? AND status = ? AND time_in <=> ? AND time_out <=> ?`+cond,
+		append(append(setArgs,
+			c.AttendanceID, rev.Revision-1, rev.PreviousStatus, rev.PreviousTimeIn, rev.PreviousTimeOut,
+		), args...)...)
+	if isDuplicateKeyError(err) {
+		return apperrors.NewConflict("another attendance record already exists for this worker, device and time_in")
+	}
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return apperrors.NewConflict("attendance record changed since the correction was requested")
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO attendance_revisions
+			(attendance_id, revision, correction_id, kind, previous_time_in, previous_time_out,
+			 time_in, time_out, previous_status, reason, requested_by, approved_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rev.AttendanceID, rev.Revision, rev.CorrectionID, rev.Kind, rev.PreviousTimeIn, rev.PreviousTimeOut,
+		rev.TimeIn, rev.TimeOut, rev.PreviousStatus, rev.Reason, rev.RequestedBy, rev.ApprovedBy, rev.CreatedAt)
+	if isDuplicateKeyError(err) {
+		return apperrors.NewConflict("attendance record changed since the correction was requested")
+	}
+	if err != nil {
+		return err
+	}
+	rev.ID, _ = result.LastInsertId()
+
+	if err := r.review(ctx, tx, c); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *CorrectionRepository) Reject(ctx context.Context, c *domain.AttendanceCorrection) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := r.review(ctx, tx, c); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// review records the outcome of a pending correction; a correction reviewed in the meantime is a conflict.
+func (r *CorrectionRepository) review(ctx context.Context, tx *sql.Tx, c *domain.AttendanceCorrection) error {
+	cond, args := tenantCondition(ctx, "user_id", c.UserID)
+	res, err := tx.ExecContext(ctx, `
+		UPDATE attendance_corrections
+		SET status = ?, reviewed_by = ?, reviewed_by_name = ?, review_note = ?, reviewed_at = ?
+		WHERE correction_id = ? AND status = ?`+cond,
+		append([]interface{}{
+			c.Status, c.ReviewedBy, sql.NullString{String: c.ReviewedByName, Valid: c.ReviewedByName != ""},
+			sql.NullString{String: c.ReviewNote, Valid: c.ReviewNote != ""}, c.ReviewedAt,
+			c.ID, domain.CorrectionPending,
+		}, args...)...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.NewConflict("correction has already been reviewed")
+	}
+	return nil
+}
+
+func (r *CorrectionRepository) ListRevisions(ctx context.Context, userID, attendanceID string) ([]domain.AttendanceRevision, error) {
+	cond, args := tenantCondition(ctx, "a.user_id", userID)
+	query := `
+		SELECT r.revision_id, r.attendance_id, r.revision, r.correction_id, r.kind,
+			r.previous_time_in, r.previous_time_out, r.time_in, r.time_out, r.previous_status,
+			r.reason, r.requested_by, r.approved_by, r.created_at
+		FROM attendance_revisions r
+		JOIN attendance a ON r.attendance_id = a.attendance_id
+		WHERE r.attendance_id = ?` + cond + `
+		ORDER BY r.revision`
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{attendanceID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []domain.AttendanceRevision{}
+	for rows.Next() {
+		var rev domain.AttendanceRevision
+		var prevIn, prevOut, timeIn, timeOut sql.NullTime
+		if err := rows.Scan(
+			&rev.ID, &rev.AttendanceID, &rev.Revision, &rev.CorrectionID, &rev.Kind,
+			&prevIn, &prevOut, &timeIn, &timeOut, &rev.PreviousStatus,
+			&rev.Reason, &rev.RequestedBy, &rev.ApprovedBy, &rev.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		rev.PreviousTimeIn = nullTimePtr(prevIn)
+		rev.PreviousTimeOut = nullTimePtr(prevOut)
+		rev.TimeIn = nullTimePtr(timeIn)
+		rev.TimeOut = nullTimePtr(timeOut)
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+func (r *CorrectionRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.AttendanceCorrection, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	corrections := []domain.AttendanceCorrection{}
+	for rows.Next() {
+		var c domain.AttendanceCorrection
+		var origIn, origOut, propIn, propOut, reviewedAt sql.NullTime
+		var requestedByName, reviewedBy, reviewedByName, reviewNote sql.NullString
+		var createdAt sql.NullTime
+		if err := rows.Scan(
+			&c.ID, &c.AttendanceID, &c.UserID, &c.SiteID, &c.WorkerID, &c.Kind, &c.Reason,
+			&origIn, &origOut, &propIn, &propOut, &c.Status,
+			&c.RequestedBy, &requestedByName, &reviewedBy, &reviewedByName, &reviewNote, &reviewedAt, &createdAt,
+		); err != nil {
+			return nil, err
+		}
+		c.OriginalTimeIn = nullTimePtr(origIn)
+		c.OriginalTimeOut = nullTimePtr(origOut)
+		c.ProposedTimeIn = nullTimePtr(propIn)
+		c.ProposedTimeOut = nullTimePtr(propOut)
+		c.ReviewedAt = nullTimePtr(reviewedAt)
+		c.RequestedByName = requestedByName.String
+		c.ReviewedBy = reviewedBy.String
+		c.ReviewedByName = reviewedByName.String
+		c.ReviewNote = reviewNote.String
+		c.CreatedAt = createdAt.Time
+		corrections = append(corrections, c)
+	}
+	return corrections, rows.Err()
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
@@ -31,29 +32,19 @@ func (r *DeviceRepository) Get(ctx context.Context, userID, id string) (*domain.
 		LEFT JOIN sites s ON d.site_id = s.site_id
 		LEFT JOIN users u ON d.user_id = u.user_id
 		WHERE d.device_id = ?`
-	if !ports.IsVendor(ctx) {
-		query += " AND d.user_id = ?"
-	}
+	cond, args := tenantCondition(ctx, "d.user_id", userID)
+	query += cond
 
 	var d domain.Device
 	var siteName, siteID, userName, scanUserID sql.NullString
 	var lastBeat, lastCheck sql.NullTime
 	var status sql.NullString
 
-	var err error
-	if ports.IsVendor(ctx) {
-		err = r.db.QueryRowContext(ctx, query, id).Scan(
-			&d.ID, &d.SN, &d.Model, &status,
-			&siteName, &siteID, &userName, &scanUserID,
-			&lastBeat, &lastCheck, &d.Battery,
-		)
-	} else {
-		err = r.db.QueryRowContext(ctx, query, id, userID).Scan(
-			&d.ID, &d.SN, &d.Model, &status,
-			&siteName, &siteID, &userName, &scanUserID,
-			&lastBeat, &lastCheck, &d.Battery,
-		)
-	}
+	err := r.db.QueryRowContext(ctx, query, append([]interface{}{id}, args...)...).Scan(
+		&d.ID, &d.SN, &d.Model, &status,
+		&siteName, &siteID, &userName, &scanUserID,
+		&lastBeat, &lastCheck, &d.Battery,
+	)
 
 	if err == sql.ErrNoRows {
 		return nil, apperrors.NewNotFound("device", id)
@@ -155,19 +146,10 @@ func (r *DeviceRepository) List(ctx context.Context, userID, siteID string) ([]d
 		LEFT JOIN sites s ON d.site_id = s.site_id
 		LEFT JOIN users u ON d.user_id = u.user_id
 		WHERE d.status != ?`
-	if userID == "" {
-		return nil, apperrors.NewPermissionDenied("user_id is required for multi-tenant isolation")
-	}
-
-	args := []interface{}{domain.StatusInactive}
-	if userID != "" {
-		// If not a vendor, strictly filter by their ID.
-		// If vendor, only filter if they are targeting a specific user (different from their own id).
-		if !ports.IsVendor(ctx) || (userID != ports.GetUserID(ctx)) {
-			query += " AND d.user_id = ?"
-			args = append(args, userID)
-		}
-	}
+	// A vendor passing another organisation's ID narrows its view to that organisation
+	cond, condArgs := tenantCondition(ctx, "d.user_id", userID)
+	query += cond
+	args := append([]interface{}{domain.StatusInactive}, condArgs...)
 
 	if siteID != "" {
 		query += " AND d.site_id = ?"
@@ -225,8 +207,9 @@ func (r *DeviceRepository) List(ctx context.Context, userID, siteID string) ([]d
 }
 
 func (r *DeviceRepository) ListSNsBySiteID(ctx context.Context, userID, siteID string) ([]string, error) {
-	query := `SELECT sn FROM devices WHERE site_id = ? AND user_id = ? AND status != ?`
-	rows, err := r.db.QueryContext(ctx, query, siteID, userID, domain.StatusInactive)
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query := `SELECT sn FROM devices WHERE site_id = ? AND status != ?` + cond
+	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{siteID, domain.StatusInactive}, args...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list device SNs by site: %w", err)
 	}
@@ -255,6 +238,8 @@ func (r *DeviceRepository) Create(ctx context.Context, d *domain.Device) error {
 	return err
 }
 
+// Update saves the device. Only a device currently owned by a tenant in the caller's scope is changed;
+// d.UserID may name its new owner.
 func (r *DeviceRepository) Update(ctx context.Context, d *domain.Device) error {
 	query := "UPDATE devices SET sn=?, model=?, status=?, site_id=?"
 	args := []interface{}{d.SN, d.Model, d.Status, d.SiteID}
@@ -262,20 +247,26 @@ func (r *DeviceRepository) Update(ctx context.Context, d *domain.Device) error {
 		query += ", user_id=?"
 		args = append(args, d.UserID)
 	}
-	query += " WHERE device_id=?"
-	args = append(args, d.ID)
-	_, err := r.db.ExecContext(ctx, query, args...)
-	return err
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
+	query += " WHERE device_id=?" + cond
+	args = append(append(args, d.ID), condArgs...)
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// MySQL reports 0 for an unchanged row too; tell the two apart
+		if _, err := r.Get(ctx, "", d.ID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (r *DeviceRepository) Delete(ctx context.Context, userID, id string) error {
-	query := "UPDATE devices SET status = ?, site_id = NULL WHERE device_id = ?"
-	args := []interface{}{domain.StatusInactive, id}
-	if !ports.IsVendor(ctx) {
-		query += " AND user_id = ?"
-		args = append(args, userID)
-	}
-	res, err := r.db.ExecContext(ctx, query, args...)
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query := "UPDATE devices SET status = ?, site_id = NULL WHERE device_id = ?" + cond
+	res, err := r.db.ExecContext(ctx, query, append([]interface{}{domain.StatusInactive, id}, args...)...)
 	if err != nil {
 		return err
 	}
@@ -286,14 +277,16 @@ func (r *DeviceRepository) Delete(ctx context.Context, userID, id string) error
 	return nil
 }
 
+// AssignToUser moves devices to userID. Only devices currently owned by a tenant in the caller's scope move.
 func (r *DeviceRepository) AssignToUser(ctx context.Context, userID string, deviceIDs []string) error {
-	stmt, err := r.db.PrepareContext(ctx, "UPDATE devices SET user_id = ?, site_id = NULL, status = 'offline' WHERE device_id = ?")
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
+	stmt, err := r.db.PrepareContext(ctx, "UPDATE devices SET user_id = ?, site_id = NULL, status = 'offline' WHERE device_id = ?"+cond)
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 	for _, id := range deviceIDs {
-		if _, err := stmt.ExecContext(ctx, userID, id); err != nil {
+		if _, err := stmt.ExecContext(ctx, append([]interface{}{userID, id}, condArgs...)...); err != nil {
 			return err
 		}
 	}
@@ -307,22 +300,27 @@ func (r *DeviceRepository) AssignToSite(ctx context.Context, siteID string, devi
 	}
 	defer tx.Rollback()
 
+	if err := requireSiteInScope(ctx, tx, siteID); err != nil {
+		return err
+	}
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
+
 	// 1. Unassign all devices currently on this site
-	_, err = tx.ExecContext(ctx, "UPDATE devices SET site_id = NULL WHERE site_id = ?", siteID)
+	_, err = tx.ExecContext(ctx, "UPDATE devices SET site_id = NULL WHERE site_id = ?"+cond, append([]interface{}{siteID}, condArgs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to clear old device assignments: %w", err)
 	}
 
 	// 2. Assign new devices
 	if len(deviceIDs) > 0 {
-		stmt, err := tx.PrepareContext(ctx, "UPDATE devices SET site_id = ? WHERE device_id = ?")
+		stmt, err := tx.PrepareContext(ctx, "UPDATE devices SET site_id = ? WHERE device_id = ?"+cond)
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 
 		for _, devId := range deviceIDs {
-			if _, err := stmt.ExecContext(ctx, siteID, devId); err != nil {
+			if _, err := stmt.ExecContext(ctx, append([]interface{}{siteID, devId}, condArgs...)...); err != nil {
 				return err
 			}
 		}
@@ -330,3 +328,59 @@ func (r *DeviceRepository) AssignToSite(ctx context.Context, siteID string, devi
 
 	return tx.Commit()
 }
+
+func (r *DeviceRepository) UpdateTelemetry(ctx context.Context, deviceID string, status domain.DeviceStatus, battery *int, heartbeat time.Time) error {
+	query := `
+		UPDATE devices 
+		SET status = ?, battery = COALESCE(?, battery), last_heartbeat = ?, last_online_check = NOW()
+		WHERE device_id = ?`
+	_, err := r.db.ExecContext(ctx, query, status, battery, heartbeat, deviceID)
+	return err
+}
+
+func (r *DeviceRepository) ListSilentDevices(ctx context.Context, cutoff time.Time) ([]domain.Device, error) {
+	query := `
+		SELECT device_id, sn, user_id, status 
+		FROM devices 
+		WHERE status = ? AND (last_heartbeat IS NULL OR last_heartbeat < ?)`
+	rows, err := r.db.QueryContext(ctx, query, domain.DeviceStatusOnline, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []domain.Device
+	for rows.Next() {
+		var d domain.Device
+		if err := rows.Scan(&d.ID, &d.SN, &d.UserID, &d.Status); err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// MarkOfflineIfSilent flips a device to offline only if no heartbeat arrived since cutoff,
+// so a report racing with the sweeper is never overwritten.
+func (r *DeviceRepository) MarkOfflineIfSilent(ctx context.Context, deviceID string, cutoff time.Time) (bool, error) {
+	query := `
+		UPDATE devices 
+		SET status = ?, last_online_check = NOW()
+		WHERE device_id = ? AND status = ? AND (last_heartbeat IS NULL OR last_heartbeat < ?)`
+	res, err := r.db.ExecContext(ctx, query, domain.DeviceStatusOffline, deviceID, domain.DeviceStatusOnline, cutoff)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *DeviceRepository) RecordStatusChange(ctx context.Context, c *domain.DeviceStatusChange) error {
+	query := `INSERT INTO device_status_history (device_id, from_status, to_status, reason, changed_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := r.db.ExecContext(ctx, query, c.DeviceID, c.FromStatus, c.ToStatus, c.Reason, c.ChangedAt)
+	if err != nil {
+		return err
+	}
+	c.ID, _ = res.LastInsertId()
+	return nil
+}
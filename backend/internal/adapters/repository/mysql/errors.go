@@ -0,0 +1,16 @@
+package mysql
+
+import (
+	"errors"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// mysqlErrDuplicateEntry is the server error number for a unique/primary key violation.
+const mysqlErrDuplicateEntry = 1062
+
+// isDuplicateKeyError reports whether err is a MySQL duplicate-key violation.
+func isDuplicateKeyError(err error) bool {
+	var mysqlErr *driver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDuplicateEntry
+}
@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+)
+
+type ImpersonationRepository struct {
+	db *sql.DB
+}
+
+func NewImpersonationRepository(db *sql.DB) ports.ImpersonationRepository {
+	return &ImpersonationRepository{db: db}
+}
+
+const impersonationBaseSelect = `
+    SELECT
+        i.impersonation_id, i.actor_user_id, i.actor_member_id, i.actor_username, i.tenant_user_id,
+        u.user_name, i.reason, i.read_only, i.expires_at, i.ip_address, i.created_at, i.ended_at
+    FROM impersonation_sessions i
+    LEFT JOIN users u ON u.user_id = i.tenant_user_id`
+
+func (r *ImpersonationRepository) Create(ctx context.Context, i *domain.Impersonation) error {
+	query := `
+		INSERT INTO impersonation_sessions
+			(impersonation_id, actor_user_id, actor_member_id, actor_username, tenant_user_id, reason, read_only, expires_at, ip_address, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		i.ID, i.ActorUserID, i.ActorMemberID, i.ActorUsername, i.TenantUserID, i.Reason, i.ReadOnly, i.ExpiresAt,
+		sql.NullString{String: i.IPAddress, Valid: i.IPAddress != ""}, i.CreatedAt)
+	return err
+}
+
+func (r *ImpersonationRepository) Get(ctx context.Context, id string) (*domain.Impersonation, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, impersonationBaseSelect+" WHERE i.impersonation_id = ?", id))
+}
+
+func (r *ImpersonationRepository) ListByTenant(ctx context.Context, tenantUserID string, limit int) ([]domain.Impersonation, error) {
+	rows, err := r.db.QueryContext(ctx, impersonationBaseSelect+" WHERE i.tenant_user_id = ? ORDER BY i.created_at DESC LIMIT ?", tenantUserID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []domain.Impersonation{}
+	for rows.Next() {
+		i, err := r.scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *i)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *ImpersonationRepository) End(ctx context.Context, id string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE impersonation_sessions SET ended_at = ? WHERE impersonation_id = ? AND ended_at IS NULL", time.Now(), id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *ImpersonationRepository) scanRow(scanner Scanner) (*domain.Impersonation, error) {
+	var i domain.Impersonation
+	var actorUsername, tenantName, ipAddress sql.NullString
+	var createdAt, endedAt sql.NullTime
+
+	err := scanner.Scan(
+		&i.ID, &i.ActorUserID, &i.ActorMemberID, &actorUsername, &i.TenantUserID,
+		&tenantName, &i.Reason, &i.ReadOnly, &i.ExpiresAt, &ipAddress, &createdAt, &endedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	i.ActorUsername = actorUsername.String
+	i.TenantName = tenantName.String
+	i.IPAddress = ipAddress.String
+	if createdAt.Valid {
+		i.CreatedAt = createdAt.Time
+	}
+	if endedAt.Valid {
+		i.EndedAt = &endedAt.Time
+	}
+	return &i, nil
+}
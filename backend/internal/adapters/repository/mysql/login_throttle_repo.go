@@ -0,0 +1,103 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+)
+
+type LoginThrottleRepository struct {
+	db *sql.DB
+}
+
+func NewLoginThrottleRepository(db *sql.DB) ports.LoginThrottleRepository {
+	return &LoginThrottleRepository{db: db}
+}
+
+const throttleBaseSelect = `SELECT scope, throttle_key, failures, last_failure_at, locked_until FROM login_throttles`
+
+func (r *LoginThrottleRepository) Get(ctx context.Context, scope, key string) (*domain.LoginThrottle, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, throttleBaseSelect+" WHERE scope = ? AND throttle_key = ?", scope, key))
+}
+
+func (r *LoginThrottleRepository) RecordFailure(ctx context.Context, scope, key string, now time.Time, window time.Duration) (*domain.LoginThrottle, error) {
+	// Assignments run left to right, so failures is computed from the previous row before
+	// locked_until and last_failure_at are overwritten
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO login_throttles (scope, throttle_key, failures, last_failure_at)
+		VALUES (?, ?, 1, ?)
+		ON DUPLICATE KEY UPDATE
+			failures = IF(last_failure_at < ? OR (locked_until IS NOT NULL AND locked_until <= ?), 1, failures + 1),
+			locked_until = IF(locked_until <= ?, NULL, locked_until),
+			last_failure_at = VALUES(last_failure_at)`,
+		scope, key, now, now.Add(-window), now, now)
+	if err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, scope, key)
+}
+
+func (r *LoginThrottleRepository) Lock(ctx context.Context, scope, key string, until time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE login_throttles SET locked_until = ? WHERE scope = ? AND throttle_key = ?",
+		until, scope, key)
+	return err
+}
+
+func (r *LoginThrottleRepository) Clear(ctx context.Context, scope, key string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM login_throttles WHERE scope = ? AND throttle_key = ?", scope, key)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *LoginThrottleRepository) ListLocked(ctx context.Context, now time.Time) ([]domain.LoginThrottle, error) {
+	rows, err := r.db.QueryContext(ctx, throttleBaseSelect+" WHERE locked_until > ? ORDER BY locked_until DESC", now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	throttles := []domain.LoginThrottle{}
+	for rows.Next() {
+		t, err := r.scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		throttles = append(throttles, *t)
+	}
+	return throttles, rows.Err()
+}
+
+// PurgeStale deletes counters whose last failure and lockout are both in the past.
+func (r *LoginThrottleRepository) PurgeStale(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		"DELETE FROM login_throttles WHERE last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)",
+		before, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *LoginThrottleRepository) scanRow(scanner Scanner) (*domain.LoginThrottle, error) {
+	var t domain.LoginThrottle
+	var lockedUntil sql.NullTime
+
+	err := scanner.Scan(&t.Scope, &t.Key, &t.Failures, &t.LastFailureAt, &lockedUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil.Valid {
+		t.LockedUntil = &lockedUntil.Time
+	}
+	return &t, nil
+}
@@ -0,0 +1,280 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/idgen"
+)
+
+type MemberRepository struct {
+	db *sql.DB
+}
+
+func NewMemberRepository(db *sql.DB) ports.MemberRepository {
+	return &MemberRepository{db: db}
+}
+
+const memberBaseSelect = `
+    SELECT
+        m.member_id, m.user_id, m.username, m.password_hash, m.name, m.email,
+        m.role, m.status, m.last_login_at, m.created_at, m.must_change_password, m.password_changed_at,
+        m.mfa_enabled, m.mfa_secret, m.mfa_enrolled_at,
+        u.user_name, u.user_type, u.status
+    FROM members m
+    JOIN users u ON u.user_id = m.user_id`
+
+func (r *MemberRepository) Get(ctx context.Context, id string) (*domain.Member, error) {
+	m, err := r.scanRow(r.db.QueryRowContext(ctx, memberBaseSelect+" WHERE m.member_id = ?", id))
+	if err != nil || m == nil {
+		return m, err
+	}
+	if domain.RoleIsSiteScoped(m.Role) {
+		if m.SiteIDs, err = r.siteAssignments(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (r *MemberRepository) GetByUsername(ctx context.Context, username string) (*domain.Member, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, memberBaseSelect+" WHERE m.username = ?", username))
+}
+
+func (r *MemberRepository) ListByUser(ctx context.Context, userID string) ([]domain.Member, error) {
+	rows, err := r.db.QueryContext(ctx, memberBaseSelect+" WHERE m.user_id = ? ORDER BY m.name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []domain.Member{}
+	for rows.Next() {
+		m, err := r.scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range members {
+		if domain.RoleIsSiteScoped(members[i].Role) {
+			if members[i].SiteIDs, err = r.siteAssignments(ctx, members[i].ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return members, nil
+}
+
+func (r *MemberRepository) Create(ctx context.Context, m *domain.Member) error {
+	if m.ID == "" {
+		id, err := idgen.GenerateNextID(r.db, "members", "member_id", "member")
+		if err != nil {
+			return err
+		}
+		m.ID = id
+	}
+
+	return insertMember(ctx, r.db, m)
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertMember inserts a member whose ID is already set.
+func insertMember(ctx context.Context, db execer, m *domain.Member) error {
+	query := `
+		INSERT INTO members (member_id, user_id, username, password_hash, must_change_password, name, email, role, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := db.ExecContext(ctx, query,
+		m.ID, m.UserID, m.Username, sql.NullString{String: m.PasswordHash, Valid: m.PasswordHash != ""}, m.MustChangePassword, m.Name, sql.NullString{String: m.Email, Valid: m.Email != ""}, m.Role, m.Status)
+	if isDuplicateKeyError(err) {
+		return apperrors.NewConflict(fmt.Sprintf("username %s is already taken", m.Username))
+	}
+	return err
+}
+
+// Update saves profile, credentials and status. Role changes go through SetRole.
+func (r *MemberRepository) Update(ctx context.Context, m *domain.Member) error {
+	query := `
+		UPDATE members SET username = ?, password_hash = ?, must_change_password = ?, password_changed_at = ?,
+			name = ?, email = ?, status = ?
+		WHERE member_id = ?`
+
+	var changedAt sql.NullTime
+	if m.PasswordChangedAt != nil {
+		changedAt = sql.NullTime{Time: *m.PasswordChangedAt, Valid: true}
+	}
+	_, err := r.db.ExecContext(ctx, query,
+		m.Username, sql.NullString{String: m.PasswordHash, Valid: m.PasswordHash != ""}, m.MustChangePassword, changedAt,
+		m.Name, sql.NullString{String: m.Email, Valid: m.Email != ""}, m.Status, m.ID)
+	if isDuplicateKeyError(err) {
+		return apperrors.NewConflict(fmt.Sprintf("username %s is already taken", m.Username))
+	}
+	return err
+}
+
+// SetRole replaces the member's role and site assignments in one transaction.
+// Site assignments are only kept for site-scoped roles and must belong to the member's organisation.
+func (r *MemberRepository) SetRole(ctx context.Context, id, role string, siteIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "UPDATE members SET role = ? WHERE member_id = ?", role, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM member_site_assignments WHERE member_id = ?", id); err != nil {
+		return err
+	}
+	if domain.RoleIsSiteScoped(role) {
+		for _, siteID := range siteIDs {
+			res, err := tx.ExecContext(ctx, `
+				INSERT INTO member_site_assignments (member_id, site_id)
+				SELECT m.member_id, s.site_id FROM members m
+				JOIN sites s ON s.user_id = m.user_id
+				WHERE m.member_id = ? AND s.site_id = ?`,
+				id, siteID)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				return apperrors.NewValidationError(fmt.Sprintf("site %s does not belong to this organisation", siteID))
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *MemberRepository) TouchLastLogin(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE members SET last_login_at = NOW() WHERE member_id = ?", id)
+	return err
+}
+
+func (r *MemberRepository) CreatePasswordReset(ctx context.Context, pr *domain.PasswordReset) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Only the newest token works
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE password_reset_tokens SET used_at = ? WHERE member_id = ? AND used_at IS NULL",
+		time.Now(), pr.MemberID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO password_reset_tokens (token_hash, member_id, created_by, expires_at)
+		VALUES (?, ?, ?, ?)`,
+		pr.TokenHash, pr.MemberID, sql.NullString{String: pr.CreatedBy, Valid: pr.CreatedBy != ""}, pr.ExpiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *MemberRepository) GetPasswordReset(ctx context.Context, tokenHash string) (*domain.PasswordReset, error) {
+	var pr domain.PasswordReset
+	var createdBy sql.NullString
+	var usedAt, createdAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `
+		SELECT token_hash, member_id, created_by, expires_at, used_at, created_at
+		FROM password_reset_tokens WHERE token_hash = ?`, tokenHash).
+		Scan(&pr.TokenHash, &pr.MemberID, &createdBy, &pr.ExpiresAt, &usedAt, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pr.CreatedBy = createdBy.String
+	if usedAt.Valid {
+		pr.UsedAt = &usedAt.Time
+	}
+	if createdAt.Valid {
+		pr.CreatedAt = createdAt.Time
+	}
+	return &pr, nil
+}
+
+func (r *MemberRepository) UsePasswordReset(ctx context.Context, tokenHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE password_reset_tokens SET used_at = ? WHERE token_hash = ? AND used_at IS NULL",
+		time.Now(), tokenHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *MemberRepository) siteAssignments(ctx context.Context, id string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT site_id FROM member_site_assignments WHERE member_id = ? ORDER BY site_id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var siteIDs []string
+	for rows.Next() {
+		var siteID string
+		if err := rows.Scan(&siteID); err != nil {
+			return nil, err
+		}
+		siteIDs = append(siteIDs, siteID)
+	}
+	return siteIDs, rows.Err()
+}
+
+func (r *MemberRepository) scanRow(scanner Scanner) (*domain.Member, error) {
+	var m domain.Member
+	var hash, email, mfaSecret sql.NullString
+	var lastLogin, createdAt, passwordChanged, mfaEnrolled sql.NullTime
+
+	err := scanner.Scan(
+		&m.ID, &m.UserID, &m.Username, &hash, &m.Name, &email,
+		&m.Role, &m.Status, &lastLogin, &createdAt, &m.MustChangePassword, &passwordChanged,
+		&m.MFAEnabled, &mfaSecret, &mfaEnrolled,
+		&m.OrgName, &m.OrgType, &m.OrgStatus,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.PasswordHash = hash.String
+	m.Email = email.String
+	if lastLogin.Valid {
+		m.LastLoginAt = &lastLogin.Time
+	}
+	if createdAt.Valid {
+		m.CreatedAt = createdAt.Time
+	}
+	if passwordChanged.Valid {
+		m.PasswordChangedAt = &passwordChanged.Time
+	}
+	m.MFASecret = mfaSecret.String
+	if mfaEnrolled.Valid {
+		m.MFAEnrolledAt = &mfaEnrolled.Time
+	}
+	return &m, nil
+}
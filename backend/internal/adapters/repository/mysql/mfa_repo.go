@@ -0,0 +1,97 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cpd-nexus/internal/core/ports"
+)
+
+type MFARepository struct {
+	db *sql.DB
+}
+
+func NewMFARepository(db *sql.DB) ports.MFARepository {
+	return &MFARepository{db: db}
+}
+
+func (r *MFARepository) SetSecret(ctx context.Context, memberID, sealedSecret string, enabled bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var enrolledAt sql.NullTime
+	if enabled {
+		enrolledAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+	// The step is only forgotten with a new secret (assignments run left to right), so enabling a
+	// pending secret keeps the step of the confirming code
+	secret := sql.NullString{String: sealedSecret, Valid: sealedSecret != ""}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE members SET mfa_last_step = IF(mfa_secret <=> ?, mfa_last_step, NULL),
+			mfa_secret = ?, mfa_enabled = ?, mfa_enrolled_at = ?
+		WHERE member_id = ?`,
+		secret, secret, enabled, enrolledAt, memberID)
+	if err != nil {
+		return err
+	}
+	if sealedSecret == "" {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM member_recovery_codes WHERE member_id = ?", memberID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepository) UseStep(ctx context.Context, memberID string, step int64) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE members SET mfa_last_step = ?
+		WHERE member_id = ? AND (mfa_last_step IS NULL OR mfa_last_step < ?)`,
+		step, memberID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, memberID string, codeHashes []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM member_recovery_codes WHERE member_id = ?", memberID); err != nil {
+		return err
+	}
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO member_recovery_codes (member_id, code_hash) VALUES (?, ?)", memberID, hash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, memberID, codeHash string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE member_recovery_codes SET used_at = ?
+		WHERE member_id = ? AND code_hash = ? AND used_at IS NULL`,
+		time.Now(), memberID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, memberID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM member_recovery_codes WHERE member_id = ? AND used_at IS NULL", memberID).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,216 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+)
+
+type OIDCRepository struct {
+	db *sql.DB
+}
+
+func NewOIDCRepository(db *sql.DB) ports.OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+const oidcProviderBaseSelect = `
+    SELECT
+        provider_id, user_id, name, issuer, client_id, client_secret, scopes, email_domains,
+        claim_mapping, auto_provision, default_role, enabled, created_at
+    FROM oidc_providers`
+
+func (r *OIDCRepository) GetProvider(ctx context.Context, id string) (*domain.OIDCProvider, error) {
+	return r.scanProvider(r.db.QueryRowContext(ctx, oidcProviderBaseSelect+" WHERE provider_id = ?", id))
+}
+
+func (r *OIDCRepository) GetProviderByDomain(ctx context.Context, emailDomain string) (*domain.OIDCProvider, error) {
+	return r.scanProvider(r.db.QueryRowContext(ctx,
+		oidcProviderBaseSelect+" WHERE enabled = 1 AND FIND_IN_SET(?, email_domains) > 0 ORDER BY created_at LIMIT 1",
+		strings.ToLower(emailDomain)))
+}
+
+func (r *OIDCRepository) ListProviders(ctx context.Context, userID string) ([]domain.OIDCProvider, error) {
+	rows, err := r.db.QueryContext(ctx, oidcProviderBaseSelect+" WHERE user_id = ? ORDER BY created_at", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	providers := []domain.OIDCProvider{}
+	for rows.Next() {
+		p, err := r.scanProvider(rows)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, *p)
+	}
+	return providers, rows.Err()
+}
+
+func (r *OIDCRepository) CreateProvider(ctx context.Context, p *domain.OIDCProvider) error {
+	mapping, err := json.Marshal(p.Mapping)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO oidc_providers (
+			provider_id, user_id, name, issuer, client_id, client_secret, scopes, email_domains,
+			claim_mapping, auto_provision, default_role, enabled
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		p.ID, p.UserID, p.Name, p.Issuer, p.ClientID,
+		sql.NullString{String: p.ClientSecret, Valid: p.ClientSecret != ""},
+		strings.Join(p.Scopes, ","), strings.Join(p.Domains, ","), string(mapping),
+		p.AutoProvision, p.DefaultRole, p.Enabled)
+	return err
+}
+
+func (r *OIDCRepository) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) error {
+	mapping, err := json.Marshal(p.Mapping)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE oidc_providers SET
+			name = ?, issuer = ?, client_id = ?, client_secret = ?, scopes = ?, email_domains = ?,
+			claim_mapping = ?, auto_provision = ?, default_role = ?, enabled = ?
+		WHERE provider_id = ?`
+
+	_, err = r.db.ExecContext(ctx, query,
+		p.Name, p.Issuer, p.ClientID,
+		sql.NullString{String: p.ClientSecret, Valid: p.ClientSecret != ""},
+		strings.Join(p.Scopes, ","), strings.Join(p.Domains, ","), string(mapping),
+		p.AutoProvision, p.DefaultRole, p.Enabled, p.ID)
+	return err
+}
+
+// DeleteProvider removes the provider together with its identity links and pending logins
+func (r *OIDCRepository) DeleteProvider(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		"DELETE FROM oidc_identities WHERE provider_id = ?",
+		"DELETE FROM oidc_login_states WHERE provider_id = ?",
+		"DELETE FROM oidc_providers WHERE provider_id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *OIDCRepository) SaveState(ctx context.Context, s *domain.OIDCLoginState) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_login_states (state_hash, provider_id, code_verifier, nonce, return_to, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		s.StateHash, s.ProviderID, s.CodeVerifier, s.Nonce, s.ReturnTo, s.ExpiresAt)
+	return err
+}
+
+func (r *OIDCRepository) TakeState(ctx context.Context, stateHash string) (*domain.OIDCLoginState, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var s domain.OIDCLoginState
+	err = tx.QueryRowContext(ctx, `
+		SELECT state_hash, provider_id, code_verifier, nonce, return_to, expires_at
+		FROM oidc_login_states WHERE state_hash = ? FOR UPDATE`, stateHash).
+		Scan(&s.StateHash, &s.ProviderID, &s.CodeVerifier, &s.Nonce, &s.ReturnTo, &s.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM oidc_login_states WHERE state_hash = ?", stateHash); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *OIDCRepository) PurgeStates(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM oidc_login_states WHERE expires_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *OIDCRepository) GetIdentity(ctx context.Context, providerID, subject string) (string, error) {
+	var memberID string
+	err := r.db.QueryRowContext(ctx,
+		"SELECT member_id FROM oidc_identities WHERE provider_id = ? AND subject = ?", providerID, subject).Scan(&memberID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return memberID, err
+}
+
+func (r *OIDCRepository) LinkIdentity(ctx context.Context, providerID, subject, memberID string) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO oidc_identities (provider_id, subject, member_id) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE member_id = VALUES(member_id)`,
+		providerID, subject, memberID)
+	return err
+}
+
+func (r *OIDCRepository) scanProvider(scanner Scanner) (*domain.OIDCProvider, error) {
+	var p domain.OIDCProvider
+	var clientSecret sql.NullString
+	var scopes, domains, mapping string
+	var createdAt sql.NullTime
+
+	err := scanner.Scan(
+		&p.ID, &p.UserID, &p.Name, &p.Issuer, &p.ClientID, &clientSecret, &scopes, &domains,
+		&mapping, &p.AutoProvision, &p.DefaultRole, &p.Enabled, &createdAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.ClientSecret = clientSecret.String
+	p.HasClientSecret = clientSecret.String != ""
+	p.Scopes = splitList(scopes)
+	p.Domains = splitList(domains)
+	if err := json.Unmarshal([]byte(mapping), &p.Mapping); err != nil {
+		return nil, err
+	}
+	if createdAt.Valid {
+		p.CreatedAt = createdAt.Time
+	}
+	return &p, nil
+}
+
+func splitList(s string) []string {
+	list := []string{}
+	for _, v := range strings.Split(s, ",") {
+		if v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
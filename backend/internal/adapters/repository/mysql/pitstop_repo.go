@@ -21,13 +21,9 @@ func (r *PitstopRepository) GetAuthorisations(ctx context.Context, userID string
 		SELECT pitstop_auth_id, dataset_id, dataset_name, user_id, 
 		       regulator_id, regulator_name, on_behalf_of_id, on_behalf_of_name, status, last_synced_at
 		FROM pitstop_authorisations
-	`
-	var args []interface{}
-	if userID != "" {
-		query += " WHERE user_id = ?"
-		args = append(args, userID)
-	}
-	query += " ORDER BY dataset_name ASC"
+		WHERE 1=1`
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query += cond + " ORDER BY dataset_name ASC"
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -37,13 +37,9 @@ func (r *ProjectRepository) Get(ctx context.Context, userID, id string) (*domain
 		LEFT JOIN sites s ON p.site_id = s.site_id
 		LEFT JOIN pitstop_authorisations pa ON p.pitstop_auth_id = pa.pitstop_auth_id`
 
-	args := []interface{}{id}
-	if ports.IsVendor(ctx) {
-		query += " WHERE p.project_id = ?"
-	} else {
-		query += " WHERE p.project_id = ? AND p.user_id = ?"
-		args = append(args, userID)
-	}
+	cond, condArgs := tenantCondition(ctx, "p.user_id", userID)
+	query += " WHERE p.project_id = ?" + cond
+	args := append([]interface{}{id}, condArgs...)
 
 	var p domain.Project
 	var siteID, scanUserID, status, ref, cRef, loc, cName, hdb sql.NullString
@@ -147,14 +143,8 @@ func (r *ProjectRepository) List(ctx context.Context, userID string) ([]domain.P
 		LEFT JOIN pitstop_authorisations pa ON p.pitstop_auth_id = pa.pitstop_auth_id
         WHERE (p.status != ? OR p.status IS NULL)`
 
-	if userID == "" && !ports.IsVendor(ctx) {
-		return nil, apperrors.NewPermissionDenied("user_id is required for multi-tenant isolation")
-	}
-	args := []interface{}{}
-	if !ports.IsVendor(ctx) {
-		query += " AND p.user_id = ?"
-		args = append(args, userID)
-	}
+	cond, args := tenantCondition(ctx, "p.user_id", userID)
+	query += cond
 
 	logger.Infof("[SECURITY] ProjectRepository.List: userID='%s'", userID)
 	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{domain.StatusInactive, domain.StatusInactive}, args...)...)
@@ -328,7 +318,8 @@ func (r *ProjectRepository) Delete(ctx context.Context, userID, id string) error
 	defer tx.Rollback()
 
 	// 1. Deactivate project
-	res, err := tx.ExecContext(ctx, "UPDATE projects SET status = ? WHERE project_id = ? AND user_id = ?", domain.StatusInactive, id, userID)
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	res, err := tx.ExecContext(ctx, "UPDATE projects SET status = ? WHERE project_id = ?"+cond, append([]interface{}{domain.StatusInactive, id}, args...)...)
 	if err != nil {
 		return err
 	}
@@ -352,22 +343,27 @@ func (r *ProjectRepository) AssignToSite(ctx context.Context, siteID string, pro
 	}
 	defer tx.Rollback()
 
+	if err := requireSiteInScope(ctx, tx, siteID); err != nil {
+		return err
+	}
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
+
 	// 1. Unassign all projects currently on this site
-	_, err = tx.ExecContext(ctx, "UPDATE projects SET site_id = NULL WHERE site_id = ?", siteID)
+	_, err = tx.ExecContext(ctx, "UPDATE projects SET site_id = NULL WHERE site_id = ?"+cond, append([]interface{}{siteID}, condArgs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to clear old project assignments: %w", err)
 	}
 
 	// 2. Assign new projects
 	if len(projectIDs) > 0 {
-		stmt, err := tx.PrepareContext(ctx, "UPDATE projects SET site_id = ? WHERE project_id = ?")
+		stmt, err := tx.PrepareContext(ctx, "UPDATE projects SET site_id = ? WHERE project_id = ?"+cond)
 		if err != nil {
 			return err
 		}
 		defer stmt.Close()
 
 		for _, projId := range projectIDs {
-			if _, err := stmt.ExecContext(ctx, siteID, projId); err != nil {
+			if _, err := stmt.ExecContext(ctx, append([]interface{}{siteID, projId}, condArgs...)...); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,137 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+)
+
+type PunchRepository struct {
+	db *sql.DB
+}
+
+func NewPunchRepository(db *sql.DB) ports.PunchRepository {
+	return &PunchRepository{db: db}
+}
+
+const punchBaseSelect = `
+	SELECT punch_id, worker_id, site_id, user_id, device_sn, direction, punched_at, source, raw_payload, created_at
+	FROM attendance_punches`
+
+// Create stores a punch, ignoring one already recorded for the same (worker, punched_at, device)
+// so re-fetching a window does not duplicate scans. Returns true when a new row was inserted.
+func (r *PunchRepository) Create(ctx context.Context, p *domain.Punch) (bool, error) {
+	query := `
+		INSERT INTO attendance_punches
+			(worker_id, site_id, user_id, device_sn, direction, punched_at, source, raw_payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, NOW())
+		ON DUPLICATE KEY UPDATE punch_id = punch_id`
+	res, err := r.db.ExecContext(ctx, query,
+		p.WorkerID,
+		sql.NullString{String: p.SiteID, Valid: p.SiteID != ""},
+		sql.NullString{String: p.UserID, Valid: p.UserID != ""},
+		p.DeviceSN, p.Direction, p.PunchedAt, p.Source,
+		sql.NullString{String: p.RawPayload, Valid: p.RawPayload != ""},
+	)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 1 {
+		if id, err := res.LastInsertId(); err == nil {
+			p.ID = id
+		}
+	}
+	return rowsAffected == 1, nil
+}
+
+// List retrieves punches filtered by optional siteID, workerID and date, within the caller's scope on userID.
+func (r *PunchRepository) List(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Punch, error) {
+	query := punchBaseSelect + " WHERE 1=1"
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query += cond
+
+	if siteID != "" {
+		query += " AND site_id = ?"
+		args = append(args, siteID)
+	}
+	if workerID != "" {
+		query += " AND worker_id = ?"
+		args = append(args, workerID)
+	}
+	if date != "" {
+		query += " AND DATE(punched_at) = ?"
+		args = append(args, date)
+	}
+	query += " ORDER BY punched_at DESC"
+	return r.query(ctx, query, args...)
+}
+
+// ListByWorker returns a worker's punches in [from, to), oldest first, within the caller's scope.
+func (r *PunchRepository) ListByWorker(ctx context.Context, workerID string, from, to time.Time) ([]domain.Punch, error) {
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
+	query := punchBaseSelect + " WHERE worker_id = ? AND punched_at >= ? AND punched_at < ?" + cond + " ORDER BY punched_at, punch_id"
+	return r.query(ctx, query, append([]interface{}{workerID, from, to}, condArgs...)...)
+}
+
+// ListWorkerIDs returns the workers with punches in [from, to) within the caller's scope on userID.
+func (r *PunchRepository) ListWorkerIDs(ctx context.Context, userID, workerID string, from, to time.Time) ([]string, error) {
+	query := "SELECT DISTINCT worker_id FROM attendance_punches WHERE punched_at >= ? AND punched_at < ?"
+	args := []interface{}{from, to}
+	cond, condArgs := tenantCondition(ctx, "user_id", userID)
+	query += cond
+	args = append(args, condArgs...)
+	if workerID != "" {
+		query += " AND worker_id = ?"
+		args = append(args, workerID)
+	}
+	query += " ORDER BY worker_id"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *PunchRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Punch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var punches []domain.Punch
+	for rows.Next() {
+		var p domain.Punch
+		var siteID, userID, raw sql.NullString
+		var created sql.NullTime
+		if err := rows.Scan(&p.ID, &p.WorkerID, &siteID, &userID, &p.DeviceSN, &p.Direction, &p.PunchedAt, &p.Source, &raw, &created); err != nil {
+			return nil, err
+		}
+		p.SiteID = siteID.String
+		p.UserID = userID.String
+		p.RawPayload = raw.String
+		if created.Valid {
+			p.CreatedAt = created.Time
+		}
+		punches = append(punches, p)
+	}
+	return punches, rows.Err()
+}
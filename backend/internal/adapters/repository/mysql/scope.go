@@ -0,0 +1,48 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// scopeCondition restricts column (an owning user_id) to the tenants in scope, as an
+// " AND ..." fragment with its arguments. A global scope adds nothing and an empty scope
+// matches no rows, so a query built with it can never see past the caller's tenants.
+func scopeCondition(column string, scope domain.Scope) (string, []interface{}) {
+	if scope.IsGlobal() {
+		return "", nil
+	}
+	if len(scope.TenantIDs) == 0 {
+		return " AND 1 = 0", nil
+	}
+	args := make([]interface{}, len(scope.TenantIDs))
+	for i, id := range scope.TenantIDs {
+		args[i] = id
+	}
+	if len(args) == 1 {
+		return " AND " + column + " = ?", args
+	}
+	return " AND " + column + " IN (" + strings.TrimSuffix(strings.Repeat("?,", len(args)), ",") + ")", args
+}
+
+// tenantCondition is scopeCondition for the scope the caller in ctx has on tenantID (see ports.ScopeFor).
+func tenantCondition(ctx context.Context, column, tenantID string) (string, []interface{}) {
+	return scopeCondition(column, ports.ScopeFor(ctx, tenantID))
+}
+
+// requireSiteInScope fails with NotFound unless siteID belongs to a tenant the caller may see.
+// Bulk assignments call it first so a caller cannot attach its assets to another tenant's site.
+func requireSiteInScope(ctx context.Context, tx *sql.Tx, siteID string) error {
+	cond, args := tenantCondition(ctx, "user_id", "")
+	var found string
+	err := tx.QueryRowContext(ctx, "SELECT site_id FROM sites WHERE site_id = ?"+cond, append([]interface{}{siteID}, args...)...).Scan(&found)
+	if err == sql.ErrNoRows {
+		return apperrors.NewNotFound("site", siteID)
+	}
+	return err
+}
@@ -0,0 +1,158 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) ports.SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+const sessionBaseSelect = `
+    SELECT
+        session_id, family_id, member_id, user_id, token_hash, access_jti, access_expires_at,
+        expires_at, status, replaced_by, ip_address, user_agent, created_at, revoked_at, revoked_reason
+    FROM auth_sessions`
+
+func (r *SessionRepository) Create(ctx context.Context, s *domain.AuthSession) error {
+	userAgent := s.UserAgent
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	query := `
+		INSERT INTO auth_sessions (session_id, family_id, member_id, user_id, token_hash, access_jti,
+			access_expires_at, expires_at, status, ip_address, user_agent)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	_, err := r.db.ExecContext(ctx, query,
+		s.ID, s.FamilyID, s.MemberID, s.UserID, s.TokenHash, s.AccessJTI,
+		s.AccessExpiresAt, s.ExpiresAt, s.Status,
+		sql.NullString{String: s.IPAddress, Valid: s.IPAddress != ""}, sql.NullString{String: userAgent, Valid: userAgent != ""})
+	return err
+}
+
+func (r *SessionRepository) Get(ctx context.Context, id string) (*domain.AuthSession, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, sessionBaseSelect+" WHERE session_id = ?", id))
+}
+
+func (r *SessionRepository) GetByTokenHash(ctx context.Context, hash string) (*domain.AuthSession, error) {
+	return r.scanRow(r.db.QueryRowContext(ctx, sessionBaseSelect+" WHERE token_hash = ?", hash))
+}
+
+func (r *SessionRepository) Rotate(ctx context.Context, id, replacedBy string) (bool, error) {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE auth_sessions SET status = 'rotated', replaced_by = ? WHERE session_id = ? AND status = 'active'",
+		replacedBy, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *SessionRepository) RevokeFamily(ctx context.Context, familyID, reason string) (int64, error) {
+	return r.revoke(ctx, "family_id = ?", familyID, reason)
+}
+
+func (r *SessionRepository) RevokeMember(ctx context.Context, memberID, reason string) (int64, error) {
+	return r.revoke(ctx, "member_id = ?", memberID, reason)
+}
+
+func (r *SessionRepository) RevokeUser(ctx context.Context, userID, reason string) (int64, error) {
+	return r.revoke(ctx, "user_id = ?", userID, reason)
+}
+
+// revoke blocks the access tokens of every matching session that may still be valid, rotated ones
+// included, then marks the active sessions revoked.
+func (r *SessionRepository) revoke(ctx context.Context, where string, arg interface{}, reason string) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Times are passed from Go so they compare in the same zone the driver wrote them in
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, `
+		INSERT IGNORE INTO revoked_tokens (jti, member_id, expires_at)
+		SELECT access_jti, member_id, access_expires_at FROM auth_sessions
+		WHERE `+where+` AND access_expires_at > ?`,
+		arg, now)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE auth_sessions SET status = 'revoked', revoked_at = ?, revoked_reason = ?
+		WHERE `+where+` AND status = 'active'`,
+		now, reason, arg)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return n, tx.Commit()
+}
+
+func (r *SessionRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var exists int
+	err := r.db.QueryRowContext(ctx, "SELECT 1 FROM revoked_tokens WHERE jti = ?", jti).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// PurgeExpired deletes sessions and revocation entries that can no longer be presented.
+func (r *SessionRepository) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM revoked_tokens WHERE expires_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	purged, _ := res.RowsAffected()
+
+	res, err = r.db.ExecContext(ctx, "DELETE FROM auth_sessions WHERE expires_at < ?", before)
+	if err != nil {
+		return purged, err
+	}
+	n, _ := res.RowsAffected()
+	return purged + n, nil
+}
+
+func (r *SessionRepository) scanRow(scanner Scanner) (*domain.AuthSession, error) {
+	var s domain.AuthSession
+	var replacedBy, ip, userAgent, reason sql.NullString
+	var createdAt, revokedAt sql.NullTime
+
+	err := scanner.Scan(
+		&s.ID, &s.FamilyID, &s.MemberID, &s.UserID, &s.TokenHash, &s.AccessJTI, &s.AccessExpiresAt,
+		&s.ExpiresAt, &s.Status, &replacedBy, &ip, &userAgent, &createdAt, &revokedAt, &reason,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.ReplacedBy = replacedBy.String
+	s.IPAddress = ip.String
+	s.UserAgent = userAgent.String
+	s.RevokedReason = reason.String
+	if createdAt.Valid {
+		s.CreatedAt = createdAt.Time
+	}
+	if revokedAt.Valid {
+		s.RevokedAt = &revokedAt.Time
+	}
+	return &s, nil
+}
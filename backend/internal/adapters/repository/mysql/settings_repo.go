@@ -17,7 +17,8 @@ func NewMySQLSettingsRepository(db *sql.DB) *MySQLSettingsRepository {
 func (r *MySQLSettingsRepository) GetSettings(ctx context.Context) (*domain.SystemSettings, error) {
 	query := `
 		SELECT id, attendance_sync_time, cpd_submission_time, 
-		       max_payload_size_kb, max_workers_per_request, max_requests_per_minute, updated_at 
+		       max_payload_size_kb, max_workers_per_request, max_requests_per_minute,
+		       attendance_pairing_mode, punch_debounce_seconds, max_session_hours, manual_attendance_policy, updated_at 
 		FROM system_settings WHERE id = 1`
 
 	var s domain.SystemSettings
@@ -31,6 +32,10 @@ func (r *MySQLSettingsRepository) GetSettings(ctx context.Context) (*domain.Syst
 		&s.MaxPayloadSizeKB,
 		&s.MaxWorkersPerRequest,
 		&s.MaxRequestsPerMinute,
+		&s.AttendancePairingMode,
+		&s.PunchDebounceSeconds,
+		&s.MaxSessionHours,
+		&s.ManualAttendancePolicy,
 		&updated,
 	)
 	if err != nil {
@@ -50,7 +55,9 @@ func (r *MySQLSettingsRepository) UpdateSettings(ctx context.Context, s domain.S
 	query := `
 		UPDATE system_settings 
 		SET attendance_sync_time=?, cpd_submission_time=?,
-		    max_payload_size_kb=?, max_workers_per_request=?, max_requests_per_minute=?
+		    max_payload_size_kb=?, max_workers_per_request=?, max_requests_per_minute=?,
+		    attendance_pairing_mode=?, punch_debounce_seconds=?, max_session_hours=?,
+		    manual_attendance_policy=?
 		WHERE id=1`
 	_, err := r.DB.ExecContext(ctx, query,
 		s.AttendanceSyncTime,
@@ -58,6 +65,10 @@ func (r *MySQLSettingsRepository) UpdateSettings(ctx context.Context, s domain.S
 		s.MaxPayloadSizeKB,
 		s.MaxWorkersPerRequest,
 		s.MaxRequestsPerMinute,
+		s.AttendancePairingMode,
+		s.PunchDebounceSeconds,
+		s.MaxSessionHours,
+		s.ManualAttendancePolicy,
 	)
 	return err
 }
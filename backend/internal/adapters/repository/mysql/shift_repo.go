@@ -0,0 +1,146 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+type ShiftRepository struct {
+	db *sql.DB
+}
+
+func NewShiftRepository(db *sql.DB) ports.ShiftRepository {
+	return &ShiftRepository{db: db}
+}
+
+const shiftBaseSelect = `
+	SELECT shift_id, user_id, site_id, project_id, name, start_time, end_time,
+		grace_before_minutes, grace_after_minutes, status, created_at, updated_at
+	FROM shifts`
+
+func (r *ShiftRepository) Get(ctx context.Context, userID, id string) (*domain.Shift, error) {
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	shifts, err := r.query(ctx, shiftBaseSelect+" WHERE shift_id = ?"+cond, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	if len(shifts) == 0 {
+		return nil, apperrors.NewNotFound("shift", id)
+	}
+	return &shifts[0], nil
+}
+
+func (r *ShiftRepository) List(ctx context.Context, userID, siteID, projectID string) ([]domain.Shift, error) {
+	query := shiftBaseSelect + " WHERE 1=1"
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	query += cond
+	if siteID != "" {
+		query += " AND site_id = ?"
+		args = append(args, siteID)
+	}
+	if projectID != "" {
+		query += " AND project_id = ?"
+		args = append(args, projectID)
+	}
+	query += " ORDER BY site_id, project_id, start_time"
+	return r.query(ctx, query, args...)
+}
+
+// ListApplicable returns the active shifts of the site (those without a project) and of the project.
+func (r *ShiftRepository) ListApplicable(ctx context.Context, siteID, projectID string) ([]domain.Shift, error) {
+	cond, args := tenantCondition(ctx, "user_id", "")
+	query := shiftBaseSelect + `
+		WHERE status = ? AND ((site_id = ? AND project_id IS NULL) OR (project_id IS NOT NULL AND project_id = ?))` + cond + `
+		ORDER BY start_time`
+	return r.query(ctx, query, append([]interface{}{domain.StatusActive, siteID, projectID}, args...)...)
+}
+
+func (r *ShiftRepository) Create(ctx context.Context, s *domain.Shift) error {
+	query := `
+		INSERT INTO shifts
+			(shift_id, user_id, site_id, project_id, name, start_time, end_time,
+			 grace_before_minutes, grace_after_minutes, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())`
+	_, err := r.db.ExecContext(ctx, query,
+		s.ID, s.UserID,
+		sql.NullString{String: s.SiteID, Valid: s.SiteID != ""},
+		sql.NullString{String: s.ProjectID, Valid: s.ProjectID != ""},
+		s.Name, s.StartTime, s.EndTime, s.GraceBeforeMinutes, s.GraceAfterMinutes, s.Status,
+	)
+	return err
+}
+
+func (r *ShiftRepository) Update(ctx context.Context, s *domain.Shift) error {
+	cond, args := tenantCondition(ctx, "user_id", s.UserID)
+	query := `
+		UPDATE shifts
+		SET name = ?, start_time = ?, end_time = ?, grace_before_minutes = ?, grace_after_minutes = ?, status = ?
+		WHERE shift_id = ?` + cond
+	res, err := r.db.ExecContext(ctx, query, append([]interface{}{
+		s.Name, s.StartTime, s.EndTime, s.GraceBeforeMinutes, s.GraceAfterMinutes, s.Status, s.ID,
+	}, args...)...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// MySQL reports 0 for an unchanged row too; tell the two apart
+		if _, err := r.Get(ctx, s.UserID, s.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ShiftRepository) Delete(ctx context.Context, userID, id string) error {
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	res, err := r.db.ExecContext(ctx, "DELETE FROM shifts WHERE shift_id = ?"+cond, append([]interface{}{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return apperrors.NewNotFound("shift", id)
+	}
+	return nil
+}
+
+func (r *ShiftRepository) query(ctx context.Context, query string, args ...interface{}) ([]domain.Shift, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shifts []domain.Shift
+	for rows.Next() {
+		var s domain.Shift
+		var siteID, projectID sql.NullString
+		if err := rows.Scan(
+			&s.ID, &s.UserID, &siteID, &projectID, &s.Name, &s.StartTime, &s.EndTime,
+			&s.GraceBeforeMinutes, &s.GraceAfterMinutes, &s.Status, &s.CreatedAt, &s.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		s.SiteID = siteID.String
+		s.ProjectID = projectID.String
+		// TIME columns come back as HH:MM:SS; shifts are defined to the minute
+		s.StartTime = shiftClock(s.StartTime)
+		s.EndTime = shiftClock(s.EndTime)
+		shifts = append(shifts, s)
+	}
+	return shifts, rows.Err()
+}
+
+func shiftClock(value string) string {
+	if len(value) == len("15:04:05") {
+		return value[:5]
+	}
+	return value
+}
@@ -30,13 +30,9 @@ func (r *SiteRepository) Get(ctx context.Context, userID, id string) (*domain.Si
 		FROM sites s
 		LEFT JOIN users u ON s.user_id = u.user_id`
 
-	args := []interface{}{id}
-	if ports.IsVendor(ctx) {
-		query += " WHERE s.site_id = ?"
-	} else {
-		query += " WHERE s.site_id = ? AND s.user_id = ?"
-		args = append(args, userID)
-	}
+	cond, condArgs := tenantCondition(ctx, "s.user_id", userID)
+	query += " WHERE s.site_id = ?" + cond
+	args := append([]interface{}{id}, condArgs...)
 
 	var s domain.Site
 	var scanUserID, loc, userName sql.NullString
@@ -84,17 +80,10 @@ func (r *SiteRepository) List(ctx context.Context, userID string) ([]domain.Site
         LEFT JOIN users u ON s.user_id = u.user_id
         WHERE 1=1 `
 
-	args := []interface{}{}
 	logger.Infof("[SECURITY] SiteRepository.List: userID='%s'", userID)
 
-	if userID == "" && !ports.IsVendor(ctx) {
-		return nil, apperrors.NewPermissionDenied("user_id is required for multi-tenant isolation")
-	}
-
-	if !ports.IsVendor(ctx) {
-		query += " AND s.user_id = ?"
-		args = append(args, userID)
-	}
+	cond, args := tenantCondition(ctx, "s.user_id", userID)
+	query += cond
 
 	rows, err := r.db.QueryContext(ctx, query, append([]interface{}{domain.StatusInactive}, args...)...)
 	if err != nil {
@@ -167,18 +156,21 @@ func (r *SiteRepository) Delete(ctx context.Context, userID, id string) error {
 	}
 	defer tx.Rollback()
 
+	cond, condArgs := tenantCondition(ctx, "user_id", userID)
+	args := append([]interface{}{id}, condArgs...)
+
 	// 1. Unassign projects from this site
-	if _, err := tx.ExecContext(ctx, "UPDATE projects SET site_id = NULL WHERE site_id = ? AND user_id = ?", id, userID); err != nil {
+	if _, err := tx.ExecContext(ctx, "UPDATE projects SET site_id = NULL WHERE site_id = ?"+cond, args...); err != nil {
 		return fmt.Errorf("failed to unassign projects: %w", err)
 	}
 
 	// 2. Unassign devices from this site
-	if _, err := tx.ExecContext(ctx, "UPDATE devices SET site_id = NULL WHERE site_id = ? AND user_id = ?", id, userID); err != nil {
+	if _, err := tx.ExecContext(ctx, "UPDATE devices SET site_id = NULL WHERE site_id = ?"+cond, args...); err != nil {
 		return fmt.Errorf("failed to unassign devices: %w", err)
 	}
 
 	// 3. Delete the site
-	res, err := tx.ExecContext(ctx, "DELETE FROM sites WHERE site_id = ? AND user_id = ?", id, userID)
+	res, err := tx.ExecContext(ctx, "DELETE FROM sites WHERE site_id = ?"+cond, args...)
 	if err != nil {
 		return fmt.Errorf("failed to delete site: %w", err)
 	}
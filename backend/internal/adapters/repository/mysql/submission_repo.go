@@ -3,16 +3,19 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"strings"
 	"time"
 )
 
 type SubmissionRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	policy domain.SubmissionRetryPolicy
 }
 
-func NewSubmissionRepository(db *sql.DB) ports.SubmissionRepository {
-	return &SubmissionRepository{db: db}
+func NewSubmissionRepository(db *sql.DB, policy domain.SubmissionRetryPolicy) ports.SubmissionRepository {
+	return &SubmissionRepository{db: db, policy: policy}
 }
 
 func (r *SubmissionRepository) LogSubmission(ctx context.Context, dataElementID, internalID, status, payload, errorMessage string) error {
@@ -31,7 +34,7 @@ func (r *SubmissionRepository) LogSubmission(ctx context.Context, dataElementID,
 func (r *SubmissionRepository) UpdateAttendanceStatus(ctx context.Context, attendanceID, status, responsePayload, errorMessage string) error {
 	query := `
 		UPDATE attendance
-		SET status = ?, response_payload = ?, error_message = ?, updated_at = ?
+		SET status = ?, response_payload = ?, error_message = ?, next_retry_at = NULL, updated_at = ?
 		WHERE attendance_id = ?
 	`
 	var p interface{} = responsePayload
@@ -41,3 +44,103 @@ func (r *SubmissionRepository) UpdateAttendanceStatus(ctx context.Context, atten
 	_, err := r.db.ExecContext(ctx, query, status, p, errorMessage, time.Now(), attendanceID)
 	return err
 }
+
+func (r *SubmissionRepository) RecordAttendanceFailure(ctx context.Context, attendanceID, responsePayload, errorMessage string, retryable bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var retryCount int
+	err = tx.QueryRowContext(ctx, "SELECT COALESCE(retry_count, 0) FROM attendance WHERE attendance_id = ? FOR UPDATE", attendanceID).Scan(&retryCount)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	attempts := retryCount + 1
+	status := domain.SubmissionStatusFailed
+	var nextRetry interface{}
+	if !retryable || r.policy.Exhausted(attempts) {
+		status = domain.SubmissionStatusDeadLetter
+	} else {
+		nextRetry = now.Add(r.policy.Backoff(attempts))
+	}
+
+	var p interface{} = responsePayload
+	if responsePayload == "" {
+		p = nil
+	}
+
+	query := `
+		UPDATE attendance
+		SET status = ?, response_payload = ?, error_message = ?, retry_count = ?, next_retry_at = ?, updated_at = ?
+		WHERE attendance_id = ?
+	`
+	if _, err := tx.ExecContext(ctx, query, status, p, errorMessage, attempts, nextRetry, now, attendanceID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListDeadLetterAttendance returns dead-lettered rows, newest first. Empty userID lists every tenant in the caller's scope.
+func (r *SubmissionRepository) ListDeadLetterAttendance(ctx context.Context, userID string) ([]domain.DeadLetterSubmission, error) {
+	query := `
+		SELECT a.attendance_id, a.worker_id, w.name, a.user_id, a.submission_date,
+		       COALESCE(a.retry_count, 0), COALESCE(a.error_message, ''), a.updated_at
+		FROM attendance a
+		LEFT JOIN workers w ON a.worker_id = w.worker_id
+		WHERE a.status = ?`
+	cond, condArgs := tenantCondition(ctx, "a.user_id", userID)
+	query += cond + " ORDER BY a.updated_at DESC"
+	args := append([]interface{}{domain.SubmissionStatusDeadLetter}, condArgs...)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []domain.DeadLetterSubmission
+	for rows.Next() {
+		var d domain.DeadLetterSubmission
+		var workerName, owner sql.NullString
+		var submissionDate time.Time
+		if err := rows.Scan(&d.AttendanceID, &d.WorkerID, &workerName, &owner, &submissionDate, &d.RetryCount, &d.ErrorMessage, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		d.WorkerName = workerName.String
+		d.UserID = owner.String
+		d.SubmissionDate = submissionDate.Format("2006-01-02")
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// RequeueAttendance moves dead-lettered rows back to pending with a fresh retry budget.
+// Empty userID allows requeueing across the tenants in the caller's scope.
+func (r *SubmissionRepository) RequeueAttendance(ctx context.Context, userID string, attendanceIDs []string) (int64, error) {
+	if len(attendanceIDs) == 0 {
+		return 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(attendanceIDs)), ",")
+	query := `
+		UPDATE attendance
+		SET status = ?, retry_count = 0, next_retry_at = NULL, error_message = NULL
+		WHERE status = ? AND attendance_id IN (` + placeholders + `)`
+	args := []interface{}{domain.SubmissionStatusPending, domain.SubmissionStatusDeadLetter}
+	for _, id := range attendanceIDs {
+		args = append(args, id)
+	}
+	cond, condArgs := tenantCondition(ctx, "user_id", userID)
+	query += cond
+	args = append(args, condArgs...)
+
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
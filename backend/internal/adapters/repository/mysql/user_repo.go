@@ -21,9 +21,11 @@ const userBaseSelect = `
         u.user_id, u.user_name, u.username, u.user_type, u.status, 
         u.latitude, u.longitude, u.contact_email, u.contact_phone, u.address, u.password_hash,
         u.bridge_ws_url, u.bridge_auth_token, u.bridge_status,
+        bc.status, bc.connected_at, bc.last_seen, bc.remote_addr,
         (SELECT COUNT(*) FROM workers w WHERE w.user_id = u.user_id AND w.status = ?) as worker_count,
         (SELECT COUNT(*) FROM devices d WHERE d.user_id = u.user_id AND d.status != ?) as device_count
-    FROM users u`
+    FROM users u
+    LEFT JOIN bridge_connections bc ON bc.user_id = u.user_id`
 
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
 	query := userBaseSelect + " WHERE u.username = ? AND u.status = ?"
@@ -35,8 +37,10 @@ func (r *UserRepository) Get(ctx context.Context, id string) (*domain.User, erro
 	return r.scanRow(r.db.QueryRowContext(ctx, query, domain.StatusActive, domain.StatusInactive, id))
 }
 
+// List returns the organisations in the caller's scope.
 func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
-	rows, err := r.db.QueryContext(ctx, userBaseSelect, domain.StatusActive, domain.StatusInactive)
+	cond, args := tenantCondition(ctx, "u.user_id", "")
+	rows, err := r.db.QueryContext(ctx, userBaseSelect+" WHERE 1=1"+cond, append([]interface{}{domain.StatusActive, domain.StatusInactive}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +57,8 @@ func (r *UserRepository) List(ctx context.Context) ([]domain.User, error) {
 	return users, nil
 }
 
-func (r *UserRepository) Create(ctx context.Context, u *domain.User) error {
+// Create stores the organisation and its primary login, if any, in one transaction.
+func (r *UserRepository) Create(ctx context.Context, u *domain.User, primary *domain.Member) error {
 	if u.ID == "" {
 		id, err := idgen.GenerateNextID(r.db, "users", "user_id", "user")
 		if err != nil {
@@ -62,6 +67,12 @@ func (r *UserRepository) Create(ctx context.Context, u *domain.User) error {
 		u.ID = id
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO users (
 			user_id, user_name, user_type, contact_email, contact_phone, 
@@ -69,11 +80,21 @@ func (r *UserRepository) Create(ctx context.Context, u *domain.User) error {
             bridge_ws_url, bridge_auth_token, bridge_status
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 
-	_, err := r.db.ExecContext(ctx, query,
+	if _, err := tx.ExecContext(ctx, query,
 		u.ID, u.Name, u.UserType, u.ContactEmail, u.ContactPhone,
 		u.Username, u.PasswordHash, u.Status, u.Address, u.Latitude, u.Longitude,
-		u.BridgeWSURL, u.BridgeAuthToken, u.BridgeStatus)
-	return err
+		u.BridgeWSURL, u.BridgeAuthToken, u.BridgeStatus); err != nil {
+		return err
+	}
+
+	if primary != nil {
+		primary.ID = u.ID
+		primary.UserID = u.ID
+		if err := insertMember(ctx, tx, primary); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
 }
 
 func (r *UserRepository) Update(ctx context.Context, u *domain.User) error {
@@ -114,6 +135,48 @@ func (r *UserRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
+	// 4. Deactivate every member login of the organisation
+	if _, err := tx.ExecContext(ctx, "UPDATE members SET status = ? WHERE user_id = ?", domain.StatusInactive, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *UserRepository) ListVendorTenants(ctx context.Context, vendorID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT tenant_user_id FROM vendor_tenants WHERE vendor_user_id = ? ORDER BY tenant_user_id", vendorID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, id)
+	}
+	return tenants, rows.Err()
+}
+
+// SetVendorTenants replaces the organisations a vendor supports. An empty list restores its global scope.
+func (r *UserRepository) SetVendorTenants(ctx context.Context, vendorID string, tenantIDs []string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM vendor_tenants WHERE vendor_user_id = ?", vendorID); err != nil {
+		return err
+	}
+	for _, id := range tenantIDs {
+		if _, err := tx.ExecContext(ctx, "INSERT IGNORE INTO vendor_tenants (vendor_user_id, tenant_user_id) VALUES (?, ?)", vendorID, id); err != nil {
+			return err
+		}
+	}
 	return tx.Commit()
 }
 
@@ -122,11 +185,14 @@ func (r *UserRepository) scanRow(scanner Scanner) (*domain.User, error) {
 	var lat, lng sql.NullFloat64
 	var email, phone, addr, hash sql.NullString
 	var bridgeWSURL, bridgeAuthToken sql.NullString
+	var connStatus, connAddr sql.NullString
+	var connectedAt, lastSeen sql.NullTime
 
 	err := scanner.Scan(
 		&u.ID, &u.Name, &u.Username, &u.UserType, &u.Status,
 		&lat, &lng, &email, &phone, &addr, &hash,
 		&bridgeWSURL, &bridgeAuthToken, &u.BridgeStatus,
+		&connStatus, &connectedAt, &lastSeen, &connAddr,
 		&u.WorkerCount, &u.DeviceCount,
 	)
 	if err == sql.ErrNoRows {
@@ -163,5 +229,17 @@ func (r *UserRepository) scanRow(scanner Scanner) (*domain.User, error) {
 		u.BridgeAuthToken = &s
 	}
 
+	u.BridgeConnection = domain.BridgeDisconnected
+	if connStatus.Valid {
+		u.BridgeConnection = connStatus.String
+	}
+	if connectedAt.Valid {
+		u.BridgeConnectedAt = &connectedAt.Time
+	}
+	if lastSeen.Valid {
+		u.BridgeLastSeen = &lastSeen.Time
+	}
+	u.BridgeRemoteAddr = connAddr.String
+
 	return &u, nil
 }
@@ -24,15 +24,8 @@ func NewWorkerRepository(db *sql.DB) ports.WorkerRepository {
 }
 
 func (r *WorkerRepository) Get(ctx context.Context, userID, id string) (*domain.Worker, error) {
-	var worker *domain.Worker
-	var err error
-	// isVendor is set in context by WorkerService, which reads it from middleware — repo does not import middleware
-	isVendor, _ := ctx.Value(ports.IsVendorContextKey).(bool)
-	if isVendor {
-		worker, err = r.scanRow(r.db.QueryRowContext(ctx, workerBaseSelect+" WHERE w.worker_id = ?", id))
-	} else {
-		worker, err = r.scanRow(r.db.QueryRowContext(ctx, workerBaseSelect+" WHERE w.worker_id = ? AND w.user_id = ?", id, userID))
-	}
+	cond, args := tenantCondition(ctx, "w.user_id", userID)
+	worker, err := r.scanRow(r.db.QueryRowContext(ctx, workerBaseSelect+" WHERE w.worker_id = ?"+cond, append([]interface{}{id}, args...)...))
 	if err == sql.ErrNoRows {
 		return nil, apperrors.NewNotFound("worker", id)
 	}
@@ -41,7 +34,11 @@ func (r *WorkerRepository) Get(ctx context.Context, userID, id string) (*domain.
 
 func (r *WorkerRepository) GetByFIN(ctx context.Context, fin string) (*domain.Worker, error) {
 	query := workerBaseSelect + " WHERE w.person_id_no = ? LIMIT 1"
-	return r.scanRow(r.db.QueryRowContext(ctx, query, fin))
+	worker, err := r.scanRow(r.db.QueryRowContext(ctx, query, fin))
+	if err == sql.ErrNoRows {
+		return nil, apperrors.NewNotFound("worker", fin)
+	}
+	return worker, err
 }
 
 const workerBaseSelect = `
@@ -67,13 +64,9 @@ const workerBaseSelect = `
 func (r *WorkerRepository) List(ctx context.Context, userID, siteID string) ([]domain.Worker, error) {
 	// Use parameterized placeholder for status — never concatenate domain constants (#3)
 	query := workerBaseSelect + " WHERE w.status = ?"
-	args := []interface{}{domain.StatusActive}
-
-	isVendor, _ := ctx.Value(ports.IsVendorContextKey).(bool)
-	if userID != "" && !isVendor {
-		query += " AND w.user_id = ?"
-		args = append(args, userID)
-	}
+	cond, condArgs := tenantCondition(ctx, "w.user_id", userID)
+	query += cond
+	args := append([]interface{}{domain.StatusActive}, condArgs...)
 	if siteID != "" {
 		query += " AND s.site_id = ?"
 		args = append(args, siteID)
@@ -131,15 +124,18 @@ func (r *WorkerRepository) Create(ctx context.Context, w *domain.Worker) error {
 	return nil
 }
 
+// Update saves the worker. Only a worker currently owned by a tenant in the caller's scope is changed;
+// w.UserID may name its new owner.
 func (r *WorkerRepository) Update(ctx context.Context, w *domain.Worker) error {
+	cond, condArgs := tenantCondition(ctx, "user_id", "")
 	query := `
         UPDATE workers SET 
             name=?, status=?, user_type=?, current_project_id=?, user_id=?,
             person_id_no=?, person_id_and_work_pass_type=?, person_nationality=?, person_trade=?,
             auth_start_time=?, auth_end_time=?, fdid=?, face_img_loc=?, card_number=?, card_type=?, is_synced=?
-        WHERE worker_id=?`
+        WHERE worker_id=?` + cond
 
-	_, err := r.db.ExecContext(ctx, query,
+	res, err := r.db.ExecContext(ctx, query, append([]interface{}{
 		w.Name, w.Status, w.UserType,
 		sql.NullString{String: w.CurrentProjectID, Valid: w.CurrentProjectID != ""},
 		w.UserID,
@@ -152,10 +148,16 @@ func (r *WorkerRepository) Update(ctx context.Context, w *domain.Worker) error {
 		sql.NullString{String: w.CardType, Valid: w.CardType != ""},
 		w.IsSynced,
 		w.ID,
-	)
+	}, condArgs...)...)
 	if err != nil {
 		return fmt.Errorf("failed to update worker: %w", err)
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// MySQL reports 0 for an unchanged row too; tell the two apart
+		if _, err := r.Get(ctx, "", w.ID); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -165,7 +167,8 @@ func (r *WorkerRepository) MarkSynced(ctx context.Context, id string) error {
 }
 
 func (r *WorkerRepository) Delete(ctx context.Context, userID, id string) error {
-	res, err := r.db.ExecContext(ctx, "DELETE FROM workers WHERE worker_id=? AND user_id=?", id, userID)
+	cond, args := tenantCondition(ctx, "user_id", userID)
+	res, err := r.db.ExecContext(ctx, "DELETE FROM workers WHERE worker_id=?"+cond, append([]interface{}{id}, args...)...)
 	if err != nil {
 		return fmt.Errorf("failed to delete worker: %w", err)
 	}
@@ -183,11 +186,9 @@ func (r *WorkerRepository) ListByIsSynced(ctx context.Context, userID string, sy
 	// Use parameterized placeholder for status — never concatenate domain constants (#3)
 	query := workerBaseSelect + " WHERE w.is_synced = ? AND w.status = ? AND u.bridge_status = ?"
 
-	args := []interface{}{syncStatus, domain.StatusActive, domain.StatusActive}
-	if userID != "" {
-		query += " AND w.user_id = ?"
-		args = append(args, userID)
-	}
+	cond, condArgs := tenantCondition(ctx, "w.user_id", userID)
+	query += cond
+	args := append([]interface{}{syncStatus, domain.StatusActive, domain.StatusActive}, condArgs...)
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -331,7 +332,8 @@ func (r *WorkerRepository) AssignToProject(ctx context.Context, projectID string
 
 func (r *WorkerRepository) GetProjectUserID(ctx context.Context, projectID string) (string, error) {
 	var projectUserID string
-	err := r.db.QueryRowContext(ctx, "SELECT user_id FROM projects WHERE project_id = ?", projectID).Scan(&projectUserID)
+	cond, args := tenantCondition(ctx, "user_id", "")
+	err := r.db.QueryRowContext(ctx, "SELECT user_id FROM projects WHERE project_id = ?"+cond, append([]interface{}{projectID}, args...)...).Scan(&projectUserID)
 	if err != nil {
 		return "", err
 	}
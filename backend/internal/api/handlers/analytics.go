@@ -3,6 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+
 	"cpd-nexus/internal/core/ports"
 )
 
@@ -14,12 +15,18 @@ func NewAnalyticsHandler(service ports.AnalyticsService) *AnalyticsHandler {
 	return &AnalyticsHandler{service: service}
 }
 
-func (h *AnalyticsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request) {
+// scopedUserID reads the optional ?user_id= filter. Empty (or the legacy "all") means every
+// organisation in the caller's scope; repositories ignore IDs outside it.
+func scopedUserID(r *http.Request) string {
 	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "Missing user_id parameter", http.StatusBadRequest)
-		return
+	if userID == "all" {
+		return ""
 	}
+	return userID
+}
+
+func (h *AnalyticsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request) {
+	userID := scopedUserID(r)
 
 	stats, err := h.service.GetDashboardStats(r.Context(), userID)
 	if err != nil {
@@ -32,23 +39,7 @@ func (h *AnalyticsHandler) GetDashboardStats(w http.ResponseWriter, r *http.Requ
 }
 
 func (h *AnalyticsHandler) GetActivityLog(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	isVendor := ports.IsVendor(r.Context())
-	contextUserID := ports.GetUserID(r.Context())
-
-	// Security/Defaulting: 
-	// 1. If not vendor, force filter to context user ID to prevent data leakage.
-	// 2. If vendor and no specific user_id requested (or own id), default to "all" for system-wide visibility.
-	if !isVendor {
-		userID = contextUserID
-	} else if userID == "" || userID == contextUserID {
-		userID = "all"
-	}
-
-	if userID == "" {
-		http.Error(w, "Missing user_id parameter", http.StatusBadRequest)
-		return
-	}
+	userID := scopedUserID(r)
 
 	filters := make(map[string]interface{})
 	if action := r.URL.Query().Get("action"); action != "" {
@@ -57,6 +48,12 @@ func (h *AnalyticsHandler) GetActivityLog(w http.ResponseWriter, r *http.Request
 	if targetType := r.URL.Query().Get("target_type"); targetType != "" {
 		filters["target_type"] = targetType
 	}
+	if memberID := r.URL.Query().Get("member_id"); memberID != "" {
+		filters["member_id"] = memberID
+	}
+	if impersonationID := r.URL.Query().Get("impersonation_id"); impersonationID != "" {
+		filters["impersonation_id"] = impersonationID
+	}
 
 	logs, err := h.service.GetActivityLog(r.Context(), userID, filters)
 	if err != nil {
@@ -69,11 +66,7 @@ func (h *AnalyticsHandler) GetActivityLog(w http.ResponseWriter, r *http.Request
 }
 
 func (h *AnalyticsHandler) GetDetailedAnalytics(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "Missing user_id parameter", http.StatusBadRequest)
-		return
-	}
+	userID := scopedUserID(r)
 
 	response, err := h.service.GetDetailedAnalytics(r.Context(), userID)
 	if err != nil {
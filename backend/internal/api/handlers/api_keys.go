@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// APIKeysHandler manages the integration keys of an organisation. Tenant managers use /api/api-keys
+// for their own organisation; vendors use /api/users/{id}/api-keys for any organisation.
+type APIKeysHandler struct {
+	service ports.APIKeyService
+}
+
+func NewAPIKeysHandler(service ports.APIKeyService) *APIKeysHandler {
+	return &APIKeysHandler{service: service}
+}
+
+func (h *APIKeysHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListKeys(r.Context(), organisationID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": keys})
+}
+
+// CreateKey handles POST .../api-keys with {"name", "scopes": ["attendance:read", ...], "expires_at"}.
+// The key in the response is shown once.
+func (h *APIKeysHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	ticket, err := h.service.CreateKey(r.Context(), organisationID(r), input.Name, input.Scopes, input.ExpiresAt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+func (h *APIKeysHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.RevokeKey(r.Context(), organisationID(r), mux.Vars(r)["keyId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "revoked"})
+}
@@ -3,9 +3,6 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"time"
-
-	"github.com/gorilla/mux"
 
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/apperrors"
@@ -36,27 +33,44 @@ func (h *AttendanceHandler) GetAttendance(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(records)
 }
 
-func (h *AttendanceHandler) UpdateAttendance(w http.ResponseWriter, r *http.Request) {
+// GetPunches lists the raw punch events behind the derived attendance.
+func (h *AttendanceHandler) GetPunches(w http.ResponseWriter, r *http.Request) {
 	userID := ports.GetUserID(r.Context())
-	vars := mux.Vars(r)
-	id := vars["id"]
+	q := r.URL.Query()
 
-	var payload struct {
-		TimeIn  *time.Time `json:"time_in"`
-		TimeOut *time.Time `json:"time_out"`
+	punches, err := h.service.ListPunches(r.Context(), userID, q.Get("site_id"), q.Get("worker_id"), q.Get("date"))
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(punches)
+}
+
+// RederiveAttendance re-runs the pairing engine over a date range, e.g. after the pairing rules changed.
+func (h *AttendanceHandler) RederiveAttendance(w http.ResponseWriter, r *http.Request) {
+	userID := ports.GetUserID(r.Context())
+
+	var payload struct {
+		From     string `json:"from"`
+		To       string `json:"to"`
+		WorkerID string `json:"worker_id"`
+	}
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		writeError(w, apperrors.NewValidationError("invalid request payload"))
 		return
 	}
+	if payload.To == "" {
+		payload.To = payload.From
+	}
 
-	err := h.service.UpdateAttendance(r.Context(), userID, id, payload.TimeIn, payload.TimeOut)
+	result, err := h.service.RederiveAttendance(r.Context(), userID, payload.WorkerID, payload.From, payload.To)
 	if err != nil {
 		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
 }
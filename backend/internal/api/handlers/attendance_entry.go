@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+const maxImportSizeBytes = 10 * 1024 * 1024 // 10MB hard limit
+
+// AttendanceEntryHandler serves attendance entered by hand or imported from timesheets, e.g. while
+// a terminal was down.
+type AttendanceEntryHandler struct {
+	service ports.AttendanceEntryService
+}
+
+func NewAttendanceEntryHandler(service ports.AttendanceEntryService) *AttendanceEntryHandler {
+	return &AttendanceEntryHandler{service: service}
+}
+
+// CreateAttendance handles POST /api/attendance with one entry {"worker_id" or "person_id_no",
+// "project_id", "time_in", "time_out", "dry_run"}. An invalid entry is a validation error.
+func (h *AttendanceEntryHandler) CreateAttendance(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		domain.AttendanceEntry
+		DryRun bool `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	result, err := h.service.CreateAttendance(r.Context(), ports.GetUserID(r.Context()), []domain.AttendanceEntry{payload.AttendanceEntry}, payload.DryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if len(result.Errors) > 0 {
+		e := result.Errors[0]
+		writeError(w, apperrors.NewValidationError(fmt.Sprintf("%s: %s", e.Field, e.Error)))
+		return
+	}
+	writeEntryResult(w, result)
+}
+
+// BulkCreateAttendance handles POST /api/attendance/bulk with {"entries": [...], "dry_run"}.
+func (h *AttendanceEntryHandler) BulkCreateAttendance(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Entries []domain.AttendanceEntry `json:"entries"`
+		DryRun  bool                     `json:"dry_run"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	result, err := h.service.CreateAttendance(r.Context(), ports.GetUserID(r.Context()), payload.Entries, payload.DryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeEntryResult(w, result)
+}
+
+// ImportAttendance handles POST /api/attendance/import?dry_run=true with a CSV or XLSX timesheet
+// in the multipart field "file".
+func (h *AttendanceEntryHandler) ImportAttendance(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSizeBytes)
+	if err := r.ParseMultipartForm(2 << 20); err != nil { // 2MB memory buffer
+		http.Error(w, "File too large (max 10MB)", http.StatusRequestEntityTooLarge)
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, apperrors.NewValidationError("a CSV or XLSX file is required in the \"file\" field"))
+		return
+	}
+	defer file.Close()
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	result, err := h.service.ImportAttendance(r.Context(), ports.GetUserID(r.Context()), header.Filename, file, dryRun)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeEntryResult(w, result)
+}
+
+// writeEntryResult answers 201 when records were created, 422 when rows were refused (nothing is
+// created then) and 200 for a clean dry run. The body is the result either way.
+func writeEntryResult(w http.ResponseWriter, result *domain.AttendanceEntryResult) {
+	status := http.StatusOK
+	switch {
+	case len(result.Errors) > 0:
+		status = http.StatusUnprocessableEntity
+	case len(result.Created) > 0:
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
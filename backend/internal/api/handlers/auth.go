@@ -2,20 +2,24 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
+	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/gorilla/mux"
 )
 
 type AuthHandler struct {
 	authService ports.AuthService
-	userService ports.UserService
 }
 
-func NewAuthHandler(authService ports.AuthService, userService ports.UserService) *AuthHandler {
+func NewAuthHandler(authService ports.AuthService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
-		userService: userService,
 	}
 }
 
@@ -27,10 +31,47 @@ type LoginRequest struct {
 
 // LoginResponse structure
 type LoginResponse struct {
-	Token string      `json:"token"`
-	User  interface{} `json:"user"`
+	Token            string      `json:"token"`
+	ExpiresAt        time.Time   `json:"expires_at"`
+	RefreshToken     string      `json:"refresh_token"`
+	RefreshExpiresAt time.Time   `json:"refresh_expires_at"`
+	User             interface{} `json:"user"`
+	// RecoveryCodes is only set when MFA enrolment completes a login
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// MFAChallengeResponse replaces LoginResponse when the password was right but a code is still needed
+type MFAChallengeResponse struct {
+	MFARequired bool `json:"mfa_required"`
+	*domain.MFAChallenge
+}
+
+// MFARequest carries the login challenge (during sign-in) and/or a TOTP or recovery code
+type MFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// RefreshRequest carries the refresh token for clients that do not use the cookie
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ChangePasswordRequest is the body of POST /api/auth/password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ResetPasswordRequest is the body of POST /api/auth/password/reset
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
 }
 
+// refreshCookiePath limits the refresh cookie to the endpoints that consume it
+const refreshCookiePath = "/api/auth"
+
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -38,44 +79,291 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, user, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	result, err := h.authService.Login(r.Context(), req.Username, req.Password)
+	if errors.Is(err, apperrors.ErrTooManyRequests) {
+		writeError(w, err)
+		return
+	}
 	if err != nil {
 		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
-	// Simple role mapping logic for frontend legacy compatibility
-	userMap := map[string]interface{}{
-		"id":       user.ID,
-		"user_id":  user.ID,
-		"name":     user.Name,
-		"username": user.Username,
-		"role":     "manager", // Default
+	if result.Challenge != nil {
+		// No cookies yet: the client completes the login at /api/auth/mfa/verify (or enrols first)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MFAChallengeResponse{MFARequired: true, MFAChallenge: result.Challenge})
+		return
 	}
-	switch user.UserType {
-	case "client":
-		userMap["role"] = "client"
-	default:
-		userMap["role"] = "manager"
+	writeTokens(w, result.Tokens, result.Member)
+}
+
+// VerifyMFA completes a challenged login with a TOTP or recovery code.
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req MFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	tokens, member, err := h.authService.VerifyMFA(r.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeTokens(w, tokens, member)
+}
+
+// StartMFAEnrolment returns a new secret and otpauth URI. It serves both the signed-in setup route
+// and the public enrolment route, where mfa_token identifies the member.
+func (h *AuthHandler) StartMFAEnrolment(w http.ResponseWriter, r *http.Request) {
+	var req MFARequest
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+
+	enrolment, err := h.authService.StartMFAEnrolment(r.Context(), req.MFAToken)
+	if err != nil {
+		writeError(w, err)
+		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(enrolment)
+}
+
+// ConfirmMFAEnrolment enables MFA with the first code. During sign-in it also starts the session.
+func (h *AuthHandler) ConfirmMFAEnrolment(w http.ResponseWriter, r *http.Request) {
+	var req MFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	confirmation, err := h.authService.ConfirmMFAEnrolment(r.Context(), req.MFAToken, req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if confirmation.Tokens != nil {
+		setSessionCookies(w, confirmation.Tokens)
+		response := loginResponse(confirmation.Tokens, confirmation.Member)
+		response.RecoveryCodes = confirmation.RecoveryCodes
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "MFA enabled", "recovery_codes": confirmation.RecoveryCodes})
+}
+
+// RegenerateRecoveryCodes replaces the caller's recovery codes; the old ones stop working.
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	var req MFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(r.Context(), req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"recovery_codes": codes})
+}
+
+// DisableMFA turns off the caller's MFA after checking a current code.
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	var req MFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.authService.DisableMFA(r.Context(), req.Code); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "MFA disabled"})
+}
+
+// ResetMFA removes the authenticator of the member in the path and signs them out.
+func (h *AuthHandler) ResetMFA(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.authService.ResetMFA(r.Context(), vars["id"], vars["memberId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "MFA reset"})
+}
+
+// Refresh exchanges the refresh token (cookie or body) for a new token pair.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	refreshToken := readRefreshToken(r)
+
+	tokens, member, err := h.authService.Refresh(r.Context(), refreshToken)
+	if err != nil {
+		clearSessionCookies(w)
+		writeError(w, err)
+		return
+	}
+
+	writeTokens(w, tokens, member)
+}
+
+// Logout revokes the current session and clears the auth cookies.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if err := h.authService.Logout(r.Context(), readRefreshToken(r)); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	clearSessionCookies(w)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out"})
+}
+
+// ChangePassword sets the caller's own password. Other sessions are signed out, so fresh tokens are returned.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	tokens, err := h.authService.ChangePassword(r.Context(), req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	member, err := h.authService.CurrentMember(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeTokens(w, tokens, member)
+}
+
+// ResetPassword redeems an administrator-issued reset token. The member signs in afterwards.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.NewPassword); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password updated"})
+}
+
+// RevokeSessions signs out all logins of the organisation in the path, or one member when memberId is set.
+func (h *AuthHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	n, err := h.authService.RevokeSessions(r.Context(), vars["id"], vars["memberId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"message": "Sessions revoked", "revoked": n})
+}
+
+// GetLockouts lists the accounts and source IPs currently locked out of login.
+func (h *AuthHandler) GetLockouts(w http.ResponseWriter, r *http.Request) {
+	lockouts, err := h.authService.ListLockouts(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": lockouts})
+}
+
+// Unlock handles DELETE /api/login-lockouts/{scope}/{key}, e.g. /api/login-lockouts/ip/203.0.113.7
+func (h *AuthHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.authService.Unlock(r.Context(), vars["scope"], vars["key"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "unlocked"})
+}
+
+func writeTokens(w http.ResponseWriter, tokens *domain.TokenPair, member *domain.Member) {
+	setSessionCookies(w, tokens)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loginResponse(tokens, member))
+}
+
+func setSessionCookies(w http.ResponseWriter, tokens *domain.TokenPair) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "auth_token",
-		Value:    token,
+		Value:    tokens.AccessToken,
 		Path:     "/",
-		MaxAge:   7200, // 2 hours — matches JWT expiry
+		MaxAge:   int(time.Until(tokens.AccessExpiresAt).Seconds()), // matches JWT expiry
 		HttpOnly: true,
 		Secure:   false, // TODO: Set to true in production (requires HTTPS)
 		SameSite: http.SameSiteLaxMode,
 	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    tokens.RefreshToken,
+		Path:     refreshCookiePath,
+		MaxAge:   int(time.Until(tokens.RefreshExpiresAt).Seconds()),
+		HttpOnly: true,
+		Secure:   false, // TODO: Set to true in production (requires HTTPS)
+		SameSite: http.SameSiteStrictMode,
+	})
+}
 
-	response := LoginResponse{
-		Token: token, // keep for backward compatibility temporarily
-		User:  userMap,
+func loginResponse(tokens *domain.TokenPair, member *domain.Member) LoginResponse {
+	return LoginResponse{
+		Token:            tokens.AccessToken, // keep for backward compatibility temporarily
+		ExpiresAt:        tokens.AccessExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		User:             memberUserMap(member),
 	}
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+func clearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{Name: "auth_token", Value: "", Path: "/", MaxAge: -1, HttpOnly: true})
+	http.SetCookie(w, &http.Cookie{Name: "refresh_token", Value: "", Path: refreshCookiePath, MaxAge: -1, HttpOnly: true})
+}
+
+// readRefreshToken prefers the JSON body and falls back to the refresh cookie.
+func readRefreshToken(r *http.Request) string {
+	var req RefreshRequest
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req)
+	}
+	if req.RefreshToken != "" {
+		return req.RefreshToken
+	}
+	if cookie, err := r.Cookie("refresh_token"); err == nil {
+		return cookie.Value
+	}
+	return ""
 }
 
 func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
@@ -85,27 +373,64 @@ func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.GetUser(r.Context(), userID)
+	member, err := h.authService.CurrentMember(r.Context())
 	if err != nil {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
 
-	// Simple role mapping logic for frontend legacy compatibility
+	userMap := memberUserMap(member)
+	if imp := ports.GetImpersonation(r.Context()); imp != nil {
+		// The vendor's support member acts as the tenant: report the tenant, what the session may do,
+		// and the session itself so the frontend can show who is impersonating and until when
+		userMap["id"] = imp.TenantUserID
+		userMap["user_id"] = imp.TenantUserID
+		userMap["organisation_name"] = imp.TenantName
+		perms := []domain.Permission{}
+		for _, p := range domain.RolePermissions(domain.RoleManager) {
+			if !imp.ReadOnly || domain.IsReadPermission(p) {
+				perms = append(perms, p)
+			}
+		}
+		userMap["permissions"] = perms
+		userMap["impersonation"] = imp
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(userMap)
+}
+
+// memberUserMap describes the signed-in member. "id"/"user_id" remain the organisation, which is
+// what the frontend scopes data by; "role" keeps the legacy client/manager mapping, while
+// "access_role" and "permissions" let the frontend hide actions the login is not allowed to perform.
+func memberUserMap(member *domain.Member) map[string]interface{} {
 	userMap := map[string]interface{}{
-		"id":       user.ID,
-		"user_id":  user.ID,
-		"name":     user.Name,
-		"username": user.Username,
-		"role":     "manager", // Default
+		"id":                member.UserID,
+		"user_id":           member.UserID,
+		"member_id":         member.ID,
+		"name":              member.Name,
+		"username":          member.Username,
+		"organisation_name": member.OrgName,
+		"role":              "manager", // Default
 	}
-	switch user.UserType {
+	switch member.OrgType {
 	case "client":
 		userMap["role"] = "client"
 	default:
 		userMap["role"] = "manager"
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(userMap)
+	perms := domain.RolePermissions(member.Role)
+	if member.OrgType == "vendor" {
+		// Vendors bypass role checks; report the full tenant permission set
+		perms = domain.RolePermissions(domain.RoleManager)
+	}
+	userMap["must_change_password"] = member.MustChangePassword
+	userMap["mfa_enabled"] = member.MFAEnabled
+	userMap["access_role"] = member.Role
+	userMap["permissions"] = perms
+	if domain.RoleIsSiteScoped(member.Role) {
+		userMap["site_ids"] = member.SiteIDs
+	}
+	return userMap
 }
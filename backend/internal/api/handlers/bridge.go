@@ -63,6 +63,7 @@ func (h *BridgeHandler) Connect(w http.ResponseWriter, r *http.Request) {
 	h.requestMgr.AddTransport(userID, t)
 
 	// 4. Start message processing in the background
-	// We use context.Background() here because the connection should live beyond the HTTP request lifecycle
-	go h.requestMgr.HandleIncomingMessages(context.Background(), userID, t)
+	// We use context.Background() here because the connection should live beyond the HTTP request lifecycle.
+	// Bridge callbacks act for no login, so they run as a system caller.
+	go h.requestMgr.HandleIncomingMessages(ports.WithSystemCaller(context.Background()), userID, t)
 }
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/gorilla/mux"
+)
+
+// BridgeOutboxHandler exposes each tenant's queue of undelivered bridge commands to admins
+type BridgeOutboxHandler struct {
+	bridgeRepo ports.BridgeRepository
+}
+
+func NewBridgeOutboxHandler(bridgeRepo ports.BridgeRepository) *BridgeOutboxHandler {
+	return &BridgeOutboxHandler{bridgeRepo: bridgeRepo}
+}
+
+func validOutboxStatus(status string) bool {
+	switch status {
+	case "", domain.BridgeCommandPending, domain.BridgeCommandSent, domain.BridgeCommandFailed, domain.BridgeCommandExpired:
+		return true
+	}
+	return false
+}
+
+// GetOutbox handles GET /api/users/{id}/bridge/outbox?status=pending
+func (h *BridgeOutboxHandler) GetOutbox(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+	status := r.URL.Query().Get("status")
+	if !validOutboxStatus(status) {
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+
+	cmds, err := h.bridgeRepo.ListBridgeCommands(r.Context(), userID, status)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if cmds == nil {
+		cmds = []domain.BridgeCommand{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cmds)
+}
+
+// PurgeOutbox handles DELETE /api/users/{id}/bridge/outbox?status=failed
+// Without a status filter the tenant's whole queue is removed.
+func (h *BridgeOutboxHandler) PurgeOutbox(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["id"]
+	status := r.URL.Query().Get("status")
+	if !validOutboxStatus(status) {
+		http.Error(w, "invalid status", http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.bridgeRepo.PurgeBridgeCommands(r.Context(), userID, status)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"status": "purged", "deleted": n})
+}
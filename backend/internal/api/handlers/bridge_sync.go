@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"sync"
+
 	"cpd-nexus/internal/core/ports"
 
 	"cpd-nexus/internal/bridge"
@@ -10,6 +13,26 @@ import (
 	"cpd-nexus/internal/pkg/logger"
 )
 
+// maxConcurrentSyncRequests bounds the number of in-flight SendAndWait calls per manual sync
+const maxConcurrentSyncRequests = 16
+
+// Per-worker outcomes reported by SyncUsers
+const (
+	syncStatusSuccess    = "success"
+	syncStatusRejected   = "rejected"
+	syncStatusTimeout    = "timeout"
+	syncStatusSendFailed = "send_failed"
+)
+
+// syncResult is the device outcome for a single REGISTER_USER / UPDATE_USER request
+type syncResult struct {
+	WorkerID string `json:"worker_id"`
+	Action   string `json:"action"`
+	Status   string `json:"status"`
+	Code     int    `json:"code,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
 // BridgeSyncHandler handles manual sync trigger from the frontend
 type BridgeSyncHandler struct {
 	builder    *bridgeHandlers.UserSyncBuilder
@@ -80,46 +103,75 @@ func (h *BridgeSyncHandler) SyncUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Send each message via bridge transport
-	var successIDs []string
-	registerCount := 0
-	updateCount := 0
-	failCount := 0
-
+	// Send each message and wait for the device result. Requests are dispatched
+	// concurrently so that one slow device does not serialize the whole batch.
+	results := make([]syncResult, len(messages))
+	sem := make(chan struct{}, maxConcurrentSyncRequests)
+	var wg sync.WaitGroup
 	for i, msg := range messages {
-		if err := transport.Write(msg); err != nil {
-			logger.Infof("[BridgeSync API] Failed to send message %d: %v", i, err)
-			failCount++
-		} else {
-			// Log the interaction
-			_ = h.bridgeRepo.LogBridgeInteraction(ctx, userID, msg.Action, msg.Meta.RequestID, msg.Payload, nil, 0)
-
-			respMsg, _ := json.MarshalIndent(msg, "", "  ")
-			logger.Infof("\n--- [BRIDGE SYNC API OUTBOUND] ---\n%s\n----------------------------------", string(respMsg))
+		wg.Add(1)
+		go func(i int, msg bridge.Message) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
+			res := syncResult{Action: msg.Action}
 			if i < len(workerIDs) {
-				successIDs = append(successIDs, workerIDs[i])
+				res.WorkerID = workerIDs[i]
 			}
 
-			if msg.Action == "REGISTER_USER" {
-				registerCount++
-			} else {
-				updateCount++
+			resp, err := h.requestMgr.SendAndWait(ctx, userID, res.WorkerID, msg)
+			switch {
+			case errors.Is(err, bridge.ErrRequestTimeout):
+				res.Status = syncStatusTimeout
+				res.Code = bridge.StatusTimeout
+				res.Message = "no response from bridge before deadline"
+			case err != nil:
+				logger.Infof("[BridgeSync API] Failed to send %s for worker %s: %v", msg.Action, res.WorkerID, err)
+				res.Status = syncStatusSendFailed
+				res.Message = err.Error()
+			default:
+				env, perr := bridge.ParseResponse(resp)
+				res.Code = env.Code
+				res.Message = env.Msg
+				if perr == nil && env.Code == http.StatusOK {
+					res.Status = syncStatusSuccess
+				} else {
+					res.Status = syncStatusRejected
+				}
 			}
+			results[i] = res
+		}(i, msg)
+	}
+	wg.Wait()
+
+	// Workers are marked synced by the async bridge response handlers,
+	// which only do so upon receiving a 200 OK from the hardware device.
+	registerCount, updateCount := 0, 0
+	counts := make(map[string]int)
+	for _, res := range results {
+		counts[res.Status]++
+		if res.Status == syncStatusSendFailed {
+			continue
+		}
+		if res.Action == "REGISTER_USER" {
+			registerCount++
+		} else {
+			updateCount++
 		}
 	}
 
-	// Note: We no longer mark workers as synced here.
-	// That responsibility is now deferred to the async bridge response handlers,
-	// which will only mark them synced upon receiving a 200 OK from the hardware device.
-
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":                 true,
 		"message":                 "Sync completed",
-		"sent":                    len(successIDs),
+		"sent":                    len(results) - counts[syncStatusSendFailed],
 		"register":                registerCount,
 		"update":                  updateCount,
-		"failed":                  failCount,
+		"succeeded":               counts[syncStatusSuccess],
+		"rejected":                counts[syncStatusRejected],
+		"timed_out":               counts[syncStatusTimeout],
+		"failed":                  counts[syncStatusSendFailed],
+		"results":                 results,
 		"invalid_workers":         invalidWorkers,
 		"unauthenticated_workers": unauthWorkers,
 	})
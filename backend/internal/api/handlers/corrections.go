@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// CorrectionsHandler serves the attendance correction workflow and the revision history of records.
+type CorrectionsHandler struct {
+	service ports.CorrectionService
+}
+
+func NewCorrectionsHandler(service ports.CorrectionService) *CorrectionsHandler {
+	return &CorrectionsHandler{service: service}
+}
+
+// RequestCorrection handles POST /api/attendance/{id}/corrections (and the former PUT /api/attendance/{id})
+// with {"reason", "time_in", "time_out"}. The record is unchanged until the correction is approved.
+func (h *CorrectionsHandler) RequestCorrection(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Reason  string     `json:"reason"`
+		TimeIn  *time.Time `json:"time_in"`
+		TimeOut *time.Time `json:"time_out"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	correction, err := h.service.RequestCorrection(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["id"], payload.Reason, payload.TimeIn, payload.TimeOut)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(correction)
+}
+
+// GetCorrections handles GET /api/attendance/corrections?status= and GET /api/attendance/{id}/corrections.
+func (h *CorrectionsHandler) GetCorrections(w http.ResponseWriter, r *http.Request) {
+	corrections, err := h.service.ListCorrections(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["id"], r.URL.Query().Get("status"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": corrections})
+}
+
+func (h *CorrectionsHandler) GetCorrection(w http.ResponseWriter, r *http.Request) {
+	correction, err := h.service.GetCorrection(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["correctionId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(correction)
+}
+
+// ApproveCorrection handles POST /api/attendance/corrections/{correctionId}/approve with an optional {"note"}.
+func (h *CorrectionsHandler) ApproveCorrection(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.ApproveCorrection)
+}
+
+// RejectCorrection handles POST /api/attendance/corrections/{correctionId}/reject with {"note"}.
+func (h *CorrectionsHandler) RejectCorrection(w http.ResponseWriter, r *http.Request) {
+	h.review(w, r, h.service.RejectCorrection)
+}
+
+func (h *CorrectionsHandler) review(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, userID, id, note string) (*domain.AttendanceCorrection, error)) {
+	var payload struct {
+		Note string `json:"note"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeError(w, apperrors.NewValidationError("invalid request payload"))
+			return
+		}
+	}
+
+	correction, err := decide(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["correctionId"], payload.Note)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(correction)
+}
+
+// GetRevisions handles GET /api/attendance/{id}/revisions, the applied corrections of a record, oldest first.
+func (h *CorrectionsHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.service.ListRevisions(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": revisions})
+}
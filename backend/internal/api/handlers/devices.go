@@ -21,8 +21,8 @@ func NewDevicesHandler(service ports.DeviceService) *DevicesHandler {
 func (h *DevicesHandler) GetDevices(w http.ResponseWriter, r *http.Request) {
 	userID := ports.GetUserID(r.Context())
 	
-	// If vendor, allow filtering by user_id query param
-	if queryUserID := r.URL.Query().Get("user_id"); queryUserID != "" && ports.IsVendor(r.Context()) {
+	// Vendors may narrow the list to one organisation; IDs outside the caller's scope match nothing
+	if queryUserID := r.URL.Query().Get("user_id"); queryUserID != "" {
 		userID = queryUserID
 	}
 
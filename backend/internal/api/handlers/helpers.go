@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 	"cpd-nexus/internal/pkg/apperrors"
 )
 
@@ -10,6 +12,11 @@ import (
 // with a generic message so internal details are never exposed to clients.
 func writeError(w http.ResponseWriter, err error) {
 	if appErr, ok := err.(*apperrors.AppError); ok {
+		if appErr.RetryAfter > 0 {
+			// Round up so clients never retry a moment too early
+			secs := int((appErr.RetryAfter + time.Second - 1) / time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+		}
 		http.Error(w, appErr.Message, appErr.Code)
 		return
 	}
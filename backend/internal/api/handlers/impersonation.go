@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// ImpersonationHandler lets vendor support staff act as a tenant organisation.
+type ImpersonationHandler struct {
+	service ports.ImpersonationService
+}
+
+func NewImpersonationHandler(service ports.ImpersonationService) *ImpersonationHandler {
+	return &ImpersonationHandler{service: service}
+}
+
+// Start handles POST /api/users/{id}/impersonate with {"reason", "allow_write", "duration_minutes"}.
+// The token is returned in the body only, so the vendor's own cookie session is left untouched;
+// the client sends it as a Bearer token.
+func (h *ImpersonationHandler) Start(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Reason          string `json:"reason"`
+		AllowWrite      bool   `json:"allow_write"`
+		DurationMinutes int    `json:"duration_minutes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	ticket, err := h.service.Start(r.Context(), mux.Vars(r)["id"], input.Reason, input.AllowWrite, input.DurationMinutes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+// End handles POST /api/auth/impersonation/end, called with the impersonation token.
+func (h *ImpersonationHandler) End(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.End(r.Context()); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ended"})
+}
+
+// List handles GET /api/users/{id}/impersonations.
+func (h *ImpersonationHandler) List(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.service.List(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": sessions})
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// MembersHandler manages the logins of an organisation. Tenant managers use /api/members for
+// their own organisation; vendors use /api/users/{id}/members for any organisation.
+type MembersHandler struct {
+	service ports.MemberService
+}
+
+func NewMembersHandler(service ports.MemberService) *MembersHandler {
+	return &MembersHandler{service: service}
+}
+
+// organisationID resolves the organisation from the admin route, or the caller's own organisation.
+func organisationID(r *http.Request) string {
+	if id := mux.Vars(r)["id"]; id != "" {
+		return id
+	}
+	return ports.GetUserID(r.Context())
+}
+
+func (h *MembersHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	members, err := h.service.ListMembers(r.Context(), organisationID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": members})
+}
+
+func (h *MembersHandler) GetMember(w http.ResponseWriter, r *http.Request) {
+	member, err := h.service.GetMember(r.Context(), organisationID(r), mux.Vars(r)["memberId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(member)
+}
+
+func (h *MembersHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		domain.Member
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.service.CreateMember(r.Context(), organisationID(r), &input.Member, input.Password); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(input.Member)
+}
+
+func (h *MembersHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.service.UpdateMember(r.Context(), organisationID(r), mux.Vars(r)["memberId"], payload); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+}
+
+func (h *MembersHandler) DeactivateMember(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeactivateMember(r.Context(), organisationID(r), mux.Vars(r)["memberId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deactivated"})
+}
+
+// AssignRole handles PUT .../members/{memberId}/role with {"role": "pic", "site_ids": [...]}
+func (h *MembersHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Role    string   `json:"role"`
+		SiteIDs []string `json:"site_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.service.AssignRole(r.Context(), organisationID(r), mux.Vars(r)["memberId"], input.Role, input.SiteIDs); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+}
+
+// IssuePasswordReset handles POST .../members/{memberId}/password-reset. The one-time token in the
+// response is shown once; the member redeems it at POST /api/auth/password/reset.
+func (h *MembersHandler) IssuePasswordReset(w http.ResponseWriter, r *http.Request) {
+	ticket, err := h.service.IssuePasswordReset(r.Context(), organisationID(r), mux.Vars(r)["memberId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ticket)
+}
+
+// UnlockMember handles POST .../members/{memberId}/unlock
+func (h *MembersHandler) UnlockMember(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.UnlockMember(r.Context(), organisationID(r), mux.Vars(r)["memberId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "unlocked"})
+}
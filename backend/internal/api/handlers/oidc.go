@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// OIDCHandler serves single sign-on. The login endpoints are public; providers are managed by
+// tenant managers at /api/oidc-providers and by vendors at /api/users/{id}/oidc-providers.
+type OIDCHandler struct {
+	service ports.OIDCService
+	// postLoginURL is where the browser lands after the callback, e.g. the frontend's root
+	postLoginURL string
+}
+
+func NewOIDCHandler(service ports.OIDCService, postLoginURL string) *OIDCHandler {
+	if postLoginURL == "" {
+		postLoginURL = "/"
+	}
+	return &OIDCHandler{service: service, postLoginURL: postLoginURL}
+}
+
+// OIDCProviderRequest is a provider definition plus the client secret, which is write-only
+type OIDCProviderRequest struct {
+	domain.OIDCProvider
+	ClientSecret string `json:"client_secret"`
+}
+
+// Discover handles GET /api/auth/oidc/discover?email=, telling the login page whether to offer SSO.
+func (h *OIDCHandler) Discover(w http.ResponseWriter, r *http.Request) {
+	info, err := h.service.Discover(r.Context(), r.URL.Query().Get("email"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
+
+// StartLogin handles GET /api/auth/oidc/{providerId}/start?return_to=/path by redirecting to the provider.
+func (h *OIDCHandler) StartLogin(w http.ResponseWriter, r *http.Request) {
+	authURL, err := h.service.StartLogin(r.Context(), mux.Vars(r)["providerId"], r.URL.Query().Get("return_to"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles the provider's redirect. A successful login sets the session cookies and
+// returns the browser to the application. When MFA is needed the challenge is passed in the URL
+// fragment (never sent to servers) for the login page to finish at /api/auth/mfa/verify; failures
+// arrive as ?sso_error=.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if idpErr := q.Get("error"); idpErr != "" {
+		h.redirectError(w, r, apperrors.NewUnauthorized("the identity provider refused the sign-in: "+idpErr))
+		return
+	}
+
+	login, err := h.service.CompleteLogin(r.Context(), q.Get("state"), q.Get("code"))
+	if err != nil {
+		h.redirectError(w, r, err)
+		return
+	}
+
+	if challenge := login.Result.Challenge; challenge != nil {
+		fragment := url.Values{"mfa_token": {challenge.Token}}
+		if challenge.EnrolmentRequired {
+			fragment.Set("enrolment_required", "true")
+		}
+		http.Redirect(w, r, h.postLoginURL+"#"+fragment.Encode(), http.StatusFound)
+		return
+	}
+
+	setSessionCookies(w, login.Result.Tokens)
+	target := h.postLoginURL
+	if login.ReturnTo != "" {
+		target = strings.TrimSuffix(h.postLoginURL, "/") + login.ReturnTo
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
+
+func (h *OIDCHandler) redirectError(w http.ResponseWriter, r *http.Request, err error) {
+	// Only application errors are meant for users; anything else stays in the logs
+	message := "single sign-on failed"
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		message = appErr.Message
+	}
+	http.Redirect(w, r, h.postLoginURL+"?"+url.Values{"sso_error": {message}}.Encode(), http.StatusFound)
+}
+
+func (h *OIDCHandler) GetProviders(w http.ResponseWriter, r *http.Request) {
+	providers, err := h.service.ListProviders(r.Context(), organisationID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": providers})
+}
+
+func (h *OIDCHandler) CreateProvider(w http.ResponseWriter, r *http.Request) {
+	var input OIDCProviderRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	p := input.OIDCProvider
+	if err := h.service.CreateProvider(r.Context(), organisationID(r), &p, input.ClientSecret); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// UpdateProvider replaces a provider's settings; omit client_secret to keep the current one.
+func (h *OIDCHandler) UpdateProvider(w http.ResponseWriter, r *http.Request) {
+	var input OIDCProviderRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	p := input.OIDCProvider
+	if err := h.service.UpdateProvider(r.Context(), organisationID(r), mux.Vars(r)["providerId"], &p, input.ClientSecret); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+func (h *OIDCHandler) DeleteProvider(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteProvider(r.Context(), organisationID(r), mux.Vars(r)["providerId"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
@@ -2,9 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
 
 	"github.com/gorilla/mux"
 )
@@ -24,9 +26,6 @@ func NewPitstopHandler(pitstopService ports.PitstopService) *PitstopHandler {
 // GetAuthorisations handles retrieving existing configuration mappings
 func (h *PitstopHandler) GetAuthorisations(w http.ResponseWriter, r *http.Request) {
 	userID := ports.GetUserID(r.Context())
-	if ports.IsVendor(r.Context()) {
-		userID = "" // Vendors can see all mappings
-	}
 
 	auths, err := h.pitstopService.GetAuthorisations(r.Context(), userID)
 	if err != nil {
@@ -47,6 +46,10 @@ func (h *PitstopHandler) SyncConfig(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.pitstopService.SyncConfig(r.Context(), userID); err != nil {
+		if errors.Is(err, apperrors.ErrPermissionDenied) {
+			writeError(w, err)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -70,6 +73,10 @@ func (h *PitstopHandler) AssignOnBehalfOf(w http.ResponseWriter, r *http.Request
 	}
 
 	if err := h.pitstopService.AssignOnBehalfOfToUser(r.Context(), userID, input.OnBehalfOfNames); err != nil {
+		if errors.Is(err, apperrors.ErrPermissionDenied) {
+			writeError(w, err)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -94,12 +101,11 @@ func (h *PitstopHandler) TestSubmission(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Admin/Vendor bypass: if they are an admin, they can test any project
-	if ports.IsVendor(r.Context()) {
-		userID = ""
-	}
-
 	submitted, failed, err := h.pitstopService.TestSubmission(r.Context(), userID, projectID)
+	if errors.Is(err, apperrors.ErrPermissionDenied) {
+		writeError(w, err)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -115,17 +121,43 @@ func (h *PitstopHandler) TestSubmission(w http.ResponseWriter, r *http.Request)
 	})
 }
 
-// GetTestingProjects handles retrieving a list of unique projects that currently have pending attendance records
-func (h *PitstopHandler) GetTestingProjects(w http.ResponseWriter, r *http.Request) {
+// PreviewSubmission handles a dry run of the CPD submission for a specific project.
+// It returns the batches that would be sent without calling Pitstop or updating attendance.
+func (h *PitstopHandler) PreviewSubmission(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	projectID := vars["project_id"]
+
+	if projectID == "" {
+		http.Error(w, "project_id is required", http.StatusBadRequest)
+		return
+	}
+
 	userID := ports.GetUserID(r.Context())
 	if userID == "" {
 		http.Error(w, "user_id is required", http.StatusUnauthorized)
 		return
 	}
 
-	// Admin/Vendor bypass: if they are an admin, they can see all projects
-	if ports.IsVendor(r.Context()) {
-		userID = ""
+	preview, err := h.pitstopService.PreviewSubmission(r.Context(), userID, projectID)
+	if errors.Is(err, apperrors.ErrPermissionDenied) {
+		writeError(w, err)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// GetTestingProjects handles retrieving a list of unique projects that currently have pending attendance records
+func (h *PitstopHandler) GetTestingProjects(w http.ResponseWriter, r *http.Request) {
+	userID := ports.GetUserID(r.Context())
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusUnauthorized)
+		return
 	}
 
 	projects, err := h.pitstopService.GetProjectsWithPendingAttendance(r.Context(), userID)
@@ -141,3 +173,44 @@ func (h *PitstopHandler) GetTestingProjects(w http.ResponseWriter, r *http.Reque
 	}
 	json.NewEncoder(w).Encode(map[string]interface{}{"data": projects})
 }
+
+// GetDeadLetterSubmissions handles GET /api/pitstop/submissions/dead-letter?user_id=
+// Lists attendance records that exhausted their retries or were permanently rejected.
+func (h *PitstopHandler) GetDeadLetterSubmissions(w http.ResponseWriter, r *http.Request) {
+	rows, err := h.pitstopService.ListDeadLetterSubmissions(r.Context(), r.URL.Query().Get("user_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(rows) == 0 {
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": []interface{}{}})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": rows})
+}
+
+// RequeueSubmissions handles POST /api/pitstop/submissions/requeue
+// Moves the given dead-lettered attendance records back to pending.
+func (h *PitstopHandler) RequeueSubmissions(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		AttendanceIDs []string `json:"attendance_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	n, err := h.pitstopService.RequeueSubmissions(r.Context(), "", input.AttendanceIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":  "Submissions requeued",
+		"requeued": n,
+	})
+}
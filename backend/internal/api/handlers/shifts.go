@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// ShiftsHandler manages the shifts defined for sites and projects.
+type ShiftsHandler struct {
+	service ports.ShiftService
+}
+
+func NewShiftsHandler(service ports.ShiftService) *ShiftsHandler {
+	return &ShiftsHandler{service: service}
+}
+
+// GetShifts handles GET /api/shifts, optionally filtered by ?site_id= and ?project_id=.
+func (h *ShiftsHandler) GetShifts(w http.ResponseWriter, r *http.Request) {
+	userID := ports.GetUserID(r.Context())
+	q := r.URL.Query()
+
+	shifts, err := h.service.ListShifts(r.Context(), userID, q.Get("site_id"), q.Get("project_id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": shifts})
+}
+
+func (h *ShiftsHandler) GetShift(w http.ResponseWriter, r *http.Request) {
+	shift, err := h.service.GetShift(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+// CreateShift handles POST /api/shifts with {"site_id" or "project_id", "name", "start_time",
+// "end_time", "grace_before_minutes", "grace_after_minutes"}.
+func (h *ShiftsHandler) CreateShift(w http.ResponseWriter, r *http.Request) {
+	var shift domain.Shift
+	if err := json.NewDecoder(r.Body).Decode(&shift); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.service.CreateShift(r.Context(), ports.GetUserID(r.Context()), &shift); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftsHandler) UpdateShift(w http.ResponseWriter, r *http.Request) {
+	var shift domain.Shift
+	if err := json.NewDecoder(r.Body).Decode(&shift); err != nil {
+		writeError(w, apperrors.NewValidationError("invalid request payload"))
+		return
+	}
+
+	if err := h.service.UpdateShift(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["id"], &shift); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(shift)
+}
+
+func (h *ShiftsHandler) DeleteShift(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.DeleteShift(r.Context(), ports.GetUserID(r.Context()), mux.Vars(r)["id"]); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "deleted"})
+}
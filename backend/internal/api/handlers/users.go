@@ -59,7 +59,7 @@ func (h *UsersHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.CreateUser(r.Context(), &input.User, input.Password); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -79,7 +79,7 @@ func (h *UsersHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.UpdateUser(r.Context(), id, payload); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, err)
 		return
 	}
 
@@ -130,3 +130,60 @@ func (h *UsersHandler) UpdateBridgeConfig(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
 }
+
+// GetRoles handles GET /api/roles and lists each role with the permissions it grants
+func (h *UsersHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	type roleInfo struct {
+		Role        string              `json:"role"`
+		Permissions []domain.Permission `json:"permissions"`
+		SiteScoped  bool                `json:"site_scoped"`
+	}
+
+	roles := make([]roleInfo, 0, len(domain.Roles))
+	for _, role := range domain.Roles {
+		roles = append(roles, roleInfo{
+			Role:        role,
+			Permissions: domain.RolePermissions(role),
+			SiteScoped:  domain.RoleIsSiteScoped(role),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": roles})
+}
+
+// GetVendorTenants handles GET /api/users/{id}/vendor-tenants.
+// An empty list means the vendor sees every organisation.
+func (h *UsersHandler) GetVendorTenants(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.service.GetVendorTenants(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if tenants == nil {
+		tenants = []string{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": tenants})
+}
+
+// SetVendorTenants handles PUT /api/users/{id}/vendor-tenants with {"tenant_ids": [...]}.
+// An empty list restores the vendor's global scope.
+func (h *UsersHandler) SetVendorTenants(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		TenantIDs []string `json:"tenant_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SetVendorTenants(r.Context(), mux.Vars(r)["id"], input.TenantIDs); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "updated"})
+}
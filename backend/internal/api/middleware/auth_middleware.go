@@ -2,12 +2,19 @@ package middleware
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
+	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/logger"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
 )
 
 // JWTSecret is the shared secret for validating tokens.
@@ -19,11 +26,53 @@ func SetJWTSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
 
-// UserScopeMiddleware validates the JWT and ensures the user exists and is active.
+// trustedProxies are the reverse proxies whose X-Forwarded-For header is believed.
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies configures the reverse proxies allowed to report the client address in X-Forwarded-For.
+// Without any, the header is ignored and the client is the TCP peer.
+func SetTrustedProxies(proxies []*net.IPNet) {
+	trustedProxies = proxies
+}
+
+// passwordChangeExempt are the only routes a member flagged with must_change_password may call
+var passwordChangeExempt = map[string]bool{
+	"/api/auth/me":       true,
+	"/api/auth/password": true,
+	"/api/auth/logout":   true,
+}
+
+// impersonationWritable are the writes a read-only impersonation token may still make: leaving the session
+var impersonationWritable = map[string]bool{
+	"/api/auth/logout":            true,
+	"/api/auth/impersonation/end": true,
+}
+
+// impersonationBlocked are never available to an impersonation token, even a read-write one. They manage the
+// vendor member's own credentials or create credentials for the tenant that would outlive the session.
+var impersonationBlocked = []string{"/api/auth/password", "/api/auth/mfa/", "/api/members", "/api/api-keys", "/api/oidc-providers"}
+
+// APIKeyHeader carries a tenant API key for machine-to-machine calls
+const APIKeyHeader = "X-API-Key"
+
+// UserScopeMiddleware validates the JWT and ensures the organisation and the member login exist and are active.
+// Tokens whose jti has been revoked (logout, admin revocation, refresh token reuse) are rejected.
+// Requests with an X-API-Key header are authenticated by that key instead (see authenticateAPIKey), and
+// impersonation tokens by their session (see authenticateImpersonation).
 // The X-User-ID header is intentionally ignored to prevent spoofing.
-func UserScopeMiddleware(userRepo ports.UserRepository) func(http.Handler) http.Handler {
+func UserScopeMiddleware(userRepo ports.UserRepository, memberRepo ports.MemberRepository, sessionRepo ports.SessionRepository, apiKeyRepo ports.APIKeyRepository, impersonationRepo ports.ImpersonationRepository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key := r.Header.Get(APIKeyHeader); key != "" {
+				ctx, ok := authenticateAPIKey(r, key, userRepo, apiKeyRepo)
+				if !ok {
+					http.Error(w, "Unauthorized: invalid, expired or revoked API key", http.StatusUnauthorized)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			var tokenStr string
 			authHeader := r.Header.Get("Authorization")
 			if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
@@ -46,9 +95,36 @@ func UserScopeMiddleware(userRepo ports.UserRepository) func(http.Handler) http.
 				return
 			}
 
+			if _, ok := claims["imp"]; ok {
+				ctx, ok := authenticateImpersonation(r, claims, userRepo, memberRepo, impersonationRepo)
+				if !ok {
+					http.Error(w, "Unauthorized: impersonation session has ended or expired", http.StatusUnauthorized)
+					return
+				}
+				if msg := impersonationForbidden(r, ports.GetImpersonation(ctx)); msg != "" {
+					http.Error(w, "Forbidden: "+msg, http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			userID, _ := claims["user_id"].(string)
 			username, _ := claims["username"].(string)
-			userType, _ := claims["user_type"].(string)
+			memberID, _ := claims["member_id"].(string)
+			if memberID == "" {
+				// Tokens issued before organisation members act as the organisation's primary login
+				memberID = userID
+			}
+			sessionID, _ := claims["sid"].(string)
+
+			if jti, _ := claims["jti"].(string); jti != "" {
+				revoked, err := sessionRepo.IsTokenRevoked(r.Context(), jti)
+				if err != nil || revoked {
+					http.Error(w, "Unauthorized: session has been revoked", http.StatusUnauthorized)
+					return
+				}
+			}
 
 			// --- New: Validate legitimacy of the User ID in Database ---
 			// Ensure the user account has not been deactivated or deleted since the token was issued.
@@ -58,27 +134,204 @@ func UserScopeMiddleware(userRepo ports.UserRepository) func(http.Handler) http.
 				return
 			}
 
+			member, err := memberRepo.Get(r.Context(), memberID)
+			if err != nil || member == nil || member.UserID != userID || member.Status != domain.StatusActive {
+				http.Error(w, "Unauthorized: login is inactive or no longer belongs to this organisation", http.StatusUnauthorized)
+				return
+			}
+
+			if member.MustChangePassword && !passwordChangeExempt[r.URL.Path] {
+				http.Error(w, "Forbidden: password change required", http.StatusForbidden)
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), ports.UserIDKey, userID)
+			ctx = context.WithValue(ctx, ports.MemberIDKey, member.ID)
 			ctx = context.WithValue(ctx, ports.UsernameKey, username)
+			if sessionID != "" {
+				ctx = context.WithValue(ctx, ports.SessionIDKey, sessionID)
+			}
 
-			// Capture IP Address
-			ip := r.Header.Get("X-Forwarded-For")
-			if ip == "" {
-				ip = strings.Split(r.RemoteAddr, ":")[0]
-			} else {
-				ip = strings.Split(ip, ",")[0]
+			// Role is read from the database rather than the token so reassignments apply immediately
+			ctx = context.WithValue(ctx, ports.RoleKey, member.Role)
+			if domain.RoleIsSiteScoped(member.Role) {
+				ctx = context.WithValue(ctx, ports.SiteIDsKey, member.SiteIDs)
 			}
-			ctx = context.WithValue(ctx, ports.IPAddressKey, strings.TrimSpace(ip))
 
-			if userType == "vendor" {
+			ctx = withClientInfo(ctx, r)
+
+			// The organisation type and supported tenants are read from the database like the role
+			if user.UserType == domain.UserTypeVendor {
 				ctx = context.WithValue(ctx, ports.IsVendorKey, true)
 			}
+			scope, err := organisationScope(r.Context(), user, userRepo)
+			if err != nil {
+				logger.Errorf("[Auth] Failed to resolve scope of %s: %v", userID, err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+			ctx = context.WithValue(ctx, ports.ScopeKey, scope)
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// authenticateAPIKey resolves a key into the same organisation scope as a JWT. The key's scopes
+// replace role permissions, and it never carries vendor privileges, even for a vendor organisation.
+func authenticateAPIKey(r *http.Request, key string, userRepo ports.UserRepository, apiKeyRepo ports.APIKeyRepository) (context.Context, bool) {
+	sum := sha256.Sum256([]byte(key))
+	apiKey, err := apiKeyRepo.GetByHash(r.Context(), hex.EncodeToString(sum[:]))
+	now := time.Now()
+	if err != nil || apiKey == nil || !apiKey.Active(now) {
+		return nil, false
+	}
+
+	user, err := userRepo.Get(r.Context(), apiKey.UserID)
+	if err != nil || user == nil || user.Status != "active" {
+		return nil, false
+	}
+
+	ctx := context.WithValue(r.Context(), ports.UserIDKey, apiKey.UserID)
+	ctx = context.WithValue(ctx, ports.UsernameKey, "api-key:"+apiKey.Name)
+	ctx = context.WithValue(ctx, ports.APIKeyIDKey, apiKey.ID)
+	ctx = context.WithValue(ctx, ports.ScopesKey, apiKey.Scopes)
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantScope(apiKey.UserID))
+	ctx = withClientInfo(ctx, r)
+
+	if err := apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, ports.GetIPAddress(ctx), now); err != nil {
+		logger.Errorf("[Auth] Failed to record use of API key %s: %v", apiKey.ID, err)
+	}
+	return ctx, true
+}
+
+// authenticateImpersonation resolves an impersonation token into the tenant's context. The session must
+// still be active, and the vendor member must still be an active manager and still have the tenant in scope.
+// The caller gets the tenant's manager permissions but no vendor privileges, so it sees what the tenant sees.
+func authenticateImpersonation(r *http.Request, claims jwt.MapClaims, userRepo ports.UserRepository, memberRepo ports.MemberRepository, impersonationRepo ports.ImpersonationRepository) (context.Context, bool) {
+	if impersonationRepo == nil {
+		return nil, false
+	}
+	id, _ := claims["imp"].(string)
+	userID, _ := claims["user_id"].(string)
+	memberID, _ := claims["member_id"].(string)
+
+	imp, err := impersonationRepo.Get(r.Context(), id)
+	if err != nil || imp == nil || !imp.Active(time.Now()) || imp.TenantUserID != userID || imp.ActorMemberID != memberID {
+		return nil, false
+	}
+
+	actor, err := memberRepo.Get(r.Context(), imp.ActorMemberID)
+	if err != nil || actor == nil || actor.UserID != imp.ActorUserID || actor.Status != domain.StatusActive || actor.Role != domain.RoleManager {
+		return nil, false
+	}
+	vendor, err := userRepo.Get(r.Context(), imp.ActorUserID)
+	if err != nil || vendor == nil || vendor.Status != domain.StatusActive || vendor.UserType != domain.UserTypeVendor {
+		return nil, false
+	}
+	tenant, err := userRepo.Get(r.Context(), imp.TenantUserID)
+	if err != nil || tenant == nil || tenant.Status != domain.StatusActive {
+		return nil, false
+	}
+	vendorScope, err := organisationScope(r.Context(), vendor, userRepo)
+	if err != nil || !vendorScope.Allows(tenant.ID) {
+		return nil, false
+	}
+
+	ctx := context.WithValue(r.Context(), ports.UserIDKey, tenant.ID)
+	ctx = context.WithValue(ctx, ports.MemberIDKey, actor.ID)
+	ctx = context.WithValue(ctx, ports.UsernameKey, actor.Username)
+	ctx = context.WithValue(ctx, ports.RoleKey, domain.RoleManager)
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantScope(tenant.ID))
+	ctx = context.WithValue(ctx, ports.ImpersonationKey, imp)
+	return withClientInfo(ctx, r), true
+}
+
+// impersonationForbidden explains why an impersonation session may not make request r, or returns "".
+func impersonationForbidden(r *http.Request, imp *domain.Impersonation) string {
+	for _, prefix := range impersonationBlocked {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return "not available while impersonating"
+		}
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return ""
+	}
+	if imp.ReadOnly && !impersonationWritable[r.URL.Path] {
+		return "impersonation session is read-only"
+	}
+	return ""
+}
+
+// organisationScope resolves which organisations user may see (see domain.OrganisationScope).
+func organisationScope(ctx context.Context, user *domain.User, userRepo ports.UserRepository) (domain.Scope, error) {
+	var supported []string
+	if user.UserType == domain.UserTypeVendor {
+		var err error
+		if supported, err = userRepo.ListVendorTenants(ctx, user.ID); err != nil {
+			return domain.Scope{}, err
+		}
+	}
+	return domain.OrganisationScope(user.ID, user.UserType, supported), nil
+}
+
+// ClientInfo records the caller's IP address and User-Agent for routes that run before
+// authentication, such as login and token refresh.
+func ClientInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(withClientInfo(r.Context(), r)))
+	})
+}
+
+func withClientInfo(ctx context.Context, r *http.Request) context.Context {
+	peer := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		peer = host
+	}
+	ctx = context.WithValue(ctx, ports.IPAddressKey, clientIP(peer, r.Header.Values("X-Forwarded-For")))
+	ctx = context.WithValue(ctx, ports.PeerAddressKey, peer)
+	return context.WithValue(ctx, ports.UserAgentKey, r.UserAgent())
+}
+
+// clientIP returns the address login throttling and audit logs attribute a request to. X-Forwarded-For
+// is only read when the peer is a trusted proxy, and then from the right: the last address not itself
+// a trusted proxy was added by one, while anything to its left may have been sent by the client.
+func clientIP(peer string, forwardedFor []string) string {
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+	var hops []string
+	for _, header := range forwardedFor {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		client = hop
+		if !isTrustedProxy(hop) {
+			break
+		}
+	}
+	return client
+}
+
+func isTrustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 // validateJWT parses and validates a JWT token string, returning its claims.
 func validateJWT(tokenStr string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
@@ -111,13 +364,41 @@ func RequireUserScope(next http.Handler) http.Handler {
 	})
 }
 
-// RequireAdminScope checks if the user has admin/vendor privileges.
+// RequireAdminScope checks if the user is a vendor manager.
 func RequireAdminScope(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !ports.IsVendor(r.Context()) {
+		if !ports.IsVendorAdmin(r.Context()) {
 			http.Error(w, "Forbidden: administrative privileges required", http.StatusForbidden)
 			return
 		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireTenantInScope rejects administrative calls on an organisation (the {id} route variable)
+// outside the caller's scope, so a vendor limited to some tenants cannot manage the others.
+func RequireTenantInScope(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := mux.Vars(r)["id"]; id != "" {
+			if err := ports.AuthorizeTenant(r.Context(), id); err != nil {
+				http.Error(w, "Forbidden: organisation is outside your scope", http.StatusForbidden)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission rejects callers whose role does not grant p with 403.
+// It must run after RequireUserScope so anonymous requests are rejected with 401 first.
+func RequirePermission(p domain.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !ports.HasPermission(r.Context(), p) {
+				http.Error(w, "Forbidden: permission "+string(p)+" required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -5,26 +5,41 @@ import (
 	"net/http"
 	"cpd-nexus/internal/api/handlers"
 	"cpd-nexus/internal/api/middleware"
+	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
 
 	"github.com/gorilla/mux"
 )
 
 type RouterConfig struct {
-	AuthHandler        *handlers.AuthHandler
-	WorkersHandler     *handlers.WorkersHandler
-	ProjectsHandler    *handlers.ProjectsHandler
-	SitesHandler       *handlers.SitesHandler
-	DevicesHandler     *handlers.DevicesHandler
-	UsersHandler       *handlers.UsersHandler
-	AssignmentsHandler *handlers.AssignmentsHandler
-	AnalyticsHandler   *handlers.AnalyticsHandler
-	AttendanceHandler  *handlers.AttendanceHandler
-	SettingsHandler    *handlers.SettingsHandler
-	BridgeSyncHandler  *handlers.BridgeSyncHandler
-	BridgeHandler      *handlers.BridgeHandler
-	PitstopHandler     *handlers.PitstopHandler
-	UserRepo           ports.UserRepository
+	AuthHandler          *handlers.AuthHandler
+	WorkersHandler       *handlers.WorkersHandler
+	ProjectsHandler      *handlers.ProjectsHandler
+	SitesHandler         *handlers.SitesHandler
+	DevicesHandler       *handlers.DevicesHandler
+	UsersHandler         *handlers.UsersHandler
+	AssignmentsHandler   *handlers.AssignmentsHandler
+	AnalyticsHandler     *handlers.AnalyticsHandler
+	AttendanceHandler    *handlers.AttendanceHandler
+	SettingsHandler      *handlers.SettingsHandler
+	BridgeSyncHandler    *handlers.BridgeSyncHandler
+	BridgeHandler        *handlers.BridgeHandler
+	BridgeOutboxHandler  *handlers.BridgeOutboxHandler
+	PitstopHandler       *handlers.PitstopHandler
+	MembersHandler       *handlers.MembersHandler
+	APIKeysHandler       *handlers.APIKeysHandler
+	OIDCHandler          *handlers.OIDCHandler
+	ImpersonationHandler *handlers.ImpersonationHandler
+	ShiftsHandler        *handlers.ShiftsHandler
+	CorrectionsHandler   *handlers.CorrectionsHandler
+	UserRepo             ports.UserRepository
+	MemberRepo           ports.MemberRepository
+	SessionRepo          ports.SessionRepository
+	APIKeyRepo           ports.APIKeyRepository
+	ImpersonationRepo    ports.ImpersonationRepository
+
+	// AttendanceEntryHandler serves manual entry and timesheet import
+	AttendanceEntryHandler *handlers.AttendanceEntryHandler
 }
 
 // RegisterRoutes sets up all API endpoints
@@ -36,21 +51,43 @@ func RegisterRoutes(r *mux.Router, cfg RouterConfig) {
 	}).Methods("GET")
 
 	// --- Auth Routes (Public) ---
-	r.HandleFunc("/api/auth/login", cfg.AuthHandler.Login).Methods("POST")
+	r.Handle("/api/auth/login", middleware.ClientInfo(http.HandlerFunc(cfg.AuthHandler.Login))).Methods("POST")
+	r.Handle("/api/auth/refresh", middleware.ClientInfo(http.HandlerFunc(cfg.AuthHandler.Refresh))).Methods("POST")
+	r.Handle("/api/auth/password/reset", middleware.ClientInfo(http.HandlerFunc(cfg.AuthHandler.ResetPassword))).Methods("POST")
+	// Second login step; these authenticate with the mfa_token returned by /api/auth/login
+	r.Handle("/api/auth/mfa/verify", middleware.ClientInfo(http.HandlerFunc(cfg.AuthHandler.VerifyMFA))).Methods("POST")
+	r.Handle("/api/auth/mfa/enrol", middleware.ClientInfo(http.HandlerFunc(cfg.AuthHandler.StartMFAEnrolment))).Methods("POST")
+	r.Handle("/api/auth/mfa/enrol/confirm", middleware.ClientInfo(http.HandlerFunc(cfg.AuthHandler.ConfirmMFAEnrolment))).Methods("POST")
+
+	// Single sign-on: the browser is redirected to the organisation's identity provider and back
+	if cfg.OIDCHandler != nil {
+		r.HandleFunc("/api/auth/oidc/discover", cfg.OIDCHandler.Discover).Methods("GET")
+		r.HandleFunc("/api/auth/oidc/{providerId}/start", cfg.OIDCHandler.StartLogin).Methods("GET")
+		r.Handle("/api/auth/oidc/callback", middleware.ClientInfo(http.HandlerFunc(cfg.OIDCHandler.Callback))).Methods("GET")
+	}
 
 	// --- Bridge Connection (Internal/Machine-to-Machine) ---
 	// This endpoint handles its own token-based authentication
 	r.HandleFunc("/api/v1/bridge/connect", cfg.BridgeHandler.Connect)
 
 	api := r.PathPrefix("/api").Subrouter()
-	api.Use(middleware.UserScopeMiddleware(cfg.UserRepo))
+	api.Use(middleware.UserScopeMiddleware(cfg.UserRepo, cfg.MemberRepo, cfg.SessionRepo, cfg.APIKeyRepo, cfg.ImpersonationRepo))
 
 	// --- Auth Routes (Protected) ---
 	api.HandleFunc("/auth/me", cfg.AuthHandler.Me).Methods("GET")
+	api.HandleFunc("/auth/logout", cfg.AuthHandler.Logout).Methods("POST")
+	api.Handle("/auth/password", middleware.RequireUserScope(http.HandlerFunc(cfg.AuthHandler.ChangePassword))).Methods("POST")
+	api.HandleFunc("/auth/mfa/setup", cfg.AuthHandler.StartMFAEnrolment).Methods("POST")
+	api.HandleFunc("/auth/mfa/setup/confirm", cfg.AuthHandler.ConfirmMFAEnrolment).Methods("POST")
+	api.HandleFunc("/auth/mfa/recovery-codes", cfg.AuthHandler.RegenerateRecoveryCodes).Methods("POST")
+	api.HandleFunc("/auth/mfa/disable", cfg.AuthHandler.DisableMFA).Methods("POST")
+	if cfg.ImpersonationHandler != nil {
+		api.HandleFunc("/auth/impersonation/end", cfg.ImpersonationHandler.End).Methods("POST")
+	}
 
 	// --- Administrative Routes (Global Admin / Vendor Only) ---
 	admin := api.PathPrefix("").Subrouter()
-	admin.Use(middleware.RequireAdminScope)
+	admin.Use(middleware.RequireAdminScope, middleware.RequireTenantInScope)
 
 	admin.HandleFunc("/users", cfg.UsersHandler.GetUsers).Methods("GET")
 	admin.HandleFunc("/users", cfg.UsersHandler.CreateUser).Methods("POST")
@@ -58,6 +95,47 @@ func RegisterRoutes(r *mux.Router, cfg RouterConfig) {
 	admin.HandleFunc("/users/{id}", cfg.UsersHandler.UpdateUser).Methods("PUT")
 	admin.HandleFunc("/users/{id}", cfg.UsersHandler.DeleteUser).Methods("DELETE")
 	admin.HandleFunc("/users/{id}/bridge", cfg.UsersHandler.UpdateBridgeConfig).Methods("PUT")
+	admin.HandleFunc("/users/{id}/vendor-tenants", cfg.UsersHandler.GetVendorTenants).Methods("GET")
+	admin.HandleFunc("/users/{id}/vendor-tenants", cfg.UsersHandler.SetVendorTenants).Methods("PUT")
+	admin.HandleFunc("/users/{id}/sessions/revoke", cfg.AuthHandler.RevokeSessions).Methods("POST")
+	admin.HandleFunc("/users/{id}/members/{memberId}/sessions/revoke", cfg.AuthHandler.RevokeSessions).Methods("POST")
+	admin.HandleFunc("/login-lockouts", cfg.AuthHandler.GetLockouts).Methods("GET")
+	admin.HandleFunc("/login-lockouts/{scope}/{key}", cfg.AuthHandler.Unlock).Methods("DELETE")
+	admin.HandleFunc("/users/{id}/members/{memberId}/mfa/reset", cfg.AuthHandler.ResetMFA).Methods("POST")
+
+	if cfg.MembersHandler != nil {
+		admin.HandleFunc("/users/{id}/members", cfg.MembersHandler.GetMembers).Methods("GET")
+		admin.HandleFunc("/users/{id}/members", cfg.MembersHandler.CreateMember).Methods("POST")
+		admin.HandleFunc("/users/{id}/members/{memberId}", cfg.MembersHandler.GetMember).Methods("GET")
+		admin.HandleFunc("/users/{id}/members/{memberId}", cfg.MembersHandler.UpdateMember).Methods("PUT")
+		admin.HandleFunc("/users/{id}/members/{memberId}", cfg.MembersHandler.DeactivateMember).Methods("DELETE")
+		admin.HandleFunc("/users/{id}/members/{memberId}/role", cfg.MembersHandler.AssignRole).Methods("PUT")
+		admin.HandleFunc("/users/{id}/members/{memberId}/password-reset", cfg.MembersHandler.IssuePasswordReset).Methods("POST")
+		admin.HandleFunc("/users/{id}/members/{memberId}/unlock", cfg.MembersHandler.UnlockMember).Methods("POST")
+	}
+
+	if cfg.APIKeysHandler != nil {
+		admin.HandleFunc("/users/{id}/api-keys", cfg.APIKeysHandler.GetKeys).Methods("GET")
+		admin.HandleFunc("/users/{id}/api-keys", cfg.APIKeysHandler.CreateKey).Methods("POST")
+		admin.HandleFunc("/users/{id}/api-keys/{keyId}", cfg.APIKeysHandler.RevokeKey).Methods("DELETE")
+	}
+
+	if cfg.ImpersonationHandler != nil {
+		admin.HandleFunc("/users/{id}/impersonate", cfg.ImpersonationHandler.Start).Methods("POST")
+		admin.HandleFunc("/users/{id}/impersonations", cfg.ImpersonationHandler.List).Methods("GET")
+	}
+
+	if cfg.OIDCHandler != nil {
+		admin.HandleFunc("/users/{id}/oidc-providers", cfg.OIDCHandler.GetProviders).Methods("GET")
+		admin.HandleFunc("/users/{id}/oidc-providers", cfg.OIDCHandler.CreateProvider).Methods("POST")
+		admin.HandleFunc("/users/{id}/oidc-providers/{providerId}", cfg.OIDCHandler.UpdateProvider).Methods("PUT")
+		admin.HandleFunc("/users/{id}/oidc-providers/{providerId}", cfg.OIDCHandler.DeleteProvider).Methods("DELETE")
+	}
+
+	if cfg.BridgeOutboxHandler != nil {
+		admin.HandleFunc("/users/{id}/bridge/outbox", cfg.BridgeOutboxHandler.GetOutbox).Methods("GET")
+		admin.HandleFunc("/users/{id}/bridge/outbox", cfg.BridgeOutboxHandler.PurgeOutbox).Methods("DELETE")
+	}
 
 	admin.HandleFunc("/devices", cfg.DevicesHandler.CreateDevice).Methods("POST")
 
@@ -65,71 +143,138 @@ func RegisterRoutes(r *mux.Router, cfg RouterConfig) {
 
 		admin.HandleFunc("/pitstop/authorisations/sync", cfg.PitstopHandler.SyncConfig).Methods("POST")
 		admin.HandleFunc("/users/{id}/pitstop-on-behalf-of", cfg.PitstopHandler.AssignOnBehalfOf).Methods("POST")
+		admin.HandleFunc("/pitstop/submissions/dead-letter", cfg.PitstopHandler.GetDeadLetterSubmissions).Methods("GET")
+		admin.HandleFunc("/pitstop/submissions/requeue", cfg.PitstopHandler.RequeueSubmissions).Methods("POST")
 	}
 
 	// --- Scoped Routes (Project Isolation) ---
 	scoped := api.PathPrefix("").Subrouter()
 	scoped.Use(middleware.RequireUserScope)
 
+	// can wraps a handler with the role permission it requires
+	can := func(p domain.Permission, h http.HandlerFunc) http.Handler {
+		return middleware.RequirePermission(p)(h)
+	}
+
+	// --- Roles (any signed-in user may read the role catalogue) ---
+	scoped.HandleFunc("/roles", cfg.UsersHandler.GetRoles).Methods("GET")
+
+	// --- Members Routes (logins of the caller's organisation) ---
+	if cfg.MembersHandler != nil {
+		scoped.Handle("/members", can(domain.PermMembersManage, cfg.MembersHandler.GetMembers)).Methods("GET")
+		scoped.Handle("/members", can(domain.PermMembersManage, cfg.MembersHandler.CreateMember)).Methods("POST")
+		scoped.Handle("/members/{memberId}", can(domain.PermMembersManage, cfg.MembersHandler.GetMember)).Methods("GET")
+		scoped.Handle("/members/{memberId}", can(domain.PermMembersManage, cfg.MembersHandler.UpdateMember)).Methods("PUT")
+		scoped.Handle("/members/{memberId}", can(domain.PermMembersManage, cfg.MembersHandler.DeactivateMember)).Methods("DELETE")
+		scoped.Handle("/members/{memberId}/role", can(domain.PermMembersManage, cfg.MembersHandler.AssignRole)).Methods("PUT")
+		scoped.Handle("/members/{memberId}/password-reset", can(domain.PermMembersManage, cfg.MembersHandler.IssuePasswordReset)).Methods("POST")
+		scoped.Handle("/members/{memberId}/unlock", can(domain.PermMembersManage, cfg.MembersHandler.UnlockMember)).Methods("POST")
+	}
+
+	// --- API Keys (machine-to-machine access of the caller's organisation) ---
+	if cfg.APIKeysHandler != nil {
+		scoped.Handle("/api-keys", can(domain.PermAPIKeysManage, cfg.APIKeysHandler.GetKeys)).Methods("GET")
+		scoped.Handle("/api-keys", can(domain.PermAPIKeysManage, cfg.APIKeysHandler.CreateKey)).Methods("POST")
+		scoped.Handle("/api-keys/{keyId}", can(domain.PermAPIKeysManage, cfg.APIKeysHandler.RevokeKey)).Methods("DELETE")
+	}
+
+	// --- Single Sign-On (identity providers of the caller's organisation) ---
+	if cfg.OIDCHandler != nil {
+		scoped.Handle("/oidc-providers", can(domain.PermMembersManage, cfg.OIDCHandler.GetProviders)).Methods("GET")
+		scoped.Handle("/oidc-providers", can(domain.PermMembersManage, cfg.OIDCHandler.CreateProvider)).Methods("POST")
+		scoped.Handle("/oidc-providers/{providerId}", can(domain.PermMembersManage, cfg.OIDCHandler.UpdateProvider)).Methods("PUT")
+		scoped.Handle("/oidc-providers/{providerId}", can(domain.PermMembersManage, cfg.OIDCHandler.DeleteProvider)).Methods("DELETE")
+	}
+
 	// --- Workers Routes ---
-	scoped.HandleFunc("/workers", cfg.WorkersHandler.GetWorkers).Methods("GET")
-	scoped.HandleFunc("/workers", cfg.WorkersHandler.CreateWorker).Methods("POST")
-	scoped.HandleFunc("/workers/{id}", cfg.WorkersHandler.GetWorkerById).Methods("GET")
-	scoped.HandleFunc("/workers/{id}", cfg.WorkersHandler.UpdateWorker).Methods("PUT")
-	scoped.HandleFunc("/workers/{id}", cfg.WorkersHandler.DeleteWorker).Methods("DELETE")
+	scoped.Handle("/workers", can(domain.PermWorkersRead, cfg.WorkersHandler.GetWorkers)).Methods("GET")
+	scoped.Handle("/workers", can(domain.PermWorkersWrite, cfg.WorkersHandler.CreateWorker)).Methods("POST")
+	scoped.Handle("/workers/{id}", can(domain.PermWorkersRead, cfg.WorkersHandler.GetWorkerById)).Methods("GET")
+	scoped.Handle("/workers/{id}", can(domain.PermWorkersWrite, cfg.WorkersHandler.UpdateWorker)).Methods("PUT")
+	scoped.Handle("/workers/{id}", can(domain.PermWorkersWrite, cfg.WorkersHandler.DeleteWorker)).Methods("DELETE")
 
 	// --- Projects Routes ---
-	scoped.HandleFunc("/projects", cfg.ProjectsHandler.GetProjects).Methods("GET")
-	scoped.HandleFunc("/projects", cfg.ProjectsHandler.CreateProject).Methods("POST")
-	scoped.HandleFunc("/projects/{id}", cfg.ProjectsHandler.GetProjectById).Methods("GET")
-	scoped.HandleFunc("/projects/{id}", cfg.ProjectsHandler.UpdateProject).Methods("PUT")
-	scoped.HandleFunc("/projects/{id}", cfg.ProjectsHandler.DeleteProject).Methods("DELETE")
+	scoped.Handle("/projects", can(domain.PermProjectsRead, cfg.ProjectsHandler.GetProjects)).Methods("GET")
+	scoped.Handle("/projects", can(domain.PermProjectsWrite, cfg.ProjectsHandler.CreateProject)).Methods("POST")
+	scoped.Handle("/projects/{id}", can(domain.PermProjectsRead, cfg.ProjectsHandler.GetProjectById)).Methods("GET")
+	scoped.Handle("/projects/{id}", can(domain.PermProjectsWrite, cfg.ProjectsHandler.UpdateProject)).Methods("PUT")
+	scoped.Handle("/projects/{id}", can(domain.PermProjectsWrite, cfg.ProjectsHandler.DeleteProject)).Methods("DELETE")
 
 	// --- Sites Routes ---
-	scoped.HandleFunc("/sites", cfg.SitesHandler.GetSites).Methods("GET")
-	scoped.HandleFunc("/sites", cfg.SitesHandler.CreateSite).Methods("POST")
-	scoped.HandleFunc("/sites/{id}", cfg.SitesHandler.GetSiteById).Methods("GET")
-	scoped.HandleFunc("/sites/{id}", cfg.SitesHandler.UpdateSite).Methods("PUT")
-	scoped.HandleFunc("/sites/{id}", cfg.SitesHandler.DeleteSite).Methods("DELETE")
+	scoped.Handle("/sites", can(domain.PermSitesRead, cfg.SitesHandler.GetSites)).Methods("GET")
+	scoped.Handle("/sites", can(domain.PermSitesWrite, cfg.SitesHandler.CreateSite)).Methods("POST")
+	scoped.Handle("/sites/{id}", can(domain.PermSitesRead, cfg.SitesHandler.GetSiteById)).Methods("GET")
+	scoped.Handle("/sites/{id}", can(domain.PermSitesWrite, cfg.SitesHandler.UpdateSite)).Methods("PUT")
+	scoped.Handle("/sites/{id}", can(domain.PermSitesWrite, cfg.SitesHandler.DeleteSite)).Methods("DELETE")
+
+	// --- Shifts Routes (working patterns of sites and projects) ---
+	if cfg.ShiftsHandler != nil {
+		scoped.Handle("/shifts", can(domain.PermSitesRead, cfg.ShiftsHandler.GetShifts)).Methods("GET")
+		scoped.Handle("/shifts", can(domain.PermSitesWrite, cfg.ShiftsHandler.CreateShift)).Methods("POST")
+		scoped.Handle("/shifts/{id}", can(domain.PermSitesRead, cfg.ShiftsHandler.GetShift)).Methods("GET")
+		scoped.Handle("/shifts/{id}", can(domain.PermSitesWrite, cfg.ShiftsHandler.UpdateShift)).Methods("PUT")
+		scoped.Handle("/shifts/{id}", can(domain.PermSitesWrite, cfg.ShiftsHandler.DeleteShift)).Methods("DELETE")
+	}
 
 	// --- Devices Routes (Scoped) ---
-	scoped.HandleFunc("/devices", cfg.DevicesHandler.GetDevices).Methods("GET")
-	scoped.HandleFunc("/devices/{id}", cfg.DevicesHandler.GetDeviceById).Methods("GET")
-	scoped.HandleFunc("/devices/{id}", cfg.DevicesHandler.UpdateDevice).Methods("PUT")
-	scoped.HandleFunc("/devices/{id}", cfg.DevicesHandler.DeleteDevice).Methods("DELETE")
+	scoped.Handle("/devices", can(domain.PermDevicesRead, cfg.DevicesHandler.GetDevices)).Methods("GET")
+	scoped.Handle("/devices/{id}", can(domain.PermDevicesRead, cfg.DevicesHandler.GetDeviceById)).Methods("GET")
+	scoped.Handle("/devices/{id}", can(domain.PermDevicesWrite, cfg.DevicesHandler.UpdateDevice)).Methods("PUT")
+	scoped.Handle("/devices/{id}", can(domain.PermDevicesWrite, cfg.DevicesHandler.DeleteDevice)).Methods("DELETE")
 
 	// --- Attendance Routes ---
-	scoped.HandleFunc("/attendance", cfg.AttendanceHandler.GetAttendance).Methods("GET")
-	scoped.HandleFunc("/attendance/{id}", cfg.AttendanceHandler.UpdateAttendance).Methods("PUT")
+	scoped.Handle("/attendance", can(domain.PermAttendanceRead, cfg.AttendanceHandler.GetAttendance)).Methods("GET")
+	scoped.Handle("/attendance/punches", can(domain.PermAttendanceRead, cfg.AttendanceHandler.GetPunches)).Methods("GET")
+	scoped.Handle("/attendance/rederive", can(domain.PermAttendanceWrite, cfg.AttendanceHandler.RederiveAttendance)).Methods("POST")
+
+	// --- Manual Attendance (entered by hand or imported, e.g. during a device outage) ---
+	if cfg.AttendanceEntryHandler != nil {
+		scoped.Handle("/attendance", can(domain.PermAttendanceWrite, cfg.AttendanceEntryHandler.CreateAttendance)).Methods("POST")
+		scoped.Handle("/attendance/bulk", can(domain.PermAttendanceWrite, cfg.AttendanceEntryHandler.BulkCreateAttendance)).Methods("POST")
+		scoped.Handle("/attendance/import", can(domain.PermAttendanceWrite, cfg.AttendanceEntryHandler.ImportAttendance)).Methods("POST")
+	}
+
+	// --- Attendance Corrections (times change only through an approved correction) ---
+	if cfg.CorrectionsHandler != nil {
+		scoped.Handle("/attendance/corrections", can(domain.PermAttendanceRead, cfg.CorrectionsHandler.GetCorrections)).Methods("GET")
+		scoped.Handle("/attendance/corrections/{correctionId}", can(domain.PermAttendanceRead, cfg.CorrectionsHandler.GetCorrection)).Methods("GET")
+		scoped.Handle("/attendance/corrections/{correctionId}/approve", can(domain.PermAttendanceApprove, cfg.CorrectionsHandler.ApproveCorrection)).Methods("POST")
+		scoped.Handle("/attendance/corrections/{correctionId}/reject", can(domain.PermAttendanceApprove, cfg.CorrectionsHandler.RejectCorrection)).Methods("POST")
+		scoped.Handle("/attendance/{id}", can(domain.PermAttendanceWrite, cfg.CorrectionsHandler.RequestCorrection)).Methods("PUT")
+		scoped.Handle("/attendance/{id}/corrections", can(domain.PermAttendanceWrite, cfg.CorrectionsHandler.RequestCorrection)).Methods("POST")
+		scoped.Handle("/attendance/{id}/corrections", can(domain.PermAttendanceRead, cfg.CorrectionsHandler.GetCorrections)).Methods("GET")
+		scoped.Handle("/attendance/{id}/revisions", can(domain.PermAttendanceRead, cfg.CorrectionsHandler.GetRevisions)).Methods("GET")
+	}
 
 	// --- Uploads ---
-	scoped.HandleFunc("/upload/face", handlers.UploadFaceHandler).Methods("POST")
+	scoped.Handle("/upload/face", can(domain.PermWorkersWrite, handlers.UploadFaceHandler)).Methods("POST")
 
 	// --- Assignments Routes ---
-	scoped.HandleFunc("/projects/{projectId}/assign-workers", cfg.AssignmentsHandler.AssignWorkers).Methods("POST")
-	scoped.HandleFunc("/sites/{siteId}/assign-devices", cfg.AssignmentsHandler.AssignDevices).Methods("POST")
-	scoped.HandleFunc("/sites/{siteId}/assign-projects", cfg.AssignmentsHandler.AssignProjects).Methods("POST")
-	scoped.HandleFunc("/users/{userId}/devices/bulk", cfg.AssignmentsHandler.AssignDevicesToUser).Methods("POST")
+	scoped.Handle("/projects/{projectId}/assign-workers", can(domain.PermProjectsWrite, cfg.AssignmentsHandler.AssignWorkers)).Methods("POST")
+	scoped.Handle("/sites/{siteId}/assign-devices", can(domain.PermDevicesWrite, cfg.AssignmentsHandler.AssignDevices)).Methods("POST")
+	scoped.Handle("/sites/{siteId}/assign-projects", can(domain.PermSitesWrite, cfg.AssignmentsHandler.AssignProjects)).Methods("POST")
+	scoped.Handle("/users/{userId}/devices/bulk", can(domain.PermDevicesWrite, cfg.AssignmentsHandler.AssignDevicesToUser)).Methods("POST")
 
 	// --- Analytics Routes ---
-	scoped.HandleFunc("/analytics/dashboard", cfg.AnalyticsHandler.GetDashboardStats).Methods("GET")
-	scoped.HandleFunc("/analytics/activity-log", cfg.AnalyticsHandler.GetActivityLog).Methods("GET")
-	scoped.HandleFunc("/analytics/detailed", cfg.AnalyticsHandler.GetDetailedAnalytics).Methods("GET")
+	scoped.Handle("/analytics/dashboard", can(domain.PermAnalyticsRead, cfg.AnalyticsHandler.GetDashboardStats)).Methods("GET")
+	scoped.Handle("/analytics/activity-log", can(domain.PermAnalyticsRead, cfg.AnalyticsHandler.GetActivityLog)).Methods("GET")
+	scoped.Handle("/analytics/detailed", can(domain.PermAnalyticsRead, cfg.AnalyticsHandler.GetDetailedAnalytics)).Methods("GET")
 
 	// --- Settings Routes ---
-	scoped.HandleFunc("/settings", cfg.SettingsHandler.GetSettings).Methods("GET")
-	scoped.HandleFunc("/settings", cfg.SettingsHandler.UpdateSettings).Methods("PUT")
+	scoped.Handle("/settings", can(domain.PermSettingsRead, cfg.SettingsHandler.GetSettings)).Methods("GET")
+	scoped.Handle("/settings", can(domain.PermSettingsWrite, cfg.SettingsHandler.UpdateSettings)).Methods("PUT")
 
 	// --- Bridge Sync Routes ---
 	if cfg.BridgeSyncHandler != nil {
-		scoped.HandleFunc("/bridge/sync-users", cfg.BridgeSyncHandler.SyncUsers).Methods("POST")
+		scoped.Handle("/bridge/sync-users", can(domain.PermBridgeSync, cfg.BridgeSyncHandler.SyncUsers)).Methods("POST")
 	}
 
 	// --- Pitstop Test Endpoints (Scoped/Admin) ---
 	if cfg.PitstopHandler != nil {
-		scoped.HandleFunc("/pitstop/authorisations", cfg.PitstopHandler.GetAuthorisations).Methods("GET")
-		scoped.HandleFunc("/pitstop/authorisations/testing-projects", cfg.PitstopHandler.GetTestingProjects).Methods("GET")
-		scoped.HandleFunc("/pitstop/authorisations/test-submission/{project_id}", cfg.PitstopHandler.TestSubmission).Methods("POST")
+		scoped.Handle("/pitstop/authorisations", can(domain.PermSubmissionsRead, cfg.PitstopHandler.GetAuthorisations)).Methods("GET")
+		scoped.Handle("/pitstop/authorisations/testing-projects", can(domain.PermSubmissionsRead, cfg.PitstopHandler.GetTestingProjects)).Methods("GET")
+		scoped.Handle("/pitstop/authorisations/test-submission/{project_id}", can(domain.PermSubmissionsRun, cfg.PitstopHandler.TestSubmission)).Methods("POST")
+		scoped.Handle("/pitstop/authorisations/preview-submission/{project_id}", can(domain.PermSubmissionsRead, cfg.PitstopHandler.PreviewSubmission)).Methods("GET")
 	}
 
 	// Serve Static Files — protected behind user scope so biometric uploads are not publicly accessible
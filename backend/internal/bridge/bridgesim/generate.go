@@ -0,0 +1,104 @@
+package bridgesim
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"time"
+)
+
+// generateRecords produces one shift per local calendar day in [start, end].
+// The same seed, worker and day always yield the same punches, so overlapping fetch windows
+// return identical records just like a real device log would.
+func generateRecords(seed int64, workerID string, shift ShiftPattern, loc *time.Location, start, end time.Time) []Record {
+	startClock, _ := time.Parse("15:04", shift.Start)
+	endClock, _ := time.Parse("15:04", shift.End)
+	overnight := !endClock.After(startClock)
+
+	var records []Record
+
+	// Begin one day early so an overnight shift that started before the window can still close inside it
+	first := start.In(loc).AddDate(0, 0, -1)
+	day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	last := end.In(loc)
+
+	for !day.After(last) {
+		if shift.SkipWeekends && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday) {
+			day = day.AddDate(0, 0, 1)
+			continue
+		}
+
+		rng := rand.New(rand.NewSource(daySeed(seed, workerID, day)))
+		if rng.Float64() < shift.AbsentRate {
+			day = day.AddDate(0, 0, 1)
+			continue
+		}
+
+		timeIn := atClock(day, startClock).Add(jitter(rng, shift.JitterMinutes))
+		outDay := day
+		if overnight {
+			outDay = day.AddDate(0, 0, 1)
+		}
+		timeOut := atClock(outDay, endClock).Add(jitter(rng, shift.JitterMinutes))
+		missingOut := rng.Float64() < shift.MissingTimeOutRate
+
+		if !timeIn.Before(start) && !timeIn.After(end) {
+			rec := Record{TimeIn: timeIn.Format(time.RFC3339)}
+			// A shift still in progress at the end of the window has no time_out yet
+			if !missingOut && !timeOut.After(end) {
+				rec.TimeOut = timeOut.Format(time.RFC3339)
+			}
+			records = append(records, rec)
+		}
+
+		day = day.AddDate(0, 0, 1)
+	}
+	return records
+}
+
+// filterRecords returns the scripted records whose time_in falls within [start, end].
+func filterRecords(records []Record, start, end time.Time) []Record {
+	var out []Record
+	for _, r := range records {
+		t, err := time.Parse(time.RFC3339, r.TimeIn)
+		if err != nil || t.Before(start) || t.After(end) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// recordPunches splits records into the raw scans behind them: the worker punches in at the first
+// queried device and out at the last one, as at a site with separate entry and exit gates.
+func recordPunches(records []Record, devices []string) []Punch {
+	entry, exit := "", ""
+	if len(devices) > 0 {
+		entry, exit = devices[0], devices[len(devices)-1]
+	}
+	punches := []Punch{}
+	for _, r := range records {
+		punches = append(punches, Punch{DeviceSN: entry, Direction: "in", Time: r.TimeIn})
+		if r.TimeOut != "" {
+			punches = append(punches, Punch{DeviceSN: exit, Direction: "out", Time: r.TimeOut})
+		}
+	}
+	return punches
+}
+
+func atClock(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(), 0, 0, day.Location())
+}
+
+func jitter(rng *rand.Rand, minutes int) time.Duration {
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(rng.Intn(2*minutes+1)-minutes) * time.Minute
+}
+
+func daySeed(seed int64, workerID string, day time.Time) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(workerID))
+	h.Write([]byte(day.Format("2006-01-02")))
+	return seed ^ int64(h.Sum64())
+}
@@ -0,0 +1,136 @@
+package bridgesim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Reply is the code and message the simulator answers a command with
+type Reply struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg,omitempty"`
+}
+
+// Record is a single attendance record as returned in GET_ATTENDANCE_RESPONSE
+type Record struct {
+	TimeIn  string `json:"time_in"`
+	TimeOut string `json:"time_out,omitempty"`
+}
+
+// Punch is a single device scan as returned in GET_ATTENDANCE_RESPONSE alongside the records
+type Punch struct {
+	DeviceSN  string `json:"device_sn"`
+	Direction string `json:"direction"`
+	Time      string `json:"time"`
+}
+
+// ShiftPattern describes how daily attendance is generated for a worker
+type ShiftPattern struct {
+	Start              string  `json:"start"`                 // HH:MM, local to Scenario.Timezone
+	End                string  `json:"end"`                   // HH:MM; earlier than Start means the shift ends the next day
+	JitterMinutes      int     `json:"jitter_minutes"`        // random +/- offset applied to both punches
+	AbsentRate         float64 `json:"absent_rate"`           // probability of no record on a day
+	MissingTimeOutRate float64 `json:"missing_time_out_rate"` // probability the worker never punched out
+	SkipWeekends       bool    `json:"skip_weekends"`
+}
+
+// WorkerScenario overrides the defaults for a single worker (keyed by employee_no / worker_id)
+type WorkerScenario struct {
+	// Register and Update override the reply to REGISTER_USER / UPDATE_USER for this worker
+	Register *Reply `json:"register,omitempty"`
+	Update   *Reply `json:"update,omitempty"`
+
+	// Attendance overrides the reply to GET_ATTENDANCE (e.g. 404); Records replaces generated records
+	Attendance *Reply        `json:"attendance,omitempty"`
+	Records    []Record      `json:"records,omitempty"`
+	Shift      *ShiftPattern `json:"shift,omitempty"`
+}
+
+// Scenario configures how the simulated bridge behaves
+type Scenario struct {
+	// Devices is the virtual device roster (serial numbers). Empty accepts any device.
+	Devices []string `json:"devices"`
+	// OfflineDevices are on the roster but unreachable; commands that target them fail with 500
+	OfflineDevices []string `json:"offline_devices"`
+
+	Register Reply `json:"register"`
+	Update   Reply `json:"update"`
+
+	// RequireRegistration makes UPDATE_USER and GET_ATTENDANCE answer 404 for workers not on the roster
+	RequireRegistration bool `json:"require_registration"`
+
+	Timezone string                    `json:"timezone"`
+	Shift    ShiftPattern              `json:"shift"`
+	Workers  map[string]WorkerScenario `json:"workers"`
+
+	// Seed makes generated attendance deterministic, so re-fetching a window returns the same records
+	Seed int64 `json:"seed"`
+
+	// ResponseDelayMs delays every reply, to exercise request timeouts
+	ResponseDelayMs int `json:"response_delay_ms"`
+}
+
+// DefaultScenario accepts every command and generates a regular 08:00-17:30 day shift.
+func DefaultScenario() *Scenario {
+	return &Scenario{
+		Register: Reply{Code: 200},
+		Update:   Reply{Code: 200},
+		Timezone: "Asia/Singapore",
+		Shift: ShiftPattern{
+			Start:         "08:00",
+			End:           "17:30",
+			JitterMinutes: 15,
+		},
+		Seed: 1,
+	}
+}
+
+// LoadScenario reads a JSON scenario file. Fields that are omitted keep their DefaultScenario values.
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	sc := DefaultScenario()
+	if err := json.Unmarshal(data, sc); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+func (sc *Scenario) validate() error {
+	if _, err := sc.location(); err != nil {
+		return err
+	}
+	patterns := []ShiftPattern{sc.Shift}
+	for _, w := range sc.Workers {
+		if w.Shift != nil {
+			patterns = append(patterns, *w.Shift)
+		}
+	}
+	for _, p := range patterns {
+		if _, err := time.Parse("15:04", p.Start); err != nil {
+			return fmt.Errorf("invalid shift start %q: expected HH:MM", p.Start)
+		}
+		if _, err := time.Parse("15:04", p.End); err != nil {
+			return fmt.Errorf("invalid shift end %q: expected HH:MM", p.End)
+		}
+	}
+	return nil
+}
+
+func (sc *Scenario) location() (*time.Location, error) {
+	if sc.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(sc.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", sc.Timezone, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,417 @@
+// Package bridgesim simulates an on-site IoT bridge. It connects to /api/v1/bridge/connect like
+// the real bridge, keeps a virtual roster of registered workers and answers REGISTER_USER,
+// UPDATE_USER and GET_ATTENDANCE according to a Scenario. It backs cmd/bridge-sim and can be
+// used directly from tests.
+package bridgesim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"cpd-nexus/internal/bridge"
+
+	"github.com/gorilla/websocket"
+)
+
+// RosterUser is a worker registered on the simulated devices
+type RosterUser struct {
+	EmployeeNo   string    `json:"employee_no"`
+	Name         string    `json:"name"`
+	Devices      []string  `json:"devices"`
+	CardNo       string    `json:"card_no,omitempty"`
+	FaceID       string    `json:"face_id,omitempty"`
+	RegisteredAt time.Time `json:"registered_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// userSyncPayload mirrors the REGISTER_USER / UPDATE_USER payload sent by the backend
+type userSyncPayload struct {
+	Devices []string `json:"devices"`
+	User    struct {
+		EmployeeNo     string `json:"employee_no"`
+		Name           string `json:"name"`
+		Authentication struct {
+			Card *struct {
+				CardNo string `json:"card_no"`
+			} `json:"card"`
+			Face *struct {
+				FaceID string `json:"face_id"`
+			} `json:"face"`
+		} `json:"authentication"`
+	} `json:"user"`
+}
+
+// attendanceRequest mirrors the GET_ATTENDANCE payload sent by the backend
+type attendanceRequest struct {
+	WorkerID  string   `json:"worker_id"`
+	Devices   []string `json:"devices"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+}
+
+// Sim is a simulated bridge. It is safe for concurrent use.
+type Sim struct {
+	BaseURL string
+	UserID  string
+	Token   string
+
+	scenario *Scenario
+	loc      *time.Location
+
+	mu       sync.Mutex
+	roster   map[string]RosterUser
+	received []bridge.Message
+
+	writeMu sync.Mutex
+	conn    *websocket.Conn
+}
+
+// New creates a simulator for the given backend and tenant credentials. A nil scenario uses DefaultScenario.
+func New(baseURL, userID, token string, sc *Scenario) (*Sim, error) {
+	if sc == nil {
+		sc = DefaultScenario()
+	}
+	if err := sc.validate(); err != nil {
+		return nil, err
+	}
+	loc, _ := sc.location()
+
+	return &Sim{
+		BaseURL:  baseURL,
+		UserID:   userID,
+		Token:    token,
+		scenario: sc,
+		loc:      loc,
+		roster:   make(map[string]RosterUser),
+	}, nil
+}
+
+// ConnectURL builds the WebSocket URL for the bridge endpoint from an http(s) or ws(s) base URL.
+func ConnectURL(baseURL, userID, token string) (string, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid base URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "ws", "":
+		u.Scheme = "ws"
+	case "https", "wss":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	u.Path += "/api/v1/bridge/connect"
+	u.RawQuery = url.Values{"user_id": {userID}, "token": {token}}.Encode()
+	return u.String(), nil
+}
+
+// Connect dials the backend. The backend rejects unknown tenants and bad tokens with 401.
+func (s *Sim) Connect(ctx context.Context) error {
+	wsURL, err := ConnectURL(s.BaseURL, s.UserID, s.Token)
+	if err != nil {
+		return err
+	}
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("bridge connect rejected with HTTP %d: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("bridge connect failed: %w", err)
+	}
+
+	s.writeMu.Lock()
+	s.conn = conn
+	s.writeMu.Unlock()
+	return nil
+}
+
+// Run reads commands from the backend and answers them until ctx is cancelled or the connection drops.
+// Pings from the backend are answered automatically while reading.
+func (s *Sim) Run(ctx context.Context) error {
+	s.writeMu.Lock()
+	conn := s.conn
+	s.writeMu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("bridge simulator is not connected")
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	for {
+		var msg bridge.Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("bridge connection closed: %w", err)
+		}
+
+		s.mu.Lock()
+		s.received = append(s.received, msg)
+		s.mu.Unlock()
+
+		go func(msg bridge.Message) {
+			resp := s.Handle(msg)
+			if resp == nil {
+				return
+			}
+			if d := time.Duration(s.scenario.ResponseDelayMs) * time.Millisecond; d > 0 {
+				select {
+				case <-time.After(d):
+				case <-ctx.Done():
+					return
+				}
+			}
+			_ = s.write(*resp)
+		}(msg)
+	}
+}
+
+// Close closes the connection to the backend.
+func (s *Sim) Close() {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+// SendHeartbeat pushes a DEVICE_HEARTBEAT for every roster device; offline devices report "offline".
+func (s *Sim) SendHeartbeat() error {
+	type device struct {
+		SN      string `json:"sn"`
+		Status  string `json:"status"`
+		Battery *int   `json:"battery,omitempty"`
+	}
+	devices := []device{}
+	for _, sn := range s.scenario.Devices {
+		d := device{SN: sn, Status: "online"}
+		if s.isOffline(sn) {
+			d.Status = "offline"
+		} else {
+			battery := 100
+			d.Battery = &battery
+		}
+		devices = append(devices, d)
+	}
+
+	msg, err := bridge.NewRequest("DEVICE_HEARTBEAT", map[string]interface{}{"devices": devices})
+	if err != nil {
+		return err
+	}
+	msg.Meta.RequestID = strings.Replace(msg.Meta.RequestID, "req-", "hb-", 1)
+	msg.Meta.SentAt = time.Now().UTC().Format(time.RFC3339)
+	return s.write(msg)
+}
+
+func (s *Sim) write(msg bridge.Message) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.conn == nil {
+		return fmt.Errorf("bridge simulator is not connected")
+	}
+	return s.conn.WriteJSON(msg)
+}
+
+// Roster returns the workers currently registered on the simulated devices.
+func (s *Sim) Roster() map[string]RosterUser {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make(map[string]RosterUser, len(s.roster))
+	for k, v := range s.roster {
+		out[k] = v
+	}
+	return out
+}
+
+// Received returns every command received from the backend, in arrival order.
+func (s *Sim) Received() []bridge.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]bridge.Message, len(s.received))
+	copy(out, s.received)
+	return out
+}
+
+// Handle builds the reply to a backend command without any network I/O.
+// It returns nil for actions the bridge does not answer.
+func (s *Sim) Handle(msg bridge.Message) *bridge.Message {
+	var reply Reply
+	var content interface{}
+
+	switch msg.Action {
+	case "REGISTER_USER", "UPDATE_USER":
+		reply = s.handleUserSync(msg)
+	case "GET_ATTENDANCE":
+		reply, content = s.handleAttendance(msg)
+	default:
+		return nil
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{
+		"code":    reply.Code,
+		"msg":     reply.Msg,
+		"content": content,
+	})
+	return &bridge.Message{
+		Meta: bridge.Meta{
+			RequestID: msg.Meta.RequestID,
+			SentAt:    time.Now().UTC().Format(time.RFC3339),
+		},
+		Action:  msg.Action + "_RESPONSE",
+		Payload: payload,
+	}
+}
+
+func (s *Sim) handleUserSync(msg bridge.Message) Reply {
+	var p userSyncPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil || p.User.EmployeeNo == "" {
+		return Reply{Code: 400, Msg: "Invalid user payload"}
+	}
+	worker := s.scenario.Workers[p.User.EmployeeNo]
+
+	isRegister := msg.Action == "REGISTER_USER"
+	reply := s.scenario.Update
+	override := worker.Update
+	if isRegister {
+		reply = s.scenario.Register
+		override = worker.Register
+	}
+	if override != nil {
+		reply = *override
+	}
+	if reply.Code != 200 {
+		return withDefaultMsg(reply)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, registered := s.roster[p.User.EmployeeNo]
+	if !isRegister && !registered && s.scenario.RequireRegistration {
+		return Reply{Code: 404, Msg: fmt.Sprintf("Worker %s not found in bridge registry", p.User.EmployeeNo)}
+	}
+	if err := s.checkDevices(p.Devices); err != nil {
+		return Reply{Code: 500, Msg: fmt.Sprintf("Failed to sync user to %d/%d devices. %v", len(p.Devices), len(p.Devices), err)}
+	}
+
+	now := time.Now()
+	user := RosterUser{
+		EmployeeNo:   p.User.EmployeeNo,
+		Name:         p.User.Name,
+		Devices:      p.Devices,
+		RegisteredAt: now,
+		UpdatedAt:    now,
+	}
+	if registered {
+		user.RegisteredAt = existing.RegisteredAt
+	}
+	if p.User.Authentication.Card != nil {
+		user.CardNo = p.User.Authentication.Card.CardNo
+	}
+	if p.User.Authentication.Face != nil {
+		user.FaceID = p.User.Authentication.Face.FaceID
+	}
+	s.roster[user.EmployeeNo] = user
+
+	if isRegister {
+		return Reply{Code: 200, Msg: fmt.Sprintf("User successfully registered on %d/%d devices", len(p.Devices), len(p.Devices))}
+	}
+	return Reply{Code: 200, Msg: "User successfully updated"}
+}
+
+func (s *Sim) handleAttendance(msg bridge.Message) (Reply, interface{}) {
+	var req attendanceRequest
+	if err := json.Unmarshal(msg.Payload, &req); err != nil || req.WorkerID == "" {
+		return Reply{Code: 400, Msg: "Invalid attendance request"}, nil
+	}
+	worker := s.scenario.Workers[req.WorkerID]
+	if worker.Attendance != nil && worker.Attendance.Code != 200 {
+		return withDefaultMsg(*worker.Attendance), nil
+	}
+
+	s.mu.Lock()
+	_, registered := s.roster[req.WorkerID]
+	deviceErr := s.checkDevices(req.Devices)
+	s.mu.Unlock()
+
+	if s.scenario.RequireRegistration && !registered {
+		return Reply{Code: 404, Msg: fmt.Sprintf("Worker %s not found in bridge registry", req.WorkerID)}, nil
+	}
+	if deviceErr != nil {
+		return Reply{Code: 500, Msg: deviceErr.Error()}, nil
+	}
+
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		return Reply{Code: 400, Msg: "Invalid start_time"}, nil
+	}
+	end, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		return Reply{Code: 400, Msg: "Invalid end_time"}, nil
+	}
+
+	var records []Record
+	if worker.Records != nil {
+		records = filterRecords(worker.Records, start, end)
+	} else {
+		shift := s.scenario.Shift
+		if worker.Shift != nil {
+			shift = *worker.Shift
+		}
+		records = generateRecords(s.scenario.Seed, req.WorkerID, shift, s.loc, start, end)
+	}
+	if records == nil {
+		records = []Record{}
+	}
+
+	return Reply{Code: 200, Msg: "Success"}, map[string]interface{}{
+		"worker_id": req.WorkerID,
+		"devices":   req.Devices,
+		"punches":   recordPunches(records, req.Devices),
+		"records":   records,
+	}
+}
+
+// checkDevices reports the first requested device that is unknown or offline. Caller holds s.mu.
+func (s *Sim) checkDevices(devices []string) error {
+	for _, sn := range devices {
+		if len(s.scenario.Devices) > 0 && !contains(s.scenario.Devices, sn) {
+			return fmt.Errorf("Device %s is not on this bridge.", sn)
+		}
+		if s.isOffline(sn) {
+			return fmt.Errorf("Device %s is offline.", sn)
+		}
+	}
+	return nil
+}
+
+func (s *Sim) isOffline(sn string) bool {
+	return contains(s.scenario.OfflineDevices, sn)
+}
+
+func withDefaultMsg(r Reply) Reply {
+	if r.Msg == "" {
+		r.Msg = fmt.Sprintf("Simulated error %d", r.Code)
+	}
+	return r
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package bridgesim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/bridge"
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func command(t *testing.T, action string, payload interface{}) bridge.Message {
+	msg, err := bridge.NewRequest(action, payload)
+	require.NoError(t, err)
+	return msg
+}
+
+func userSync(employeeNo string, devices ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"devices": devices,
+		"user": map[string]interface{}{
+			"employee_no": employeeNo,
+			"name":        "Worker " + employeeNo,
+			"authentication": map[string]interface{}{
+				"card": map[string]string{"card_no": "123", "card_type": "normal"},
+			},
+		},
+	}
+}
+
+func decode(t *testing.T, msg *bridge.Message) (bridge.ResponseEnvelope, map[string]json.RawMessage) {
+	require.NotNil(t, msg)
+	env, err := bridge.ParseResponse(*msg)
+	require.NoError(t, err)
+	var content map[string]json.RawMessage
+	if len(env.Content) > 0 && string(env.Content) != "null" {
+		require.NoError(t, json.Unmarshal(env.Content, &content))
+	}
+	return env, content
+}
+
+func TestHandle_UserSync(t *testing.T) {
+	sc := DefaultScenario()
+	sc.Devices = []string{"SN-1", "SN-2"}
+	sc.OfflineDevices = []string{"SN-2"}
+	sc.RequireRegistration = true
+	sc.Workers = map[string]WorkerScenario{
+		"w-reject": {Register: &Reply{Code: 409, Msg: "Face photo rejected"}},
+	}
+
+	tests := []struct {
+		name       string
+		action     string
+		payload    interface{}
+		wantCode   int
+		wantRoster bool
+	}{
+		{"register succeeds", "REGISTER_USER", userSync("w1", "SN-1"), 200, true},
+		{"scripted error code", "REGISTER_USER", userSync("w-reject", "SN-1"), 409, false},
+		{"offline device", "REGISTER_USER", userSync("w2", "SN-2"), 500, false},
+		{"unknown device", "REGISTER_USER", userSync("w3", "SN-9"), 500, false},
+		{"update of unregistered worker", "UPDATE_USER", userSync("w4", "SN-1"), 404, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim, err := New("http://localhost", "u1", "tok", sc)
+			require.NoError(t, err)
+
+			msg := command(t, tt.action, tt.payload)
+			resp := sim.Handle(msg)
+			env, _ := decode(t, resp)
+
+			assert.Equal(t, tt.action+"_RESPONSE", resp.Action)
+			assert.Equal(t, msg.Meta.RequestID, resp.Meta.RequestID)
+			assert.Equal(t, tt.wantCode, env.Code)
+			assert.Equal(t, tt.wantRoster, len(sim.Roster()) == 1)
+		})
+	}
+}
+
+func TestHandle_UpdateKeepsRegistration(t *testing.T) {
+	sim, err := New("http://localhost", "u1", "tok", nil)
+	require.NoError(t, err)
+
+	sim.Handle(command(t, "REGISTER_USER", userSync("w1", "SN-1")))
+	registered := sim.Roster()["w1"]
+
+	env, _ := decode(t, sim.Handle(command(t, "UPDATE_USER", userSync("w1", "SN-1", "SN-2"))))
+	assert.Equal(t, 200, env.Code)
+
+	updated := sim.Roster()["w1"]
+	assert.Equal(t, []string{"SN-1", "SN-2"}, updated.Devices)
+	assert.Equal(t, registered.RegisteredAt, updated.RegisteredAt)
+	assert.Equal(t, "123", updated.CardNo)
+}
+
+func TestHandle_GetAttendance(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Singapore")
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, loc)
+	end := time.Date(2026, 3, 3, 12, 0, 0, 0, loc)
+	request := func(workerID string) map[string]interface{} {
+		return map[string]interface{}{
+			"worker_id":  workerID,
+			"devices":    []string{"SN-1"},
+			"start_time": start.Format(time.RFC3339),
+			"end_time":   end.Format(time.RFC3339),
+		}
+	}
+
+	sc := DefaultScenario()
+	sc.Shift.JitterMinutes = 0
+	sc.Workers = map[string]WorkerScenario{
+		"w-night":   {Shift: &ShiftPattern{Start: "20:00", End: "06:00"}},
+		"w-missing": {Attendance: &Reply{Code: 404}},
+		"w-script":  {Records: []Record{{TimeIn: "2026-03-02T09:00:00+08:00", TimeOut: "2026-03-02T18:00:00+08:00"}, {TimeIn: "2026-04-01T09:00:00+08:00"}}},
+	}
+	sim, err := New("http://localhost", "u1", "tok", sc)
+	require.NoError(t, err)
+
+	records := func(workerID string) (int, []Record) {
+		env, content := decode(t, sim.Handle(command(t, "GET_ATTENDANCE", request(workerID))))
+		var recs []Record
+		if raw, ok := content["records"]; ok {
+			require.NoError(t, json.Unmarshal(raw, &recs))
+		}
+		return env.Code, recs
+	}
+
+	code, day := records("w1")
+	assert.Equal(t, 200, code)
+	assert.Equal(t, []Record{
+		{TimeIn: "2026-03-02T08:00:00+08:00", TimeOut: "2026-03-02T17:30:00+08:00"},
+		{TimeIn: "2026-03-03T08:00:00+08:00"}, // still on shift when the window closes
+	}, day)
+
+	env, content := decode(t, sim.Handle(command(t, "GET_ATTENDANCE", request("w1"))))
+	require.Equal(t, 200, env.Code)
+	var punches []Punch
+	require.NoError(t, json.Unmarshal(content["punches"], &punches))
+	assert.Equal(t, []Punch{
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-02T08:00:00+08:00"},
+		{DeviceSN: "SN-1", Direction: "out", Time: "2026-03-02T17:30:00+08:00"},
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-03T08:00:00+08:00"},
+	}, punches)
+
+	_, night := records("w-night")
+	assert.Equal(t, []Record{
+		{TimeIn: "2026-03-02T20:00:00+08:00", TimeOut: "2026-03-03T06:00:00+08:00"},
+	}, night)
+
+	code, _ = records("w-missing")
+	assert.Equal(t, 404, code)
+
+	_, scripted := records("w-script")
+	assert.Equal(t, []Record{{TimeIn: "2026-03-02T09:00:00+08:00", TimeOut: "2026-03-02T18:00:00+08:00"}}, scripted)
+}
+
+func TestGenerateRecords_Deterministic(t *testing.T) {
+	shift := ShiftPattern{Start: "08:00", End: "17:00", JitterMinutes: 20, AbsentRate: 0.3, MissingTimeOutRate: 0.2}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 14)
+
+	first := generateRecords(7, "w1", shift, time.UTC, start, end)
+	again := generateRecords(7, "w1", shift, time.UTC, start, end)
+	assert.Equal(t, first, again)
+	assert.NotEmpty(t, first)
+	assert.Less(t, len(first), 15, "absent days should be skipped")
+
+	// A narrower window returns the same punches for the days it covers
+	narrow := generateRecords(7, "w1", shift, time.UTC, start.AddDate(0, 0, 7), end)
+	assert.Equal(t, first[len(first)-len(narrow):], narrow)
+}
+
+// simTestRepo satisfies the bridge manager's persistence calls; everything else is unused.
+type simTestRepo struct {
+	ports.BridgeRepository
+}
+
+func (r *simTestRepo) MarkBridgeConnected(ctx context.Context, userID, remoteAddr string, connectedAt time.Time) error {
+	return nil
+}
+
+func (r *simTestRepo) TouchBridgeConnection(ctx context.Context, userID string, lastSeen time.Time) error {
+	return nil
+}
+
+func (r *simTestRepo) MarkBridgeDisconnected(ctx context.Context, userID string, at time.Time) error {
+	return nil
+}
+
+func (r *simTestRepo) ExpireBridgeCommands(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *simTestRepo) ListBridgeCommands(ctx context.Context, userID, status string) ([]domain.BridgeCommand, error) {
+	return nil, nil
+}
+
+func (r *simTestRepo) LogBridgeInteraction(ctx context.Context, userID, action, requestID string, requestPayload, responsePayload []byte, statusCode int) error {
+	return nil
+}
+
+func TestSim_AgainstRequestManager(t *testing.T) {
+	rm := bridge.NewRequestManager(&simTestRepo{})
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/bridge/connect" || r.URL.Query().Get("token") != "tok" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		tr := bridge.NewServerTransport(conn, "tok")
+		userID := r.URL.Query().Get("user_id")
+		rm.AddTransport(userID, tr)
+		go rm.HandleIncomingMessages(context.Background(), userID, tr)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bad, err := New(srv.URL, "u1", "wrong", nil)
+	require.NoError(t, err)
+	assert.Error(t, bad.Connect(ctx))
+
+	sim, err := New(srv.URL, "u1", "tok", nil)
+	require.NoError(t, err)
+	require.NoError(t, sim.Connect(ctx))
+	go sim.Run(ctx)
+
+	require.Eventually(t, func() bool {
+		_, ok := rm.GetTransport("u1")
+		return ok
+	}, time.Second, 10*time.Millisecond)
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 2*time.Second)
+	defer waitCancel()
+	resp, err := rm.SendAndWait(waitCtx, "u1", "w1", command(t, "REGISTER_USER", userSync("w1", "SN-1")))
+	require.NoError(t, err)
+
+	env, err := bridge.ParseResponse(resp)
+	require.NoError(t, err)
+	assert.Equal(t, 200, env.Code)
+	assert.Contains(t, sim.Roster(), "w1")
+	require.Len(t, sim.Received(), 1)
+	assert.Equal(t, "REGISTER_USER", sim.Received()[0].Action)
+}
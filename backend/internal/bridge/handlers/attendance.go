@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"cpd-nexus/internal/bridge"
+	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/logger"
 )
@@ -14,10 +15,18 @@ import (
 type AttendanceResult struct {
 	WorkerID string            `json:"worker_id"`
 	Devices  []string          `json:"devices"`
+	Punches  []PunchEvent      `json:"punches"`
 	Records  []AttendanceEvent `json:"records"`
 }
 
-// AttendanceEvent matches the individual record structure from the bridge
+// PunchEvent is a single raw scan at a device
+type PunchEvent struct {
+	DeviceSN  string `json:"device_sn"`
+	Direction string `json:"direction"`
+	Time      string `json:"time"`
+}
+
+// AttendanceEvent matches the aggregated record structure older bridges return instead of punches
 type AttendanceEvent struct {
 	TimeIn  string `json:"time_in"`
 	TimeOut string `json:"time_out"`
@@ -62,27 +71,61 @@ func (h *AttendanceHandler) Handle(ctx context.Context, msg bridge.Message) (*br
 		return nil, nil
 	}
 
-	// Elevate context to Vendor status for background database operations
+	// Background database operations act for the bridge's organisation and may only see its data
 	ctx = context.WithValue(ctx, ports.UserIDKey, ownerID)
-	ctx = context.WithValue(ctx, ports.IsVendorKey, true)
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantScope(ownerID))
 
 	result := envelope.Content
-	for _, rec := range result.Records {
-		rawPayload, _ := json.Marshal(rec)
-
-		err := h.service.ProcessBridgeAttendance(
-			ctx,
-			result.WorkerID,
-			rec.TimeIn,
-			rec.TimeOut,
-			rawPayload,
-		)
-
-		if err != nil {
-			logger.Infof("AttendanceHandler: Service error for worker %s (owner %s): %v", result.WorkerID, ownerID, err)
-		}
+	punches := bridgePunches(result)
+	if err := h.service.ProcessBridgeAttendance(ctx, result.WorkerID, punches); err != nil {
+		logger.Infof("AttendanceHandler: Service error for worker %s (owner %s): %v", result.WorkerID, ownerID, err)
 	}
-	logger.Infof("AttendanceHandler: Finished processing %d records for worker %s (owner: %s)", len(result.Records), result.WorkerID, ownerID)
+	logger.Infof("AttendanceHandler: Finished processing %d punches for worker %s (owner: %s)", len(punches), result.WorkerID, ownerID)
 
 	return nil, nil
 }
+
+// bridgePunches returns the punches in a response. Bridges that only report aggregated records
+// have each record split into an in and an out punch; the device is known only when a single
+// device was queried.
+func bridgePunches(result AttendanceResult) []domain.BridgePunch {
+	var punches []domain.BridgePunch
+	for _, p := range result.Punches {
+		raw, _ := json.Marshal(p)
+		punches = append(punches, domain.BridgePunch{
+			DeviceSN:   p.DeviceSN,
+			Direction:  p.Direction,
+			Time:       p.Time,
+			Source:     domain.PunchSourceDevice,
+			RawPayload: string(raw),
+		})
+	}
+	if len(result.Punches) > 0 {
+		return punches
+	}
+
+	device := domain.AttendanceDeviceBridgeAggregated
+	if len(result.Devices) == 1 {
+		device = result.Devices[0]
+	}
+	for _, rec := range result.Records {
+		raw, _ := json.Marshal(rec)
+		punches = append(punches, domain.BridgePunch{
+			DeviceSN:   device,
+			Direction:  domain.PunchIn,
+			Time:       rec.TimeIn,
+			Source:     domain.PunchSourceBridgeRecord,
+			RawPayload: string(raw),
+		})
+		if rec.TimeOut != "" {
+			punches = append(punches, domain.BridgePunch{
+				DeviceSN:   device,
+				Direction:  domain.PunchOut,
+				Time:       rec.TimeOut,
+				Source:     domain.PunchSourceBridgeRecord,
+				RawPayload: string(raw),
+			})
+		}
+	}
+	return punches
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cpd-nexus/internal/bridge"
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/logger"
+)
+
+// DeviceTelemetry is the per-device report inside a DEVICE_STATUS / DEVICE_HEARTBEAT payload
+type DeviceTelemetry struct {
+	SN      string `json:"sn"`
+	Status  string `json:"status,omitempty"`
+	Battery *int   `json:"battery,omitempty"`
+}
+
+// DeviceStatusPayload accepts either a single device report or a batch under "devices"
+type DeviceStatusPayload struct {
+	DeviceTelemetry
+	Devices []DeviceTelemetry `json:"devices,omitempty"`
+}
+
+// DeviceStatusHandler processes DEVICE_STATUS and DEVICE_HEARTBEAT pushed by the bridge
+type DeviceStatusHandler struct {
+	service ports.DeviceService
+}
+
+func NewDeviceStatusHandler(service ports.DeviceService) *DeviceStatusHandler {
+	return &DeviceStatusHandler{service: service}
+}
+
+func (h *DeviceStatusHandler) Handle(ctx context.Context, msg bridge.Message) (*bridge.Message, error) {
+	var payload DeviceStatusPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal device status payload: %w", err)
+	}
+
+	ownerID, _ := ctx.Value("bridge_userID").(string)
+	if ownerID == "" {
+		logger.Infof("DeviceStatusHandler: Cannot determine owner for %s, skipping", msg.Action)
+		return nil, nil
+	}
+
+	reports := payload.Devices
+	if payload.SN != "" {
+		reports = append(reports, payload.DeviceTelemetry)
+	}
+
+	for _, rep := range reports {
+		hb := domain.DeviceHeartbeat{
+			SN:      rep.SN,
+			Status:  domain.DeviceStatus(rep.Status),
+			Battery: rep.Battery,
+		}
+		if err := h.service.RecordHeartbeat(ctx, ownerID, hb); err != nil {
+			logger.Infof("DeviceStatusHandler: Failed to record %s for device %s (owner %s): %v", msg.Action, rep.SN, ownerID, err)
+		}
+	}
+
+	return nil, nil
+}
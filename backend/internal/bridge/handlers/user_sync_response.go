@@ -7,7 +7,6 @@ import (
 	"cpd-nexus/internal/bridge"
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/logger"
-	"strings"
 )
 
 // UserSyncResponsePayload maps the generic payload response from bridge
@@ -20,13 +19,11 @@ type UserSyncResponsePayload struct {
 // UserSyncResponseHandler processes REGISTER_USER_RESPONSE and UPDATE_USER_RESPONSE
 type UserSyncResponseHandler struct {
 	workerRepo ports.WorkerRepository
-	bridgeRepo ports.BridgeRepository
 }
 
-func NewUserSyncResponseHandler(workerRepo ports.WorkerRepository, bridgeRepo ports.BridgeRepository) *UserSyncResponseHandler {
+func NewUserSyncResponseHandler(workerRepo ports.WorkerRepository) *UserSyncResponseHandler {
 	return &UserSyncResponseHandler{
 		workerRepo: workerRepo,
-		bridgeRepo: bridgeRepo,
 	}
 }
 
@@ -36,15 +33,13 @@ func (h *UserSyncResponseHandler) Handle(ctx context.Context, msg bridge.Message
 		return nil, fmt.Errorf("failed to unmarshal user sync response: %w", err)
 	}
 
-	// RequestID is injected as "req-xxx|workerID" from BuildSyncRequests
-	parts := strings.Split(msg.Meta.RequestID, "|")
-	if len(parts) < 2 {
-		logger.Infof("[UserSyncResponse] Warning: Cannot extract worker ID from request_id: %s", msg.Meta.RequestID)
+	// The request registry remembers which worker each outbound request targeted
+	workerID := bridge.RequestWorkerID(ctx)
+	if workerID == "" {
+		logger.Infof("[UserSyncResponse] Warning: No pending request for request_id: %s", msg.Meta.RequestID)
 		return nil, nil // Cannot determine which worker to update
 	}
 
-	workerID := parts[len(parts)-1] // Target worker ID
-
 	if payload.Code == 200 {
 		logger.Infof("[UserSyncResponse] Bridge returned success (200) for worker %s. Marking as synced.", workerID)
 
@@ -58,11 +53,5 @@ func (h *UserSyncResponseHandler) Handle(ctx context.Context, msg bridge.Message
 		logger.Infof("[UserSyncResponse] Bridge rejected sync for worker %s (Code: %d, Msg: %s). Sync status unchanged.", workerID, payload.Code, payload.Msg)
 	}
 
-	// Update the interaction log with the actual status code
-	// bridge_userID was injected into ctx by RequestManager
-	if userID, ok := ctx.Value("bridge_userID").(string); ok {
-		_ = h.bridgeRepo.LogBridgeInteraction(ctx, userID, msg.Action, msg.Meta.RequestID, nil, msg.Payload, payload.Code)
-	}
-
 	return nil, nil
 }
@@ -0,0 +1,84 @@
+package bridge
+
+import (
+	"context"
+	"time"
+
+	"cpd-nexus/internal/pkg/logger"
+)
+
+// Default heartbeat timings. pongWait must exceed pingInterval so that a single
+// delayed pong does not evict a healthy bridge.
+const (
+	defaultPingInterval = 30 * time.Second
+	defaultPongWait     = 75 * time.Second
+)
+
+// SetHeartbeat overrides the ping interval and the silence after which a bridge is considered dead.
+func (rm *RequestManager) SetHeartbeat(pingInterval, pongWait time.Duration) {
+	if pingInterval <= 0 || pongWait <= pingInterval {
+		logger.Infof("RequestManager: Ignoring invalid heartbeat settings (ping %s, pong wait %s)", pingInterval, pongWait)
+		return
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.pingInterval = pingInterval
+	rm.pongWait = pongWait
+}
+
+func (rm *RequestManager) heartbeatSettings() (time.Duration, time.Duration) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.pingInterval, rm.pongWait
+}
+
+// runHeartbeat pings the bridge every pingInterval and persists last_seen until the
+// transport is closed. A failed ping closes the transport, which ends the listener.
+func (rm *RequestManager) runHeartbeat(ctx context.Context, userID string, t *Transport, pingInterval time.Duration) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	persisted := t.LastSeen()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.Done():
+			return
+		case <-ticker.C:
+			if err := t.Ping(); err != nil {
+				logger.Infof("RequestManager (%s): Ping failed, closing transport: %v", userID, err)
+				t.Close()
+				return
+			}
+			if seen := t.LastSeen(); seen.After(persisted) {
+				if err := rm.BridgeRepo.TouchBridgeConnection(ctx, userID, seen); err != nil {
+					logger.Errorf("RequestManager (%s): Failed to persist last_seen: %v", userID, err)
+				}
+				persisted = seen
+			}
+		}
+	}
+}
+
+// evictTransport drops a dead transport and records the disconnect. If the tenant has
+// already reconnected with a newer transport, only the stale one is closed.
+func (rm *RequestManager) evictTransport(ctx context.Context, userID string, t *Transport) {
+	rm.mu.Lock()
+	current, ok := rm.Transports[userID]
+	isCurrent := ok && current == t
+	if isCurrent {
+		delete(rm.Transports, userID)
+	}
+	rm.mu.Unlock()
+
+	t.Close()
+	if !isCurrent {
+		return
+	}
+
+	logger.Infof("RequestManager (%s): Bridge transport evicted", userID)
+	if err := rm.BridgeRepo.MarkBridgeDisconnected(ctx, userID, time.Now()); err != nil {
+		logger.Errorf("RequestManager (%s): Failed to persist disconnect: %v", userID, err)
+	}
+}
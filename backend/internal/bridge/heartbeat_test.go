@@ -0,0 +1,90 @@
+package bridge
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// heartbeatTestRepo records connection state transitions; other methods are no-ops.
+type heartbeatTestRepo struct {
+	ports.BridgeRepository
+	mu           sync.Mutex
+	connected    []string
+	disconnected []string
+}
+
+func (r *heartbeatTestRepo) MarkBridgeConnected(ctx context.Context, userID, remoteAddr string, connectedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connected = append(r.connected, userID)
+	return nil
+}
+
+func (r *heartbeatTestRepo) TouchBridgeConnection(ctx context.Context, userID string, lastSeen time.Time) error {
+	return nil
+}
+
+func (r *heartbeatTestRepo) MarkBridgeDisconnected(ctx context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.disconnected = append(r.disconnected, userID)
+	return nil
+}
+
+func (r *heartbeatTestRepo) ExpireBridgeCommands(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *heartbeatTestRepo) ListBridgeCommands(ctx context.Context, userID, status string) ([]domain.BridgeCommand, error) {
+	return nil, nil
+}
+
+func TestRequestManager_EvictsSilentBridge(t *testing.T) {
+	repo := &heartbeatTestRepo{}
+	rm := NewRequestManager(repo)
+	rm.SetHeartbeat(20*time.Millisecond, 60*time.Millisecond)
+
+	listenerDone := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		tr := NewServerTransport(conn, "token")
+		rm.AddTransport("u1", tr)
+		go func() {
+			rm.HandleIncomingMessages(context.Background(), "u1", tr)
+			close(listenerDone)
+		}()
+	}))
+	defer srv.Close()
+
+	// The client never reads, so it never answers pings.
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	select {
+	case <-listenerDone:
+	case <-time.After(2 * time.Second):
+		t.Fatal("listener did not exit after missed heartbeats")
+	}
+
+	_, exists := rm.GetTransport("u1")
+	assert.False(t, exists)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, []string{"u1"}, repo.connected)
+	assert.Equal(t, []string{"u1"}, repo.disconnected)
+}
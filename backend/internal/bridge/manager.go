@@ -5,6 +5,7 @@ import (
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/timeutil"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -16,15 +17,35 @@ type RequestManager struct {
 	Transports map[string]*Transport // Key: user_id
 	BridgeRepo ports.BridgeRepository
 	Handlers   map[string]Handler
-	mu         sync.RWMutex // protects Transports
+	mu         sync.RWMutex // protects Transports and heartbeat settings
 	handlersMu sync.RWMutex // protects Handlers
+
+	pending    *pendingRegistry         // outstanding requests keyed by request_id
+	timeouts   map[string]time.Duration // per-action response deadlines
+	timeoutsMu sync.RWMutex             // protects timeouts
+
+	pingInterval time.Duration // how often the backend pings each bridge
+	pongWait     time.Duration // silence after which a bridge is considered dead
+
+	flushing map[string]bool // tenants whose outbox is currently being flushed
 }
 
 func NewRequestManager(bridgeRepo ports.BridgeRepository) *RequestManager {
+	timeouts := make(map[string]time.Duration, len(defaultActionTimeouts))
+	for action, d := range defaultActionTimeouts {
+		timeouts[action] = d
+	}
 	return &RequestManager{
 		Transports: make(map[string]*Transport),
 		BridgeRepo: bridgeRepo,
 		Handlers:   make(map[string]Handler),
+		pending:    newPendingRegistry(),
+		timeouts:   timeouts,
+
+		pingInterval: defaultPingInterval,
+		pongWait:     defaultPongWait,
+
+		flushing: make(map[string]bool),
 	}
 }
 
@@ -35,26 +56,31 @@ func (rm *RequestManager) RegisterHandler(msgType string, h Handler) {
 	rm.Handlers[msgType] = h
 }
 
-// AddTransport adds a new transport for a user
+// AddTransport adds a new transport for a user and records the connection state
 func (rm *RequestManager) AddTransport(userID string, t *Transport) {
 	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
 	// Close existing if replacing
 	if existing, ok := rm.Transports[userID]; ok {
 		existing.Close()
 	}
 	rm.Transports[userID] = t
+	pongWait := rm.pongWait
+	rm.mu.Unlock()
+
+	t.EnableHeartbeat(pongWait)
+
+	if err := rm.BridgeRepo.MarkBridgeConnected(ports.WithSystemCaller(context.Background()), userID, t.RemoteAddr(), t.ConnectedAt()); err != nil {
+		logger.Errorf("RequestManager (%s): Failed to persist connection state: %v", userID, err)
+	}
+
+	// Deliver anything queued while the bridge was offline
+	go rm.FlushOutbox(ports.WithSystemCaller(context.Background()), userID)
 }
 
 // RemoveTransport removes a transport for a user
 func (rm *RequestManager) RemoveTransport(userID string) {
-	rm.mu.Lock()
-	defer rm.mu.Unlock()
-
-	if existing, ok := rm.Transports[userID]; ok {
-		existing.Close()
-		delete(rm.Transports, userID)
+	if existing, ok := rm.GetTransport(userID); ok {
+		rm.evictTransport(ports.WithSystemCaller(context.Background()), userID, existing)
 	}
 }
 
@@ -99,11 +125,9 @@ func (rm *RequestManager) RequestAttendance(ctx context.Context) error {
 		tasksByOwner[task.UserID] = append(tasksByOwner[task.UserID], task)
 	}
 
-	now := time.Now()
-	yesterday := now.Add(-24 * time.Hour)
-	startTime := time.Date(yesterday.Year(), yesterday.Month(), yesterday.Day(), 0, 0, 0, 0, now.Location())
-	timeRangeFrom := startTime.Format(time.RFC3339)
-	timeRangeTo := now.Format(time.RFC3339)
+	from, to := attendanceFetchWindow(timeutil.BusinessNow())
+	timeRangeFrom := from.Format(time.RFC3339)
+	timeRangeTo := to.Format(time.RFC3339)
 
 	var wg sync.WaitGroup
 	for ownerID, ownerTasks := range tasksByOwner {
@@ -111,10 +135,8 @@ func (rm *RequestManager) RequestAttendance(ctx context.Context) error {
 		go func(uid string, workerTasks []ports.BridgeWorkerTask) {
 			defer wg.Done()
 
-			transport, exists := rm.GetTransport(uid)
-			if !exists || !transport.IsConnected() {
-				logger.Infof("RequestManager (%s): Skipping fetch, bridge not connected", uid)
-				return
+			if transport, exists := rm.GetTransport(uid); !exists || !transport.IsConnected() {
+				logger.Infof("RequestManager (%s): Bridge not connected, attendance requests will be queued", uid)
 			}
 
 			for _, task := range workerTasks {
@@ -142,13 +164,7 @@ func (rm *RequestManager) RequestAttendance(ctx context.Context) error {
 					continue
 				}
 
-				if err := transport.Write(req); err != nil {
-					logger.Infof("RequestManager (%s): Failed to send request for worker %s: %v", uid, task.WorkerID, err)
-				} else {
-					// Log the interaction
-					_ = rm.BridgeRepo.LogBridgeInteraction(ctx, uid, req.Action, req.Meta.RequestID, req.Payload, nil, 0)
-					logger.Infof("RequestManager (%s): Queued attendance request for worker %s", uid, task.WorkerID)
-				}
+				rm.sendOrEnqueue(ctx, uid, task.WorkerID, req)
 			}
 		}(ownerID, ownerTasks)
 	}
@@ -200,10 +216,8 @@ func (rm *RequestManager) RequestUserSync(ctx context.Context, builder interface
 		go func(uid string, subTasks []syncTask) {
 			defer wg.Done()
 
-			transport, exists := rm.GetTransport(uid)
-			if !exists || !transport.IsConnected() {
-				logger.Infof("RequestManager (%s): Skipping user sync, bridge not connected", uid)
-				return
+			if transport, exists := rm.GetTransport(uid); !exists || !transport.IsConnected() {
+				logger.Infof("RequestManager (%s): Bridge not connected, user sync will be queued", uid)
 			}
 
 			for _, t := range subTasks {
@@ -213,13 +227,7 @@ func (rm *RequestManager) RequestUserSync(ctx context.Context, builder interface
 				default:
 				}
 
-				if err := transport.Write(t.msg); err != nil {
-					logger.Infof("RequestManager (%s): Failed to send sync for worker %s: %v", uid, t.workerID, err)
-				} else {
-					// Log the interaction
-					_ = rm.BridgeRepo.LogBridgeInteraction(ctx, uid, t.msg.Action, t.msg.Meta.RequestID, t.msg.Payload, nil, 0)
-					logger.Infof("RequestManager (%s): Queued sync for worker %s", uid, t.workerID)
-				}
+				rm.sendOrEnqueue(ctx, uid, t.workerID, t.msg)
 			}
 		}(ownerID, tasks)
 	}
@@ -228,30 +236,37 @@ func (rm *RequestManager) RequestUserSync(ctx context.Context, builder interface
 	return nil
 }
 
-// HandleIncomingMessages needs to be updated. Now we'll run a listener per transport.
+// HandleIncomingMessages runs the listener for a single transport. It keeps the bridge alive
+// with pings and returns once the connection fails or misses its heartbeat, evicting the transport.
 func (rm *RequestManager) HandleIncomingMessages(ctx context.Context, userID string, transport *Transport) {
+	defer rm.evictTransport(ctx, userID, transport)
+
+	pingInterval, _ := rm.heartbeatSettings()
+	go rm.runHeartbeat(ctx, userID, transport, pingInterval)
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		default:
 			if !transport.IsConnected() {
-				time.Sleep(2 * time.Second)
-				continue
+				return
 			}
 
 			msg, err := transport.Read()
 			if err != nil {
 				logger.Infof("RequestManager (%s): Read error: %v", userID, err)
-				transport.Close()
-				continue
+				return
 			}
 
 			fullMsg, _ := json.MarshalIndent(msg, "", "  ")
 			logger.Infof("\n--- [BRIDGE INBOUND (%s)] ---\n%s\n------------------------", userID, string(fullMsg))
 
-			// Log inbound message
-			_ = rm.BridgeRepo.LogBridgeInteraction(ctx, userID, msg.Action, msg.Meta.RequestID, nil, msg.Payload, 0)
+			// Log inbound message against the originating request, recording the bridge's result code
+			_ = rm.BridgeRepo.LogBridgeInteraction(ctx, userID, msg.Action, msg.Meta.RequestID, nil, msg.Payload, responseCode(msg))
+
+			// Wake any SendAndWait caller and clear the request from the timeout registry
+			req := rm.pending.resolve(msg)
 
 			if handler, ok := func() (Handler, bool) {
 				rm.handlersMu.RLock()
@@ -261,6 +276,9 @@ func (rm *RequestManager) HandleIncomingMessages(ctx context.Context, userID str
 			}(); ok {
 				// Setting up an extended context to pass the owner ID to handlers if needed
 				reqCtx := context.WithValue(ctx, "bridge_userID", userID)
+				if req != nil {
+					reqCtx = context.WithValue(reqCtx, requestWorkerKey{}, req.workerID)
+				}
 				resp, err := handler.Handle(reqCtx, msg)
 				if err != nil {
 					logger.Infof("RequestManager (%s): Handler for %s failed: %v", userID, msg.Action, err)
@@ -281,3 +299,10 @@ func (rm *RequestManager) HandleIncomingMessages(ctx context.Context, userID str
 		}
 	}
 }
+
+// attendanceFetchWindow returns the span fetched from the bridges: from the start of yesterday
+// up to now, with the day boundary taken in now's location (the business timezone).
+func attendanceFetchWindow(now time.Time) (from, to time.Time) {
+	from = time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+	return from, now
+}
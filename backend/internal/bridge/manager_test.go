@@ -0,0 +1,23 @@
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttendanceFetchWindow_StartsAtBusinessMidnight(t *testing.T) {
+	sgt := time.FixedZone("SGT", 8*60*60)
+
+	// 17:00 UTC on 1 March is 01:00 on 2 March in Singapore, so "yesterday" is 1 March there
+	now := time.Date(2026, 3, 1, 17, 0, 0, 0, time.UTC).In(sgt)
+	from, to := attendanceFetchWindow(now)
+
+	assert.Equal(t, "2026-03-01T00:00:00+08:00", from.Format(time.RFC3339))
+	assert.Equal(t, "2026-03-02T01:00:00+08:00", to.Format(time.RFC3339))
+
+	// Across a month end
+	from, _ = attendanceFetchWindow(time.Date(2026, 3, 1, 9, 0, 0, 0, sgt))
+	assert.Equal(t, "2026-02-28T00:00:00+08:00", from.Format(time.RFC3339))
+}
@@ -0,0 +1,147 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/timeutil"
+)
+
+const (
+	// outboxTTL is how long a queued command stays deliverable before it is expired
+	outboxTTL = 72 * time.Hour
+	// outboxMaxAttempts is the number of failed deliveries after which a command is marked failed
+	outboxMaxAttempts = 5
+)
+
+// rosterActions replace each other in the outbox rather than being queued again for the same
+// worker, since only the latest roster state matters to the devices.
+var rosterActions = []string{"REGISTER_USER", "UPDATE_USER"}
+
+// Enqueue stores a command in the tenant's outbox for delivery when the bridge next connects.
+func (rm *RequestManager) Enqueue(ctx context.Context, userID, workerID string, msg Message) error {
+	cmd := &domain.BridgeCommand{
+		UserID:    userID,
+		Action:    msg.Action,
+		RequestID: msg.Meta.RequestID,
+		WorkerID:  workerID,
+		Payload:   msg.Payload,
+		ExpiresAt: time.Now().Add(outboxTTL),
+	}
+	var replaces []string
+	for _, action := range rosterActions {
+		if action == msg.Action {
+			replaces = rosterActions
+		}
+	}
+	return rm.BridgeRepo.EnqueueBridgeCommand(ctx, cmd, replaces)
+}
+
+// sendOrEnqueue delivers a command immediately, falling back to the outbox if the bridge is unreachable.
+func (rm *RequestManager) sendOrEnqueue(ctx context.Context, userID, workerID string, msg Message) {
+	err := rm.Send(ctx, userID, workerID, msg)
+	if err == nil {
+		logger.Infof("RequestManager (%s): Sent %s for worker %s", userID, msg.Action, workerID)
+		return
+	}
+	logger.Infof("RequestManager (%s): Bridge unavailable for %s (worker %s): %v", userID, msg.Action, workerID, err)
+
+	if err := rm.Enqueue(ctx, userID, workerID, msg); err != nil {
+		logger.Errorf("RequestManager (%s): Failed to queue %s for worker %s: %v", userID, msg.Action, workerID, err)
+		return
+	}
+	logger.Infof("RequestManager (%s): Queued %s for worker %s in outbox", userID, msg.Action, workerID)
+}
+
+// FlushOutbox delivers a tenant's pending commands in queue order. It stops at the first
+// delivery failure so that later commands never overtake earlier ones.
+func (rm *RequestManager) FlushOutbox(ctx context.Context, userID string) {
+	if !rm.beginFlush(userID) {
+		return
+	}
+	defer rm.endFlush(userID)
+
+	if n, err := rm.BridgeRepo.ExpireBridgeCommands(ctx, time.Now()); err != nil {
+		logger.Errorf("RequestManager (%s): Failed to expire outbox: %v", userID, err)
+	} else if n > 0 {
+		logger.Infof("RequestManager: Expired %d outbox commands", n)
+	}
+
+	cmds, err := rm.BridgeRepo.ListBridgeCommands(ctx, userID, domain.BridgeCommandPending)
+	if err != nil {
+		logger.Errorf("RequestManager (%s): Failed to load outbox: %v", userID, err)
+		return
+	}
+	if len(cmds) == 0 {
+		return
+	}
+
+	logger.Infof("RequestManager (%s): Flushing %d queued commands", userID, len(cmds))
+	for _, cmd := range cmds {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		payload := cmd.Payload
+		if cmd.Action == "GET_ATTENDANCE" {
+			payload = refreshFetchWindow(payload, timeutil.BusinessNow())
+		}
+		msg := Message{
+			Meta:    Meta{RequestID: cmd.RequestID, SentAt: time.Now().Format(time.RFC3339)},
+			Action:  cmd.Action,
+			Payload: payload,
+		}
+		if err := rm.Send(ctx, userID, cmd.WorkerID, msg); err != nil {
+			logger.Infof("RequestManager (%s): Outbox flush stopped at command %d: %v", userID, cmd.ID, err)
+			_ = rm.BridgeRepo.MarkBridgeCommandAttempt(ctx, cmd.ID, err.Error(), outboxMaxAttempts)
+			return
+		}
+		if err := rm.BridgeRepo.MarkBridgeCommandSent(ctx, cmd.ID); err != nil {
+			logger.Errorf("RequestManager (%s): Failed to mark command %d sent: %v", userID, cmd.ID, err)
+		}
+	}
+}
+
+// refreshFetchWindow brings the window of a queued GET_ATTENDANCE up to now, so that punches recorded
+// while the command waited are fetched too. The start is kept when it is earlier than the usual window.
+func refreshFetchWindow(payload json.RawMessage, now time.Time) json.RawMessage {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return payload
+	}
+	from, to := attendanceFetchWindow(now)
+	if start, ok := fields["start_time"].(string); ok {
+		if queued, err := time.Parse(time.RFC3339, start); err == nil && queued.Before(from) {
+			from = queued.In(now.Location())
+		}
+	}
+	fields["start_time"] = from.Format(time.RFC3339)
+	fields["end_time"] = to.Format(time.RFC3339)
+
+	refreshed, err := json.Marshal(fields)
+	if err != nil {
+		return payload
+	}
+	return refreshed
+}
+
+func (rm *RequestManager) beginFlush(userID string) bool {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if rm.flushing[userID] {
+		return false
+	}
+	rm.flushing[userID] = true
+	return true
+}
+
+func (rm *RequestManager) endFlush(userID string) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	delete(rm.flushing, userID)
+}
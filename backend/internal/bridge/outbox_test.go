@@ -0,0 +1,97 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outboxTestRepo serves a fixed queue and records delivery outcomes.
+type outboxTestRepo struct {
+	ports.BridgeRepository
+	queue    []domain.BridgeCommand
+	attempts []int64
+	sent     []int64
+	enqueued []*domain.BridgeCommand
+	replaces [][]string
+}
+
+func (r *outboxTestRepo) ExpireBridgeCommands(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *outboxTestRepo) ListBridgeCommands(ctx context.Context, userID, status string) ([]domain.BridgeCommand, error) {
+	return r.queue, nil
+}
+
+func (r *outboxTestRepo) MarkBridgeCommandSent(ctx context.Context, id int64) error {
+	r.sent = append(r.sent, id)
+	return nil
+}
+
+func (r *outboxTestRepo) MarkBridgeCommandAttempt(ctx context.Context, id int64, errMsg string, maxAttempts int) error {
+	r.attempts = append(r.attempts, id)
+	return nil
+}
+
+func (r *outboxTestRepo) EnqueueBridgeCommand(ctx context.Context, cmd *domain.BridgeCommand, replaces []string) error {
+	r.enqueued = append(r.enqueued, cmd)
+	r.replaces = append(r.replaces, replaces)
+	return nil
+}
+
+func TestFlushOutbox_StopsAtFirstFailure(t *testing.T) {
+	repo := &outboxTestRepo{queue: []domain.BridgeCommand{
+		{ID: 1, Action: "UPDATE_USER", RequestID: "req-1"},
+		{ID: 2, Action: "UPDATE_USER", RequestID: "req-2"},
+	}}
+	rm := NewRequestManager(repo)
+
+	// No transport registered, so the first delivery fails and the second must not be attempted.
+	rm.FlushOutbox(context.Background(), "u1")
+
+	assert.Equal(t, []int64{1}, repo.attempts)
+	assert.Empty(t, repo.sent)
+}
+
+func TestSendOrEnqueue_QueuesWhenOffline(t *testing.T) {
+	repo := &outboxTestRepo{}
+	rm := NewRequestManager(repo)
+
+	update, _ := NewRequest("UPDATE_USER", map[string]string{"employee_no": "w1"})
+	fetch, _ := NewRequest("GET_ATTENDANCE", map[string]string{"worker_id": "w1"})
+	rm.sendOrEnqueue(context.Background(), "u1", "w1", update)
+	rm.sendOrEnqueue(context.Background(), "u1", "w1", fetch)
+
+	if assert.Len(t, repo.enqueued, 2) {
+		assert.Equal(t, "UPDATE_USER", repo.enqueued[0].Action)
+		assert.Equal(t, "w1", repo.enqueued[0].WorkerID)
+		// A roster command replaces a queued REGISTER_USER or UPDATE_USER for the worker
+		assert.Equal(t, [][]string{{"REGISTER_USER", "UPDATE_USER"}, nil}, repo.replaces)
+	}
+}
+
+func TestRefreshFetchWindow(t *testing.T) {
+	loc := time.FixedZone("SGT", 8*3600)
+	now := time.Date(2026, 3, 5, 10, 0, 0, 0, loc)
+
+	// Queued three days earlier: the original start stays, the end moves up to now
+	queued := []byte(`{"worker_id":"w1","devices":["SN-1"],"start_time":"2026-03-01T00:00:00+08:00","end_time":"2026-03-02T09:00:00+08:00"}`)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(refreshFetchWindow(queued, now), &got))
+	assert.Equal(t, "2026-03-01T00:00:00+08:00", got["start_time"])
+	assert.Equal(t, "2026-03-05T10:00:00+08:00", got["end_time"])
+	assert.Equal(t, "w1", got["worker_id"])
+
+	// A start later than the usual window is widened back to the start of yesterday
+	recent := []byte(`{"worker_id":"w1","start_time":"2026-03-05T09:00:00+08:00","end_time":"2026-03-05T09:30:00+08:00"}`)
+	require.NoError(t, json.Unmarshal(refreshFetchWindow(recent, now), &got))
+	assert.Equal(t, "2026-03-04T00:00:00+08:00", got["start_time"])
+}
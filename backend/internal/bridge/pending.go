@@ -0,0 +1,244 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"cpd-nexus/internal/pkg/logger"
+)
+
+// StatusTimeout is written to bridge_logs.status_code when a request expires without a response.
+const StatusTimeout = 408
+
+// defaultRequestTimeout applies to actions without an explicit entry in defaultActionTimeouts.
+const defaultRequestTimeout = 30 * time.Second
+
+// defaultActionTimeouts holds the per-action response deadlines.
+// GET_ATTENDANCE is slower because the bridge has to query every device at the site.
+var defaultActionTimeouts = map[string]time.Duration{
+	"GET_ATTENDANCE": 2 * time.Minute,
+	"REGISTER_USER":  30 * time.Second,
+	"UPDATE_USER":    30 * time.Second,
+}
+
+var (
+	// ErrRequestTimeout is returned by SendAndWait when the bridge does not answer before the deadline.
+	ErrRequestTimeout = errors.New("bridge request timed out")
+	// ErrBridgeNotConnected is returned when no live transport is registered for the tenant.
+	ErrBridgeNotConnected = errors.New("bridge not connected")
+)
+
+// pendingRequest tracks an outbound request that is still waiting for its response.
+type pendingRequest struct {
+	userID   string
+	workerID string // worker the command targets, empty for site-wide requests
+	action   string
+	deadline time.Time
+	done     chan Message // nil for fire-and-forget requests; buffered(1) otherwise
+}
+
+type requestWorkerKey struct{}
+
+// RequestWorkerID returns the worker targeted by the request a response answers,
+// or "" if the response did not match a pending request.
+func RequestWorkerID(ctx context.Context) string {
+	id, _ := ctx.Value(requestWorkerKey{}).(string)
+	return id
+}
+
+// pendingRegistry is the set of outstanding requests keyed by meta.request_id.
+// Every transition (resolve/expire/cancel) removes the entry under the lock first,
+// so exactly one of them ever touches the done channel.
+type pendingRegistry struct {
+	mu       sync.Mutex
+	requests map[string]*pendingRequest
+}
+
+func newPendingRegistry() *pendingRegistry {
+	return &pendingRegistry{requests: make(map[string]*pendingRequest)}
+}
+
+func (p *pendingRegistry) add(requestID string, req *pendingRequest) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[requestID] = req
+}
+
+// resolve delivers a response to the matching pending request. Returns nil if none was waiting.
+func (p *pendingRegistry) resolve(msg Message) *pendingRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	req, ok := p.requests[msg.Meta.RequestID]
+	if !ok {
+		return nil
+	}
+	delete(p.requests, msg.Meta.RequestID)
+	if req.done != nil {
+		req.done <- msg
+	}
+	return req
+}
+
+// expire removes a pending request and closes its waiter channel. Returns the request if it was still pending.
+func (p *pendingRegistry) expire(requestID string) *pendingRequest {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	req, ok := p.requests[requestID]
+	if !ok {
+		return nil
+	}
+	delete(p.requests, requestID)
+	if req.done != nil {
+		close(req.done)
+	}
+	return req
+}
+
+// expired returns the IDs of all requests whose deadline is before now.
+func (p *pendingRegistry) expired(now time.Time) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ids []string
+	for id, req := range p.requests {
+		if now.After(req.deadline) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// count returns the number of outstanding requests, optionally filtered by tenant.
+func (p *pendingRegistry) count(userID string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if userID == "" {
+		return len(p.requests)
+	}
+	n := 0
+	for _, req := range p.requests {
+		if req.userID == userID {
+			n++
+		}
+	}
+	return n
+}
+
+// SetActionTimeout overrides the response deadline for a given outbound action.
+func (rm *RequestManager) SetActionTimeout(action string, d time.Duration) {
+	rm.timeoutsMu.Lock()
+	defer rm.timeoutsMu.Unlock()
+	rm.timeouts[action] = d
+}
+
+func (rm *RequestManager) timeoutFor(action string) time.Duration {
+	rm.timeoutsMu.RLock()
+	defer rm.timeoutsMu.RUnlock()
+	if d, ok := rm.timeouts[action]; ok && d > 0 {
+		return d
+	}
+	return defaultRequestTimeout
+}
+
+// PendingCount returns the number of requests still awaiting a response (all tenants if userID is empty).
+func (rm *RequestManager) PendingCount(userID string) int {
+	return rm.pending.count(userID)
+}
+
+// Send writes a request to the tenant's bridge and registers it as pending so that
+// a missing response is eventually recorded as a timeout in bridge_logs.
+// workerID is handed to the response handler through RequestWorkerID.
+func (rm *RequestManager) Send(ctx context.Context, userID, workerID string, msg Message) error {
+	return rm.send(ctx, userID, workerID, msg, nil)
+}
+
+// SendAndWait writes a request to the tenant's bridge and blocks until the correlated
+// response arrives, the action deadline passes (ErrRequestTimeout) or ctx is cancelled.
+func (rm *RequestManager) SendAndWait(ctx context.Context, userID, workerID string, msg Message) (Message, error) {
+	done := make(chan Message, 1)
+	if err := rm.send(ctx, userID, workerID, msg, done); err != nil {
+		return Message{}, err
+	}
+
+	timer := time.NewTimer(rm.timeoutFor(msg.Action))
+	defer timer.Stop()
+
+	select {
+	case resp, ok := <-done:
+		if !ok {
+			return Message{}, ErrRequestTimeout
+		}
+		return resp, nil
+	case <-timer.C:
+		if req := rm.pending.expire(msg.Meta.RequestID); req != nil {
+			rm.markTimedOut(ctx, msg.Meta.RequestID, req)
+			return Message{}, ErrRequestTimeout
+		}
+		// Resolved or swept concurrently — the channel already holds the outcome.
+		if resp, ok := <-done; ok {
+			return resp, nil
+		}
+		return Message{}, ErrRequestTimeout
+	case <-ctx.Done():
+		rm.pending.expire(msg.Meta.RequestID)
+		return Message{}, ctx.Err()
+	}
+}
+
+func (rm *RequestManager) send(ctx context.Context, userID, workerID string, msg Message, done chan Message) error {
+	transport, exists := rm.GetTransport(userID)
+	if !exists || !transport.IsConnected() {
+		return ErrBridgeNotConnected
+	}
+
+	// Register before writing so a fast response cannot race past the registry.
+	rm.pending.add(msg.Meta.RequestID, &pendingRequest{
+		userID:   userID,
+		workerID: workerID,
+		action:   msg.Action,
+		deadline: time.Now().Add(rm.timeoutFor(msg.Action)),
+		done:     done,
+	})
+
+	if err := transport.Write(msg); err != nil {
+		rm.pending.expire(msg.Meta.RequestID)
+		return err
+	}
+
+	_ = rm.BridgeRepo.LogBridgeInteraction(ctx, userID, msg.Action, msg.Meta.RequestID, msg.Payload, nil, 0)
+	return nil
+}
+
+// ExpirePendingRequests marks every request past its deadline as timed out.
+func (rm *RequestManager) ExpirePendingRequests(ctx context.Context) int {
+	expiredCount := 0
+	for _, id := range rm.pending.expired(time.Now()) {
+		if req := rm.pending.expire(id); req != nil {
+			rm.markTimedOut(ctx, id, req)
+			expiredCount++
+		}
+	}
+	return expiredCount
+}
+
+// StartTimeoutSweeper periodically expires unanswered requests until ctx is cancelled.
+func (rm *RequestManager) StartTimeoutSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := rm.ExpirePendingRequests(ctx); n > 0 {
+				logger.Infof("RequestManager: Marked %d bridge requests as timed out", n)
+			}
+		}
+	}
+}
+
+func (rm *RequestManager) markTimedOut(ctx context.Context, requestID string, req *pendingRequest) {
+	logger.Infof("RequestManager (%s): %s request %s timed out without a response", req.userID, req.action, requestID)
+	_ = rm.BridgeRepo.LogBridgeInteraction(ctx, req.userID, req.action, requestID, nil, nil, StatusTimeout)
+}
@@ -0,0 +1,112 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingTestRepo keeps one bridge_logs row per request ID, following the rule the MySQL upsert
+// implements: a recorded timeout is kept when a late response arrives.
+type pendingTestRepo struct {
+	heartbeatTestRepo
+	logMu    sync.Mutex
+	codes    map[string]int
+	payloads map[string][]byte
+}
+
+func (r *pendingTestRepo) LogBridgeInteraction(ctx context.Context, userID, action, requestID string, requestPayload, responsePayload []byte, statusCode int) error {
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+	if len(responsePayload) > 0 {
+		r.payloads[requestID] = responsePayload
+	}
+	if statusCode != 0 && r.codes[requestID] != StatusTimeout {
+		r.codes[requestID] = statusCode
+	}
+	return nil
+}
+
+func (r *pendingTestRepo) logged(requestID string) (int, []byte) {
+	r.logMu.Lock()
+	defer r.logMu.Unlock()
+	return r.codes[requestID], r.payloads[requestID]
+}
+
+func TestPendingRegistry_ResolveDeliversResponse(t *testing.T) {
+	p := newPendingRegistry()
+	done := make(chan Message, 1)
+	p.add("req-1", &pendingRequest{userID: "u1", workerID: "w1", action: "UPDATE_USER", deadline: time.Now().Add(time.Minute), done: done})
+
+	resp := Message{Action: "UPDATE_USER_RESPONSE", Meta: Meta{RequestID: "req-1"}}
+	req := p.resolve(resp)
+	if assert.NotNil(t, req) {
+		assert.Equal(t, "w1", req.workerID)
+	}
+	assert.Equal(t, resp, <-done)
+	assert.Equal(t, 0, p.count(""))
+
+	// A second response for the same request is ignored
+	assert.Nil(t, p.resolve(resp))
+}
+
+func TestPendingRegistry_ExpireOnlyPastDeadline(t *testing.T) {
+	p := newPendingRegistry()
+	now := time.Now()
+	p.add("old", &pendingRequest{userID: "u1", action: "GET_ATTENDANCE", deadline: now.Add(-time.Second)})
+	p.add("new", &pendingRequest{userID: "u2", action: "GET_ATTENDANCE", deadline: now.Add(time.Minute)})
+
+	assert.Equal(t, []string{"old"}, p.expired(now))
+
+	req := p.expire("old")
+	if assert.NotNil(t, req) {
+		assert.Equal(t, "u1", req.userID)
+	}
+	assert.Nil(t, p.expire("old"))
+	assert.Equal(t, 0, p.count("u1"))
+	assert.Equal(t, 1, p.count("u2"))
+}
+
+func TestRequestManager_LateResponseKeepsTimeout(t *testing.T) {
+	repo := &pendingTestRepo{codes: map[string]int{}, payloads: map[string][]byte{}}
+	rm := NewRequestManager(repo)
+	rm.pending.add("req-1", &pendingRequest{userID: "u1", workerID: "w1", action: "GET_ATTENDANCE", deadline: time.Now().Add(-time.Second)})
+
+	assert.Equal(t, 1, rm.ExpirePendingRequests(context.Background()))
+	code, _ := repo.logged("req-1")
+	assert.Equal(t, StatusTimeout, code)
+
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		tr := NewServerTransport(conn, "token")
+		rm.AddTransport("u1", tr)
+		go rm.HandleIncomingMessages(context.Background(), "u1", tr)
+	}))
+	defer srv.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer client.Close()
+
+	late := Message{Action: "GET_ATTENDANCE_RESPONSE", Meta: Meta{RequestID: "req-1"}, Payload: json.RawMessage(`{"code":200,"msg":"ok","content":[]}`)}
+	require.NoError(t, client.WriteJSON(late))
+
+	// The late payload is stored, but the request stays recorded as timed out
+	assert.Eventually(t, func() bool {
+		_, payload := repo.logged("req-1")
+		return len(payload) > 0
+	}, 2*time.Second, 10*time.Millisecond)
+	code, _ = repo.logged("req-1")
+	assert.Equal(t, StatusTimeout, code)
+}
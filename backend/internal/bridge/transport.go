@@ -16,22 +16,38 @@ type Transport struct {
 	token string
 	conn  *websocket.Conn
 	mu    sync.Mutex
+
+	remoteAddr  string
+	connectedAt time.Time
+	lastSeen    time.Time
+	pongWait    time.Duration // read deadline extended on every frame; 0 disables it
+	closed      chan struct{}
+	closeOnce   sync.Once
 }
 
 func NewTransport(bridgeURL, token string) *Transport {
 	return &Transport{
-		url:   bridgeURL,
-		token: token,
+		url:    bridgeURL,
+		token:  token,
+		closed: make(chan struct{}),
 	}
 }
 
 // NewServerTransport creates a transport from an already-established server-side connection.
 // This is used when the bridge initiates the connection to the backend.
 func NewServerTransport(conn *websocket.Conn, token string) *Transport {
-	return &Transport{
-		conn:  conn,
-		token: token,
+	now := time.Now()
+	t := &Transport{
+		conn:        conn,
+		token:       token,
+		connectedAt: now,
+		lastSeen:    now,
+		closed:      make(chan struct{}),
+	}
+	if addr := conn.RemoteAddr(); addr != nil {
+		t.remoteAddr = addr.String()
 	}
+	return t
 }
 
 // Connect dial the bridge and maintains the connection
@@ -48,6 +64,9 @@ func (t *Transport) Connect() error {
 
 	t.mu.Lock()
 	t.conn = conn
+	t.connectedAt = time.Now()
+	t.lastSeen = t.connectedAt
+	t.remoteAddr = conn.RemoteAddr().String()
 	t.mu.Unlock()
 
 	logger.Infof("Transport: Connected to Bridge at %s", t.url)
@@ -72,16 +91,86 @@ func (t *Transport) Write(msg Message) error {
 	return t.conn.WriteJSON(msg)
 }
 
-// Read waits for a single JSON message from the bridge
+// Read waits for a single JSON message from the bridge.
+// When a heartbeat is enabled, Read fails once nothing (message or pong) arrives within pongWait.
 func (t *Transport) Read() (Message, error) {
 	var msg Message
 
-	if t.conn == nil {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+	if conn == nil {
 		return msg, fmt.Errorf("transport not connected")
 	}
 
-	err := t.conn.ReadJSON(&msg)
-	return msg, err
+	if err := conn.ReadJSON(&msg); err != nil {
+		return msg, err
+	}
+	t.markSeen(conn)
+	return msg, nil
+}
+
+// EnableHeartbeat arms the read deadline and extends it whenever the bridge answers a ping.
+func (t *Transport) EnableHeartbeat(pongWait time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn == nil || pongWait <= 0 {
+		return
+	}
+	t.pongWait = pongWait
+	conn := t.conn
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		t.markSeen(conn)
+		return nil
+	})
+}
+
+// Ping sends a WebSocket ping control frame. Safe to call concurrently with Read and Write.
+func (t *Transport) Ping() error {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("transport not connected")
+	}
+	return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second))
+}
+
+// markSeen records bridge activity and pushes the read deadline forward
+func (t *Transport) markSeen(conn *websocket.Conn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastSeen = time.Now()
+	if t.pongWait > 0 {
+		conn.SetReadDeadline(t.lastSeen.Add(t.pongWait))
+	}
+}
+
+// LastSeen returns the time of the last message or pong received from the bridge
+func (t *Transport) LastSeen() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.lastSeen
+}
+
+// ConnectedAt returns when the current connection was established
+func (t *Transport) ConnectedAt() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connectedAt
+}
+
+// RemoteAddr returns the network address of the bridge
+func (t *Transport) RemoteAddr() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.remoteAddr
+}
+
+// Done is closed once the transport has been closed
+func (t *Transport) Done() <-chan struct{} {
+	return t.closed
 }
 
 // Close explicitly closes the connection
@@ -92,6 +181,7 @@ func (t *Transport) Close() {
 		t.conn.Close()
 		t.conn = nil
 	}
+	t.closeOnce.Do(func() { close(t.closed) })
 }
 
 // IsConnected returns true if the connection is active
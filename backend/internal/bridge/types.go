@@ -22,6 +22,31 @@ type Message struct {
 	Payload json.RawMessage `json:"payload,omitempty"`
 }
 
+// ResponseEnvelope is the common wrapper used by every *_RESPONSE payload from the bridge
+type ResponseEnvelope struct {
+	Code    int             `json:"code"`
+	Msg     string          `json:"msg"`
+	Content json.RawMessage `json:"content"`
+}
+
+// ParseResponse decodes the standard response envelope from a bridge message payload
+func ParseResponse(msg Message) (ResponseEnvelope, error) {
+	var env ResponseEnvelope
+	if err := json.Unmarshal(msg.Payload, &env); err != nil {
+		return env, fmt.Errorf("failed to unmarshal response envelope: %w", err)
+	}
+	return env, nil
+}
+
+// responseCode returns the envelope code of a response message, or 0 if it has none
+func responseCode(msg Message) int {
+	env, err := ParseResponse(msg)
+	if err != nil {
+		return 0
+	}
+	return env.Code
+}
+
 // Handler interface for bridge requests
 type Handler interface {
 	Handle(ctx context.Context, msg Message) (*Message, error)
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix starts every API key so leaked keys are easy to recognise in logs and scanners
+const APIKeyPrefix = "nxk_"
+
+// APIKey lets an integration, such as a tenant's ERP, call the API as its organisation without a
+// member login. Only the SHA-256 of the key is stored; Prefix identifies it in listings.
+type APIKey struct {
+	ID         string       `json:"key_id"`
+	UserID     string       `json:"user_id"`
+	Name       string       `json:"name"`
+	Prefix     string       `json:"prefix"`
+	KeyHash    string       `json:"-"`
+	Scopes     []Permission `json:"scopes"`
+	ExpiresAt  *time.Time   `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+	LastUsedIP string       `json:"last_used_ip,omitempty"`
+	CreatedBy  string       `json:"created_by,omitempty"` // member who created the key
+	CreatedAt  time.Time    `json:"created_at"`
+	RevokedAt  *time.Time   `json:"revoked_at,omitempty"`
+}
+
+// APIKeyTicket is returned once when a key is created; the plain key cannot be shown again
+type APIKeyTicket struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// Active reports whether the key is neither revoked nor expired at now
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// APIKeyScopes are the permissions a key may be granted. Managing logins and keys, and approving
+// attendance corrections, is reserved for people.
+var APIKeyScopes = []Permission{
+	PermWorkersRead, PermWorkersWrite, PermProjectsRead, PermProjectsWrite,
+	PermSitesRead, PermSitesWrite, PermDevicesRead, PermDevicesWrite,
+	PermAttendanceRead, PermAttendanceWrite, PermSubmissionsRead, PermSubmissionsRun,
+	PermAnalyticsRead, PermSettingsRead,
+}
+
+// ParseAPIKeyScope accepts a grantable scope written as a permission ("attendance:read")
+// or verb first ("read:attendance").
+func ParseAPIKeyScope(s string) (Permission, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if parts := strings.SplitN(s, ":", 2); len(parts) == 2 {
+		for _, p := range APIKeyScopes {
+			if string(p) == s || string(p) == parts[1]+":"+parts[0] {
+				return p, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ScopesInclude reports whether scopes grants p
+func ScopesInclude(scopes []Permission, p Permission) bool {
+	for _, s := range scopes {
+		if s == p {
+			return true
+		}
+	}
+	return false
+}
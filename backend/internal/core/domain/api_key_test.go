@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Permission
+		ok   bool
+	}{
+		{"attendance:read", PermAttendanceRead, true},
+		{"read:attendance", PermAttendanceRead, true},
+		{" Write:Workers ", PermWorkersWrite, true},
+		{"submissions:trigger", PermSubmissionsRun, true},
+		{"members:manage", "", false},
+		{"write:settings", "", false},
+		{"attendance", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseAPIKeyScope(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ParseAPIKeyScope(%q) = %q, %v; want %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAPIKey_Active(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name string
+		key  APIKey
+		want bool
+	}{
+		{"no expiry", APIKey{}, true},
+		{"not yet expired", APIKey{ExpiresAt: &future}, true},
+		{"expired", APIKey{ExpiresAt: &past}, false},
+		{"revoked", APIKey{ExpiresAt: &future, RevokedAt: &past}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.key.Active(now); got != tt.want {
+			t.Errorf("%s: Active() = %v; want %v", tt.name, got, tt.want)
+		}
+	}
+}
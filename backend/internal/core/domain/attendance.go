@@ -2,6 +2,17 @@ package domain
 
 import "time"
 
+// AttendanceDeviceBridgeAggregated is the device_id recorded for attendance that the bridge
+// aggregates across several devices into a single time_in/time_out pair.
+const AttendanceDeviceBridgeAggregated = "BRIDGE_AGGREGATED"
+
+// Attendance directions (attendance.direction)
+const (
+	AttendanceDirectionEntry   = "entry"
+	AttendanceDirectionExit    = "exit"
+	AttendanceDirectionUnknown = "unknown"
+)
+
 type Attendance struct {
 	ID              string     `json:"attendance_id"`
 	DeviceID        string     `json:"device_id"`
@@ -10,9 +21,15 @@ type Attendance struct {
 	UserID          string     `json:"user_id"`
 	TimeIn          *time.Time `json:"time_in"`
 	TimeOut         *time.Time `json:"time_out"`
+	OpenUntil       *time.Time `json:"open_until,omitempty"` // an open session is not submitted before this; see AttendanceSession
 	Direction       string     `json:"direction"`
+	Source          string     `json:"source"`               // device, manual or import; see AttendanceSourceDevice
+	EnteredBy       string     `json:"entered_by,omitempty"` // who entered a manual or imported record
+	EnteredByName   string     `json:"entered_by_name,omitempty"`
+	Flagged         bool       `json:"flagged"` // submitted under ManualPolicyFlag; CPD has no field for it
 	TradeCode       string     `json:"trade_code"`
 	Status          string     `json:"status"`
+	Revision        int        `json:"revision"` // approved corrections; a corrected record is no longer re-derived
 	SubmissionDate  string     `json:"submission_date"`
 	ResponsePayload string     `json:"response_payload,omitempty"`
 	CreatedAt       time.Time  `json:"created_at"`
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// Attendance sources (attendance.source). Device attendance is derived from punches; manual and
+// imported attendance is entered by hand, e.g. from paper timesheets while a terminal is down.
+const (
+	AttendanceSourceDevice = "device"
+	AttendanceSourceManual = "manual"
+	AttendanceSourceImport = "import"
+)
+
+// AttendanceDeviceManual is the device_id recorded for manual and imported attendance.
+const AttendanceDeviceManual = "MANUAL"
+
+// Manual attendance policies (system_settings.manual_attendance_policy) decide how attendance not
+// recorded by a device is submitted to CPD.
+const (
+	// ManualPolicySubmit submits it like device attendance.
+	ManualPolicySubmit = "submit"
+	// ManualPolicyFlag submits it, reports it separately in previews and the activity log, and
+	// marks the record flagged.
+	ManualPolicyFlag = "flag"
+	// ManualPolicyExclude never submits it; it stays pending.
+	ManualPolicyExclude = "exclude"
+)
+
+// MaxAttendanceEntries bounds a single bulk entry or import.
+const MaxAttendanceEntries = 2000
+
+// IsValidManualPolicy reports whether policy is a known manual attendance policy.
+func IsValidManualPolicy(policy string) bool {
+	return policy == ManualPolicySubmit || policy == ManualPolicyFlag || policy == ManualPolicyExclude
+}
+
+// IsDeviceSource reports whether source is device attendance. Rows from before sources were
+// recorded have none and came from devices.
+func IsDeviceSource(source string) bool {
+	return source == "" || source == AttendanceSourceDevice
+}
+
+// AttendanceEntry is one attendance session entered by hand. The worker is given by ID or by
+// person_id_no; project_id, when set, must be the worker's current project.
+type AttendanceEntry struct {
+	WorkerID   string     `json:"worker_id"`
+	PersonIDNo string     `json:"person_id_no,omitempty"`
+	ProjectID  string     `json:"project_id,omitempty"`
+	TimeIn     *time.Time `json:"time_in"`
+	TimeOut    *time.Time `json:"time_out"`
+}
+
+// AttendanceEntryError explains why one entry was refused. Row is the entry's 1-based position,
+// or its row number in an imported sheet.
+type AttendanceEntryError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	Error string `json:"error"`
+}
+
+// AttendanceEntryResult reports a bulk entry or import. Nothing is created unless every row is
+// valid, so a corrected file can be uploaded again; a dry run only validates.
+type AttendanceEntryResult struct {
+	DryRun  bool                   `json:"dry_run"`
+	Source  string                 `json:"source"`
+	Rows    int                    `json:"rows"`
+	Valid   int                    `json:"valid"`
+	Created []string               `json:"created"`
+	Errors  []AttendanceEntryError `json:"errors"`
+}
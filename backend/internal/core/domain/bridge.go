@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Bridge outbox command statuses
+const (
+	BridgeCommandPending = "pending"
+	BridgeCommandSent    = "sent"
+	BridgeCommandFailed  = "failed"
+	BridgeCommandExpired = "expired"
+)
+
+// BridgeCommand is an outbound bridge message held in the per-tenant outbox until the bridge is reachable
+type BridgeCommand struct {
+	ID        int64           `json:"id"`
+	UserID    string          `json:"user_id"`
+	Action    string          `json:"action"`
+	RequestID string          `json:"request_id"`
+	WorkerID  string          `json:"worker_id,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	Status    string          `json:"status"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	CreatedAt time.Time       `json:"created_at"`
+	SentAt    *time.Time      `json:"sent_at,omitempty"`
+}
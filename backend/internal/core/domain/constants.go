@@ -10,9 +10,12 @@ const (
 	// User Types
 	UserTypeUser  = "user"
 	UserTypeAdmin = "admin"
+	// UserTypeVendor organisations operate the platform; see Scope for what they may see
+	UserTypeVendor = "vendor"
 
-	// Roles
+	// Roles (users.role); permissions per role are defined in permission.go
 	RoleWorker  = "worker"
 	RolePIC     = "pic"
 	RoleManager = "manager"
+	RoleViewer  = "viewer"
 )
@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// Correction kinds. An amendment corrects attendance that was already submitted to CPD;
+// once approved the record is submitted again.
+const (
+	CorrectionKindCorrection = "correction"
+	CorrectionKindAmendment  = "amendment"
+)
+
+// Correction statuses.
+const (
+	CorrectionPending  = "pending"
+	CorrectionApproved = "approved"
+	CorrectionRejected = "rejected"
+)
+
+// MaxCorrectionReasonLength bounds the reason and review note of a correction.
+const MaxCorrectionReasonLength = 500
+
+// AttendanceCorrection is a request to change the times of an attendance record. It records the
+// values at the time of the request next to the proposed ones and is applied only once approved
+// by someone other than the requester.
+type AttendanceCorrection struct {
+	ID              string     `json:"correction_id"`
+	AttendanceID    string     `json:"attendance_id"`
+	UserID          string     `json:"user_id"`
+	SiteID          string     `json:"site_id"`
+	WorkerID        string     `json:"worker_id"`
+	Kind            string     `json:"kind"`
+	Reason          string     `json:"reason"`
+	OriginalTimeIn  *time.Time `json:"original_time_in"`
+	OriginalTimeOut *time.Time `json:"original_time_out"`
+	ProposedTimeIn  *time.Time `json:"proposed_time_in"`
+	ProposedTimeOut *time.Time `json:"proposed_time_out"`
+	Status          string     `json:"status"`
+	RequestedBy     string     `json:"requested_by"`
+	RequestedByName string     `json:"requested_by_name,omitempty"`
+	ReviewedBy      string     `json:"reviewed_by,omitempty"`
+	ReviewedByName  string     `json:"reviewed_by_name,omitempty"`
+	ReviewNote      string     `json:"review_note,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// AttendanceRevision is one applied change to an attendance record. Revisions are append-only:
+// the database refuses to update or delete them.
+type AttendanceRevision struct {
+	ID              int64      `json:"revision_id"`
+	AttendanceID    string     `json:"attendance_id"`
+	Revision        int        `json:"revision"`
+	CorrectionID    string     `json:"correction_id"`
+	Kind            string     `json:"kind"`
+	PreviousTimeIn  *time.Time `json:"previous_time_in"`
+	PreviousTimeOut *time.Time `json:"previous_time_out"`
+	TimeIn          *time.Time `json:"time_in"`
+	TimeOut         *time.Time `json:"time_out"`
+	// PreviousStatus is the record's submission status before the change; an amendment resets it to pending
+	PreviousStatus string    `json:"previous_status"`
+	Reason         string    `json:"reason"`
+	RequestedBy    string    `json:"requested_by"`
+	ApprovedBy     string    `json:"approved_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CorrectionKindFor returns the kind of correction a record in status needs.
+func CorrectionKindFor(status string) string {
+	if status == SubmissionStatusSubmitted {
+		return CorrectionKindAmendment
+	}
+	return CorrectionKindCorrection
+}
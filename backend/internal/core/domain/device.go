@@ -34,3 +34,27 @@ type Device struct {
 	LastOnlineCheck *time.Time `json:"last_online_check,omitempty"`
 	Battery         int        `json:"battery"`
 }
+
+// Reasons recorded in device_status_history
+const (
+	DeviceStatusReasonHeartbeat = "heartbeat"
+	DeviceStatusReasonReported  = "reported"
+	DeviceStatusReasonSilence   = "heartbeat_timeout"
+)
+
+// DeviceHeartbeat is a telemetry report for a single device pushed by the bridge
+type DeviceHeartbeat struct {
+	SN      string
+	Status  DeviceStatus // empty means the device is alive and reporting
+	Battery *int
+}
+
+// DeviceStatusChange is one row of the device status history
+type DeviceStatusChange struct {
+	ID         int64        `json:"id"`
+	DeviceID   string       `json:"device_id"`
+	FromStatus DeviceStatus `json:"from_status"`
+	ToStatus   DeviceStatus `json:"to_status"`
+	Reason     string       `json:"reason"`
+	ChangedAt  time.Time    `json:"changed_at"`
+}
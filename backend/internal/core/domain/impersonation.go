@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Impersonation is a vendor support member acting as a tenant organisation ("act as tenant").
+// Its access token is scoped to the tenant; every action taken with it is logged in the tenant's
+// activity feed with both the vendor member and the vendor organisation.
+type Impersonation struct {
+	ID            string     `json:"impersonation_id"`
+	ActorUserID   string     `json:"actor_user_id"` // vendor organisation of the support member
+	ActorMemberID string     `json:"actor_member_id"`
+	ActorUsername string     `json:"actor_username"`
+	TenantUserID  string     `json:"tenant_user_id"`
+	TenantName    string     `json:"tenant_name,omitempty"`
+	Reason        string     `json:"reason"`
+	ReadOnly      bool       `json:"read_only"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	IPAddress     string     `json:"ip_address,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	EndedAt       *time.Time `json:"ended_at,omitempty"`
+}
+
+// ImpersonationTicket is returned when impersonation starts. The token is a bearer access token
+// for the tenant; it cannot be refreshed and stops working when the session ends or expires.
+type ImpersonationTicket struct {
+	Impersonation
+	Token string `json:"token"`
+}
+
+// Active reports whether the session has neither been ended nor expired at now
+func (i *Impersonation) Active(now time.Time) bool {
+	return i.EndedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoginThrottlePolicy_Delay(t *testing.T) {
+	p := LoginThrottlePolicy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 4 * time.Second},
+		{5, 8 * time.Second},
+		{6, 10 * time.Second},
+		{60, 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := p.Delay(tt.failures); got != tt.expected {
+			t.Errorf("Delay(%d) = %v; want %v", tt.failures, got, tt.expected)
+		}
+	}
+}
+
+func TestLoginThrottlePolicy_RetryAfter(t *testing.T) {
+	p := DefaultLoginThrottlePolicy
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	future := now.Add(5 * time.Minute)
+	past := now.Add(-time.Minute)
+
+	tests := []struct {
+		name       string
+		throttle   *LoginThrottle
+		wantWait   time.Duration
+		wantLocked bool
+	}{
+		{"no record", nil, 0, false},
+		{"locked", &LoginThrottle{Scope: ThrottleScopeAccount, Failures: 5, LastFailureAt: now, LockedUntil: &future}, 5 * time.Minute, true},
+		{"lockout expired", &LoginThrottle{Scope: ThrottleScopeAccount, Failures: 5, LastFailureAt: now.Add(-20 * time.Minute), LockedUntil: &past}, 0, false},
+		{"delay pending", &LoginThrottle{Scope: ThrottleScopeAccount, Failures: 3, LastFailureAt: now.Add(-500 * time.Millisecond)}, 1500 * time.Millisecond, false},
+		{"delay elapsed", &LoginThrottle{Scope: ThrottleScopeAccount, Failures: 3, LastFailureAt: now.Add(-3 * time.Second)}, 0, false},
+		{"failures outside window", &LoginThrottle{Scope: ThrottleScopeAccount, Failures: 4, LastFailureAt: now.Add(-time.Hour)}, 0, false},
+		{"ip scope has no delay", &LoginThrottle{Scope: ThrottleScopeIP, Failures: 10, LastFailureAt: now}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, locked := p.RetryAfter(tt.throttle, now)
+			if wait != tt.wantWait || locked != tt.wantLocked {
+				t.Errorf("RetryAfter = (%v, %t); want (%v, %t)", wait, locked, tt.wantWait, tt.wantLocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Member is an individual login belonging to a tenant organisation. The organisation itself is
+// the users row (User): it owns workers, sites, devices and bridge tokens, and every tenant-scoped
+// repository keeps filtering by its user_id. Members carry their own credentials and role.
+type Member struct {
+	ID           string     `json:"member_id"`
+	UserID       string     `json:"user_id"` // owning organisation
+	Username     string     `json:"username"`
+	Name         string     `json:"name"`
+	Email        string     `json:"email"`
+	Role         string     `json:"role"`
+	Status       string     `json:"status"`
+	PasswordHash string     `json:"-"`
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	// MustChangePassword blocks every API except change-password until the member picks their own
+	MustChangePassword bool       `json:"must_change_password"`
+	PasswordChangedAt  *time.Time `json:"password_changed_at,omitempty"`
+
+	// MFASecret is the sealed TOTP secret; it is set but not yet enabled while enrolment is pending
+	MFAEnabled    bool       `json:"mfa_enabled"`
+	MFASecret     string     `json:"-"`
+	MFAEnrolledAt *time.Time `json:"mfa_enrolled_at,omitempty"`
+
+	// SiteIDs are the sites a PIC is responsible for; empty for other roles
+	SiteIDs []string `json:"site_ids,omitempty"`
+
+	// Joined from the organisation
+	OrgName   string `json:"organisation_name,omitempty"`
+	OrgType   string `json:"user_type,omitempty"`
+	OrgStatus string `json:"-"`
+}
+
+// IsPrimary reports whether this is the organisation's original login, which shares its ID
+func (m *Member) IsPrimary() bool {
+	return m.ID == m.UserID
+}
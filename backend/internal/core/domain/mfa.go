@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// LoginResult is the outcome of a correct username and password. Members with MFA (or who must
+// enrol in it) receive a Challenge instead of Tokens and finish signing in with a code.
+type LoginResult struct {
+	Member    *Member
+	Tokens    *TokenPair
+	Challenge *MFAChallenge
+}
+
+// MFAChallenge is a short-lived token proving the password step succeeded. When EnrolmentRequired
+// is set the member has no authenticator yet and must enrol with it before they can sign in.
+type MFAChallenge struct {
+	Token             string    `json:"mfa_token"`
+	ExpiresAt         time.Time `json:"mfa_expires_at"`
+	EnrolmentRequired bool      `json:"enrolment_required"`
+}
+
+// MFAEnrolment is shown once while enrolling: the secret for manual entry and the otpauth URI for a QR code
+type MFAEnrolment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+// MFAConfirmation completes enrolment. RecoveryCodes are shown once; Tokens are only issued when
+// enrolment was part of signing in.
+type MFAConfirmation struct {
+	RecoveryCodes []string
+	Tokens        *TokenPair
+	Member        *Member
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+)
+
+// PasswordPolicy is the complexity every new password must meet. The zero value accepts anything.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+// DefaultPasswordPolicy applies when no overrides are configured
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    10,
+	RequireDigit: true,
+}
+
+// Violations lists every rule the password breaks, in a form suitable for showing to the user.
+func (p PasswordPolicy) Violations(password string) []string {
+	var upper, lower, digit, symbol bool
+	length := 0
+	for _, r := range password {
+		length++
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	var v []string
+	if length < p.MinLength {
+		v = append(v, fmt.Sprintf("be at least %d characters long", p.MinLength))
+	}
+	if p.RequireUpper && !upper {
+		v = append(v, "contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		v = append(v, "contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		v = append(v, "contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		v = append(v, "contain a symbol")
+	}
+	return v
+}
+
+// PasswordReset is a one-time token issued by an administrator. Only its SHA-256 is stored.
+type PasswordReset struct {
+	TokenHash string
+	MemberID  string
+	CreatedBy string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// PasswordResetTicket is handed to the administrator, who passes the token on to the member
+type PasswordResetTicket struct {
+	MemberID  string    `json:"member_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
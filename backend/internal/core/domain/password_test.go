@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPasswordPolicy_Violations(t *testing.T) {
+	strict := PasswordPolicy{MinLength: 10, RequireUpper: true, RequireLower: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		expected []string
+	}{
+		{"zero policy accepts anything", PasswordPolicy{}, "a", nil},
+		{"default accepts letters and digit", DefaultPasswordPolicy, "s3cret-pass", nil},
+		{"default rejects short", DefaultPasswordPolicy, "abc12", []string{"be at least 10 characters long"}},
+		{"default rejects no digit", DefaultPasswordPolicy, "longpassword", []string{"contain a digit"}},
+		{"strict accepts mixed", strict, "Nexus@2026!ChangeMe", nil},
+		{"strict lists every rule", strict, "abc", []string{
+			"be at least 10 characters long", "contain an uppercase letter", "contain a digit", "contain a symbol",
+		}},
+		{"length counts characters not bytes", PasswordPolicy{MinLength: 4}, "ééé", []string{"be at least 4 characters long"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Violations(tt.password); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Violations(%q) = %v; want %v", tt.password, got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package domain
+
+// Permission is a single action a login may perform. Vendor logins are held to their role too;
+// only internal system callers bypass these checks.
+type Permission string
+
+const (
+	PermWorkersRead     Permission = "workers:read"
+	PermWorkersWrite    Permission = "workers:write"
+	PermProjectsRead    Permission = "projects:read"
+	PermProjectsWrite   Permission = "projects:write"
+	PermSitesRead       Permission = "sites:read"
+	PermSitesWrite      Permission = "sites:write"
+	PermDevicesRead     Permission = "devices:read"
+	PermDevicesWrite    Permission = "devices:write"
+	PermAttendanceRead  Permission = "attendance:read"
+	PermAttendanceWrite Permission = "attendance:write"
+	PermSubmissionsRead Permission = "submissions:read"
+	PermSubmissionsRun  Permission = "submissions:trigger"
+	PermAnalyticsRead   Permission = "analytics:read"
+	PermSettingsRead    Permission = "settings:read"
+	PermSettingsWrite   Permission = "settings:write"
+	PermBridgeSync      Permission = "bridge:sync"
+	PermMembersManage   Permission = "members:manage"
+	PermAPIKeysManage   Permission = "api_keys:manage"
+
+	// PermAttendanceApprove reviews attendance corrections; nobody may approve their own request
+	PermAttendanceApprove Permission = "attendance:approve"
+)
+
+var readPermissions = []Permission{
+	PermWorkersRead, PermProjectsRead, PermSitesRead, PermDevicesRead,
+	PermAttendanceRead, PermSubmissionsRead, PermAnalyticsRead, PermSettingsRead,
+}
+
+// rolePermissions is the fixed role -> permission mapping. A PIC's attendance:write is further
+// limited to the sites assigned to them (see RoleIsSiteScoped).
+var rolePermissions = map[string][]Permission{
+	RoleManager: append(append([]Permission{}, readPermissions...),
+		PermWorkersWrite, PermProjectsWrite, PermSitesWrite, PermDevicesWrite,
+		PermAttendanceWrite, PermAttendanceApprove, PermSubmissionsRun, PermSettingsWrite, PermBridgeSync, PermMembersManage, PermAPIKeysManage),
+	RolePIC:    append(append([]Permission{}, readPermissions...), PermAttendanceWrite),
+	RoleViewer: readPermissions,
+	RoleWorker: {},
+}
+
+// Roles lists the assignable roles, most privileged first
+var Roles = []string{RoleManager, RolePIC, RoleViewer, RoleWorker}
+
+// ValidRole reports whether role is one of Roles
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RolePermissions returns the permissions granted to role, or nil for an unknown role
+func RolePermissions(role string) []Permission {
+	return rolePermissions[role]
+}
+
+// RoleHas reports whether role grants p
+func RoleHas(role string, p Permission) bool {
+	for _, granted := range rolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReadPermission reports whether p only reads data
+func IsReadPermission(p Permission) bool {
+	for _, r := range readPermissions {
+		if r == p {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleIsSiteScoped reports whether writes by role are limited to the user's assigned sites
+func RoleIsSiteScoped(role string) bool {
+	return role == RolePIC
+}
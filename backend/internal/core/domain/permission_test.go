@@ -0,0 +1,58 @@
+package domain
+
+import "testing"
+
+func TestRoleHas(t *testing.T) {
+	tests := []struct {
+		role string
+		perm Permission
+		want bool
+	}{
+		{RoleManager, PermSubmissionsRun, true},
+		{RoleManager, PermAttendanceWrite, true},
+		{RoleManager, PermAttendanceApprove, true},
+		{RolePIC, PermAttendanceWrite, true},
+		{RolePIC, PermAttendanceApprove, false},
+		{RolePIC, PermAttendanceRead, true},
+		{RolePIC, PermSubmissionsRun, false},
+		{RolePIC, PermWorkersWrite, false},
+		{RoleViewer, PermAttendanceRead, true},
+		{RoleViewer, PermAttendanceWrite, false},
+		{RoleViewer, PermSettingsWrite, false},
+		{RoleWorker, PermAttendanceRead, false},
+		{"unknown", PermAttendanceRead, false},
+	}
+
+	for _, tt := range tests {
+		if got := RoleHas(tt.role, tt.perm); got != tt.want {
+			t.Errorf("RoleHas(%q, %q) = %v; want %v", tt.role, tt.perm, got, tt.want)
+		}
+	}
+}
+
+func TestRoles_AreValid(t *testing.T) {
+	for _, r := range Roles {
+		if !ValidRole(r) {
+			t.Errorf("ValidRole(%q) = false", r)
+		}
+	}
+	if ValidRole("admin") {
+		t.Errorf("ValidRole(admin) = true; want false")
+	}
+	if !RoleIsSiteScoped(RolePIC) || RoleIsSiteScoped(RoleManager) {
+		t.Errorf("only PIC should be site scoped")
+	}
+}
+
+func TestIsReadPermission(t *testing.T) {
+	for _, p := range RolePermissions(RoleViewer) {
+		if !IsReadPermission(p) {
+			t.Errorf("IsReadPermission(%q) = false; want true", p)
+		}
+	}
+	for _, p := range []Permission{PermWorkersWrite, PermAttendanceWrite, PermSubmissionsRun, PermMembersManage} {
+		if IsReadPermission(p) {
+			t.Errorf("IsReadPermission(%q) = true; want false", p)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Punch directions as reported by the devices.
+const (
+	PunchIn      = "in"
+	PunchOut     = "out"
+	PunchUnknown = "unknown"
+)
+
+// Punch sources.
+const (
+	PunchSourceDevice       = "device"        // a single scan reported by the bridge
+	PunchSourceBridgeRecord = "bridge_record" // one end of a time_in/time_out record the bridge aggregated itself
+)
+
+// Pairing modes decide how a worker's punches are turned into attendance sessions.
+const (
+	// PairingFirstInLastOut derives one session per day from the first in and the last out.
+	PairingFirstInLastOut = "first_in_last_out"
+	// PairingPaired pairs each in with the next out, so a day may hold several sessions.
+	PairingPaired = "paired"
+)
+
+// Punch is a single raw scan of a worker at a device. Attendance rows are derived from punches.
+type Punch struct {
+	ID         int64     `json:"punch_id"`
+	WorkerID   string    `json:"worker_id"`
+	SiteID     string    `json:"site_id"`
+	UserID     string    `json:"user_id"`
+	DeviceSN   string    `json:"device_sn"`
+	Direction  string    `json:"direction"`
+	PunchedAt  time.Time `json:"punched_at"`
+	Source     string    `json:"source"`
+	RawPayload string    `json:"raw_payload,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BridgePunch is a punch as received from the bridge, before its time has been parsed.
+type BridgePunch struct {
+	DeviceSN   string
+	Direction  string
+	Time       string
+	Source     string
+	RawPayload string
+}
+
+// NormalizePunchDirection maps the direction vocabulary of the devices onto PunchIn, PunchOut or PunchUnknown.
+func NormalizePunchDirection(direction string) string {
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case "in", "entry", "check_in", "checkin":
+		return PunchIn
+	case "out", "exit", "check_out", "checkout":
+		return PunchOut
+	}
+	return PunchUnknown
+}
+
+// PairingRules configure the pairing engine; they are kept in system_settings.
+type PairingRules struct {
+	Mode string `json:"mode"`
+	// DebounceSeconds drops repeat scans in the same direction within this window of the previous one.
+	DebounceSeconds int `json:"debounce_seconds"`
+	// MaxSessionHours is how long a session may stay open; a later punch is not paired with it.
+	MaxSessionHours int `json:"max_session_hours"`
+	// Shifts govern the worker being paired; punches inside a shift's window count towards
+	// the shift's business date rather than their calendar date.
+	Shifts []Shift `json:"shifts,omitempty"`
+	// Location is the business timezone calendar dates and shift times are reckoned in.
+	// Nil leaves each punch in the location it carries.
+	Location *time.Location `json:"-"`
+}
+
+// DefaultPairingRules are used when no rules have been configured.
+func DefaultPairingRules() PairingRules {
+	return PairingRules{Mode: PairingFirstInLastOut, DebounceSeconds: 60, MaxSessionHours: 16}
+}
+
+// IsValidPairingMode reports whether mode is a known pairing mode.
+func IsValidPairingMode(mode string) bool {
+	return mode == PairingFirstInLastOut || mode == PairingPaired
+}
+
+func (r PairingRules) withDefaults() PairingRules {
+	def := DefaultPairingRules()
+	if !IsValidPairingMode(r.Mode) {
+		r.Mode = def.Mode
+	}
+	if r.DebounceSeconds < 0 {
+		r.DebounceSeconds = 0
+	}
+	if r.MaxSessionHours <= 0 {
+		r.MaxSessionHours = def.MaxSessionHours
+	}
+	return r
+}
+
+// BusinessDate returns the date (YYYY-MM-DD) a punch at t counts towards: the business date of
+// the shift whose window contains it, or its calendar date outside any shift.
+func (r PairingRules) BusinessDate(t time.Time) string {
+	t = r.local(t)
+	if w, ok := ShiftWindowAt(r.Shifts, t); ok {
+		return w.BusinessDate
+	}
+	return t.Format("2006-01-02")
+}
+
+// openUntil is how long a session opened at t can still be closed: the end of its shift window,
+// or MaxSessionHours without a shift.
+func (r PairingRules) openUntil(t time.Time) time.Time {
+	if w, ok := ShiftWindowAt(r.Shifts, r.local(t)); ok {
+		return w.End
+	}
+	return t.Add(time.Duration(r.MaxSessionHours) * time.Hour)
+}
+
+func (r PairingRules) local(t time.Time) time.Time {
+	if r.Location == nil {
+		return t
+	}
+	return t.In(r.Location)
+}
+
+// AttendanceSession is one time_in/time_out pair derived from punches.
+type AttendanceSession struct {
+	// Date is the business date the session counts towards (YYYY-MM-DD), taken from its opening punch.
+	Date      string
+	TimeIn    time.Time
+	TimeOut   *time.Time // nil while the worker has not punched out
+	DeviceIn  string
+	DeviceOut string
+	// OpenUntil is set on a session without a time_out: until then its out punch may still arrive.
+	OpenUntil *time.Time
+	// Direction is AttendanceDirectionEntry when the opening punch was an explicit in.
+	Direction string
+}
+
+// PairPunches derives attendance sessions from one worker's punches under rules.
+// The result is ordered by time_in and depends only on the punches and the rules,
+// so the same inputs always re-derive the same sessions.
+func PairPunches(punches []Punch, rules PairingRules) []AttendanceSession {
+	rules = rules.withDefaults()
+	sorted := debouncePunches(punches, time.Duration(rules.DebounceSeconds)*time.Second)
+	var sessions []AttendanceSession
+	if rules.Mode == PairingPaired {
+		sessions = pairSequential(sorted, rules)
+	} else {
+		sessions = pairFirstInLastOut(sorted, rules)
+	}
+	for i := range sessions {
+		if sessions[i].TimeOut == nil {
+			until := rules.openUntil(sessions[i].TimeIn)
+			sessions[i].OpenUntil = &until
+		}
+	}
+	return sessions
+}
+
+// debouncePunches sorts punches by time and drops repeat scans in the same direction
+// within window of the last punch kept.
+func debouncePunches(punches []Punch, window time.Duration) []Punch {
+	sorted := append([]Punch(nil), punches...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].PunchedAt.Before(sorted[j].PunchedAt) })
+
+	kept := sorted[:0]
+	for _, p := range sorted {
+		if n := len(kept); n > 0 {
+			last := kept[n-1]
+			if last.Direction == p.Direction && p.PunchedAt.Sub(last.PunchedAt) <= window {
+				continue
+			}
+		}
+		kept = append(kept, p)
+	}
+	return kept
+}
+
+// pairFirstInLastOut opens a session per business date at the first punch that is not an
+// explicit out and closes it at the last punch after it that is not an explicit in.
+func pairFirstInLastOut(punches []Punch, rules PairingRules) []AttendanceSession {
+	maxOpen := time.Duration(rules.MaxSessionHours) * time.Hour
+	var dates []string
+	days := make(map[string][]Punch)
+	for _, p := range punches {
+		date := rules.BusinessDate(p.PunchedAt)
+		if _, seen := days[date]; !seen {
+			dates = append(dates, date)
+		}
+		days[date] = append(days[date], p)
+	}
+	sort.Strings(dates)
+
+	var sessions []AttendanceSession
+	for _, date := range dates {
+		day := days[date]
+		first := -1
+		for i, p := range day {
+			if p.Direction != PunchOut {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			continue // only out punches: nothing to open a session with
+		}
+		session := openSession(day[first], date)
+		for i := len(day) - 1; i > first; i-- {
+			p := day[i]
+			if p.Direction != PunchIn && p.PunchedAt.Sub(session.TimeIn) <= maxOpen {
+				closeSession(&session, p)
+				break
+			}
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
+
+// pairSequential walks the punches in order: an in (or unknown) opens a session and the next
+// out (or unknown) closes it. An in while a session is open leaves that session without an out,
+// and an out with no open session extends the session it follows.
+func pairSequential(punches []Punch, rules PairingRules) []AttendanceSession {
+	maxOpen := time.Duration(rules.MaxSessionHours) * time.Hour
+	var sessions []AttendanceSession
+	var open *AttendanceSession
+
+	for _, p := range punches {
+		if open != nil && p.PunchedAt.Sub(open.TimeIn) > maxOpen {
+			sessions = append(sessions, *open)
+			open = nil
+		}
+
+		switch {
+		case open == nil && p.Direction != PunchOut:
+			s := openSession(p, rules.BusinessDate(p.PunchedAt))
+			open = &s
+		case open == nil:
+			if n := len(sessions); n > 0 && sessions[n-1].TimeOut != nil && p.PunchedAt.Sub(sessions[n-1].TimeIn) <= maxOpen {
+				closeSession(&sessions[n-1], p)
+			}
+			// Otherwise an orphan out; it stays in the raw punches only
+		case p.Direction == PunchIn:
+			sessions = append(sessions, *open)
+			s := openSession(p, rules.BusinessDate(p.PunchedAt))
+			open = &s
+		default:
+			closeSession(open, p)
+			sessions = append(sessions, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		sessions = append(sessions, *open)
+	}
+	return sessions
+}
+
+func openSession(p Punch, date string) AttendanceSession {
+	direction := AttendanceDirectionUnknown
+	if p.Direction == PunchIn {
+		direction = AttendanceDirectionEntry
+	}
+	return AttendanceSession{
+		Date:      date,
+		TimeIn:    p.PunchedAt,
+		DeviceIn:  p.DeviceSN,
+		Direction: direction,
+	}
+}
+
+func closeSession(s *AttendanceSession, p Punch) {
+	t := p.PunchedAt
+	s.TimeOut = &t
+	s.DeviceOut = p.DeviceSN
+}
+
+// RederiveResult summarises a re-derivation of attendance from punches.
+type RederiveResult struct {
+	Workers int `json:"workers"`
+	Days    int `json:"days"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+	// LockedDays were left alone because they hold attendance already submitted to CPD or corrected by hand.
+	LockedDays int `json:"locked_days"`
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func punchAt(clock, direction, device string) Punch {
+	t, err := time.Parse("2006-01-02 15:04", clock)
+	if err != nil {
+		panic(err)
+	}
+	return Punch{WorkerID: "w1", DeviceSN: device, Direction: direction, PunchedAt: t}
+}
+
+func sessionSummary(s AttendanceSession) string {
+	out := "open"
+	if s.TimeOut != nil {
+		out = s.TimeOut.Format("15:04")
+	}
+	return s.Date + " " + s.TimeIn.Format("15:04") + "-" + out
+}
+
+func assertSessions(t *testing.T, got []AttendanceSession, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		summaries := make([]string, len(got))
+		for i, s := range got {
+			summaries[i] = sessionSummary(s)
+		}
+		t.Fatalf("got %d sessions %v; want %v", len(got), summaries, want)
+	}
+	for i, s := range got {
+		if sessionSummary(s) != want[i] {
+			t.Errorf("session %d = %s; want %s", i, sessionSummary(s), want[i])
+		}
+	}
+}
+
+func TestPairPunches_FirstInLastOut(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 12:00", PunchOut, "SN-2"),
+		punchAt("2026-03-02 08:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 13:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 17:30", PunchOut, "SN-2"),
+		punchAt("2026-03-03 08:05", PunchIn, "SN-1"),
+	}
+
+	got := PairPunches(punches, PairingRules{Mode: PairingFirstInLastOut})
+
+	assertSessions(t, got, "2026-03-02 08:00-17:30", "2026-03-03 08:05-open")
+	if got[0].DeviceIn != "SN-1" || got[0].DeviceOut != "SN-2" || got[0].Direction != AttendanceDirectionEntry {
+		t.Errorf("session = %+v; want SN-1 -> SN-2 entry", got[0])
+	}
+}
+
+func TestPairPunches_FirstInLastOut_IgnoresTrailingIn(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 08:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 17:00", PunchOut, "SN-1"),
+		punchAt("2026-03-02 19:00", PunchIn, "SN-1"),
+	}
+
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingFirstInLastOut}), "2026-03-02 08:00-17:00")
+}
+
+func TestPairPunches_Paired_MultipleSessions(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 08:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 12:00", PunchOut, "SN-1"),
+		punchAt("2026-03-02 13:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 17:30", PunchOut, "SN-1"),
+	}
+
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingPaired}),
+		"2026-03-02 08:00-12:00", "2026-03-02 13:00-17:30")
+}
+
+func TestPairPunches_Paired_MissingAndRepeatedPunches(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 08:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 13:00", PunchIn, "SN-1"),  // previous session never punched out
+		punchAt("2026-03-02 17:00", PunchOut, "SN-1"), // closes the 13:00 session
+		punchAt("2026-03-02 18:00", PunchOut, "SN-1"), // a later out extends it
+	}
+
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingPaired}),
+		"2026-03-02 08:00-open", "2026-03-02 13:00-18:00")
+}
+
+func TestPairPunches_Paired_UnknownDirectionAlternates(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 08:00", PunchUnknown, "SN-1"),
+		punchAt("2026-03-02 12:00", PunchUnknown, "SN-1"),
+		punchAt("2026-03-02 13:00", PunchUnknown, "SN-1"),
+	}
+
+	got := PairPunches(punches, PairingRules{Mode: PairingPaired})
+
+	assertSessions(t, got, "2026-03-02 08:00-12:00", "2026-03-02 13:00-open")
+	if got[0].Direction != AttendanceDirectionUnknown {
+		t.Errorf("direction = %q; want unknown when the opening punch had no direction", got[0].Direction)
+	}
+}
+
+func TestPairPunches_Paired_MaxSessionHours(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 08:00", PunchIn, "SN-1"),
+		punchAt("2026-03-03 09:00", PunchOut, "SN-1"),
+	}
+
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingPaired, MaxSessionHours: 16}), "2026-03-02 08:00-open")
+}
+
+func TestPairPunches_Debounce(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 08:00", PunchIn, "SN-1"),
+		punchAt("2026-03-02 08:00", PunchIn, "SN-2"), // the same scan reported by a second device
+		punchAt("2026-03-02 08:01", PunchIn, "SN-1"),
+		punchAt("2026-03-02 17:00", PunchOut, "SN-1"),
+	}
+
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingPaired, DebounceSeconds: 60}), "2026-03-02 08:00-17:00")
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingPaired, DebounceSeconds: 0}),
+		"2026-03-02 08:00-open", "2026-03-02 08:01-17:00")
+}
+
+func TestNormalizePunchDirection(t *testing.T) {
+	tests := map[string]string{"IN": PunchIn, "entry": PunchIn, "out": PunchOut, "Exit": PunchOut, "": PunchUnknown, "?": PunchUnknown}
+	for in, want := range tests {
+		if got := NormalizePunchDirection(in); got != want {
+			t.Errorf("NormalizePunchDirection(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package domain
+
+// ScopeKind says how many tenant organisations a caller may see.
+type ScopeKind string
+
+const (
+	ScopeTenant  ScopeKind = "tenant"  // a tenant login or API key: its own organisation only
+	ScopeTenants ScopeKind = "tenants" // a vendor account limited to the organisations it supports
+	ScopeGlobal  ScopeKind = "global"  // a vendor account that sees every organisation
+)
+
+// Scope is the set of tenant organisations (users.user_id) whose data a caller may read or change.
+// The zero value covers no tenant at all, so a missing or mis-built scope matches nothing.
+type Scope struct {
+	Kind      ScopeKind `json:"kind"`
+	TenantIDs []string  `json:"tenant_ids,omitempty"`
+}
+
+// TenantScope limits a caller to a single organisation.
+func TenantScope(tenantID string) Scope {
+	if tenantID == "" {
+		return Scope{}
+	}
+	return Scope{Kind: ScopeTenant, TenantIDs: []string{tenantID}}
+}
+
+// TenantsScope limits a caller to the given organisations. Duplicates and empty IDs are dropped.
+func TenantsScope(tenantIDs ...string) Scope {
+	seen := make(map[string]bool, len(tenantIDs))
+	var ids []string
+	for _, id := range tenantIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return Scope{}
+	}
+	return Scope{Kind: ScopeTenants, TenantIDs: ids}
+}
+
+// GlobalScope covers every organisation.
+func GlobalScope() Scope {
+	return Scope{Kind: ScopeGlobal}
+}
+
+// IsGlobal reports whether the scope covers every organisation.
+func (s Scope) IsGlobal() bool {
+	return s.Kind == ScopeGlobal
+}
+
+// IsEmpty reports whether the scope covers no organisation.
+func (s Scope) IsEmpty() bool {
+	return !s.IsGlobal() && len(s.TenantIDs) == 0
+}
+
+// Allows reports whether tenantID is inside the scope.
+func (s Scope) Allows(tenantID string) bool {
+	if tenantID == "" {
+		return false
+	}
+	if s.IsGlobal() {
+		return true
+	}
+	for _, id := range s.TenantIDs {
+		if id == tenantID {
+			return true
+		}
+	}
+	return false
+}
+
+// Narrow restricts the scope to one organisation. Narrowing to a tenant outside the scope
+// yields the empty scope rather than an error, so the query simply returns nothing.
+func (s Scope) Narrow(tenantID string) Scope {
+	if !s.Allows(tenantID) {
+		return Scope{}
+	}
+	return TenantScope(tenantID)
+}
+
+// OrganisationScope is the scope of a signed-in organisation. Tenants see only themselves. Vendors see
+// every organisation unless they are assigned supported tenants, in which case they see those and themselves.
+func OrganisationScope(userID, userType string, supportedTenants []string) Scope {
+	if userType != UserTypeVendor {
+		return TenantScope(userID)
+	}
+	if len(supportedTenants) == 0 {
+		return GlobalScope()
+	}
+	return TenantsScope(append([]string{userID}, supportedTenants...)...)
+}
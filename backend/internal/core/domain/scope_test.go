@@ -0,0 +1,51 @@
+package domain
+
+import "testing"
+
+func TestOrganisationScope(t *testing.T) {
+	tenant := OrganisationScope("user-1", UserTypeUser, []string{"user-2"})
+	if tenant.Kind != ScopeTenant || !tenant.Allows("user-1") || tenant.Allows("user-2") {
+		t.Errorf("tenant scope = %+v; want only user-1", tenant)
+	}
+
+	global := OrganisationScope("vendor-1", UserTypeVendor, nil)
+	if !global.IsGlobal() || !global.Allows("anyone") {
+		t.Errorf("vendor without supported tenants = %+v; want global", global)
+	}
+
+	limited := OrganisationScope("vendor-1", UserTypeVendor, []string{"user-2", "user-3", "user-2"})
+	if limited.Kind != ScopeTenants || len(limited.TenantIDs) != 3 {
+		t.Fatalf("limited vendor scope = %+v; want vendor-1, user-2, user-3", limited)
+	}
+	for _, id := range []string{"vendor-1", "user-2", "user-3"} {
+		if !limited.Allows(id) {
+			t.Errorf("limited.Allows(%q) = false", id)
+		}
+	}
+	if limited.Allows("user-4") {
+		t.Errorf("limited.Allows(user-4) = true")
+	}
+}
+
+func TestScope_Narrow(t *testing.T) {
+	limited := TenantsScope("user-1", "user-2")
+	if got := limited.Narrow("user-2"); got.Kind != ScopeTenant || !got.Allows("user-2") || got.Allows("user-1") {
+		t.Errorf("Narrow(user-2) = %+v; want tenant user-2", got)
+	}
+	if got := limited.Narrow("user-9"); !got.IsEmpty() {
+		t.Errorf("Narrow outside scope = %+v; want empty", got)
+	}
+	if got := GlobalScope().Narrow("user-9"); got.Kind != ScopeTenant || !got.Allows("user-9") {
+		t.Errorf("global Narrow(user-9) = %+v; want tenant user-9", got)
+	}
+}
+
+func TestScope_ZeroValueAllowsNothing(t *testing.T) {
+	var s Scope
+	if !s.IsEmpty() || s.IsGlobal() || s.Allows("user-1") || s.Allows("") {
+		t.Errorf("zero Scope = %+v; want empty", s)
+	}
+	if !TenantScope("").IsEmpty() || !TenantsScope("", "").IsEmpty() {
+		t.Errorf("scopes built from empty IDs must be empty")
+	}
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Refresh session states (auth_sessions.status)
+const (
+	SessionActive  = "active"
+	SessionRotated = "rotated" // exchanged for a newer session; presenting it again is token reuse
+	SessionRevoked = "revoked"
+)
+
+// AuthSession is one refresh token. Each refresh rotates it into a new session of the same family,
+// so a stolen token that is replayed after rotation can be detected and the whole family revoked.
+// Only the SHA-256 of the refresh token is stored.
+type AuthSession struct {
+	ID              string     `json:"session_id"`
+	FamilyID        string     `json:"family_id"`
+	MemberID        string     `json:"member_id"`
+	UserID          string     `json:"user_id"`
+	TokenHash       string     `json:"-"`
+	AccessJTI       string     `json:"-"`
+	AccessExpiresAt time.Time  `json:"-"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	Status          string     `json:"status"`
+	ReplacedBy      string     `json:"replaced_by,omitempty"`
+	IPAddress       string     `json:"ip_address,omitempty"`
+	UserAgent       string     `json:"user_agent,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason   string     `json:"revoked_reason,omitempty"`
+}
+
+// TokenPair is what a successful login or refresh hands back to the client
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	AccessExpiresAt  time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
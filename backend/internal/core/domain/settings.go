@@ -3,13 +3,31 @@ package domain
 import "time"
 
 type SystemSettings struct {
-	ID                   int       `json:"id"`
-	AttendanceSyncTime   string    `json:"attendance_sync_time"`    // "HH:MM:SS"
-	CPDSubmissionTime    string    `json:"cpd_submission_time"`     // "HH:MM:SS"
-	MaxPayloadSizeKB     int       `json:"max_payload_size_kb"`     // KB
-	MaxWorkersPerRequest int       `json:"max_workers_per_request"` // Batch size
-	MaxRequestsPerMinute int       `json:"max_requests_per_minute"` // Rate limit
-	UpdatedAt            time.Time `json:"updated_at"`
+	ID                   int    `json:"id"`
+	AttendanceSyncTime   string `json:"attendance_sync_time"`    // "HH:MM:SS"
+	CPDSubmissionTime    string `json:"cpd_submission_time"`     // "HH:MM:SS"
+	MaxPayloadSizeKB     int    `json:"max_payload_size_kb"`     // KB
+	MaxWorkersPerRequest int    `json:"max_workers_per_request"` // Batch size
+	MaxRequestsPerMinute int    `json:"max_requests_per_minute"` // Rate limit
+
+	// Pairing rules for deriving attendance from punches; see PairingRules
+	AttendancePairingMode string `json:"attendance_pairing_mode"`
+	PunchDebounceSeconds  int    `json:"punch_debounce_seconds"`
+	MaxSessionHours       int    `json:"max_session_hours"`
+
+	// How attendance entered manually or imported is submitted; see ManualPolicySubmit
+	ManualAttendancePolicy string `json:"manual_attendance_policy"`
+
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PairingRules returns the pairing rules held in the settings.
+func (s SystemSettings) PairingRules() PairingRules {
+	return PairingRules{
+		Mode:            s.AttendancePairingMode,
+		DebounceSeconds: s.PunchDebounceSeconds,
+		MaxSessionHours: s.MaxSessionHours,
+	}
 }
 
 // DTO to include extra stats not in the settings table
@@ -12,6 +12,7 @@ type AttendanceRow struct {
 	TimeIn       time.Time
 	TimeOut      *time.Time
 	Direction    string
+	Source       string
 	TradeCode    string
 	Status       string
 
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Shift is a working pattern defined for a site, or for a project which then takes precedence
+// over its site. Attendance is assigned to the business date the shift starts on, so a night
+// shift from 20:00 to 06:00 counts entirely towards the day it began.
+type Shift struct {
+	ID        string `json:"shift_id"`
+	UserID    string `json:"user_id"`
+	SiteID    string `json:"site_id,omitempty"`
+	ProjectID string `json:"project_id,omitempty"`
+	Name      string `json:"name"`
+	StartTime string `json:"start_time"` // HH:MM
+	EndTime   string `json:"end_time"`   // HH:MM; not after StartTime means the shift ends the next day
+	// GraceBeforeMinutes lets workers punch in this early; GraceAfterMinutes this late after the end.
+	GraceBeforeMinutes int       `json:"grace_before_minutes"`
+	GraceAfterMinutes  int       `json:"grace_after_minutes"`
+	Status             string    `json:"status"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// ShiftWindow is one occurrence of a shift, widened by its grace windows.
+type ShiftWindow struct {
+	Shift        Shift
+	BusinessDate string // YYYY-MM-DD the occurrence starts on
+	Start        time.Time
+	End          time.Time
+}
+
+// ParseShiftClock parses an "HH:MM" (or "HH:MM:SS") time of day.
+func ParseShiftClock(value string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a time of day (HH:MM)", value)
+}
+
+// Overnight reports whether the shift ends on the day after it starts.
+func (s Shift) Overnight() bool {
+	start, err1 := ParseShiftClock(s.StartTime)
+	end, err2 := ParseShiftClock(s.EndTime)
+	return err1 == nil && err2 == nil && end <= start
+}
+
+// Window returns the occurrence of the shift starting on day (any time on that date, in the
+// location the shift's clock times are meant in).
+func (s Shift) Window(day time.Time) (ShiftWindow, error) {
+	start, err := ParseShiftClock(s.StartTime)
+	if err != nil {
+		return ShiftWindow{}, err
+	}
+	end, err := ParseShiftClock(s.EndTime)
+	if err != nil {
+		return ShiftWindow{}, err
+	}
+	midnight := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	return ShiftWindow{
+		Shift:        s,
+		BusinessDate: midnight.Format("2006-01-02"),
+		Start:        midnight.Add(start - time.Duration(s.GraceBeforeMinutes)*time.Minute),
+		End:          midnight.Add(end + time.Duration(s.GraceAfterMinutes)*time.Minute),
+	}, nil
+}
+
+// ShiftWindowAt finds the shift occurrence whose window contains t. When windows overlap the one
+// that started last wins, so an early arrival for a night shift is not taken for a late day-shift exit.
+func ShiftWindowAt(shifts []Shift, t time.Time) (ShiftWindow, bool) {
+	var best ShiftWindow
+	found := false
+	for _, s := range shifts {
+		// Grace windows can reach across midnight either way, so try the neighbouring days too
+		for _, offset := range []int{-1, 0, 1} {
+			w, err := s.Window(t.AddDate(0, 0, offset))
+			if err != nil || t.Before(w.Start) || t.After(w.End) {
+				continue
+			}
+			if !found || w.Start.After(best.Start) {
+				best, found = w, true
+			}
+		}
+	}
+	return best, found
+}
+
+// ApplicableShifts picks the active shifts that govern a worker: those of the worker's project
+// when it defines any, otherwise those of the site.
+func ApplicableShifts(shifts []Shift, projectID string) []Shift {
+	var project, site []Shift
+	for _, s := range shifts {
+		if s.Status != "" && s.Status != StatusActive {
+			continue
+		}
+		if projectID != "" && s.ProjectID == projectID {
+			project = append(project, s)
+		} else if s.ProjectID == "" {
+			site = append(site, s)
+		}
+	}
+	if len(project) > 0 {
+		return project
+	}
+	return site
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+var nightShift = Shift{Name: "Night", StartTime: "20:00", EndTime: "06:00", GraceBeforeMinutes: 60, GraceAfterMinutes: 120}
+var dayShift = Shift{Name: "Day", StartTime: "08:00", EndTime: "17:30", GraceBeforeMinutes: 60, GraceAfterMinutes: 120}
+
+func TestShift_Window(t *testing.T) {
+	w, err := nightShift.Window(time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.BusinessDate != "2026-03-02" ||
+		!w.Start.Equal(time.Date(2026, 3, 2, 19, 0, 0, 0, time.UTC)) ||
+		!w.End.Equal(time.Date(2026, 3, 3, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("night window = %s %s..%s; want 2026-03-02 19:00..08:00 next day", w.BusinessDate, w.Start, w.End)
+	}
+	if !nightShift.Overnight() || dayShift.Overnight() {
+		t.Errorf("Overnight() night=%v day=%v; want true, false", nightShift.Overnight(), dayShift.Overnight())
+	}
+	if _, err := (Shift{StartTime: "25:00", EndTime: "06:00"}).Window(time.Now()); err == nil {
+		t.Error("Window with an invalid start time succeeded")
+	}
+}
+
+func TestShiftWindowAt(t *testing.T) {
+	shifts := []Shift{dayShift, nightShift}
+	tests := []struct {
+		at   time.Time
+		date string
+		name string
+	}{
+		{time.Date(2026, 3, 3, 5, 30, 0, 0, time.UTC), "2026-03-02", "Night"}, // after midnight, before the night shift ends
+		{time.Date(2026, 3, 3, 7, 45, 0, 0, time.UTC), "2026-03-03", "Day"},   // early for the day shift wins over a late night exit
+		{time.Date(2026, 3, 2, 19, 10, 0, 0, time.UTC), "2026-03-02", "Night"},
+		{time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), "2026-03-02", "Day"},
+	}
+	for _, tt := range tests {
+		w, ok := ShiftWindowAt(shifts, tt.at)
+		if !ok || w.BusinessDate != tt.date || w.Shift.Name != tt.name {
+			t.Errorf("ShiftWindowAt(%s) = %s %s (found %v); want %s %s", tt.at.Format("01-02 15:04"), w.BusinessDate, w.Shift.Name, ok, tt.date, tt.name)
+		}
+	}
+
+	if _, ok := ShiftWindowAt([]Shift{nightShift}, time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)); ok {
+		t.Error("midday punch matched the night shift")
+	}
+}
+
+func TestApplicableShifts(t *testing.T) {
+	site := Shift{ID: "site-day", SiteID: "s1", Status: StatusActive}
+	project := Shift{ID: "p1-night", SiteID: "s1", ProjectID: "p1", Status: StatusActive}
+	retired := Shift{ID: "p2-old", SiteID: "s1", ProjectID: "p2", Status: StatusInactive}
+	all := []Shift{site, project, retired}
+
+	if got := ApplicableShifts(all, "p1"); len(got) != 1 || got[0].ID != "p1-night" {
+		t.Errorf("project p1 shifts = %+v; want its own night shift", got)
+	}
+	if got := ApplicableShifts(all, "p2"); len(got) != 1 || got[0].ID != "site-day" {
+		t.Errorf("project p2 shifts = %+v; want the site shift as its own is inactive", got)
+	}
+}
+
+func TestPairPunches_OvernightShift(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 19:50", PunchIn, "SN-1"),
+		punchAt("2026-03-03 06:05", PunchOut, "SN-1"),
+		punchAt("2026-03-03 19:55", PunchIn, "SN-1"),
+	}
+
+	for _, mode := range []string{PairingFirstInLastOut, PairingPaired} {
+		got := PairPunches(punches, PairingRules{Mode: mode, Shifts: []Shift{nightShift}})
+		assertSessions(t, got, "2026-03-02 19:50-06:05", "2026-03-03 19:55-open")
+		if got[0].OpenUntil != nil {
+			t.Errorf("%s: closed session has open_until %v", mode, got[0].OpenUntil)
+		}
+		// The second night is still in progress: it stays open until its shift window ends
+		if want := time.Date(2026, 3, 4, 8, 0, 0, 0, time.UTC); got[1].OpenUntil == nil || !got[1].OpenUntil.Equal(want) {
+			t.Errorf("%s: open_until = %v; want %s", mode, got[1].OpenUntil, want)
+		}
+	}
+}
+
+func TestPairPunches_OvernightWithoutShiftSplitsDays(t *testing.T) {
+	punches := []Punch{
+		punchAt("2026-03-02 19:50", PunchIn, "SN-1"),
+		punchAt("2026-03-03 06:05", PunchOut, "SN-1"),
+	}
+
+	// Without a shift, first-in/last-out groups by calendar date and the out is orphaned
+	assertSessions(t, PairPunches(punches, PairingRules{Mode: PairingFirstInLastOut}), "2026-03-02 19:50-open")
+}
+
+func TestPairingRules_BusinessDateInLocation(t *testing.T) {
+	sgt := time.FixedZone("SGT", 8*60*60)
+	lateUTC := time.Date(2026, 3, 1, 16, 30, 0, 0, time.UTC) // 00:30 on 2 March in Singapore
+
+	if got := (PairingRules{}).BusinessDate(lateUTC); got != "2026-03-01" {
+		t.Errorf("without a location BusinessDate = %s; want the punch's own calendar date 2026-03-01", got)
+	}
+	if got := (PairingRules{Location: sgt}).BusinessDate(lateUTC); got != "2026-03-02" {
+		t.Errorf("in SGT BusinessDate = %s; want 2026-03-02", got)
+	}
+
+	// The night shift's times are Singapore wall-clock, so 05:30 SGT belongs to the shift of 1 March
+	night := PairingRules{Location: sgt, Shifts: []Shift{nightShift}}
+	if got := night.BusinessDate(time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC)); got != "2026-03-01" {
+		t.Errorf("night shift BusinessDate = %s; want 2026-03-01", got)
+	}
+}
@@ -0,0 +1,65 @@
+package domain
+
+import "time"
+
+// Attendance submission statuses (attendance.status)
+const (
+	SubmissionStatusPending    = "pending"
+	SubmissionStatusSubmitting = "submitting" // claimed by a submission run until next_retry_at
+	SubmissionStatusSubmitted  = "submitted"
+	SubmissionStatusFailed     = "failed"      // transient failure, retried once next_retry_at has passed
+	SubmissionStatusDeadLetter = "dead_letter" // permanent failure or retries exhausted; needs manual requeue
+)
+
+// SubmissionClaimLease is how long a submission run holds the rows it claimed. Rows of a run that
+// died before recording an outcome become due again once it has passed.
+const SubmissionClaimLease = 30 * time.Minute
+
+// SubmissionRetryPolicy controls exponential backoff for failed CPD submissions
+type SubmissionRetryPolicy struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxAttempts int
+}
+
+// DefaultSubmissionRetryPolicy retries after 5m, 10m, 20m, ... capped at 6h, for up to 6 attempts
+var DefaultSubmissionRetryPolicy = SubmissionRetryPolicy{
+	BaseDelay:   5 * time.Minute,
+	MaxDelay:    6 * time.Hour,
+	MaxAttempts: 6,
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts (1-based)
+func (p SubmissionRetryPolicy) Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	d := p.BaseDelay
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	if d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// Exhausted reports whether no further retries are allowed after the given number of failed attempts
+func (p SubmissionRetryPolicy) Exhausted(attempts int) bool {
+	return attempts >= p.MaxAttempts
+}
+
+// DeadLetterSubmission is an attendance record that will not be submitted again without manual requeue
+type DeadLetterSubmission struct {
+	AttendanceID   string    `json:"attendance_id"`
+	WorkerID       string    `json:"worker_id"`
+	WorkerName     string    `json:"worker_name,omitempty"`
+	UserID         string    `json:"user_id"`
+	SubmissionDate string    `json:"submission_date"`
+	RetryCount     int       `json:"retry_count"`
+	ErrorMessage   string    `json:"error_message"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSubmissionRetryPolicy_Backoff(t *testing.T) {
+	p := SubmissionRetryPolicy{BaseDelay: 5 * time.Minute, MaxDelay: time.Hour, MaxAttempts: 4}
+
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 5 * time.Minute},
+		{2, 10 * time.Minute},
+		{3, 20 * time.Minute},
+		{4, 40 * time.Minute},
+		{5, time.Hour},
+		{50, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := p.Backoff(tt.attempts); got != tt.expected {
+			t.Errorf("Backoff(%d) = %v; want %v", tt.attempts, got, tt.expected)
+		}
+	}
+
+	if p.Exhausted(3) {
+		t.Errorf("Exhausted(3) = true; want false")
+	}
+	if !p.Exhausted(4) {
+		t.Errorf("Exhausted(4) = false; want true")
+	}
+}
@@ -1,5 +1,15 @@
 package domain
 
+import "time"
+
+// Live bridge connection states (bridge_connections.status)
+const (
+	BridgeConnected    = "connected"
+	BridgeDisconnected = "disconnected"
+)
+
+// User is a tenant organisation: it owns workers, sites, devices and bridge tokens.
+// The people who sign in on its behalf are Members.
 type User struct {
 	ID              string  `json:"user_id"`
 	Name            string  `json:"user_name"`
@@ -15,6 +25,13 @@ type User struct {
 	BridgeWSURL     *string `json:"bridge_ws_url,omitempty"`
 	BridgeAuthToken *string `json:"bridge_auth_token,omitempty"`
 	BridgeStatus    string  `json:"bridge_status"`
-	WorkerCount     int     `json:"worker_count,omitempty"`
-	DeviceCount     int     `json:"device_count,omitempty"`
+
+	// Live connection state reported by the bridge transport
+	BridgeConnection  string     `json:"bridge_connection"`
+	BridgeConnectedAt *time.Time `json:"bridge_connected_at,omitempty"`
+	BridgeLastSeen    *time.Time `json:"bridge_last_seen,omitempty"`
+	BridgeRemoteAddr  string     `json:"bridge_remote_addr,omitempty"`
+
+	WorkerCount int `json:"worker_count,omitempty"`
+	DeviceCount int `json:"device_count,omitempty"`
 }
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, k *domain.APIKey) error
+	Get(ctx context.Context, id string) (*domain.APIKey, error)
+	GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error)
+	ListByUser(ctx context.Context, userID string) ([]domain.APIKey, error)
+	// Revoke returns false when the key was already revoked.
+	Revoke(ctx context.Context, id string) (bool, error)
+	// TouchLastUsed records a use of the key. Writes are skipped while the stored time is recent,
+	// so busy integrations do not update the row on every request.
+	TouchLastUsed(ctx context.Context, id, ip string, now time.Time) error
+}
+
+// APIKeyService manages the API keys of an organisation. userID is always the organisation.
+type APIKeyService interface {
+	// CreateKey returns the plain key, which is only shown once. A nil expiresAt uses the default lifetime.
+	CreateKey(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*domain.APIKeyTicket, error)
+	ListKeys(ctx context.Context, userID string) ([]domain.APIKey, error)
+	RevokeKey(ctx context.Context, userID, id string) error
+}
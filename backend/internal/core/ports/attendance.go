@@ -3,26 +3,69 @@ package ports
 import (
 	"context"
 	"cpd-nexus/internal/core/domain"
+	"io"
 	"time"
 )
 
 type AttendanceRepository interface {
 	Get(ctx context.Context, userID, id string) (*domain.Attendance, error)
 	List(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Attendance, error)
-	Create(ctx context.Context, a *domain.Attendance) error
+	// Create numbers a record and stores it unless a record with the same worker, time_in and device
+	// already exists. Returns false when nothing was stored.
+	Create(ctx context.Context, a *domain.Attendance) (bool, error)
+	// CreateBatch numbers the records and creates them in one transaction: either all are stored or none.
+	CreateBatch(ctx context.Context, records []*domain.Attendance) error
 	GetMaxID(ctx context.Context, pattern string) (string, error)
-	// GenerateNextID atomically generates the next sequential attendance ID for a given day.
-	// This is implemented at the DB layer to be safe for multi-instance deployments.
-	GenerateNextID(ctx context.Context) (string, error)
-	Update(ctx context.Context, userID, id string, timeIn, timeOut *time.Time) error
+	// ListByWorkerDates returns a worker's attendance whose submission_date is one of dates.
+	ListByWorkerDates(ctx context.Context, workerID string, dates []string) ([]domain.Attendance, error)
+	// UpdateSession rewrites the time_out, open_until and direction of a device-derived session that
+	// has been neither submitted nor corrected.
+	UpdateSession(ctx context.Context, id string, timeOut, openUntil *time.Time, direction string) error
+	// Delete removes a device-derived record that has been neither submitted nor corrected. Returns
+	// false when nothing was removed.
+	Delete(ctx context.Context, id string) (bool, error)
 	ExtractPendingAttendance(ctx context.Context) ([]domain.AttendanceRow, error)
+	ExtractDueRetries(ctx context.Context) ([]domain.AttendanceRow, error)
 	ExtractPendingAttendanceByProject(ctx context.Context, userID, projectID string) ([]domain.AttendanceRow, error)
 	ExtractProjectsWithPendingAttendance(ctx context.Context, userID string) ([]domain.Project, error)
+	// ClaimForSubmission marks those of the given rows that are still due as submitting, so that
+	// concurrent submission runs never send the same row. Returns the IDs claimed by this call.
+	ClaimForSubmission(ctx context.Context, attendanceIDs []string) ([]string, error)
+	// ReleaseSubmissionClaims puts rows still marked submitting back to pending, or to failed if
+	// they were being retried. Rows whose outcome was recorded are left alone.
+	ReleaseSubmissionClaims(ctx context.Context, attendanceIDs []string) error
+	// MarkFlagged records that rows not recorded by a device were submitted under ManualPolicyFlag.
+	MarkFlagged(ctx context.Context, attendanceIDs []string) error
 }
 
 type AttendanceService interface {
 	GetAttendance(ctx context.Context, userID, id string) (*domain.Attendance, error)
 	ListAttendance(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Attendance, error)
-	ProcessBridgeAttendance(ctx context.Context, workerID string, timeIn, timeOut string, rawPayload []byte) error
-	UpdateAttendance(ctx context.Context, userID, id string, timeIn, timeOut *time.Time) error
+	ListPunches(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Punch, error)
+	// ProcessBridgeAttendance stores the punches the bridge reported for a worker and re-derives
+	// the attendance of the days they fall on.
+	ProcessBridgeAttendance(ctx context.Context, workerID string, punches []domain.BridgePunch) error
+	// RederiveAttendance re-runs the pairing engine over the punches between from and to (YYYY-MM-DD).
+	RederiveAttendance(ctx context.Context, userID, workerID, from, to string) (*domain.RederiveResult, error)
+}
+
+// AttendanceEntryService records attendance a device did not capture, e.g. from paper timesheets
+// kept during an outage. Each row is checked against the worker and project registry; nothing is
+// created unless every row is valid, and a dry run only reports what would be created.
+type AttendanceEntryService interface {
+	// CreateAttendance enters sessions by hand.
+	CreateAttendance(ctx context.Context, userID string, entries []domain.AttendanceEntry, dryRun bool) (*domain.AttendanceEntryResult, error)
+	// ImportAttendance enters the sessions of a CSV or XLSX timesheet; errors name its row numbers.
+	ImportAttendance(ctx context.Context, userID, filename string, file io.Reader, dryRun bool) (*domain.AttendanceEntryResult, error)
+}
+
+type PunchRepository interface {
+	// Create stores a punch unless one with the same (worker, punched_at, device) already exists.
+	// Returns true when a new row was inserted.
+	Create(ctx context.Context, p *domain.Punch) (bool, error)
+	List(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Punch, error)
+	// ListByWorker returns a worker's punches in [from, to), oldest first.
+	ListByWorker(ctx context.Context, workerID string, from, to time.Time) ([]domain.Punch, error)
+	// ListWorkerIDs returns the workers with punches in [from, to), optionally limited to workerID.
+	ListWorkerIDs(ctx context.Context, userID, workerID string, from, to time.Time) ([]string, error)
 }
@@ -6,5 +6,34 @@ import (
 )
 
 type AuthService interface {
-	Login(ctx context.Context, username, password string) (string, *domain.User, error)
+	// Login checks the password; members with MFA get a challenge to complete with VerifyMFA.
+	Login(ctx context.Context, username, password string) (*domain.LoginResult, error)
+	// CompleteExternalLogin signs in a member authenticated by single sign-on; MFA still applies.
+	CompleteExternalLogin(ctx context.Context, member *domain.Member) (*domain.LoginResult, error)
+	// Refresh exchanges a refresh token for a new token pair; the presented token is rotated out.
+	Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, *domain.Member, error)
+	// Logout revokes the session family of the presented refresh token, or of the caller's access token.
+	Logout(ctx context.Context, refreshToken string) error
+	// RevokeSessions signs out every login of an organisation, or a single member when memberID is set.
+	RevokeSessions(ctx context.Context, userID, memberID string) (int64, error)
+	CurrentMember(ctx context.Context) (*domain.Member, error)
+	// ChangePassword replaces the caller's password, signs out their other sessions and returns fresh tokens.
+	ChangePassword(ctx context.Context, currentPassword, newPassword string) (*domain.TokenPair, error)
+	// ResetPassword redeems a one-time reset token issued by an administrator.
+	ResetPassword(ctx context.Context, token, newPassword string) error
+
+	// ListLockouts and Unlock let vendors see and lift login lockouts of any account or source IP.
+	ListLockouts(ctx context.Context) ([]domain.LoginThrottle, error)
+	Unlock(ctx context.Context, scope, key string) error
+
+	// VerifyMFA completes a challenged login with a TOTP or recovery code.
+	VerifyMFA(ctx context.Context, challengeToken, code string) (*domain.TokenPair, *domain.Member, error)
+	// StartMFAEnrolment and ConfirmMFAEnrolment enrol an authenticator for the signed-in member,
+	// or for a member whose login challenge requires enrolment (challengeToken set).
+	StartMFAEnrolment(ctx context.Context, challengeToken string) (*domain.MFAEnrolment, error)
+	ConfirmMFAEnrolment(ctx context.Context, challengeToken, code string) (*domain.MFAConfirmation, error)
+	RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error)
+	DisableMFA(ctx context.Context, code string) error
+	// ResetMFA removes a member's authenticator after a lost device. Vendor only.
+	ResetMFA(ctx context.Context, userID, memberID string) error
 }
@@ -0,0 +1,82 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// HasPermission reports whether the caller in ctx may perform p.
+// System callers (see WithSystemCaller) may do anything; any other context without a user may do
+// nothing. A login, vendor or tenant, is limited to the permissions of its role, an API key to its
+// scopes, and a read-only impersonation to reading.
+func HasPermission(ctx context.Context, p domain.Permission) bool {
+	if imp := GetImpersonation(ctx); imp != nil && imp.ReadOnly && !domain.IsReadPermission(p) {
+		return false
+	}
+	if IsSystemCaller(ctx) {
+		return true
+	}
+	if GetUserID(ctx) == "" {
+		return false
+	}
+	if GetAPIKeyID(ctx) != "" {
+		return domain.ScopesInclude(GetAPIKeyScopes(ctx), p)
+	}
+	return domain.RoleHas(GetRole(ctx), p)
+}
+
+// IsVendorAdmin reports whether ctx is a vendor manager, the only login allowed on administrative
+// routes such as managing other organisations, revoking their sessions or impersonating them.
+func IsVendorAdmin(ctx context.Context) bool {
+	return IsVendor(ctx) && GetRole(ctx) == domain.RoleManager
+}
+
+// Authorize returns a permission-denied AppError when the caller lacks p.
+func Authorize(ctx context.Context, p domain.Permission) error {
+	if !HasPermission(ctx, p) {
+		return apperrors.NewPermissionDenied(fmt.Sprintf("permission %s required", p))
+	}
+	return nil
+}
+
+// AuthorizeSite checks p and, for site-scoped roles, that siteID is one of the caller's sites.
+// A vendor's site assignments belong to its own organisation, so they do not limit it here.
+func AuthorizeSite(ctx context.Context, p domain.Permission, siteID string) error {
+	if err := Authorize(ctx, p); err != nil {
+		return err
+	}
+	if IsVendor(ctx) || IsSystemCaller(ctx) || !domain.RoleIsSiteScoped(GetRole(ctx)) {
+		return nil
+	}
+	for _, id := range GetSiteIDs(ctx) {
+		if id == siteID {
+			return nil
+		}
+	}
+	return apperrors.NewPermissionDenied(fmt.Sprintf("site %s is not assigned to you", siteID))
+}
+
+// ScopeFor returns the scope a repository must filter by when a caller asks for tenantID's data.
+// An empty tenantID, or the caller's own organisation, means "everything the caller may see", so a
+// vendor passing its own ID gets its whole scope. Any other tenantID narrows the scope, and a tenant
+// outside it yields the empty scope. A context without a scope gets the empty scope; internal jobs
+// carry the global scope explicitly (see WithSystemCaller).
+func ScopeFor(ctx context.Context, tenantID string) domain.Scope {
+	scope, _ := GetScope(ctx)
+	if tenantID == "" || tenantID == GetUserID(ctx) {
+		return scope
+	}
+	return scope.Narrow(tenantID)
+}
+
+// AuthorizeTenant returns a permission-denied AppError when tenantID is outside the caller's scope.
+// A context without a scope is refused every tenant.
+func AuthorizeTenant(ctx context.Context, tenantID string) error {
+	if scope, _ := GetScope(ctx); !scope.Allows(tenantID) {
+		return apperrors.NewPermissionDenied(fmt.Sprintf("organisation %s is outside your scope", tenantID))
+	}
+	return nil
+}
@@ -1,6 +1,10 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+	"time"
+)
 
 type BridgeWorkerTask struct {
 	WorkerID string
@@ -20,4 +24,20 @@ type BridgeRepository interface {
 	GetWorkerOwnerID(ctx context.Context, workerID string) (string, error)
 	GetActiveBridges(ctx context.Context) ([]BridgeConfig, error)
 	LogBridgeInteraction(ctx context.Context, userID, action, requestID string, requestPayload, responsePayload []byte, statusCode int) error
+
+	// Live connection state (bridge_connections)
+	MarkBridgeConnected(ctx context.Context, userID, remoteAddr string, connectedAt time.Time) error
+	TouchBridgeConnection(ctx context.Context, userID string, lastSeen time.Time) error
+	MarkBridgeDisconnected(ctx context.Context, userID string, at time.Time) error
+	ResetBridgeConnections(ctx context.Context) error
+
+	// Outbox of commands waiting for an offline bridge (bridge_outbox)
+	// EnqueueBridgeCommand stores a command. A pending command for the same worker whose action is in
+	// replaces is overwritten with cmd instead, so the worker keeps a single queued command.
+	EnqueueBridgeCommand(ctx context.Context, cmd *domain.BridgeCommand, replaces []string) error
+	ListBridgeCommands(ctx context.Context, userID, status string) ([]domain.BridgeCommand, error)
+	MarkBridgeCommandSent(ctx context.Context, id int64) error
+	MarkBridgeCommandAttempt(ctx context.Context, id int64, errMsg string, maxAttempts int) error
+	ExpireBridgeCommands(ctx context.Context, now time.Time) (int64, error)
+	PurgeBridgeCommands(ctx context.Context, userID, status string) (int64, error)
 }
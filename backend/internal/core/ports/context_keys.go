@@ -1,14 +1,29 @@
 package ports
 
-import "context"
+import (
+	"context"
+
+	"cpd-nexus/internal/core/domain"
+)
 
 type ContextKey string
 
 const (
-	UserIDKey   ContextKey = "userID"
-	IsVendorKey ContextKey = "isVendor"
-	UsernameKey  ContextKey = "username"
-	IPAddressKey ContextKey = "ipAddress"
+	UserIDKey        ContextKey = "userID"
+	IsVendorKey      ContextKey = "isVendor"
+	UsernameKey      ContextKey = "username"
+	IPAddressKey     ContextKey = "ipAddress"
+	PeerAddressKey   ContextKey = "peerAddress"
+	MemberIDKey      ContextKey = "memberID"
+	RoleKey          ContextKey = "role"
+	SiteIDsKey       ContextKey = "siteIDs"
+	SessionIDKey     ContextKey = "sessionID"
+	UserAgentKey     ContextKey = "userAgent"
+	APIKeyIDKey      ContextKey = "apiKeyID"
+	ScopesKey        ContextKey = "apiKeyScopes"
+	ScopeKey         ContextKey = "tenantScope"
+	ImpersonationKey ContextKey = "impersonation"
+	SystemCallerKey  ContextKey = "systemCaller"
 )
 
 // GetUserID retrieves the userID from the context.
@@ -20,6 +35,7 @@ func GetUserID(ctx context.Context) string {
 }
 
 // IsVendor checks if the current context belongs to a system vendor or admin.
+// It grants vendor privileges only; which organisations the caller may see is its Scope.
 func IsVendor(ctx context.Context) bool {
 	if v, ok := ctx.Value(IsVendorKey).(bool); ok {
 		return v
@@ -42,3 +58,99 @@ func GetIPAddress(ctx context.Context) string {
 	}
 	return ""
 }
+
+// GetPeerAddress retrieves the address of the TCP peer, which differs from GetIPAddress behind a trusted proxy.
+func GetPeerAddress(ctx context.Context) string {
+	if v, ok := ctx.Value(PeerAddressKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// GetMemberID retrieves the individual login acting on behalf of the organisation in UserIDKey.
+func GetMemberID(ctx context.Context) string {
+	if v, ok := ctx.Value(MemberIDKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// GetRole retrieves the caller's role from the context.
+func GetRole(ctx context.Context) string {
+	if v, ok := ctx.Value(RoleKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// GetSiteIDs retrieves the sites a site-scoped caller (PIC) is assigned to.
+func GetSiteIDs(ctx context.Context) []string {
+	if v, ok := ctx.Value(SiteIDsKey).([]string); ok {
+		return v
+	}
+	return nil
+}
+
+// GetSessionID retrieves the refresh session the caller's access token was issued with.
+func GetSessionID(ctx context.Context) string {
+	if v, ok := ctx.Value(SessionIDKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// GetUserAgent retrieves the client's User-Agent from the context.
+func GetUserAgent(ctx context.Context) string {
+	if v, ok := ctx.Value(UserAgentKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// GetAPIKeyID retrieves the API key the request was authenticated with, if any.
+func GetAPIKeyID(ctx context.Context) string {
+	if v, ok := ctx.Value(APIKeyIDKey).(string); ok {
+		return v
+	}
+	return ""
+}
+
+// GetAPIKeyScopes retrieves the scopes granted to the caller's API key.
+func GetAPIKeyScopes(ctx context.Context) []domain.Permission {
+	if v, ok := ctx.Value(ScopesKey).([]domain.Permission); ok {
+		return v
+	}
+	return nil
+}
+
+// GetScope retrieves the tenant organisations the caller may see. ok is false for contexts
+// that were never scoped, which may see no organisation.
+func GetScope(ctx context.Context) (domain.Scope, bool) {
+	v, ok := ctx.Value(ScopeKey).(domain.Scope)
+	return v, ok
+}
+
+// GetImpersonation retrieves the impersonation session the request is made under, if any.
+// UserIDKey is then the impersonated tenant and MemberIDKey the vendor's support member.
+func GetImpersonation(ctx context.Context) *domain.Impersonation {
+	if v, ok := ctx.Value(ImpersonationKey).(*domain.Impersonation); ok {
+		return v
+	}
+	return nil
+}
+
+// WithSystemCaller marks ctx as a trusted internal caller: a scheduler, retry loop, sweeper or bridge
+// callback acting for no login. Contexts without a user are only authorized when marked this way.
+// It also gives ctx the global scope, which a caller may narrow by setting ScopeKey afterwards.
+func WithSystemCaller(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, SystemCallerKey, true)
+	return context.WithValue(ctx, ScopeKey, domain.GlobalScope())
+}
+
+// IsSystemCaller reports whether ctx was marked by WithSystemCaller.
+func IsSystemCaller(ctx context.Context) bool {
+	if v, ok := ctx.Value(SystemCallerKey).(bool); ok {
+		return v
+	}
+	return false
+}
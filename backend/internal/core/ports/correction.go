@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+	"time"
+)
+
+type CorrectionRepository interface {
+	Create(ctx context.Context, c *domain.AttendanceCorrection) error
+	Get(ctx context.Context, userID, id string) (*domain.AttendanceCorrection, error)
+	// List returns corrections, newest first, optionally limited to one attendance record and/or status.
+	List(ctx context.Context, userID, attendanceID, status string) ([]domain.AttendanceCorrection, error)
+	// Apply approves c in one transaction: the attendance record takes the proposed times if it
+	// still holds the original ones, rev is appended to its history and c is marked approved.
+	// A record or correction changed in the meantime yields a conflict.
+	Apply(ctx context.Context, c *domain.AttendanceCorrection, rev *domain.AttendanceRevision) error
+	// Reject marks a pending correction rejected.
+	Reject(ctx context.Context, c *domain.AttendanceCorrection) error
+	// ListRevisions returns the history of an attendance record, oldest first.
+	ListRevisions(ctx context.Context, userID, attendanceID string) ([]domain.AttendanceRevision, error)
+}
+
+type CorrectionService interface {
+	// RequestCorrection records a request to change the times of an attendance record. Records
+	// already submitted to CPD get an amendment, which re-submits the record once approved.
+	RequestCorrection(ctx context.Context, userID, attendanceID, reason string, timeIn, timeOut *time.Time) (*domain.AttendanceCorrection, error)
+	GetCorrection(ctx context.Context, userID, id string) (*domain.AttendanceCorrection, error)
+	ListCorrections(ctx context.Context, userID, attendanceID, status string) ([]domain.AttendanceCorrection, error)
+	ApproveCorrection(ctx context.Context, userID, id, note string) (*domain.AttendanceCorrection, error)
+	RejectCorrection(ctx context.Context, userID, id, note string) (*domain.AttendanceCorrection, error)
+	ListRevisions(ctx context.Context, userID, attendanceID string) ([]domain.AttendanceRevision, error)
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"cpd-nexus/internal/core/domain"
+	"time"
 )
 
 // DeviceRepository defines how to store and retrieve devices
@@ -18,6 +19,12 @@ type DeviceRepository interface {
 	// Bulk operations
 	AssignToUser(ctx context.Context, userID string, deviceIDs []string) error
 	AssignToSite(ctx context.Context, siteID string, deviceIDs []string) error
+
+	// Telemetry
+	UpdateTelemetry(ctx context.Context, deviceID string, status domain.DeviceStatus, battery *int, heartbeat time.Time) error
+	ListSilentDevices(ctx context.Context, cutoff time.Time) ([]domain.Device, error)
+	MarkOfflineIfSilent(ctx context.Context, deviceID string, cutoff time.Time) (bool, error)
+	RecordStatusChange(ctx context.Context, change *domain.DeviceStatusChange) error
 }
 
 // DeviceService defines the business logic for devices
@@ -30,4 +37,8 @@ type DeviceService interface {
 
 	AssignDevicesToUser(ctx context.Context, userID string, deviceIDs []string) error
 	AssignDevicesToSite(ctx context.Context, siteID string, deviceIDs []string) error
+
+	// Telemetry reported by the bridge
+	RecordHeartbeat(ctx context.Context, ownerID string, hb domain.DeviceHeartbeat) error
+	MarkSilentDevicesOffline(ctx context.Context, silence time.Duration) (int, error)
 }
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"cpd-nexus/internal/core/domain"
+	"encoding/json"
 )
 
 // PitstopOnBehalfConfig maps the onBehalfOf fields
@@ -30,8 +31,49 @@ type PitstopConfigResponse struct {
 	Produces []PitstopProduceConfig `json:"produces"`
 }
 
+// SubmissionPreviewFailure is a row that would not be submitted, with the reason
+type SubmissionPreviewFailure struct {
+	AttendanceID string `json:"attendance_id"`
+	Error        string `json:"error"`
+}
+
+// SubmissionPreviewGroup lists the rows a batch sends for one participant / on-behalf-of pair
+type SubmissionPreviewGroup struct {
+	ParticipantID   string   `json:"participant_id"`
+	ParticipantName string   `json:"participant_name"`
+	DataRefID       string   `json:"data_ref_id,omitempty"`
+	OnBehalfOfID    string   `json:"on_behalf_of_id,omitempty"`
+	AttendanceIDs   []string `json:"attendance_ids"`
+}
+
+// SubmissionPreviewBatch describes one push request exactly as it would be sent
+type SubmissionPreviewBatch struct {
+	Index         int                      `json:"index"`
+	ItemCount     int                      `json:"item_count"`
+	SizeBytes     int                      `json:"size_bytes"`
+	AttendanceIDs []string                 `json:"attendance_ids"`
+	Groups        []SubmissionPreviewGroup `json:"groups"`
+	OnBehalfOf    []string                 `json:"on_behalf_of"`
+	Request       json.RawMessage          `json:"request"`
+}
+
+// SubmissionPreview is the dry-run result of a submission: nothing is sent and no row is updated
+type SubmissionPreview struct {
+	DataElementID    string                     `json:"data_element_id"`
+	TotalRows        int                        `json:"total_rows"`
+	ValidRows        int                        `json:"valid_rows"`
+	MaxItemsPerBatch int                        `json:"max_items_per_batch"`
+	MaxPayloadBytes  int                        `json:"max_payload_bytes"`
+	Batches          []SubmissionPreviewBatch   `json:"batches"`
+	Failures         []SubmissionPreviewFailure `json:"validation_failures"`
+	Skipped          []SubmissionPreviewFailure `json:"skipped"`
+	// Flagged lists the rows sent that were entered manually or imported rather than recorded by a device
+	Flagged []string `json:"flagged"`
+}
+
 // ExternalSubmitter defines the interface for external Pitstop/SGBuildex submissions
 type ExternalSubmitter interface {
 	FetchPitstopConfig(ctx context.Context) (*PitstopConfigResponse, error)
 	SubmitManpowerUtilization(ctx context.Context, repo SubmissionRepository, settings *domain.SystemSettings, rows []domain.AttendanceRow) (int, int, error)
+	PreviewManpowerUtilization(ctx context.Context, settings *domain.SystemSettings, rows []domain.AttendanceRow) (*SubmissionPreview, error)
 }
@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+
+	"cpd-nexus/internal/core/domain"
+)
+
+type ImpersonationRepository interface {
+	Create(ctx context.Context, i *domain.Impersonation) error
+	Get(ctx context.Context, id string) (*domain.Impersonation, error)
+	// ListByTenant returns the most recent sessions on a tenant organisation, newest first.
+	ListByTenant(ctx context.Context, tenantUserID string, limit int) ([]domain.Impersonation, error)
+	// End returns false when the session had already ended.
+	End(ctx context.Context, id string) (bool, error)
+}
+
+// ImpersonationService lets vendor support staff act as a tenant organisation.
+type ImpersonationService interface {
+	// Start issues a time-limited token for tenantUserID. The session is read-only unless allowWrite
+	// is set; a zero ttlMinutes uses the default lifetime.
+	Start(ctx context.Context, tenantUserID, reason string, allowWrite bool, ttlMinutes int) (*domain.ImpersonationTicket, error)
+	// End stops the impersonation session the caller's token belongs to.
+	End(ctx context.Context) error
+	List(ctx context.Context, tenantUserID string) ([]domain.Impersonation, error)
+}
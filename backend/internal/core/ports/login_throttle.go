@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+	"time"
+)
+
+type LoginThrottleRepository interface {
+	Get(ctx context.Context, scope, key string) (*domain.LoginThrottle, error)
+	// RecordFailure counts a failed login and returns the updated record. The count restarts when
+	// the previous failure is older than window or an earlier lockout has expired.
+	RecordFailure(ctx context.Context, scope, key string, now time.Time, window time.Duration) (*domain.LoginThrottle, error)
+	Lock(ctx context.Context, scope, key string, until time.Time) error
+	// Clear forgets the failures of a scope/key; it returns false when there was nothing to clear.
+	Clear(ctx context.Context, scope, key string) (bool, error)
+	ListLocked(ctx context.Context, now time.Time) ([]domain.LoginThrottle, error)
+	PurgeStale(ctx context.Context, before time.Time) (int64, error)
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+)
+
+type MemberRepository interface {
+	Get(ctx context.Context, id string) (*domain.Member, error)
+	GetByUsername(ctx context.Context, username string) (*domain.Member, error)
+	ListByUser(ctx context.Context, userID string) ([]domain.Member, error)
+	Create(ctx context.Context, m *domain.Member) error
+	Update(ctx context.Context, m *domain.Member) error
+	SetRole(ctx context.Context, id, role string, siteIDs []string) error
+	TouchLastLogin(ctx context.Context, id string) error
+
+	// CreatePasswordReset stores a reset token and invalidates the member's earlier unused ones.
+	CreatePasswordReset(ctx context.Context, r *domain.PasswordReset) error
+	GetPasswordReset(ctx context.Context, tokenHash string) (*domain.PasswordReset, error)
+	// UsePasswordReset marks the token used; false means it was already used.
+	UsePasswordReset(ctx context.Context, tokenHash string) (bool, error)
+}
+
+// MemberService manages the logins of a tenant organisation. userID is always the organisation.
+type MemberService interface {
+	ListMembers(ctx context.Context, userID string) ([]domain.Member, error)
+	GetMember(ctx context.Context, userID, id string) (*domain.Member, error)
+	CreateMember(ctx context.Context, userID string, m *domain.Member, password string) error
+	UpdateMember(ctx context.Context, userID, id string, payload map[string]interface{}) error
+	DeactivateMember(ctx context.Context, userID, id string) error
+	AssignRole(ctx context.Context, userID, id, role string, siteIDs []string) error
+	// IssuePasswordReset creates a one-time token the member can use to set a new password.
+	IssuePasswordReset(ctx context.Context, userID, id string) (*domain.PasswordResetTicket, error)
+	// UnlockMember clears the member's failed login attempts and any lockout.
+	UnlockMember(ctx context.Context, userID, id string) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import "context"
+
+// MFARepository stores TOTP secrets and recovery codes. The MFA state is read with the member.
+type MFARepository interface {
+	// SetSecret stores a sealed secret; enabled is false while enrolment awaits its first code.
+	// An empty secret turns MFA off and deletes the recovery codes.
+	SetSecret(ctx context.Context, memberID, sealedSecret string, enabled bool) error
+	// UseStep records the time step of an accepted code. It returns false when that step or a
+	// later one was already used, so a code cannot be replayed.
+	UseStep(ctx context.Context, memberID string, step int64) (bool, error)
+
+	ReplaceRecoveryCodes(ctx context.Context, memberID string, codeHashes []string) error
+	// UseRecoveryCode spends an unused code; false means it does not exist or was used.
+	UseRecoveryCode(ctx context.Context, memberID, codeHash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, memberID string) (int, error)
+}
@@ -0,0 +1,51 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+)
+
+type OIDCRepository interface {
+	GetProvider(ctx context.Context, id string) (*domain.OIDCProvider, error)
+	// GetProviderByDomain returns the enabled provider that claims an e-mail domain.
+	GetProviderByDomain(ctx context.Context, domain string) (*domain.OIDCProvider, error)
+	ListProviders(ctx context.Context, userID string) ([]domain.OIDCProvider, error)
+	CreateProvider(ctx context.Context, p *domain.OIDCProvider) error
+	UpdateProvider(ctx context.Context, p *domain.OIDCProvider) error
+	DeleteProvider(ctx context.Context, id string) error
+
+	SaveState(ctx context.Context, s *domain.OIDCLoginState) error
+	// TakeState returns and deletes a login state, so each callback can only be used once.
+	TakeState(ctx context.Context, stateHash string) (*domain.OIDCLoginState, error)
+	PurgeStates(ctx context.Context, before time.Time) (int64, error)
+
+	// GetIdentity returns the member linked to an IdP subject, or "" when none is linked.
+	GetIdentity(ctx context.Context, providerID, subject string) (string, error)
+	LinkIdentity(ctx context.Context, providerID, subject, memberID string) error
+}
+
+// OIDCClient talks to identity providers.
+type OIDCClient interface {
+	// AuthorizationURL builds the redirect to the provider's login page.
+	AuthorizationURL(ctx context.Context, issuer string, req domain.OIDCAuthRequest) (string, error)
+	// Exchange redeems an authorization code and returns the claims of the verified ID token.
+	Exchange(ctx context.Context, issuer string, req domain.OIDCTokenRequest) (map[string]interface{}, error)
+}
+
+// OIDCService manages identity providers and runs the single sign-on flow.
+type OIDCService interface {
+	ListProviders(ctx context.Context, userID string) ([]domain.OIDCProvider, error)
+	// CreateProvider and UpdateProvider store clientSecret sealed; on update an empty secret keeps the current one.
+	CreateProvider(ctx context.Context, userID string, p *domain.OIDCProvider, clientSecret string) error
+	UpdateProvider(ctx context.Context, userID, id string, p *domain.OIDCProvider, clientSecret string) error
+	DeleteProvider(ctx context.Context, userID, id string) error
+
+	// Discover finds the provider for an e-mail address, so the login page can offer SSO.
+	Discover(ctx context.Context, email string) (*domain.OIDCProviderInfo, error)
+	// StartLogin returns the provider URL the browser is redirected to.
+	StartLogin(ctx context.Context, providerID, returnTo string) (string, error)
+	// CompleteLogin handles the provider's callback and signs the matching member in.
+	CompleteLogin(ctx context.Context, state, code string) (*domain.OIDCLogin, error)
+}
@@ -18,7 +18,11 @@ type PitstopService interface {
 	GetAuthorisations(ctx context.Context, userID string) ([]*domain.PitstopAuthorisation, error)
 	SyncConfig(ctx context.Context, userID string) error
 	TestSubmission(ctx context.Context, userID, projectID string) (int, int, error)
+	PreviewSubmission(ctx context.Context, userID, projectID string) (*SubmissionPreview, error)
 	GetProjectsWithPendingAttendance(ctx context.Context, userID string) ([]domain.Project, error)
 	SubmitPendingAttendance(ctx context.Context) error
+	RetryFailedSubmissions(ctx context.Context) (int, error)
+	ListDeadLetterSubmissions(ctx context.Context, userID string) ([]domain.DeadLetterSubmission, error)
+	RequeueSubmissions(ctx context.Context, userID string, attendanceIDs []string) (int64, error)
 	AssignOnBehalfOfToUser(ctx context.Context, userID string, onBehalfOfNames []string) error
 }
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+	"time"
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, s *domain.AuthSession) error
+	Get(ctx context.Context, id string) (*domain.AuthSession, error)
+	GetByTokenHash(ctx context.Context, hash string) (*domain.AuthSession, error)
+	// Rotate marks an active session as replaced. It returns false when the session was no longer
+	// active, i.e. another request already used the same refresh token.
+	Rotate(ctx context.Context, id, replacedBy string) (bool, error)
+
+	// The Revoke* methods revoke active sessions and add every unexpired access token issued
+	// from them to the revocation list. They return the number of sessions revoked.
+	RevokeFamily(ctx context.Context, familyID, reason string) (int64, error)
+	RevokeMember(ctx context.Context, memberID, reason string) (int64, error)
+	RevokeUser(ctx context.Context, userID, reason string) (int64, error)
+
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeExpired(ctx context.Context, before time.Time) (int64, error)
+}
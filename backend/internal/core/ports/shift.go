@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+
+	"cpd-nexus/internal/core/domain"
+)
+
+type ShiftRepository interface {
+	Get(ctx context.Context, userID, id string) (*domain.Shift, error)
+	// List returns shifts within the caller's scope on userID, optionally limited to a site or project.
+	List(ctx context.Context, userID, siteID, projectID string) ([]domain.Shift, error)
+	// ListApplicable returns the active shifts defined for siteID or projectID; see domain.ApplicableShifts.
+	ListApplicable(ctx context.Context, siteID, projectID string) ([]domain.Shift, error)
+	Create(ctx context.Context, s *domain.Shift) error
+	Update(ctx context.Context, s *domain.Shift) error
+	Delete(ctx context.Context, userID, id string) error
+}
+
+type ShiftService interface {
+	GetShift(ctx context.Context, userID, id string) (*domain.Shift, error)
+	ListShifts(ctx context.Context, userID, siteID, projectID string) ([]domain.Shift, error)
+	CreateShift(ctx context.Context, userID string, s *domain.Shift) error
+	UpdateShift(ctx context.Context, userID, id string, s *domain.Shift) error
+	DeleteShift(ctx context.Context, userID, id string) error
+}
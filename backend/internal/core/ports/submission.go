@@ -1,8 +1,17 @@
 package ports
 
-import "context"
+import (
+	"context"
+	"cpd-nexus/internal/core/domain"
+)
 
 type SubmissionRepository interface {
 	LogSubmission(ctx context.Context, dataElementID, internalID, status, payload, errorMessage string) error
 	UpdateAttendanceStatus(ctx context.Context, attendanceID, status, responsePayload, errorMessage string) error
+
+	// RecordAttendanceFailure counts a failed submission attempt. Retryable failures are scheduled
+	// with exponential backoff; permanent ones and those out of attempts move to dead_letter.
+	RecordAttendanceFailure(ctx context.Context, attendanceID, responsePayload, errorMessage string, retryable bool) error
+	ListDeadLetterAttendance(ctx context.Context, userID string) ([]domain.DeadLetterSubmission, error)
+	RequeueAttendance(ctx context.Context, userID string, attendanceIDs []string) (int64, error)
 }
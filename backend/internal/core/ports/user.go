@@ -9,9 +9,14 @@ type UserRepository interface {
 	Get(ctx context.Context, id string) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
 	List(ctx context.Context) ([]domain.User, error)
-	Create(ctx context.Context, user *domain.User) error
+	// Create stores the organisation and, when primary is given, its first login in one transaction.
+	// The primary login takes the organisation's ID.
+	Create(ctx context.Context, user *domain.User, primary *domain.Member) error
 	Update(ctx context.Context, user *domain.User) error
 	Delete(ctx context.Context, id string) error
+	// ListVendorTenants returns the organisations a vendor supports; none means it sees every organisation.
+	ListVendorTenants(ctx context.Context, vendorID string) ([]string, error)
+	SetVendorTenants(ctx context.Context, vendorID string, tenantIDs []string) error
 }
 
 type UserService interface {
@@ -20,4 +25,6 @@ type UserService interface {
 	CreateUser(ctx context.Context, user *domain.User, password string) error
 	UpdateUser(ctx context.Context, id string, payload map[string]interface{}) error
 	DeleteUser(ctx context.Context, id string) error
+	GetVendorTenants(ctx context.Context, vendorID string) ([]string, error)
+	SetVendorTenants(ctx context.Context, vendorID string, tenantIDs []string) error
 }
@@ -69,6 +69,7 @@ func (s *AnalyticsService) LogActivity(ctx context.Context, userID, action, targ
 
 	activity := map[string]interface{}{
 		"user_id":     ownerID,
+		"member_id":   ports.GetMemberID(ctx), // the individual login, when there is one
 		"user_name":   actorName,
 		"action":      action,
 		"target_type": targetType,
@@ -76,6 +77,12 @@ func (s *AnalyticsService) LogActivity(ctx context.Context, userID, action, targ
 		"details":     details,
 		"ip_address":  ports.GetIPAddress(ctx),
 	}
+	// Under impersonation the owner is the tenant and member_id the vendor's support member;
+	// keep the vendor organisation and the session so the entry names both identities.
+	if imp := ports.GetImpersonation(ctx); imp != nil {
+		activity["impersonator_user_id"] = imp.ActorUserID
+		activity["impersonation_id"] = imp.ID
+	}
 	return s.repo.LogActivity(ctx, activity)
 }
 
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/google/uuid"
+)
+
+// DefaultAPIKeyTTL applies when a key is created without an expiry
+const DefaultAPIKeyTTL = 365 * 24 * time.Hour
+
+// APIKeyService manages the machine-to-machine keys of a tenant organisation.
+// Every method requires api_keys:manage; vendors may manage any organisation.
+type APIKeyService struct {
+	repo      ports.APIKeyRepository
+	analytics ports.AnalyticsService
+}
+
+func NewAPIKeyService(repo ports.APIKeyRepository, analytics ports.AnalyticsService) ports.APIKeyService {
+	return &APIKeyService{repo: repo, analytics: analytics}
+}
+
+// CreateKey issues a key with the given scopes. Callers can only grant permissions they hold themselves.
+func (s *APIKeyService) CreateKey(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*domain.APIKeyTicket, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, apperrors.NewValidationError("name is required and must be at most 100 characters")
+	}
+	granted, err := parseScopes(ctx, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if expiresAt == nil {
+		expires := now.Add(DefaultAPIKeyTTL)
+		expiresAt = &expires
+	} else if !expiresAt.After(now) {
+		return nil, apperrors.NewValidationError("expires_at must be in the future")
+	}
+
+	secret, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key := domain.APIKeyPrefix + secret
+	k := &domain.APIKey{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:len(domain.APIKeyPrefix)+8],
+		KeyHash:   hashToken(key),
+		Scopes:    granted,
+		ExpiresAt: expiresAt,
+		CreatedBy: ports.GetMemberID(ctx),
+		CreatedAt: now,
+	}
+	if err := s.repo.Create(ctx, k); err != nil {
+		return nil, err
+	}
+
+	s.analytics.LogActivity(ctx, userID, "API Key Created", "api_key", k.ID,
+		fmt.Sprintf("Created API key %q (%s) with scopes %s", k.Name, k.Prefix, joinScopes(k.Scopes)))
+	return &domain.APIKeyTicket{APIKey: *k, Key: key}, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// RevokeKey disables a key immediately; the middleware rejects it from the next request on.
+func (s *APIKeyService) RevokeKey(ctx context.Context, userID, id string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	k, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if k == nil || k.UserID != userID {
+		return apperrors.NewNotFound("api key", id)
+	}
+
+	revoked, err := s.repo.Revoke(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		s.analytics.LogActivity(ctx, userID, "API Key Revoked", "api_key", id, fmt.Sprintf("Revoked API key %q (%s)", k.Name, k.Prefix))
+	}
+	return nil
+}
+
+func (s *APIKeyService) authorize(ctx context.Context, userID string) error {
+	if userID == "" {
+		return apperrors.NewValidationError("user_id is required")
+	}
+	return ports.Authorize(ctx, domain.PermAPIKeysManage)
+}
+
+// parseScopes validates and de-duplicates the requested scopes
+func parseScopes(ctx context.Context, scopes []string) ([]domain.Permission, error) {
+	if len(scopes) == 0 {
+		return nil, apperrors.NewValidationError("at least one scope is required")
+	}
+	granted := []domain.Permission{}
+	for _, raw := range scopes {
+		p, ok := domain.ParseAPIKeyScope(raw)
+		if !ok {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("invalid scope %q", raw))
+		}
+		if !ports.HasPermission(ctx, p) {
+			return nil, apperrors.NewPermissionDenied(fmt.Sprintf("cannot grant %s without holding it", p))
+		}
+		if !domain.ScopesInclude(granted, p) {
+			granted = append(granted, p)
+		}
+	}
+	return granted, nil
+}
+
+func joinScopes(scopes []domain.Permission) string {
+	names := make([]string, len(scopes))
+	for i, p := range scopes {
+		names[i] = string(p)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// apiKeyTestRepo is an in-memory APIKeyRepository
+type apiKeyTestRepo struct {
+	keys map[string]*domain.APIKey
+}
+
+func newAPIKeyTestRepo() *apiKeyTestRepo {
+	return &apiKeyTestRepo{keys: map[string]*domain.APIKey{}}
+}
+
+func (r *apiKeyTestRepo) Create(ctx context.Context, k *domain.APIKey) error {
+	cp := *k
+	r.keys[k.ID] = &cp
+	return nil
+}
+
+func (r *apiKeyTestRepo) Get(ctx context.Context, id string) (*domain.APIKey, error) {
+	return r.keys[id], nil
+}
+
+func (r *apiKeyTestRepo) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	for _, k := range r.keys {
+		if k.KeyHash == keyHash {
+			return k, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *apiKeyTestRepo) ListByUser(ctx context.Context, userID string) ([]domain.APIKey, error) {
+	keys := []domain.APIKey{}
+	for _, k := range r.keys {
+		if k.UserID == userID {
+			keys = append(keys, *k)
+		}
+	}
+	return keys, nil
+}
+
+func (r *apiKeyTestRepo) Revoke(ctx context.Context, id string) (bool, error) {
+	k := r.keys[id]
+	if k == nil || k.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	return true, nil
+}
+
+func (r *apiKeyTestRepo) TouchLastUsed(ctx context.Context, id, ip string, now time.Time) error {
+	return nil
+}
+
+func TestAPIKeyService_CreateKey(t *testing.T) {
+	repo := newAPIKeyTestRepo()
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, "u-1", "API Key Created", "api_key", mock.Anything, mock.Anything).Return(nil)
+	svc := NewAPIKeyService(repo, analytics)
+
+	ctx := memberContext("u-1", "m-1", domain.RoleManager)
+	ticket, err := svc.CreateKey(ctx, "u-1", " ERP ", []string{"read:attendance", "workers:write", "attendance:read"}, nil)
+	require.NoError(t, err)
+
+	assert.Regexp(t, "^"+domain.APIKeyPrefix, ticket.Key)
+	assert.Equal(t, "ERP", ticket.Name)
+	assert.Equal(t, []domain.Permission{domain.PermAttendanceRead, domain.PermWorkersWrite}, ticket.Scopes)
+	assert.Equal(t, "m-1", ticket.CreatedBy)
+	if assert.NotNil(t, ticket.ExpiresAt) {
+		assert.WithinDuration(t, time.Now().Add(DefaultAPIKeyTTL), *ticket.ExpiresAt, time.Minute)
+	}
+
+	// Only the hash is stored, and the key resolves back to its record
+	stored, _ := repo.GetByHash(context.Background(), hashToken(ticket.Key))
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, ticket.ID, stored.ID)
+		assert.NotContains(t, stored.KeyHash, ticket.Key)
+		assert.True(t, len(ticket.Key) > len(stored.Prefix) && ticket.Key[:len(stored.Prefix)] == stored.Prefix)
+	}
+	analytics.AssertExpectations(t)
+}
+
+func TestAPIKeyService_CreateKey_Validation(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name      string
+		keyName   string
+		scopes    []string
+		expiresAt *time.Time
+		wantErr   error
+	}{
+		{"missing name", "", []string{"attendance:read"}, nil, apperrors.ErrValidation},
+		{"no scopes", "ERP", nil, nil, apperrors.ErrValidation},
+		{"unknown scope", "ERP", []string{"read:payroll"}, nil, apperrors.ErrValidation},
+		{"members:manage is not grantable", "ERP", []string{"members:manage"}, nil, apperrors.ErrValidation},
+		{"expiry in the past", "ERP", []string{"attendance:read"}, &past, apperrors.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAPIKeyService(newAPIKeyTestRepo(), new(MockAnalyticsService))
+			_, err := svc.CreateKey(memberContext("u-1", "m-1", domain.RoleManager), "u-1", tt.keyName, tt.scopes, tt.expiresAt)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAPIKeyService_RequiresManagePermission(t *testing.T) {
+	svc := NewAPIKeyService(newAPIKeyTestRepo(), new(MockAnalyticsService))
+
+	_, err := svc.CreateKey(memberContext("u-1", "m-2", domain.RolePIC), "u-1", "ERP", []string{"attendance:read"}, nil)
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	// A key cannot mint further keys, whatever its scopes
+	keyCtx := context.WithValue(context.Background(), ports.UserIDKey, "u-1")
+	keyCtx = context.WithValue(keyCtx, ports.APIKeyIDKey, "k-1")
+	keyCtx = context.WithValue(keyCtx, ports.ScopesKey, domain.APIKeyScopes)
+	_, err = svc.ListKeys(keyCtx, "u-1")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+}
+
+func TestAPIKeyService_RevokeKey(t *testing.T) {
+	repo := newAPIKeyTestRepo()
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	svc := NewAPIKeyService(repo, analytics)
+	ctx := memberContext("u-1", "m-1", domain.RoleManager)
+
+	ticket, err := svc.CreateKey(ctx, "u-1", "ERP", []string{"attendance:read"}, nil)
+	require.NoError(t, err)
+
+	err = svc.RevokeKey(memberContext("u-2", "m-9", domain.RoleManager), "u-2", ticket.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound, "keys of other organisations are hidden")
+
+	require.NoError(t, svc.RevokeKey(ctx, "u-1", ticket.ID))
+	require.NoError(t, svc.RevokeKey(ctx, "u-1", ticket.ID))
+	assert.False(t, repo.keys[ticket.ID].Active(time.Now()))
+	analytics.AssertNumberOfCalls(t, "LogActivity", 2) // created, revoked once
+}
+
+func TestHasPermission_APIKeyScopes(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ports.UserIDKey, "u-1")
+	ctx = context.WithValue(ctx, ports.RoleKey, domain.RoleManager)
+	ctx = context.WithValue(ctx, ports.APIKeyIDKey, "k-1")
+	ctx = context.WithValue(ctx, ports.ScopesKey, []domain.Permission{domain.PermAttendanceRead})
+
+	assert.True(t, ports.HasPermission(ctx, domain.PermAttendanceRead))
+	assert.False(t, ports.HasPermission(ctx, domain.PermAttendanceWrite), "scopes replace role permissions")
+	assert.False(t, ports.HasPermission(ctx, domain.PermWorkersRead))
+}
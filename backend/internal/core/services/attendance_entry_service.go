@@ -0,0 +1,535 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/spreadsheet"
+	"cpd-nexus/internal/pkg/timeutil"
+)
+
+type AttendanceEntryService struct {
+	repo         ports.AttendanceRepository
+	workerRepo   ports.WorkerRepository
+	projectRepo  ports.ProjectRepository
+	shiftRepo    ports.ShiftRepository
+	settingsRepo ports.SettingsRepository
+	analytics    ports.AnalyticsService
+}
+
+func NewAttendanceEntryService(repo ports.AttendanceRepository, workerRepo ports.WorkerRepository, projectRepo ports.ProjectRepository, shiftRepo ports.ShiftRepository, settingsRepo ports.SettingsRepository, analytics ports.AnalyticsService) ports.AttendanceEntryService {
+	return &AttendanceEntryService{
+		repo:         repo,
+		workerRepo:   workerRepo,
+		projectRepo:  projectRepo,
+		shiftRepo:    shiftRepo,
+		settingsRepo: settingsRepo,
+		analytics:    analytics,
+	}
+}
+
+// entryRow is an entry and the row it is reported under.
+type entryRow struct {
+	row   int
+	entry domain.AttendanceEntry
+}
+
+func (s *AttendanceEntryService) CreateAttendance(ctx context.Context, userID string, entries []domain.AttendanceEntry, dryRun bool) (*domain.AttendanceEntryResult, error) {
+	if err := ports.Authorize(ctx, domain.PermAttendanceWrite); err != nil {
+		return nil, err
+	}
+	rows := make([]entryRow, len(entries))
+	for i, e := range entries {
+		rows[i] = entryRow{row: i + 1, entry: e}
+	}
+	return s.enter(ctx, userID, domain.AttendanceSourceManual, rows, nil, dryRun)
+}
+
+func (s *AttendanceEntryService) ImportAttendance(ctx context.Context, userID, filename string, file io.Reader, dryRun bool) (*domain.AttendanceEntryResult, error) {
+	if err := ports.Authorize(ctx, domain.PermAttendanceWrite); err != nil {
+		return nil, err
+	}
+	sheet, err := spreadsheet.Read(filename, file)
+	if err != nil {
+		return nil, apperrors.NewValidationError(err.Error())
+	}
+	rows, rowErrs, err := parseTimesheet(sheet)
+	if err != nil {
+		return nil, err
+	}
+	return s.enter(ctx, userID, domain.AttendanceSourceImport, rows, rowErrs, dryRun)
+}
+
+// enter validates every row and, unless any is invalid or this is a dry run, creates them all.
+// rowErrs are problems found before validation, such as unreadable cells.
+func (s *AttendanceEntryService) enter(ctx context.Context, userID, source string, rows []entryRow, rowErrs []domain.AttendanceEntryError, dryRun bool) (*domain.AttendanceEntryResult, error) {
+	total := len(rows) + len(rowErrs)
+	if total == 0 {
+		return nil, apperrors.NewValidationError("no attendance entries were given")
+	}
+	if total > domain.MaxAttendanceEntries {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("at most %d attendance entries can be entered at once", domain.MaxAttendanceEntries))
+	}
+
+	settings, err := s.settingsRepo.GetSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	v := &entryValidator{
+		service:  s,
+		userID:   userID,
+		maxHours: settings.MaxSessionHours,
+		now:      time.Now(),
+		workers:  make(map[string]*domain.Worker),
+		projects: make(map[string]*domain.Project),
+		rules:    make(map[string]domain.PairingRules),
+		batch:    make(map[string][]domain.Attendance),
+	}
+	if v.maxHours <= 0 {
+		v.maxHours = domain.DefaultPairingRules().MaxSessionHours
+	}
+	if v.base, err = pairingRules(ctx, s.settingsRepo); err != nil {
+		return nil, err
+	}
+
+	result := &domain.AttendanceEntryResult{
+		DryRun:  dryRun,
+		Source:  source,
+		Rows:    total,
+		Created: []string{},
+		Errors:  append([]domain.AttendanceEntryError{}, rowErrs...),
+	}
+	var records []*domain.Attendance
+	for _, r := range rows {
+		record, rowErr, err := v.validate(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		if rowErr != nil {
+			result.Errors = append(result.Errors, *rowErr)
+			continue
+		}
+		record.Source = source
+		records = append(records, record)
+	}
+	result.Valid = len(records)
+	sortEntryErrors(result.Errors)
+	if dryRun || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	action, verb := "Attendance Entered", "Entered"
+	if source == domain.AttendanceSourceImport {
+		action, verb = "Attendance Imported", "Imported"
+	}
+	// One transaction, so a failure part-way leaves nothing behind and the batch can simply be retried
+	if err := s.repo.CreateBatch(ctx, records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		result.Created = append(result.Created, record.ID)
+	}
+	s.analytics.LogActivity(ctx, userID, action, "attendance", result.Created[0],
+		fmt.Sprintf("%s %d attendance record(s), %s to %s", verb, len(result.Created), result.Created[0], result.Created[len(result.Created)-1]))
+	return result, nil
+}
+
+// entryValidator checks rows against the registry, caching lookups across a batch.
+type entryValidator struct {
+	service  *AttendanceEntryService
+	userID   string
+	maxHours int
+	now      time.Time
+	base     domain.PairingRules
+	workers  map[string]*domain.Worker
+	projects map[string]*domain.Project
+	rules    map[string]domain.PairingRules
+	// batch holds the rows of this batch already accepted, per worker
+	batch map[string][]domain.Attendance
+}
+
+// validate returns the record a row creates, or why it cannot be created. An error is returned
+// only for failures unrelated to the row, such as the database being unavailable.
+func (v *entryValidator) validate(ctx context.Context, r entryRow) (*domain.Attendance, *domain.AttendanceEntryError, error) {
+	refuse := func(field, msg string) (*domain.Attendance, *domain.AttendanceEntryError, error) {
+		return nil, &domain.AttendanceEntryError{Row: r.row, Field: field, Error: msg}, nil
+	}
+	e := r.entry
+
+	worker, field, err := v.worker(ctx, strings.TrimSpace(e.WorkerID), strings.ToUpper(strings.TrimSpace(e.PersonIDNo)))
+	if errors.Is(err, apperrors.ErrNotFound) || errors.Is(err, apperrors.ErrValidation) {
+		return refuse(field, errorMessage(err))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if worker.Status != domain.StatusActive {
+		return refuse(field, fmt.Sprintf("worker %s is not active", worker.ID))
+	}
+	if ports.AuthorizeSite(ctx, domain.PermAttendanceWrite, worker.SiteID) != nil {
+		return refuse(field, fmt.Sprintf("worker %s is at a site you cannot enter attendance for", worker.ID))
+	}
+
+	projectID := strings.TrimSpace(e.ProjectID)
+	if worker.CurrentProjectID == "" {
+		return refuse("project_id", fmt.Sprintf("worker %s is not assigned to a project", worker.ID))
+	}
+	if projectID != "" && projectID != worker.CurrentProjectID {
+		return refuse("project_id", fmt.Sprintf("worker %s is assigned to project %s, not %s", worker.ID, worker.CurrentProjectID, projectID))
+	}
+	project, err := v.project(ctx, worker.CurrentProjectID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return refuse("project_id", errorMessage(err))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if project.Status != domain.StatusActive {
+		return refuse("project_id", fmt.Sprintf("project %s is not active", project.ID))
+	}
+
+	if e.TimeIn == nil {
+		return refuse("time_in", "time_in is required")
+	}
+	if e.TimeOut == nil {
+		return refuse("time_out", "time_out is required")
+	}
+	// Attendance times are stored to the second
+	timeIn, timeOut := truncateToSecond(e.TimeIn), truncateToSecond(e.TimeOut)
+	if !timeOut.After(*timeIn) {
+		return refuse("time_out", "time_out must be after time_in")
+	}
+	if timeOut.Sub(*timeIn) > time.Duration(v.maxHours)*time.Hour {
+		return refuse("time_out", fmt.Sprintf("session is longer than %d hours", v.maxHours))
+	}
+	if timeOut.After(v.now) {
+		return refuse("time_out", "time_out is in the future")
+	}
+
+	rules, err := v.workerRules(ctx, worker)
+	if err != nil {
+		return nil, nil, err
+	}
+	date := rules.BusinessDate(*timeIn)
+	day, _ := time.ParseInLocation("2006-01-02", date, timeutil.BusinessLocation())
+	existing, err := v.service.repo.ListByWorkerDates(ctx, worker.ID, []string{
+		day.AddDate(0, 0, -1).Format("2006-01-02"), date, day.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, a := range append(existing, v.batch[worker.ID]...) {
+		if overlapsSession(*timeIn, *timeOut, a) {
+			if a.ID == "" {
+				return refuse("time_in", "overlaps an earlier row for the same worker")
+			}
+			return refuse("time_in", fmt.Sprintf("overlaps attendance %s of worker %s", a.ID, worker.ID))
+		}
+	}
+
+	record := &domain.Attendance{
+		DeviceID:       domain.AttendanceDeviceManual,
+		WorkerID:       worker.ID,
+		SiteID:         worker.SiteID,
+		UserID:         worker.UserID,
+		TimeIn:         timeIn,
+		TimeOut:        timeOut,
+		Direction:      domain.AttendanceDirectionEntry,
+		EnteredBy:      actorID(ctx),
+		EnteredByName:  ports.GetUsername(ctx),
+		TradeCode:      worker.PersonTrade,
+		Status:         domain.SubmissionStatusPending,
+		SubmissionDate: date,
+	}
+	v.batch[worker.ID] = append(v.batch[worker.ID], *record)
+	return record, nil, nil
+}
+
+// worker resolves a worker by ID, or else by person_id_no, within the caller's scope. The field
+// returned is the one errors about the worker are reported under.
+func (v *entryValidator) worker(ctx context.Context, workerID, personIDNo string) (*domain.Worker, string, error) {
+	field := "worker_id"
+	if workerID == "" {
+		if personIDNo == "" {
+			return nil, field, apperrors.NewValidationError("worker_id or person_id_no is required")
+		}
+		field = "person_id_no"
+		if w, ok := v.workers["fin:"+personIDNo]; ok {
+			return w, field, nil
+		}
+		// Look the number up across organisations, then load the worker within the caller's scope
+		w, err := v.service.workerRepo.GetByFIN(ctx, personIDNo)
+		if errors.Is(err, apperrors.ErrNotFound) || (err == nil && w == nil) {
+			return nil, field, apperrors.NewValidationError(fmt.Sprintf("no worker has person_id_no %s", personIDNo))
+		}
+		if err != nil {
+			return nil, field, err
+		}
+		workerID = w.ID
+	}
+
+	w, ok := v.workers[workerID]
+	if !ok {
+		var err error
+		w, err = v.service.workerRepo.Get(ctx, v.userID, workerID)
+		if errors.Is(err, apperrors.ErrNotFound) || (err == nil && w == nil) {
+			if field == "person_id_no" {
+				return nil, field, apperrors.NewValidationError(fmt.Sprintf("no worker has person_id_no %s", personIDNo))
+			}
+			return nil, field, apperrors.NewNotFound("worker", workerID)
+		}
+		if err != nil {
+			return nil, field, err
+		}
+		v.workers[workerID] = w
+	}
+	if personIDNo != "" {
+		if !strings.EqualFold(w.PersonIDNo, personIDNo) {
+			return nil, "person_id_no", apperrors.NewValidationError(fmt.Sprintf("person_id_no does not match worker %s", w.ID))
+		}
+		v.workers["fin:"+personIDNo] = w
+	}
+	return w, field, nil
+}
+
+func (v *entryValidator) project(ctx context.Context, id string) (*domain.Project, error) {
+	if p, ok := v.projects[id]; ok {
+		return p, nil
+	}
+	p, err := v.service.projectRepo.Get(ctx, v.userID, id)
+	if err != nil {
+		return nil, err
+	}
+	v.projects[id] = p
+	return p, nil
+}
+
+func (v *entryValidator) workerRules(ctx context.Context, worker *domain.Worker) (domain.PairingRules, error) {
+	if r, ok := v.rules[worker.ID]; ok {
+		return r, nil
+	}
+	r, err := workerRules(ctx, v.service.shiftRepo, v.base, worker)
+	if err != nil {
+		return r, err
+	}
+	v.rules[worker.ID] = r
+	return r, nil
+}
+
+// overlapsSession reports whether [in, out) overlaps a stored session. A session without a
+// time_out covers its time_in only.
+func overlapsSession(in, out time.Time, a domain.Attendance) bool {
+	if a.TimeIn == nil {
+		return false
+	}
+	if a.TimeOut == nil {
+		return !a.TimeIn.Before(in) && a.TimeIn.Before(out)
+	}
+	return in.Before(*a.TimeOut) && a.TimeIn.Before(out)
+}
+
+// errorMessage returns the message of an application error without its category.
+func errorMessage(err error) string {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		return appErr.Message
+	}
+	return err.Error()
+}
+
+func sortEntryErrors(errs []domain.AttendanceEntryError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+}
+
+// timesheetColumns maps the accepted header names of an imported timesheet to its fields.
+var timesheetColumns = map[string]string{
+	"worker_id":    "worker_id",
+	"worker":       "worker_id",
+	"person_id_no": "person_id_no",
+	"fin":          "person_id_no",
+	"nric":         "person_id_no",
+	"nric_fin":     "person_id_no",
+	"project_id":   "project_id",
+	"project":      "project_id",
+	"date":         "date",
+	"work_date":    "date",
+	"time_in":      "time_in",
+	"clock_in":     "time_in",
+	"in":           "time_in",
+	"time_out":     "time_out",
+	"clock_out":    "time_out",
+	"out":          "time_out",
+}
+
+// parseTimesheet reads entries from a sheet whose first non-blank row names its columns. time_in
+// and time_out hold a date and time, or only a time when a date column is present; a time_out
+// earlier than its time_in is then on the next day. Rows are numbered as in the sheet.
+func parseTimesheet(sheet *spreadsheet.Sheet) ([]entryRow, []domain.AttendanceEntryError, error) {
+	header := -1
+	for i, cells := range sheet.Rows {
+		if !blankRow(cells) {
+			header = i
+			break
+		}
+	}
+	if header < 0 {
+		return nil, nil, apperrors.NewValidationError("the file is empty")
+	}
+	cols := make(map[string]int)
+	for i, name := range sheet.Rows[header] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		name = strings.NewReplacer(" ", "_", "-", "_", "/", "_").Replace(name)
+		if field, ok := timesheetColumns[name]; ok {
+			if _, dup := cols[field]; dup {
+				return nil, nil, apperrors.NewValidationError(fmt.Sprintf("more than one column holds %s", field))
+			}
+			cols[field] = i
+		}
+	}
+	_, hasWorker := cols["worker_id"]
+	_, hasFIN := cols["person_id_no"]
+	_, hasIn := cols["time_in"]
+	_, hasOut := cols["time_out"]
+	if !(hasWorker || hasFIN) || !hasIn || !hasOut {
+		return nil, nil, apperrors.NewValidationError("the first row must name the columns: worker_id or person_id_no, time_in and time_out, optionally project_id and date")
+	}
+
+	var rows []entryRow
+	var errs []domain.AttendanceEntryError
+	for i := header + 1; i < len(sheet.Rows); i++ {
+		cells := sheet.Rows[i]
+		if blankRow(cells) {
+			continue
+		}
+		cell := func(field string) string {
+			if c, ok := cols[field]; ok && c < len(cells) {
+				return strings.TrimSpace(cells[c])
+			}
+			return ""
+		}
+		row := i + 1
+		entry := domain.AttendanceEntry{
+			WorkerID:   cell("worker_id"),
+			PersonIDNo: cell("person_id_no"),
+			ProjectID:  cell("project_id"),
+		}
+
+		var date *time.Time
+		if value := cell("date"); value != "" {
+			d, err := parseSheetDate(sheet, value)
+			if err != nil {
+				errs = append(errs, domain.AttendanceEntryError{Row: row, Field: "date", Error: err.Error()})
+				continue
+			}
+			date = &d
+		}
+		timeIn, inErr := parseSheetTime(sheet, cell("time_in"), date)
+		if inErr != nil {
+			errs = append(errs, domain.AttendanceEntryError{Row: row, Field: "time_in", Error: inErr.Error()})
+			continue
+		}
+		timeOut, outErr := parseSheetTime(sheet, cell("time_out"), date)
+		if outErr != nil {
+			errs = append(errs, domain.AttendanceEntryError{Row: row, Field: "time_out", Error: outErr.Error()})
+			continue
+		}
+		// A night worked from 22:00 to 06:00 is written against the date it started
+		if date != nil && timeIn != nil && timeOut != nil && isTimeOnly(cell("time_out")) && timeOut.Before(*timeIn) {
+			next := timeOut.AddDate(0, 0, 1)
+			timeOut = &next
+		}
+		entry.TimeIn, entry.TimeOut = timeIn, timeOut
+		rows = append(rows, entryRow{row: row, entry: entry})
+	}
+	if len(rows)+len(errs) == 0 {
+		return nil, nil, apperrors.NewValidationError("the file has no attendance rows")
+	}
+	return rows, errs, nil
+}
+
+// parseSheetDate reads a date cell: a spreadsheet date, YYYY-MM-DD or DD/MM/YYYY.
+func parseSheetDate(sheet *spreadsheet.Sheet, value string) (time.Time, error) {
+	loc := timeutil.BusinessLocation()
+	if serial, err := strconv.ParseFloat(value, 64); err == nil && serial >= 1 {
+		if t, ok := sheet.SerialTime(value, loc); ok {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc), nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02", "2/1/2006"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date; use YYYY-MM-DD", value)
+}
+
+// parseSheetTime reads a time_in or time_out cell. A time of day (HH:MM) needs the row's date;
+// dates and times without a zone are in the business timezone.
+func parseSheetTime(sheet *spreadsheet.Sheet, value string, date *time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	loc := timeutil.BusinessLocation()
+	if isTimeOnly(value) {
+		if date == nil {
+			return nil, fmt.Errorf("%q has no date; add one or a date column", value)
+		}
+		var clock time.Time
+		if t, ok := sheet.SerialTime(value, loc); ok {
+			clock = t
+		} else {
+			for _, layout := range []string{"15:04", "15:04:05"} {
+				var err error
+				if clock, err = time.Parse(layout, value); err == nil {
+					break
+				}
+			}
+		}
+		t := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, loc)
+		return &t, nil
+	}
+	if t, ok := sheet.SerialTime(value, loc); ok {
+		return &t, nil
+	}
+	if t, err := timeutil.ParseBusinessTime(value); err == nil {
+		return &t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2/1/2006 15:04", "2/1/2006 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a date and time; use YYYY-MM-DD HH:MM", value)
+}
+
+// isTimeOnly reports whether a cell holds a time of day without a date: HH:MM, or a spreadsheet
+// time, which is a fraction of a day.
+func isTimeOnly(value string) bool {
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		return serial >= 0 && serial < 1
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func blankRow(cells []string) bool {
+	for _, c := range cells {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeEntryAttendanceRepo keeps attendance in memory and numbers it like the database.
+type fakeEntryAttendanceRepo struct {
+	ports.AttendanceRepository
+	records []domain.Attendance
+	// failAt makes CreateBatch fail, storing nothing, for batches of at least this many records
+	failAt int
+}
+
+func (f *fakeEntryAttendanceRepo) CreateBatch(ctx context.Context, records []*domain.Attendance) error {
+	if f.failAt > 0 && len(records) >= f.failAt {
+		return errors.New("connection reset")
+	}
+	for _, a := range records {
+		a.ID = fmt.Sprintf("ATT-%04d", len(f.records)+1)
+		f.records = append(f.records, *a)
+	}
+	return nil
+}
+
+func (f *fakeEntryAttendanceRepo) ListByWorkerDates(ctx context.Context, workerID string, dates []string) ([]domain.Attendance, error) {
+	var out []domain.Attendance
+	for _, a := range f.records {
+		for _, d := range dates {
+			if a.WorkerID == workerID && a.SubmissionDate == d {
+				out = append(out, a)
+			}
+		}
+	}
+	return out, nil
+}
+
+type stubWorkerRepo struct {
+	ports.WorkerRepository
+	workers map[string]*domain.Worker
+}
+
+func (r *stubWorkerRepo) Get(ctx context.Context, userID, id string) (*domain.Worker, error) {
+	if w, ok := r.workers[id]; ok {
+		return w, nil
+	}
+	return nil, apperrors.NewNotFound("worker", id)
+}
+
+func (r *stubWorkerRepo) GetByFIN(ctx context.Context, fin string) (*domain.Worker, error) {
+	for _, w := range r.workers {
+		if w.PersonIDNo == fin {
+			return w, nil
+		}
+	}
+	return nil, apperrors.NewNotFound("worker", fin)
+}
+
+func newTestEntryService() (ports.AttendanceEntryService, *fakeEntryAttendanceRepo) {
+	repo := &fakeEntryAttendanceRepo{}
+	workers := &stubWorkerRepo{workers: map[string]*domain.Worker{
+		"w1": {ID: "w1", UserID: "user1", SiteID: "s1", Status: domain.StatusActive, CurrentProjectID: "p1", PersonIDNo: "S1234567D", PersonTrade: "2.3"},
+		"w2": {ID: "w2", UserID: "user1", SiteID: "s2", Status: domain.StatusActive, CurrentProjectID: "p2", PersonIDNo: "G7654321N"},
+		"w3": {ID: "w3", UserID: "user1", SiteID: "s1", Status: domain.StatusInactive, CurrentProjectID: "p1"},
+		"w4": {ID: "w4", UserID: "user1", SiteID: "s1", Status: domain.StatusActive, CurrentProjectID: "p3"},
+	}}
+	projects := &stubProjectRepo{projects: map[string]*domain.Project{
+		"p1": {ID: "p1", SiteID: "s1", Status: domain.StatusActive},
+		"p2": {ID: "p2", SiteID: "s2", Status: domain.StatusActive},
+		"p3": {ID: "p3", SiteID: "s1", Status: domain.StatusInactive},
+	}}
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return NewAttendanceEntryService(repo, workers, projects, &fakeShiftRepo{}, pairingSettings(domain.PairingFirstInLastOut), analytics), repo
+}
+
+func entry(workerID, in, out string) domain.AttendanceEntry {
+	e := domain.AttendanceEntry{WorkerID: workerID}
+	if in != "" {
+		t := mustTime(in)
+		e.TimeIn = &t
+	}
+	if out != "" {
+		t := mustTime(out)
+		e.TimeOut = &t
+	}
+	return e
+}
+
+func TestAttendanceEntryService_CreateAttendance(t *testing.T) {
+	svc, repo := newTestEntryService()
+	ctx := siteMemberContext("m-pic", domain.RolePIC, "s1")
+
+	result, err := svc.CreateAttendance(ctx, "user1", []domain.AttendanceEntry{
+		entry("w1", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"),
+		entry("w1", "2026-03-03T08:00:00+08:00", "2026-03-03T12:00:00+08:00"),
+	}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ATT-0001", "ATT-0002"}, result.Created)
+	assert.Empty(t, result.Errors)
+	if assert.Len(t, repo.records, 2) {
+		a := repo.records[0]
+		assert.Equal(t, domain.AttendanceSourceManual, a.Source)
+		assert.Equal(t, domain.AttendanceDeviceManual, a.DeviceID)
+		assert.Equal(t, "m-pic", a.EnteredBy)
+		assert.Equal(t, "s1", a.SiteID)
+		assert.Equal(t, "2.3", a.TradeCode)
+		assert.Equal(t, "2026-03-02", a.SubmissionDate)
+		assert.Equal(t, domain.SubmissionStatusPending, a.Status)
+	}
+}
+
+func TestAttendanceEntryService_CreateAttendance_RowErrors(t *testing.T) {
+	svc, repo := newTestEntryService()
+	ctx := siteMemberContext("m-pic", domain.RolePIC, "s1")
+	repo.records = []domain.Attendance{{ID: "ATT-0001", WorkerID: "w1", SubmissionDate: "2026-03-02",
+		TimeIn: ptrTime(mustTime("2026-03-02T07:00:00+08:00")), TimeOut: ptrTime(mustTime("2026-03-02T09:00:00+08:00"))}}
+	future := time.Now().Add(2 * time.Hour).Format(time.RFC3339)
+	wrongProject := entry("w1", "2026-03-04T08:00:00+08:00", "2026-03-04T17:00:00+08:00")
+	wrongProject.ProjectID = "p2"
+	wrongFIN := entry("w1", "2026-03-05T08:00:00+08:00", "2026-03-05T17:00:00+08:00")
+	wrongFIN.PersonIDNo = "G7654321N"
+
+	tests := []struct {
+		entry domain.AttendanceEntry
+		field string
+		error string
+	}{
+		{entry("w9", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"), "worker_id", "not found"},
+		{entry("", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"), "worker_id", "is required"},
+		{entry("w2", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"), "worker_id", "site you cannot"},
+		{entry("w3", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"), "worker_id", "not active"},
+		{entry("w4", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"), "project_id", "project p3 is not active"},
+		{wrongProject, "project_id", "assigned to project p1"},
+		{wrongFIN, "person_id_no", "does not match"},
+		{entry("w1", "2026-03-06T08:00:00+08:00", ""), "time_out", "required"},
+		{entry("w1", "2026-03-06T08:00:00+08:00", "2026-03-06T07:00:00+08:00"), "time_out", "after time_in"},
+		{entry("w1", "2026-03-06T08:00:00+08:00", "2026-03-07T08:00:00+08:00"), "time_out", "longer than 16 hours"},
+		{entry("w1", time.Now().Add(time.Hour).Format(time.RFC3339), future), "time_out", "future"},
+		{entry("w1", "2026-03-02T08:30:00+08:00", "2026-03-02T17:00:00+08:00"), "time_in", "overlaps attendance ATT-0001"},
+	}
+	var entries []domain.AttendanceEntry
+	for _, tt := range tests {
+		entries = append(entries, tt.entry)
+	}
+	// Valid on its own, but overlapping the row before it
+	entries = append(entries,
+		entry("w1", "2026-03-08T08:00:00+08:00", "2026-03-08T17:00:00+08:00"),
+		entry("w1", "2026-03-08T16:00:00+08:00", "2026-03-08T20:00:00+08:00"))
+
+	result, err := svc.CreateAttendance(ctx, "user1", entries, false)
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Created)
+	assert.Len(t, repo.records, 1, "nothing is created while any row is invalid")
+	assert.Equal(t, len(entries), result.Rows)
+	assert.Equal(t, 1, result.Valid)
+	if assert.Len(t, result.Errors, len(tests)+1) {
+		for i, tt := range tests {
+			got := result.Errors[i]
+			assert.Equal(t, i+1, got.Row)
+			assert.Equal(t, tt.field, got.Field, "row %d", i+1)
+			assert.Contains(t, got.Error, tt.error, "row %d", i+1)
+		}
+		last := result.Errors[len(tests)]
+		assert.Equal(t, len(entries), last.Row)
+		assert.Contains(t, last.Error, "earlier row")
+	}
+}
+
+func TestAttendanceEntryService_CreateAttendance_FailureStoresNothing(t *testing.T) {
+	svc, repo := newTestEntryService()
+	ctx := roleContext("user1", domain.RoleManager)
+	entries := []domain.AttendanceEntry{
+		entry("w1", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"),
+		entry("w1", "2026-03-03T08:00:00+08:00", "2026-03-03T17:00:00+08:00"),
+	}
+	repo.failAt = 2
+
+	_, err := svc.CreateAttendance(ctx, "user1", entries, false)
+	assert.Error(t, err)
+	assert.Empty(t, repo.records)
+
+	// The same batch goes through once the database recovers, without overlapping itself
+	repo.failAt = 0
+	result, err := svc.CreateAttendance(ctx, "user1", entries, false)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	assert.Len(t, result.Created, 2)
+}
+
+func TestAttendanceEntryService_CreateAttendance_DryRun(t *testing.T) {
+	svc, repo := newTestEntryService()
+
+	result, err := svc.CreateAttendance(roleContext("user1", domain.RoleManager), "user1", []domain.AttendanceEntry{
+		entry("w1", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"),
+		entry("w2", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00"),
+	}, true)
+
+	assert.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 2, result.Valid)
+	assert.Empty(t, result.Created)
+	assert.Empty(t, repo.records)
+}
+
+func TestAttendanceEntryService_CreateAttendance_Permissions(t *testing.T) {
+	svc, repo := newTestEntryService()
+	entries := []domain.AttendanceEntry{entry("w1", "2026-03-02T08:00:00+08:00", "2026-03-02T17:00:00+08:00")}
+
+	_, err := svc.CreateAttendance(roleContext("user1", domain.RoleViewer), "user1", entries, false)
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	// Vendor logins are held to their role too
+	vendorViewer := context.WithValue(roleContext("vendor-1", domain.RoleViewer), ports.IsVendorKey, true)
+	_, err = svc.CreateAttendance(vendorViewer, "user1", entries, false)
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	_, err = svc.CreateAttendance(roleContext("user1", domain.RoleManager), "user1", nil, false)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Empty(t, repo.records)
+}
+
+func TestAttendanceEntryService_ImportAttendance(t *testing.T) {
+	svc, repo := newTestEntryService()
+	ctx := roleContext("user1", domain.RoleManager)
+	csv := strings.Join([]string{
+		"FIN,Project,Date,Time In,Time Out",
+		"S1234567D,p1,2026-03-02,08:00,17:30",
+		"",
+		// Night work is written against the date it started
+		"G7654321N,,02/03/2026,22:00,06:00",
+		"S1234567D,,2026-03-03 08:00,2026-03-03 12:00",
+	}, "\n")
+
+	result, err := svc.ImportAttendance(ctx, "user1", "timesheet.csv", strings.NewReader(csv), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.AttendanceSourceImport, result.Source)
+	if assert.Len(t, result.Errors, 1) {
+		// The date column holds a time, so the row is refused without a lookup
+		assert.Equal(t, 5, result.Errors[0].Row)
+		assert.Equal(t, "date", result.Errors[0].Field)
+	}
+	assert.Empty(t, repo.records)
+
+	csv = strings.Join(strings.Split(csv, "\n")[:4], "\n")
+	result, err = svc.ImportAttendance(ctx, "user1", "timesheet.csv", strings.NewReader(csv), false)
+
+	assert.NoError(t, err)
+	assert.Empty(t, result.Errors)
+	if assert.Len(t, repo.records, 2) {
+		assert.Equal(t, "w1", repo.records[0].WorkerID)
+		assert.True(t, repo.records[0].TimeOut.Equal(mustTime("2026-03-02T17:30:00+08:00")))
+		night := repo.records[1]
+		assert.Equal(t, "w2", night.WorkerID)
+		assert.Equal(t, domain.AttendanceSourceImport, night.Source)
+		assert.Equal(t, "2026-03-02", night.SubmissionDate)
+		assert.True(t, night.TimeOut.Equal(mustTime("2026-03-03T06:00:00+08:00")))
+	}
+}
+
+func TestAttendanceEntryService_ImportAttendance_BadFile(t *testing.T) {
+	svc, _ := newTestEntryService()
+	ctx := roleContext("user1", domain.RoleManager)
+
+	for name, file := range map[string]string{
+		"timesheet.pdf": "%PDF",
+		"empty.csv":     "\n\n",
+		"columns.csv":   "name,hours\nJohn,8",
+		"header.csv":    "worker_id,time_in,time_out",
+	} {
+		_, err := svc.ImportAttendance(ctx, "user1", name, strings.NewReader(file), false)
+		assert.ErrorIs(t, err, apperrors.ErrValidation, name)
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -2,26 +2,37 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
+
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
-	"time"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/timeutil"
 )
 
 type AttendanceService struct {
-	repo       ports.AttendanceRepository
-	workerRepo ports.WorkerRepository
-	deviceRepo ports.DeviceRepository
-	analytics  ports.AnalyticsService
+	repo         ports.AttendanceRepository
+	punchRepo    ports.PunchRepository
+	shiftRepo    ports.ShiftRepository
+	workerRepo   ports.WorkerRepository
+	deviceRepo   ports.DeviceRepository
+	settingsRepo ports.SettingsRepository
+	analytics    ports.AnalyticsService
 	// No in-process state for ID generation — delegated to the DB for scale safety.
 }
 
-func NewAttendanceService(repo ports.AttendanceRepository, workerRepo ports.WorkerRepository, deviceRepo ports.DeviceRepository, analytics ports.AnalyticsService) ports.AttendanceService {
+func NewAttendanceService(repo ports.AttendanceRepository, punchRepo ports.PunchRepository, shiftRepo ports.ShiftRepository, workerRepo ports.WorkerRepository, deviceRepo ports.DeviceRepository, settingsRepo ports.SettingsRepository, analytics ports.AnalyticsService) ports.AttendanceService {
 	return &AttendanceService{
-		repo:       repo,
-		workerRepo: workerRepo,
-		deviceRepo: deviceRepo,
-		analytics:  analytics,
+		repo:         repo,
+		punchRepo:    punchRepo,
+		shiftRepo:    shiftRepo,
+		workerRepo:   workerRepo,
+		deviceRepo:   deviceRepo,
+		settingsRepo: settingsRepo,
+		analytics:    analytics,
 	}
 }
 
@@ -33,19 +44,11 @@ func (s *AttendanceService) ListAttendance(ctx context.Context, userID, siteID,
 	return s.repo.List(ctx, userID, siteID, workerID, date)
 }
 
-func (s *AttendanceService) UpdateAttendance(ctx context.Context, userID, id string, timeIn, timeOut *time.Time) error {
-	if id == "" {
-		return fmt.Errorf("attendance ID is required")
-	}
-
-	err := s.repo.Update(ctx, userID, id, timeIn, timeOut)
-	if err == nil {
-		s.analytics.LogActivity(ctx, userID, "Attendance Updated", "attendance", id, fmt.Sprintf("Updated attendance times for %s", id))
-	}
-	return err
+func (s *AttendanceService) ListPunches(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Punch, error) {
+	return s.punchRepo.List(ctx, userID, siteID, workerID, date)
 }
 
-func (s *AttendanceService) ProcessBridgeAttendance(ctx context.Context, workerID string, timeIn, timeOut string, rawPayload []byte) error {
+func (s *AttendanceService) ProcessBridgeAttendance(ctx context.Context, workerID string, reported []domain.BridgePunch) error {
 	// 1. Resolve Worker
 	// We use the internal workerID provided by the bridge (which we sent in the request)
 	worker, err := s.workerRepo.Get(ctx, "", workerID)
@@ -56,46 +59,290 @@ func (s *AttendanceService) ProcessBridgeAttendance(ctx context.Context, workerI
 		return fmt.Errorf("worker ID %s not found in the database", workerID)
 	}
 
-	// 2. Parse Times
-	tIn, err := time.Parse(time.RFC3339, timeIn)
+	// The worker's shifts decide which business date each punch counts towards
+	rules, err := pairingRules(ctx, s.settingsRepo)
+	if err != nil {
+		return err
+	}
+	if rules, err = workerRules(ctx, s.shiftRepo, rules, worker); err != nil {
+		return err
+	}
+
+	// 2. Store the raw punches. Unparseable ones are reported but do not hold back the rest,
+	// otherwise a single corrupt scan would block the worker's attendance on every re-fetch.
+	var errs []error
+	days := make(map[string]bool)
+	stored := 0
+	for _, r := range reported {
+		t, err := timeutil.ParseBusinessTime(r.Time)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid punch time %q for worker %s: %w", r.Time, workerID, err))
+			continue
+		}
+		punch := &domain.Punch{
+			WorkerID:   worker.ID,
+			SiteID:     worker.SiteID,
+			UserID:     worker.UserID,
+			DeviceSN:   r.DeviceSN,
+			Direction:  domain.NormalizePunchDirection(r.Direction),
+			PunchedAt:  t,
+			Source:     r.Source,
+			RawPayload: r.RawPayload,
+		}
+		if punch.DeviceSN == "" {
+			punch.DeviceSN = domain.AttendanceDeviceBridgeAggregated
+		}
+		if punch.Source == "" {
+			punch.Source = domain.PunchSourceDevice
+		}
+
+		// Re-fetches of the same window report the same scans; they are stored once
+		created, err := s.punchRepo.Create(ctx, punch)
+		if err != nil {
+			return fmt.Errorf("failed to store punch for worker %s: %w", workerID, err)
+		}
+		if created {
+			stored++
+			days[rules.BusinessDate(t)] = true
+		}
+	}
+
+	// 3. Re-derive the days that received new punches.
+	if stored > 0 {
+		result := &domain.RederiveResult{}
+		if err := s.rederiveWorker(ctx, worker, sortedDays(days), rules, result); err != nil {
+			return err
+		}
+		if result.Created > 0 {
+			s.analytics.LogActivity(ctx, worker.UserID, "Attendance Logged", "worker", worker.ID, fmt.Sprintf("%d attendance session(s) derived from %d punch(es) for %s at site %s", result.Created, stored, worker.Name, worker.SiteID))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RederiveAttendance re-runs the pairing engine with the current rules over every worker with
+// punches between from and to, for example after the pairing rules were changed.
+func (s *AttendanceService) RederiveAttendance(ctx context.Context, userID, workerID, from, to string) (*domain.RederiveResult, error) {
+	if err := ports.Authorize(ctx, domain.PermAttendanceWrite); err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation("2006-01-02", from, timeutil.BusinessLocation())
+	if err != nil {
+		return nil, apperrors.NewValidationError("from must be a date (YYYY-MM-DD)")
+	}
+	end, err := time.ParseInLocation("2006-01-02", to, timeutil.BusinessLocation())
+	if err != nil {
+		return nil, apperrors.NewValidationError("to must be a date (YYYY-MM-DD)")
+	}
+	if end.Before(start) {
+		return nil, apperrors.NewValidationError("to must not be before from")
+	}
+	if end.Sub(start) >= maxRederiveDays*24*time.Hour {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("at most %d days can be re-derived at once", maxRederiveDays))
+	}
+
+	var days []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		days = append(days, d.Format("2006-01-02"))
+	}
+
+	rules, err := pairingRules(ctx, s.settingsRepo)
 	if err != nil {
-		// Try fallback format if RFC3339 fails
-		tIn, err = time.Parse("2006-01-02T15:04:05", timeIn)
+		return nil, err
 	}
-	var tOutPtr *time.Time
-	if timeOut != "" {
-		tOut, err := time.Parse(time.RFC3339, timeOut)
+	workerIDs, err := s.punchRepo.ListWorkerIDs(ctx, userID, workerID, start.AddDate(0, 0, -1), end.AddDate(0, 0, 2))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.RederiveResult{}
+	for _, id := range workerIDs {
+		worker, err := s.workerRepo.Get(ctx, userID, id)
 		if err != nil {
-			tOut, _ = time.Parse("2006-01-02T15:04:05", timeOut)
+			return nil, err
+		}
+		if worker == nil {
+			continue
+		}
+		// PICs may only re-derive attendance of workers at their own sites
+		if domain.RoleIsSiteScoped(ports.GetRole(ctx)) && ports.AuthorizeSite(ctx, domain.PermAttendanceWrite, worker.SiteID) != nil {
+			continue
+		}
+		rules, err := workerRules(ctx, s.shiftRepo, rules, worker)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.rederiveWorker(ctx, worker, days, rules, result); err != nil {
+			return nil, err
 		}
-		tOutPtr = &tOut
+		result.Workers++
 	}
 
-	// 3. Generate attendance ID atomically at the DB layer (scale-safe)
-	attendanceID, err := s.repo.GenerateNextID(ctx)
+	s.analytics.LogActivity(ctx, userID, "Attendance Re-derived", "attendance", from+".."+to,
+		fmt.Sprintf("Re-derived %d day(s) for %d worker(s) with %s pairing: %d created, %d updated, %d removed, %d locked",
+			result.Days, result.Workers, rules.Mode, result.Created, result.Updated, result.Removed, result.LockedDays))
+	return result, nil
+}
+
+// rederiveWorker pairs a worker's punches and reconciles the derived sessions with the stored
+// attendance of each business day in days. A session matching an existing row on (time_in, device) updates
+// it in place, so its attendance_id and submission state survive; rows no longer produced by the
+// rules are removed. Days without punches are left alone, and so are days holding a row already
+// submitted to CPD or corrected by hand; those change only through the correction workflow.
+// Manually entered and imported rows are not derived from punches and are never reconciled.
+func (s *AttendanceService) rederiveWorker(ctx context.Context, worker *domain.Worker, days []string, rules domain.PairingRules, result *domain.RederiveResult) error {
+	if len(days) == 0 {
+		return nil
+	}
+	first, _ := time.ParseInLocation("2006-01-02", days[0], timeutil.BusinessLocation())
+	last, _ := time.ParseInLocation("2006-01-02", days[len(days)-1], timeutil.BusinessLocation())
+
+	// Widen the window by a day either side so sessions spanning midnight pair correctly
+	punches, err := s.punchRepo.ListByWorker(ctx, worker.ID, first.AddDate(0, 0, -1), last.AddDate(0, 0, 2))
+	if err != nil {
+		return err
+	}
+	punchDays := make(map[string]bool)
+	for _, p := range punches {
+		punchDays[rules.BusinessDate(p.PunchedAt)] = true
+	}
+	sessions := make(map[string][]domain.AttendanceSession)
+	for _, sess := range domain.PairPunches(punches, rules) {
+		sessions[sess.Date] = append(sessions[sess.Date], sess)
+	}
+
+	existing, err := s.repo.ListByWorkerDates(ctx, worker.ID, days)
+	if err != nil {
+		return err
+	}
+	rows := make(map[string][]domain.Attendance)
+	for _, a := range existing {
+		if domain.IsDeviceSource(a.Source) {
+			rows[a.SubmissionDate] = append(rows[a.SubmissionDate], a)
+		}
+	}
+
+	for _, day := range days {
+		if !punchDays[day] {
+			continue
+		}
+		result.Days++
+		if isLocked(rows[day]) {
+			result.LockedDays++
+			continue
+		}
+
+		matched := make(map[string]bool)
+		for _, sess := range sessions[day] {
+			row := matchSession(rows[day], sess, matched)
+			if row != nil {
+				matched[row.ID] = true
+				if !sameTime(row.TimeOut, sess.TimeOut) || !sameTime(row.OpenUntil, sess.OpenUntil) || row.Direction != sess.Direction {
+					if err := s.repo.UpdateSession(ctx, row.ID, sess.TimeOut, sess.OpenUntil, sess.Direction); err != nil {
+						return err
+					}
+					result.Updated++
+				}
+				continue
+			}
+
+			// The repository numbers the record, and skips it if the session is already stored
+			timeIn := sess.TimeIn
+			created, err := s.repo.Create(ctx, &domain.Attendance{
+				DeviceID:       sess.DeviceIn,
+				WorkerID:       worker.ID,
+				SiteID:         worker.SiteID,
+				UserID:         worker.UserID,
+				TimeIn:         &timeIn,
+				TimeOut:        sess.TimeOut,
+				OpenUntil:      sess.OpenUntil,
+				Direction:      sess.Direction,
+				Source:         domain.AttendanceSourceDevice,
+				TradeCode:      worker.PersonTrade,
+				Status:         domain.SubmissionStatusPending,
+				SubmissionDate: sess.Date,
+			})
+			if err != nil {
+				return err
+			}
+			if created {
+				result.Created++
+			}
+		}
+
+		for _, row := range rows[day] {
+			if matched[row.ID] {
+				continue
+			}
+			removed, err := s.repo.Delete(ctx, row.ID)
+			if err != nil {
+				return err
+			}
+			if removed {
+				result.Removed++
+			}
+		}
+	}
+	return nil
+}
+
+// pairingRules loads the pairing rules from the system settings. Dates are reckoned in the business timezone.
+func pairingRules(ctx context.Context, settingsRepo ports.SettingsRepository) (domain.PairingRules, error) {
+	settings, err := settingsRepo.GetSettings(ctx)
+	if err != nil {
+		return domain.PairingRules{}, fmt.Errorf("failed to load pairing rules: %w", err)
+	}
+	rules := settings.PairingRules()
+	rules.Location = timeutil.BusinessLocation()
+	return rules, nil
+}
+
+// workerRules adds the shifts governing the worker, those of its current project or else its site, to rules.
+func workerRules(ctx context.Context, shiftRepo ports.ShiftRepository, rules domain.PairingRules, worker *domain.Worker) (domain.PairingRules, error) {
+	shifts, err := shiftRepo.ListApplicable(ctx, worker.SiteID, worker.CurrentProjectID)
 	if err != nil {
-		return fmt.Errorf("failed to generate attendance ID: %w", err)
-	}
-
-	// 4. Create Attendance Record
-	attendance := &domain.Attendance{
-		ID:              attendanceID,
-		DeviceID:        "BRIDGE_AGGREGATED", // No single device ID for aggregated records
-		WorkerID:        worker.ID,
-		SiteID:          worker.SiteID,
-		UserID:          worker.UserID,
-		TimeIn:          &tIn,
-		TimeOut:         tOutPtr,
-		Direction:       "unknown",
-		TradeCode:       worker.PersonTrade,
-		Status:          "pending",
-		SubmissionDate:  tIn.Format("2006-01-02"),
-		ResponsePayload: string(rawPayload),
-	}
-
-	err = s.repo.Create(ctx, attendance)
-	if err == nil {
-		s.analytics.LogActivity(ctx, worker.UserID, "Attendance Logged", "worker", worker.ID, fmt.Sprintf("Aggregated attendance processed for %s at site %s", worker.Name, worker.SiteID))
-	}
-	return err
+		return rules, fmt.Errorf("failed to load shifts for worker %s: %w", worker.ID, err)
+	}
+	rules.Shifts = domain.ApplicableShifts(shifts, worker.CurrentProjectID)
+	return rules, nil
+}
+
+// maxRederiveDays bounds a single re-derivation request.
+const maxRederiveDays = 92
+
+// isLocked reports whether a day's rows include one re-derivation must not touch.
+func isLocked(rows []domain.Attendance) bool {
+	for _, a := range rows {
+		if a.Status == domain.SubmissionStatusSubmitted || a.Revision > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSession finds the unmatched row sharing the session's (time_in, device) natural key.
+func matchSession(rows []domain.Attendance, sess domain.AttendanceSession, matched map[string]bool) *domain.Attendance {
+	for i := range rows {
+		a := &rows[i]
+		if !matched[a.ID] && a.TimeIn != nil && a.TimeIn.Equal(sess.TimeIn) && a.DeviceID == sess.DeviceIn {
+			return a
+		}
+	}
+	return nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+func sortedDays(days map[string]bool) []string {
+	out := make([]string, 0, len(days))
+	for d := range days {
+		out = append(out, d)
+	}
+	sort.Strings(out)
+	return out
 }
@@ -0,0 +1,376 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakePunchRepo keeps punches in memory and, like the database, ignores repeats of a
+// (worker, punched_at, device) natural key.
+type fakePunchRepo struct {
+	punches []domain.Punch
+}
+
+func (f *fakePunchRepo) Create(ctx context.Context, p *domain.Punch) (bool, error) {
+	for _, existing := range f.punches {
+		if existing.WorkerID == p.WorkerID && existing.PunchedAt.Equal(p.PunchedAt) && existing.DeviceSN == p.DeviceSN {
+			return false, nil
+		}
+	}
+	p.ID = int64(len(f.punches) + 1)
+	f.punches = append(f.punches, *p)
+	return true, nil
+}
+
+func (f *fakePunchRepo) List(ctx context.Context, userID, siteID, workerID, date string) ([]domain.Punch, error) {
+	return f.punches, nil
+}
+
+func (f *fakePunchRepo) ListByWorker(ctx context.Context, workerID string, from, to time.Time) ([]domain.Punch, error) {
+	var out []domain.Punch
+	for _, p := range f.punches {
+		if p.WorkerID == workerID && !p.PunchedAt.Before(from) && p.PunchedAt.Before(to) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePunchRepo) ListWorkerIDs(ctx context.Context, userID, workerID string, from, to time.Time) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	for _, p := range f.punches {
+		if (workerID == "" || p.WorkerID == workerID) && !p.PunchedAt.Before(from) && p.PunchedAt.Before(to) && !seen[p.WorkerID] {
+			seen[p.WorkerID] = true
+			ids = append(ids, p.WorkerID)
+		}
+	}
+	return ids, nil
+}
+
+// fakeShiftRepo serves a fixed set of shifts to the pairing engine.
+type fakeShiftRepo struct {
+	ports.ShiftRepository
+	shifts []domain.Shift
+}
+
+func (f *fakeShiftRepo) ListApplicable(ctx context.Context, siteID, projectID string) ([]domain.Shift, error) {
+	var out []domain.Shift
+	for _, s := range f.shifts {
+		if (s.ProjectID == "" && s.SiteID == siteID) || (s.ProjectID != "" && s.ProjectID == projectID) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func pairingSettings(mode string) *MockSettingsRepository {
+	settings := new(MockSettingsRepository)
+	settings.On("GetSettings", mock.Anything).Return(&domain.SystemSettings{AttendancePairingMode: mode, PunchDebounceSeconds: 60, MaxSessionHours: 16}, nil)
+	return settings
+}
+
+func mustTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestAttendanceService_ProcessBridgeAttendance_DerivesSessionFromPunches(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := context.Background()
+
+	worker := &domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1", Name: "John", PersonTrade: "2.3"}
+	mockWorkerRepo.On("Get", ctx, "", "w1").Return(worker, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-01"}).Return(nil, nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(a *domain.Attendance) bool {
+		return a.WorkerID == "w1" &&
+			a.DeviceID == "SN-1" &&
+			a.Direction == domain.AttendanceDirectionEntry &&
+			a.SubmissionDate == "2026-03-01" &&
+			a.TimeOut != nil && a.TimeOut.Equal(mustTime("2026-03-01T17:45:00+08:00"))
+	})).Return(true, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Logged", "worker", "w1", mock.Anything).Return(nil)
+
+	err := svc.ProcessBridgeAttendance(ctx, "w1", []domain.BridgePunch{
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-01T08:30:00+08:00"},
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-01T08:30:20+08:00"}, // double tap, debounced
+		{DeviceSN: "SN-2", Direction: "out", Time: "2026-03-01T17:45:00+08:00"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, punches.punches, 3, "every raw scan is kept")
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	mockAnalytics.AssertExpectations(t)
+}
+
+func TestAttendanceService_ProcessBridgeAttendance_UsesBusinessDayBoundary(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := context.Background()
+
+	mockWorkerRepo.On("Get", ctx, "", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	// 16:30 UTC on 1 March is already 2 March in Singapore
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-02"}).Return(nil, nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(a *domain.Attendance) bool {
+		return a.SubmissionDate == "2026-03-02" &&
+			a.TimeIn.Equal(mustTime("2026-03-02T00:30:00+08:00")) &&
+			a.TimeOut != nil && a.TimeOut.Equal(mustTime("2026-03-02T09:00:00+08:00"))
+	})).Return(true, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Logged", "worker", "w1", mock.Anything).Return(nil)
+
+	err := svc.ProcessBridgeAttendance(ctx, "w1", []domain.BridgePunch{
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-01T16:30:00Z"},
+		{DeviceSN: "SN-1", Direction: "out", Time: "2026-03-02T09:00:00"}, // zone-less: Singapore wall-clock time
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttendanceService_ProcessBridgeAttendance_RefetchDoesNotRederive(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{punches: []domain.Punch{{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-01T08:30:00+08:00")}}}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := context.Background()
+
+	mockWorkerRepo.On("Get", ctx, "", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+
+	err := svc.ProcessBridgeAttendance(ctx, "w1", []domain.BridgePunch{{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-01T08:30:00+08:00"}})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "ListByWorkerDates", mock.Anything, mock.Anything, mock.Anything)
+	mockAnalytics.AssertNotCalled(t, "LogActivity")
+}
+
+func TestAttendanceService_ProcessBridgeAttendance_SessionAlreadyStored(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	svc := NewAttendanceService(mockRepo, &fakePunchRepo{}, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := context.Background()
+
+	mockWorkerRepo.On("Get", ctx, "", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-01"}).Return(nil, nil)
+	// A concurrent fetch stored the session first, so the repository keeps the existing row
+	mockRepo.On("Create", ctx, mock.Anything).Return(false, nil)
+
+	err := svc.ProcessBridgeAttendance(ctx, "w1", []domain.BridgePunch{{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-01T08:30:00+08:00"}})
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "Create", 1)
+	mockAnalytics.AssertNotCalled(t, "LogActivity")
+}
+
+func TestAttendanceService_ProcessBridgeAttendance_InvalidTimeIsSkipped(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := context.Background()
+
+	mockWorkerRepo.On("Get", ctx, "", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-01"}).Return(nil, nil)
+	mockRepo.On("Create", ctx, mock.Anything).Return(true, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Logged", "worker", "w1", mock.Anything).Return(nil)
+
+	err := svc.ProcessBridgeAttendance(ctx, "w1", []domain.BridgePunch{
+		{DeviceSN: "SN-1", Time: "not-a-time"},
+		{DeviceSN: "SN-1", Time: "2026-03-01T08:30:00+08:00"},
+	})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid punch time")
+	assert.Len(t, punches.punches, 1, "valid punches are stored despite the bad one")
+}
+
+func TestAttendanceService_ProcessBridgeAttendance_OvernightShiftUsesBusinessDate(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{}
+	shifts := &fakeShiftRepo{shifts: []domain.Shift{
+		{ID: "night", SiteID: "s1", StartTime: "20:00", EndTime: "06:00", GraceBeforeMinutes: 60, GraceAfterMinutes: 120, Status: domain.StatusActive},
+	}}
+	svc := NewAttendanceService(mockRepo, punches, shifts, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := context.Background()
+
+	mockWorkerRepo.On("Get", ctx, "", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-02", "2026-03-03"}).Return(nil, nil)
+	// The first night is complete and counts towards the day it started
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(a *domain.Attendance) bool {
+		return a.SubmissionDate == "2026-03-02" && a.TimeOut != nil && a.TimeOut.Equal(mustTime("2026-03-03T06:05:00+08:00")) && a.OpenUntil == nil
+	})).Return(true, nil).Once()
+	// The second night is in progress and stays open until its shift window ends
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(a *domain.Attendance) bool {
+		return a.SubmissionDate == "2026-03-03" && a.TimeOut == nil && a.OpenUntil != nil && a.OpenUntil.Equal(mustTime("2026-03-04T08:00:00+08:00"))
+	})).Return(true, nil).Once()
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Logged", "worker", "w1", mock.Anything).Return(nil)
+
+	err := svc.ProcessBridgeAttendance(ctx, "w1", []domain.BridgePunch{
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-02T19:50:00+08:00"},
+		{DeviceSN: "SN-1", Direction: "out", Time: "2026-03-03T06:05:00+08:00"},
+		{DeviceSN: "SN-1", Direction: "in", Time: "2026-03-03T19:55:00+08:00"},
+	})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAttendanceService_RederiveAttendance_ReconcilesWithStoredRows(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{punches: []domain.Punch{
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-02T08:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchOut, PunchedAt: mustTime("2026-03-02T12:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-02T13:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchOut, PunchedAt: mustTime("2026-03-02T17:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-03T08:00:00+08:00")},
+	}}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingPaired), mockAnalytics)
+	ctx := roleContext("user1", domain.RoleManager)
+
+	in1, out1 := mustTime("2026-03-02T08:00:00+08:00"), mustTime("2026-03-02T17:00:00+08:00")
+	in3 := mustTime("2026-03-03T08:00:00+08:00")
+	mockWorkerRepo.On("Get", ctx, "user1", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-02", "2026-03-03"}).Return([]domain.Attendance{
+		// Derived earlier under first-in/last-out: same opening punch, now closed at 12:00
+		{ID: "ATT-1", DeviceID: "SN-1", TimeIn: &in1, TimeOut: &out1, Direction: "entry", Status: "pending", SubmissionDate: "2026-03-02"},
+		// Already submitted, so the day is locked
+		{ID: "ATT-2", DeviceID: "SN-1", TimeIn: &in3, Direction: "entry", Status: "submitted", SubmissionDate: "2026-03-03"},
+	}, nil)
+	mockRepo.On("UpdateSession", ctx, "ATT-1", mock.MatchedBy(func(t *time.Time) bool { return t != nil && t.Equal(mustTime("2026-03-02T12:00:00+08:00")) }), (*time.Time)(nil), "entry").Return(nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(a *domain.Attendance) bool { return a.TimeIn.Equal(mustTime("2026-03-02T13:00:00+08:00")) })).Return(true, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Re-derived", "attendance", "2026-03-02..2026-03-03", mock.Anything).Return(nil)
+
+	result, err := svc.RederiveAttendance(ctx, "user1", "", "2026-03-02", "2026-03-03")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.RederiveResult{Workers: 1, Days: 2, Created: 1, Updated: 1, LockedDays: 1}, result)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAttendanceService_RederiveAttendance_RemovesSessionsNoLongerDerived(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{punches: []domain.Punch{
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-02T08:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchOut, PunchedAt: mustTime("2026-03-02T17:00:00+08:00")},
+	}}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := roleContext("user1", domain.RoleManager)
+
+	in, out := mustTime("2026-03-02T08:00:00+08:00"), mustTime("2026-03-02T17:00:00+08:00")
+	mockWorkerRepo.On("Get", ctx, "user1", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-02"}).Return([]domain.Attendance{
+		{ID: "ATT-1", DeviceID: "SN-1", TimeIn: &in, TimeOut: &out, Direction: "entry", Status: "pending", SubmissionDate: "2026-03-02"},
+		{ID: "ATT-LEGACY", DeviceID: domain.AttendanceDeviceBridgeAggregated, TimeIn: &in, TimeOut: &out, Direction: "unknown", Status: "failed", SubmissionDate: "2026-03-02"},
+	}, nil)
+	mockRepo.On("Delete", ctx, "ATT-LEGACY").Return(true, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Re-derived", "attendance", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := svc.RederiveAttendance(ctx, "user1", "w1", "2026-03-02", "2026-03-02")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Removed)
+	assert.Equal(t, 0, result.Created+result.Updated)
+}
+
+func TestAttendanceService_RederiveAttendance_LeavesCorrectedDaysAlone(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{punches: []domain.Punch{
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-02T08:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchOut, PunchedAt: mustTime("2026-03-02T17:00:00+08:00")},
+	}}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := roleContext("user1", domain.RoleManager)
+
+	// Corrected by hand to 07:30-17:00; re-deriving must not restore the punched 08:00
+	in, out := mustTime("2026-03-02T07:30:00+08:00"), mustTime("2026-03-02T17:00:00+08:00")
+	mockWorkerRepo.On("Get", ctx, "user1", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-02"}).Return([]domain.Attendance{
+		{ID: "ATT-1", DeviceID: "SN-1", TimeIn: &in, TimeOut: &out, Direction: "entry", Status: "pending", Revision: 1, SubmissionDate: "2026-03-02"},
+	}, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Re-derived", "attendance", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := svc.RederiveAttendance(ctx, "user1", "w1", "2026-03-02", "2026-03-02")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.LockedDays)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestAttendanceService_RederiveAttendance_IgnoresManualEntries(t *testing.T) {
+	mockRepo := new(MockAttendanceRepository)
+	mockWorkerRepo := new(MockWorkerRepository)
+	mockAnalytics := new(MockAnalyticsService)
+	punches := &fakePunchRepo{punches: []domain.Punch{
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchIn, PunchedAt: mustTime("2026-03-02T13:00:00+08:00")},
+		{WorkerID: "w1", DeviceSN: "SN-1", Direction: domain.PunchOut, PunchedAt: mustTime("2026-03-02T17:00:00+08:00")},
+	}}
+	svc := NewAttendanceService(mockRepo, punches, &fakeShiftRepo{}, mockWorkerRepo, nil, pairingSettings(domain.PairingFirstInLastOut), mockAnalytics)
+	ctx := roleContext("user1", domain.RoleManager)
+
+	// Entered from a paper timesheet while the terminal was down in the morning
+	in, out := mustTime("2026-03-02T08:00:00+08:00"), mustTime("2026-03-02T12:00:00+08:00")
+	mockWorkerRepo.On("Get", ctx, "user1", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", SiteID: "s1"}, nil)
+	mockRepo.On("ListByWorkerDates", ctx, "w1", []string{"2026-03-02"}).Return([]domain.Attendance{
+		{ID: "ATT-1", DeviceID: domain.AttendanceDeviceManual, TimeIn: &in, TimeOut: &out, Direction: "entry", Source: domain.AttendanceSourceManual, Status: "pending", SubmissionDate: "2026-03-02"},
+	}, nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(a *domain.Attendance) bool { return a.Source == domain.AttendanceSourceDevice })).Return(true, nil)
+	mockAnalytics.On("LogActivity", ctx, "user1", "Attendance Re-derived", "attendance", mock.Anything, mock.Anything).Return(nil)
+
+	result, err := svc.RederiveAttendance(ctx, "user1", "w1", "2026-03-02", "2026-03-02")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 0, result.LockedDays)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "UpdateSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAttendanceService_RederiveAttendance_Validation(t *testing.T) {
+	svc := NewAttendanceService(nil, &fakePunchRepo{}, nil, nil, nil, nil, nil)
+	ctx := roleContext("user1", domain.RoleManager)
+
+	_, err := svc.RederiveAttendance(ctx, "user1", "", "2026-03-05", "2026-03-01")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	_, err = svc.RederiveAttendance(ctx, "user1", "", "2026-01-01", "2026-12-31")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	_, err = svc.RederiveAttendance(roleContext("user1", domain.RoleViewer), "user1", "", "2026-03-01", "2026-03-01")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+}
+
+func roleContext(userID, role string, siteIDs ...string) context.Context {
+	ctx := context.WithValue(context.Background(), ports.UserIDKey, userID)
+	ctx = context.WithValue(ctx, ports.RoleKey, role)
+	return context.WithValue(ctx, ports.SiteIDsKey, siteIDs)
+}
@@ -2,53 +2,434 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/logger"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Default token lifetimes. Access tokens stay short to limit stolen-token blast radius;
+// refresh tokens keep the login alive and are rotated on every use.
+const (
+	DefaultAccessTokenTTL  = 2 * time.Hour
+	DefaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+var errInvalidSession = apperrors.NewUnauthorized("invalid or expired session")
+
+// AuthConfig holds the token settings of the AuthService. Zero TTLs fall back to the defaults.
+type AuthConfig struct {
+	JWTSecret  string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	Passwords  PasswordConfig
+	Throttle   domain.LoginThrottlePolicy
+	MFA        MFAConfig
+}
+
 type AuthService struct {
-	repo             ports.UserRepository
-	jwtSecret        string
+	members          ports.MemberRepository
+	sessions         ports.SessionRepository
+	throttles        ports.LoginThrottleRepository
+	mfa              ports.MFARepository
+	cfg              AuthConfig
 	analyticsService ports.AnalyticsService
 }
 
-func NewAuthService(repo ports.UserRepository, jwtSecret string, analytics ports.AnalyticsService) ports.AuthService {
-	return &AuthService{repo: repo, jwtSecret: jwtSecret, analyticsService: analytics}
+func NewAuthService(members ports.MemberRepository, sessions ports.SessionRepository, throttles ports.LoginThrottleRepository, mfa ports.MFARepository, cfg AuthConfig, analytics ports.AnalyticsService) ports.AuthService {
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = DefaultAccessTokenTTL
+	}
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = DefaultRefreshTokenTTL
+	}
+	if cfg.MFA.ChallengeTTL <= 0 {
+		cfg.MFA.ChallengeTTL = DefaultMFAChallengeTTL
+	}
+	return &AuthService{members: members, sessions: sessions, throttles: throttles, mfa: mfa, cfg: cfg, analyticsService: analytics}
 }
 
-func (s *AuthService) Login(ctx context.Context, username, password string) (string, *domain.User, error) {
-	user, err := s.repo.GetByUsername(ctx, username)
+// Login authenticates an individual member. The token's user_id is the member's organisation,
+// so every tenant-scoped query keeps working; member_id identifies the person.
+// Members with MFA, or whose organisation requires it, get a short-lived challenge instead of tokens.
+func (s *AuthService) Login(ctx context.Context, username, password string) (*domain.LoginResult, error) {
+	// Throttled callers are turned away before the password is checked, so guesses made while
+	// locked out reveal nothing
+	accountKey := strings.ToLower(strings.TrimSpace(username))
+	if err := s.checkThrottle(ctx, accountKey); err != nil {
+		return nil, err
+	}
+
+	member, err := s.members.GetByUsername(ctx, username)
 	if err != nil {
-		return "", nil, err
+		return nil, err
 	}
-	if user == nil {
-		return "", nil, errors.New("invalid credentials")
+	if member == nil || member.Status != domain.StatusActive || member.OrgStatus != domain.StatusActive {
+		s.recordLoginFailure(ctx, accountKey, member)
+		return nil, errors.New("invalid credentials")
 	}
 
 	// Verify password using bcrypt
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", nil, errors.New("invalid credentials")
+	if err := bcrypt.CompareHashAndPassword([]byte(member.PasswordHash), []byte(password)); err != nil {
+		s.recordLoginFailure(ctx, accountKey, member)
+		return nil, errors.New("invalid credentials")
+	}
+
+	// Accounts still on the shared default password must pick their own before doing anything else
+	if s.cfg.Passwords.DefaultPassword != "" && password == s.cfg.Passwords.DefaultPassword && !member.MustChangePassword {
+		member.MustChangePassword = true
+		if err := s.members.Update(ctx, member); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.firstFactorPassed(ctx, member)
+}
+
+// CompleteExternalLogin signs in a member whose identity was verified by their organisation's
+// identity provider. The MFA policy applies exactly as after a password.
+func (s *AuthService) CompleteExternalLogin(ctx context.Context, member *domain.Member) (*domain.LoginResult, error) {
+	if member == nil || member.Status != domain.StatusActive || member.OrgStatus != domain.StatusActive {
+		return nil, apperrors.NewUnauthorized("this login is not active")
+	}
+	return s.firstFactorPassed(ctx, member)
+}
+
+// firstFactorPassed issues tokens, or an MFA challenge when the member needs a second factor.
+func (s *AuthService) firstFactorPassed(ctx context.Context, member *domain.Member) (*domain.LoginResult, error) {
+	// The account throttle is only cleared once the second factor passes, so codes cannot be
+	// guessed without limit behind a known password
+	if member.MFAEnabled || s.cfg.MFA.required(member) {
+		challenge, err := s.issueChallenge(member)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LoginResult{Member: member, Challenge: challenge}, nil
+	}
+
+	tokens, err := s.completeLogin(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LoginResult{Member: member, Tokens: tokens}, nil
+}
+
+// completeLogin starts a new session family once every factor has been verified.
+func (s *AuthService) completeLogin(ctx context.Context, member *domain.Member) (*domain.TokenPair, error) {
+	s.clearThrottle(ctx, domain.ThrottleScopeAccount, strings.ToLower(member.Username))
+
+	tokens, _, err := s.startSession(ctx, member, uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.members.TouchLastLogin(ctx, member.ID); err != nil {
+		logger.Errorf("[AuthService] Failed to record last login for %s: %v", member.ID, err)
+	}
+
+	// The request is not authenticated yet, so attribute the audit entry to the member explicitly
+	s.analyticsService.LogActivity(loginContext(ctx, member), member.UserID, "Login", "user", member.ID, "User logged in to the system")
+	return tokens, nil
+}
+
+// Refresh rotates a refresh token. A token that was already rotated is being replayed, which means
+// it leaked: the whole session family is revoked so neither the thief nor the owner can continue.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, *domain.Member, error) {
+	if refreshToken == "" {
+		return nil, nil, errInvalidSession
+	}
+	session, err := s.sessions.GetByTokenHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, nil, err
+	}
+	if session == nil {
+		return nil, nil, errInvalidSession
+	}
+
+	switch {
+	case session.Status == domain.SessionRotated:
+		s.revokeReusedFamily(ctx, session)
+		return nil, nil, errInvalidSession
+	case session.Status != domain.SessionActive, time.Now().After(session.ExpiresAt):
+		return nil, nil, errInvalidSession
+	}
+
+	member, err := s.members.Get(ctx, session.MemberID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if member == nil || member.UserID != session.UserID || member.Status != domain.StatusActive || member.OrgStatus != domain.StatusActive {
+		if _, err := s.sessions.RevokeFamily(ctx, session.FamilyID, "login deactivated"); err != nil {
+			logger.Errorf("[AuthService] Failed to revoke sessions of inactive member %s: %v", session.MemberID, err)
+		}
+		return nil, nil, errInvalidSession
+	}
+
+	tokens, next, err := s.startSession(ctx, member, session.FamilyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	rotated, err := s.sessions.Rotate(ctx, session.ID, next.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !rotated {
+		// A concurrent request rotated the same token first
+		s.revokeReusedFamily(ctx, session)
+		return nil, nil, errInvalidSession
+	}
+	return tokens, member, nil
+}
+
+// Logout ends the caller's session family. The refresh token identifies it when presented,
+// otherwise the session ID carried in the access token is used.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	var session *domain.AuthSession
+	var err error
+	if refreshToken != "" {
+		session, err = s.sessions.GetByTokenHash(ctx, hashToken(refreshToken))
+	} else if sid := ports.GetSessionID(ctx); sid != "" {
+		session, err = s.sessions.Get(ctx, sid)
+	}
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+
+	if _, err := s.sessions.RevokeFamily(ctx, session.FamilyID, "logout"); err != nil {
+		return err
+	}
+	s.analyticsService.LogActivity(ctx, session.UserID, "Logout", "user", session.MemberID, "User logged out")
+	return nil
+}
+
+// RevokeSessions signs out an organisation's logins, e.g. after a suspected compromise. Vendor only.
+func (s *AuthService) RevokeSessions(ctx context.Context, userID, memberID string) (int64, error) {
+	if !ports.IsVendorAdmin(ctx) {
+		return 0, apperrors.NewPermissionDenied("only administrators can revoke sessions")
+	}
+
+	var n int64
+	var err error
+	if memberID != "" {
+		member, err := s.members.Get(ctx, memberID)
+		if err != nil {
+			return 0, err
+		}
+		if member == nil || member.UserID != userID {
+			return 0, apperrors.NewNotFound("member", memberID)
+		}
+		n, err = s.sessions.RevokeMember(ctx, memberID, "revoked by administrator")
+		if err != nil {
+			return 0, err
+		}
+		s.analyticsService.LogActivity(ctx, userID, "Sessions Revoked", "member", memberID, fmt.Sprintf("Revoked %d sessions of login %s", n, member.Username))
+		return n, nil
+	}
+
+	n, err = s.sessions.RevokeUser(ctx, userID, "revoked by administrator")
+	if err != nil {
+		return 0, err
+	}
+	s.analyticsService.LogActivity(ctx, userID, "Sessions Revoked", "user", userID, fmt.Sprintf("Revoked %d sessions", n))
+	return n, nil
+}
+
+// CurrentMember returns the member login making the request.
+func (s *AuthService) CurrentMember(ctx context.Context) (*domain.Member, error) {
+	memberID := ports.GetMemberID(ctx)
+	if memberID == "" {
+		return nil, apperrors.NewPermissionDenied("no member login in context")
+	}
+	member, err := s.members.Get(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil {
+		return nil, apperrors.NewNotFound("member", memberID)
+	}
+	return member, nil
+}
+
+// ChangePassword verifies the current password, applies the policy and clears must_change_password.
+// All sessions of the member are revoked; the caller continues with the returned tokens.
+func (s *AuthService) ChangePassword(ctx context.Context, currentPassword, newPassword string) (*domain.TokenPair, error) {
+	member, err := s.CurrentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(member.PasswordHash), []byte(currentPassword)) != nil {
+		return nil, apperrors.NewValidationError("current password is incorrect")
+	}
+	if newPassword == currentPassword {
+		return nil, apperrors.NewValidationError("new password must differ from the current one")
+	}
+	if err := s.setPassword(ctx, member, newPassword, "password changed"); err != nil {
+		return nil, err
+	}
+
+	tokens, _, err := s.startSession(ctx, member, uuid.NewString())
+	if err != nil {
+		return nil, err
+	}
+	s.analyticsService.LogActivity(ctx, member.UserID, "Password Changed", "member", member.ID, "User changed their password")
+	return tokens, nil
+}
+
+// ResetPassword sets a new password with a one-time token. The token is only spent once the new
+// password has passed the policy, so a rejected attempt can be retried.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	invalid := apperrors.NewValidationError("invalid or expired reset token")
+	if token == "" {
+		return invalid
+	}
+	reset, err := s.members.GetPasswordReset(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if reset == nil || reset.UsedAt != nil || time.Now().After(reset.ExpiresAt) {
+		return invalid
+	}
+	member, err := s.members.Get(ctx, reset.MemberID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.Status != domain.StatusActive {
+		return invalid
+	}
+	if err := s.cfg.Passwords.validatePassword(member.Username, newPassword); err != nil {
+		return err
+	}
+
+	used, err := s.members.UsePasswordReset(ctx, reset.TokenHash)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return invalid
+	}
+	if err := s.setPassword(ctx, member, newPassword, "password reset"); err != nil {
+		return err
+	}
+
+	s.analyticsService.LogActivity(loginContext(ctx, member), member.UserID, "Password Reset", "member", member.ID, "Password set with a reset token")
+	return nil
+}
+
+// setPassword validates and stores a password the member chose themselves, then signs them out everywhere.
+func (s *AuthService) setPassword(ctx context.Context, member *domain.Member, password, reason string) error {
+	if err := s.cfg.Passwords.validatePassword(member.Username, password); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	now := time.Now()
+	member.PasswordHash = string(hash)
+	member.PasswordChangedAt = &now
+	member.MustChangePassword = false
+	if err := s.members.Update(ctx, member); err != nil {
+		return err
+	}
+	if _, err := s.sessions.RevokeMember(ctx, member.ID, reason); err != nil {
+		logger.Errorf("[AuthService] Failed to revoke sessions of member %s: %v", member.ID, err)
+	}
+	return nil
+}
+
+// startSession stores a new refresh session in the family and issues the access token that goes with it.
+func (s *AuthService) startSession(ctx context.Context, member *domain.Member, familyID string) (*domain.TokenPair, *domain.AuthSession, error) {
+	refreshToken, err := newOpaqueToken()
+	if err != nil {
+		return nil, nil, errors.New("failed to issue token")
+	}
+
+	now := time.Now()
+	session := &domain.AuthSession{
+		ID:              uuid.NewString(),
+		FamilyID:        familyID,
+		MemberID:        member.ID,
+		UserID:          member.UserID,
+		TokenHash:       hashToken(refreshToken),
+		AccessJTI:       uuid.NewString(),
+		AccessExpiresAt: now.Add(s.cfg.AccessTTL),
+		ExpiresAt:       now.Add(s.cfg.RefreshTTL),
+		Status:          domain.SessionActive,
+		IPAddress:       ports.GetIPAddress(ctx),
+		UserAgent:       ports.GetUserAgent(ctx),
 	}
 
-	// Issue a real signed JWT — 2 hour expiry to limit stolen-token blast radius
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":   user.ID,
-		"username":  user.Username,
-		"user_type": user.UserType,
-		"exp":       time.Now().Add(2 * time.Hour).Unix(),
-		"iat":       time.Now().Unix(),
+		"user_id":   member.UserID,
+		"member_id": member.ID,
+		"username":  member.Username,
+		"user_type": member.OrgType,
+		"sid":       session.ID,
+		"jti":       session.AccessJTI,
+		"exp":       session.AccessExpiresAt.Unix(),
+		"iat":       now.Unix(),
 	})
+	accessToken, err := token.SignedString([]byte(s.cfg.JWTSecret))
+	if err != nil {
+		return nil, nil, errors.New("failed to issue token")
+	}
+
+	if err := s.sessions.Create(ctx, session); err != nil {
+		return nil, nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  session.AccessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+	}, session, nil
+}
 
-	tokenStr, err := token.SignedString([]byte(s.jwtSecret))
+func (s *AuthService) revokeReusedFamily(ctx context.Context, session *domain.AuthSession) {
+	n, err := s.sessions.RevokeFamily(ctx, session.FamilyID, "refresh token reuse")
 	if err != nil {
-		return "", nil, errors.New("failed to issue token")
+		logger.Errorf("[AuthService] Failed to revoke session family %s after token reuse: %v", session.FamilyID, err)
+		return
 	}
+	logger.Errorf("[AuthService] Refresh token reuse for member %s; revoked %d sessions", session.MemberID, n)
+
+	ctx = context.WithValue(ctx, ports.MemberIDKey, session.MemberID)
+	s.analyticsService.LogActivity(ctx, session.UserID, "Session Reuse Detected", "user", session.MemberID,
+		fmt.Sprintf("A rotated refresh token was presented again from %s; the session was revoked", ports.GetIPAddress(ctx)))
+}
+
+func loginContext(ctx context.Context, member *domain.Member) context.Context {
+	ctx = context.WithValue(ctx, ports.MemberIDKey, member.ID)
+	return context.WithValue(ctx, ports.UsernameKey, member.Username)
+}
+
+// newOpaqueToken returns 256 random bits for refresh and reset tokens; only their SHA-256 is ever stored.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
 
-	s.analyticsService.LogActivity(ctx, user.ID, "Login", "user", user.ID, "User logged in to the system")
-	return tokenStr, user, nil
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -2,11 +2,13 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -18,30 +20,192 @@ import (
 // Mocks matching actual port interfaces
 // ─────────────────────────────────────────────
 
-type authTestUserRepo struct {
+type authTestMemberRepo struct {
 	mock.Mock
+	updated *domain.Member
+	resets  map[string]*domain.PasswordReset
 }
 
-func (m *authTestUserRepo) Get(ctx context.Context, id string) (*domain.User, error) {
+func (m *authTestMemberRepo) Get(ctx context.Context, id string) (*domain.Member, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.User), args.Error(1)
+	return args.Get(0).(*domain.Member), args.Error(1)
 }
 
-func (m *authTestUserRepo) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
+func (m *authTestMemberRepo) GetByUsername(ctx context.Context, username string) (*domain.Member, error) {
 	args := m.Called(ctx, username)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*domain.User), args.Error(1)
+	return args.Get(0).(*domain.Member), args.Error(1)
 }
 
-func (m *authTestUserRepo) List(ctx context.Context) ([]domain.User, error)           { return nil, nil }
-func (m *authTestUserRepo) Create(ctx context.Context, u *domain.User) error           { return nil }
-func (m *authTestUserRepo) Update(ctx context.Context, u *domain.User) error           { return nil }
-func (m *authTestUserRepo) Delete(ctx context.Context, id string) error                { return nil }
+func (m *authTestMemberRepo) ListByUser(ctx context.Context, userID string) ([]domain.Member, error) {
+	return nil, nil
+}
+func (m *authTestMemberRepo) Create(ctx context.Context, mem *domain.Member) error { return nil }
+func (m *authTestMemberRepo) Update(ctx context.Context, mem *domain.Member) error {
+	cp := *mem
+	m.updated = &cp
+	return nil
+}
+func (m *authTestMemberRepo) SetRole(ctx context.Context, id, role string, siteIDs []string) error {
+	return nil
+}
+func (m *authTestMemberRepo) TouchLastLogin(ctx context.Context, id string) error { return nil }
+func (m *authTestMemberRepo) CreatePasswordReset(ctx context.Context, r *domain.PasswordReset) error {
+	if m.resets == nil {
+		m.resets = map[string]*domain.PasswordReset{}
+	}
+	m.resets[r.TokenHash] = r
+	return nil
+}
+func (m *authTestMemberRepo) GetPasswordReset(ctx context.Context, tokenHash string) (*domain.PasswordReset, error) {
+	return m.resets[tokenHash], nil
+}
+func (m *authTestMemberRepo) UsePasswordReset(ctx context.Context, tokenHash string) (bool, error) {
+	r := m.resets[tokenHash]
+	if r == nil || r.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	r.UsedAt = &now
+	return true, nil
+}
+
+// authTestSessionRepo is an in-memory ports.SessionRepository so rotation can be exercised end to end
+type authTestSessionRepo struct {
+	byID    map[string]*domain.AuthSession
+	revoked map[string]bool
+}
+
+func newAuthTestSessionRepo() *authTestSessionRepo {
+	return &authTestSessionRepo{byID: map[string]*domain.AuthSession{}, revoked: map[string]bool{}}
+}
+
+func (r *authTestSessionRepo) Create(ctx context.Context, s *domain.AuthSession) error {
+	cp := *s
+	r.byID[s.ID] = &cp
+	return nil
+}
+
+func (r *authTestSessionRepo) Get(ctx context.Context, id string) (*domain.AuthSession, error) {
+	return r.byID[id], nil
+}
+
+func (r *authTestSessionRepo) GetByTokenHash(ctx context.Context, hash string) (*domain.AuthSession, error) {
+	for _, s := range r.byID {
+		if s.TokenHash == hash {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *authTestSessionRepo) Rotate(ctx context.Context, id, replacedBy string) (bool, error) {
+	s := r.byID[id]
+	if s == nil || s.Status != domain.SessionActive {
+		return false, nil
+	}
+	s.Status = domain.SessionRotated
+	s.ReplacedBy = replacedBy
+	return true, nil
+}
+
+func (r *authTestSessionRepo) revoke(match func(*domain.AuthSession) bool, reason string) (int64, error) {
+	var n int64
+	for _, s := range r.byID {
+		if !match(s) {
+			continue
+		}
+		r.revoked[s.AccessJTI] = true
+		if s.Status == domain.SessionActive {
+			s.Status = domain.SessionRevoked
+			s.RevokedReason = reason
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *authTestSessionRepo) RevokeFamily(ctx context.Context, familyID, reason string) (int64, error) {
+	return r.revoke(func(s *domain.AuthSession) bool { return s.FamilyID == familyID }, reason)
+}
+
+func (r *authTestSessionRepo) RevokeMember(ctx context.Context, memberID, reason string) (int64, error) {
+	return r.revoke(func(s *domain.AuthSession) bool { return s.MemberID == memberID }, reason)
+}
+
+func (r *authTestSessionRepo) RevokeUser(ctx context.Context, userID, reason string) (int64, error) {
+	return r.revoke(func(s *domain.AuthSession) bool { return s.UserID == userID }, reason)
+}
+
+func (r *authTestSessionRepo) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return r.revoked[jti], nil
+}
+
+func (r *authTestSessionRepo) PurgeExpired(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// authTestThrottleRepo is an in-memory ports.LoginThrottleRepository
+type authTestThrottleRepo struct {
+	rows map[string]*domain.LoginThrottle
+}
+
+func newAuthTestThrottleRepo() *authTestThrottleRepo {
+	return &authTestThrottleRepo{rows: map[string]*domain.LoginThrottle{}}
+}
+
+func (r *authTestThrottleRepo) Get(ctx context.Context, scope, key string) (*domain.LoginThrottle, error) {
+	if t := r.rows[scope+"/"+key]; t != nil {
+		cp := *t
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *authTestThrottleRepo) RecordFailure(ctx context.Context, scope, key string, now time.Time, window time.Duration) (*domain.LoginThrottle, error) {
+	t := r.rows[scope+"/"+key]
+	switch {
+	case t == nil:
+		t = &domain.LoginThrottle{Scope: scope, Key: key}
+		r.rows[scope+"/"+key] = t
+	case t.LastFailureAt.Before(now.Add(-window)) || (t.LockedUntil != nil && !t.LockedUntil.After(now)):
+		t.Failures = 0
+		t.LockedUntil = nil
+	}
+	t.Failures++
+	t.LastFailureAt = now
+	return r.Get(ctx, scope, key)
+}
+
+func (r *authTestThrottleRepo) Lock(ctx context.Context, scope, key string, until time.Time) error {
+	r.rows[scope+"/"+key].LockedUntil = &until
+	return nil
+}
+
+func (r *authTestThrottleRepo) Clear(ctx context.Context, scope, key string) (bool, error) {
+	_, ok := r.rows[scope+"/"+key]
+	delete(r.rows, scope+"/"+key)
+	return ok, nil
+}
+
+func (r *authTestThrottleRepo) ListLocked(ctx context.Context, now time.Time) ([]domain.LoginThrottle, error) {
+	var locked []domain.LoginThrottle
+	for _, t := range r.rows {
+		if t.LockedUntil != nil && t.LockedUntil.After(now) {
+			locked = append(locked, *t)
+		}
+	}
+	return locked, nil
+}
+
+func (r *authTestThrottleRepo) PurgeStale(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
 
 // analytics matching ports.AnalyticsService exactly
 type authTestAnalytics struct {
@@ -96,63 +260,65 @@ func authTestParseJWT(t *testing.T, tokenStr, secret string) jwt.MapClaims {
 // ─────────────────────────────────────────────
 
 func TestAuthService_Login_Success(t *testing.T) {
-	repo := &authTestUserRepo{}
+	repo := &authTestMemberRepo{}
 	analytics := &authTestAnalytics{}
 
-	u := &domain.User{ID: "u-001", Username: "admin", UserType: "vendor", Status: "active"}
+	u := &domain.Member{ID: "u-001", UserID: "u-001", Username: "admin", OrgType: "vendor", Status: "active", OrgStatus: "active"}
 	u.PasswordHash = authTestHashPwd(t, "testpass")
 	repo.On("GetByUsername", mock.Anything, "admin").Return(u, nil)
 	analytics.On("LogActivity", mock.Anything, "u-001", "Login", "user", "u-001", mock.Anything).Return(nil)
 
-	svc := NewAuthService(repo, "secret", analytics)
-	token, user, err := svc.Login(context.Background(), "admin", "testpass")
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "secret"}, analytics)
+	res, err := svc.Login(context.Background(), "admin", "testpass")
 
 	assert.NoError(t, err)
-	assert.NotEmpty(t, token)
-	assert.Equal(t, "admin", user.Username)
+	assert.Nil(t, res.Challenge)
+	assert.NotEmpty(t, res.Tokens.AccessToken)
+	assert.NotEmpty(t, res.Tokens.RefreshToken)
+	assert.Equal(t, "admin", res.Member.Username)
 }
 
 func TestAuthService_Login_WrongPassword(t *testing.T) {
-	repo := &authTestUserRepo{}
+	repo := &authTestMemberRepo{}
 	analytics := &authTestAnalytics{}
 
-	u := &domain.User{ID: "u-001", Username: "admin", Status: "active"}
+	u := &domain.Member{ID: "u-001", UserID: "u-001", Username: "admin", Status: "active", OrgStatus: "active"}
 	u.PasswordHash = authTestHashPwd(t, "correct")
 	repo.On("GetByUsername", mock.Anything, "admin").Return(u, nil)
 
-	svc := NewAuthService(repo, "secret", analytics)
-	_, _, err := svc.Login(context.Background(), "admin", "wrong")
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "secret"}, analytics)
+	_, err := svc.Login(context.Background(), "admin", "wrong")
 
 	assert.Error(t, err)
 	assert.Equal(t, "invalid credentials", err.Error())
 }
 
 func TestAuthService_Login_UserNotFound(t *testing.T) {
-	repo := &authTestUserRepo{}
+	repo := &authTestMemberRepo{}
 	analytics := &authTestAnalytics{}
 	repo.On("GetByUsername", mock.Anything, "nobody").Return(nil, nil)
 
-	svc := NewAuthService(repo, "secret", analytics)
-	_, _, err := svc.Login(context.Background(), "nobody", "x")
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "secret"}, analytics)
+	_, err := svc.Login(context.Background(), "nobody", "x")
 
 	assert.Error(t, err)
 	assert.Equal(t, "invalid credentials", err.Error())
 }
 
 func TestAuthService_Login_JWTClaimsAndExpiry(t *testing.T) {
-	repo := &authTestUserRepo{}
+	repo := &authTestMemberRepo{}
 	analytics := &authTestAnalytics{}
 
-	u := &domain.User{ID: "u-abc", Username: "testuser", UserType: "client", Status: "active"}
+	u := &domain.Member{ID: "u-abc", UserID: "u-abc", Username: "testuser", OrgType: "client", Status: "active", OrgStatus: "active"}
 	u.PasswordHash = authTestHashPwd(t, "mypass")
 	repo.On("GetByUsername", mock.Anything, "testuser").Return(u, nil)
 	analytics.On("LogActivity", mock.Anything, mock.Anything, "Login", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
-	svc := NewAuthService(repo, "my-secret", analytics)
-	token, _, err := svc.Login(context.Background(), "testuser", "mypass")
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "my-secret"}, analytics)
+	res, err := svc.Login(context.Background(), "testuser", "mypass")
 	assert.NoError(t, err)
 
-	claims := authTestParseJWT(t, token, "my-secret")
+	claims := authTestParseJWT(t, res.Tokens.AccessToken, "my-secret")
 
 	assert.Equal(t, "u-abc", claims["user_id"])
 	assert.Equal(t, "client", claims["user_type"])
@@ -166,17 +332,360 @@ func TestAuthService_Login_JWTClaimsAndExpiry(t *testing.T) {
 }
 
 func TestAuthService_Login_NoAuditLogOnFailure(t *testing.T) {
-	repo := &authTestUserRepo{}
+	repo := &authTestMemberRepo{}
 	analytics := &authTestAnalytics{}
 
-	u := &domain.User{ID: "u-001", Username: "admin", Status: "active"}
+	u := &domain.Member{ID: "u-001", UserID: "u-001", Username: "admin", Status: "active", OrgStatus: "active"}
 	u.PasswordHash = authTestHashPwd(t, "correct")
 	repo.On("GetByUsername", mock.Anything, "admin").Return(u, nil)
 
-	svc := NewAuthService(repo, "secret", analytics)
-	_, _, err := svc.Login(context.Background(), "admin", "wrong")
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "secret"}, analytics)
+	_, err := svc.Login(context.Background(), "admin", "wrong")
 	assert.Error(t, err)
 
 	// LogActivity must NOT be called when authentication fails
 	analytics.AssertNotCalled(t, "LogActivity")
 }
+
+func TestAuthService_Login_MemberActsForOrganisation(t *testing.T) {
+	repo := &authTestMemberRepo{}
+	analytics := &authTestAnalytics{}
+
+	m := &domain.Member{ID: "m-002", UserID: "u-abc", Username: "site.pic", OrgType: "client", Role: domain.RolePIC, Status: "active", OrgStatus: "active"}
+	m.PasswordHash = authTestHashPwd(t, "pic-pass")
+	repo.On("GetByUsername", mock.Anything, "site.pic").Return(m, nil)
+	analytics.On("LogActivity", mock.MatchedBy(func(ctx context.Context) bool {
+		return ports.GetMemberID(ctx) == "m-002"
+	}), "u-abc", "Login", "user", "m-002", mock.Anything).Return(nil)
+
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "my-secret"}, analytics)
+	res, err := svc.Login(context.Background(), "site.pic", "pic-pass")
+	assert.NoError(t, err)
+	assert.Equal(t, "m-002", res.Member.ID)
+
+	claims := authTestParseJWT(t, res.Tokens.AccessToken, "my-secret")
+	assert.Equal(t, "u-abc", claims["user_id"], "tenant scope stays the organisation")
+	assert.Equal(t, "m-002", claims["member_id"])
+	analytics.AssertExpectations(t)
+}
+
+func TestAuthService_Login_InactiveMemberOrOrganisation(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		orgStatus string
+	}{
+		{"inactive member", "inactive", "active"},
+		{"inactive organisation", "active", "inactive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &authTestMemberRepo{}
+			analytics := &authTestAnalytics{}
+
+			m := &domain.Member{ID: "m-1", UserID: "u-1", Username: "bob", Status: tt.status, OrgStatus: tt.orgStatus}
+			m.PasswordHash = authTestHashPwd(t, "pw")
+			repo.On("GetByUsername", mock.Anything, "bob").Return(m, nil)
+
+			svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "secret"}, analytics)
+			_, err := svc.Login(context.Background(), "bob", "pw")
+
+			assert.EqualError(t, err, "invalid credentials")
+			analytics.AssertNotCalled(t, "LogActivity")
+		})
+	}
+}
+
+// authTestLogin signs in an active member and returns the service, its session store and the tokens
+func authTestLogin(t *testing.T, analytics *authTestAnalytics) (*AuthService, *authTestSessionRepo, *authTestMemberRepo, *domain.TokenPair) {
+	t.Helper()
+	repo := &authTestMemberRepo{}
+	sessions := newAuthTestSessionRepo()
+
+	m := &domain.Member{ID: "m-1", UserID: "u-1", Username: "bob", OrgType: "client", Status: "active", OrgStatus: "active"}
+	m.PasswordHash = authTestHashPwd(t, "pw")
+	repo.On("GetByUsername", mock.Anything, "bob").Return(m, nil)
+	repo.On("Get", mock.Anything, "m-1").Return(m, nil)
+	analytics.On("LogActivity", mock.Anything, "u-1", "Login", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := NewAuthService(repo, sessions, newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{JWTSecret: "secret"}, analytics).(*AuthService)
+	res, err := svc.Login(context.Background(), "bob", "pw")
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	return svc, sessions, repo, res.Tokens
+}
+
+func TestAuthService_Refresh_RotatesToken(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, sessions, _, first := authTestLogin(t, analytics)
+
+	second, member, err := svc.Refresh(context.Background(), first.RefreshToken)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "m-1", member.ID)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	firstClaims := authTestParseJWT(t, first.AccessToken, "secret")
+	secondClaims := authTestParseJWT(t, second.AccessToken, "secret")
+	assert.NotEqual(t, firstClaims["jti"], secondClaims["jti"])
+
+	old := sessions.byID[firstClaims["sid"].(string)]
+	next := sessions.byID[secondClaims["sid"].(string)]
+	assert.Equal(t, domain.SessionRotated, old.Status)
+	assert.Equal(t, next.ID, old.ReplacedBy)
+	assert.Equal(t, old.FamilyID, next.FamilyID, "rotation stays within the login's session family")
+	assert.NotEqual(t, second.RefreshToken, next.TokenHash, "only the hash is stored")
+}
+
+func TestAuthService_Refresh_ReuseRevokesFamily(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, sessions, _, first := authTestLogin(t, analytics)
+	analytics.On("LogActivity", mock.Anything, "u-1", "Session Reuse Detected", "user", "m-1", mock.Anything).Return(nil)
+
+	second, _, err := svc.Refresh(context.Background(), first.RefreshToken)
+	assert.NoError(t, err)
+
+	// The first token is replayed, e.g. by an attacker who copied it
+	_, _, err = svc.Refresh(context.Background(), first.RefreshToken)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+
+	// The legitimate holder is signed out as well, and both access tokens are blocked
+	_, _, err = svc.Refresh(context.Background(), second.RefreshToken)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	for _, tok := range []*domain.TokenPair{first, second} {
+		jti := authTestParseJWT(t, tok.AccessToken, "secret")["jti"].(string)
+		revoked, _ := sessions.IsTokenRevoked(context.Background(), jti)
+		assert.True(t, revoked)
+	}
+	analytics.AssertCalled(t, "LogActivity", mock.Anything, "u-1", "Session Reuse Detected", "user", "m-1", mock.Anything)
+}
+
+func TestAuthService_Refresh_RejectsExpiredOrUnknown(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, sessions, _, tokens := authTestLogin(t, analytics)
+
+	_, _, err := svc.Refresh(context.Background(), "not-a-token")
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+
+	for _, s := range sessions.byID {
+		s.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+	_, _, err = svc.Refresh(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_Logout_RevokesSession(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, sessions, _, tokens := authTestLogin(t, analytics)
+	analytics.On("LogActivity", mock.Anything, "u-1", "Logout", "user", "m-1", mock.Anything).Return(nil)
+
+	claims := authTestParseJWT(t, tokens.AccessToken, "secret")
+	ctx := context.WithValue(context.Background(), ports.SessionIDKey, claims["sid"].(string))
+
+	// Logout from the access token alone, as a client without the refresh cookie would
+	assert.NoError(t, svc.Logout(ctx, ""))
+
+	revoked, _ := sessions.IsTokenRevoked(ctx, claims["jti"].(string))
+	assert.True(t, revoked)
+	_, _, err := svc.Refresh(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_RevokeSessions_VendorOnly(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, _, _, tokens := authTestLogin(t, analytics)
+	analytics.On("LogActivity", mock.Anything, "u-1", "Sessions Revoked", "member", "m-1", mock.Anything).Return(nil)
+
+	_, err := svc.RevokeSessions(context.Background(), "u-1", "m-1")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+
+	vendorViewer := context.WithValue(roleContext("vendor-1", domain.RoleViewer), ports.IsVendorKey, true)
+	_, err = svc.RevokeSessions(vendorViewer, "u-1", "m-1")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied, "only vendor managers may revoke sessions")
+
+	vendor := context.WithValue(roleContext("vendor-1", domain.RoleManager), ports.IsVendorKey, true)
+	_, err = svc.RevokeSessions(vendor, "u-other", "m-1")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound, "member must belong to the organisation in the path")
+
+	n, err := svc.RevokeSessions(vendor, "u-1", "m-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	_, _, err = svc.Refresh(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_Login_DefaultPasswordForcesChange(t *testing.T) {
+	repo := &authTestMemberRepo{}
+	analytics := &authTestAnalytics{}
+
+	m := &domain.Member{ID: "u-1", UserID: "u-1", Username: "acme", Status: "active", OrgStatus: "active"}
+	m.PasswordHash = authTestHashPwd(t, "Nexus@2026!ChangeMe")
+	repo.On("GetByUsername", mock.Anything, "acme").Return(m, nil)
+	analytics.On("LogActivity", mock.Anything, "u-1", "Login", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{
+		JWTSecret: "secret",
+		Passwords: PasswordConfig{DefaultPassword: "Nexus@2026!ChangeMe"},
+	}, analytics)
+	res, err := svc.Login(context.Background(), "acme", "Nexus@2026!ChangeMe")
+
+	assert.NoError(t, err)
+	assert.True(t, res.Member.MustChangePassword)
+	if assert.NotNil(t, repo.updated) {
+		assert.True(t, repo.updated.MustChangePassword)
+	}
+}
+
+func TestAuthService_ChangePassword(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, sessions, repo, tokens := authTestLogin(t, analytics)
+	svc.cfg.Passwords.Policy = domain.DefaultPasswordPolicy
+	analytics.On("LogActivity", mock.Anything, "u-1", "Password Changed", "member", "m-1", mock.Anything).Return(nil)
+	ctx := context.WithValue(context.Background(), ports.MemberIDKey, "m-1")
+
+	_, err := svc.ChangePassword(ctx, "wrong", "new-password-1")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	_, err = svc.ChangePassword(ctx, "pw", "short")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Nil(t, repo.updated, "rejected passwords are not stored")
+
+	fresh, err := svc.ChangePassword(ctx, "pw", "new-password-1")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fresh.AccessToken)
+	assert.False(t, repo.updated.MustChangePassword)
+	assert.NotNil(t, repo.updated.PasswordChangedAt)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(repo.updated.PasswordHash), []byte("new-password-1")))
+
+	// Sessions started with the old password are signed out
+	jti := authTestParseJWT(t, tokens.AccessToken, "secret")["jti"].(string)
+	revoked, _ := sessions.IsTokenRevoked(ctx, jti)
+	assert.True(t, revoked)
+	_, _, err = svc.Refresh(context.Background(), tokens.RefreshToken)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, _, repo, _ := authTestLogin(t, analytics)
+	svc.cfg.Passwords.Policy = domain.DefaultPasswordPolicy
+	analytics.On("LogActivity", mock.Anything, "u-1", "Password Reset", "member", "m-1", mock.Anything).Return(nil)
+
+	repo.CreatePasswordReset(context.Background(), &domain.PasswordReset{TokenHash: hashToken("good"), MemberID: "m-1", ExpiresAt: time.Now().Add(time.Hour)})
+	repo.CreatePasswordReset(context.Background(), &domain.PasswordReset{TokenHash: hashToken("stale"), MemberID: "m-1", ExpiresAt: time.Now().Add(-time.Minute)})
+
+	err := svc.ResetPassword(context.Background(), "stale", "new-password-1")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	// A password rejected by the policy does not spend the token
+	err = svc.ResetPassword(context.Background(), "good", "weak")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	assert.Nil(t, repo.resets[hashToken("good")].UsedAt)
+
+	err = svc.ResetPassword(context.Background(), "good", "new-password-1")
+	assert.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(repo.updated.PasswordHash), []byte("new-password-1")))
+
+	err = svc.ResetPassword(context.Background(), "good", "another-pass-2")
+	assert.ErrorIs(t, err, apperrors.ErrValidation, "reset tokens are single use")
+}
+
+// authTestThrottledService has a policy without delays so lockout thresholds can be reached quickly
+func authTestThrottledService(t *testing.T, analytics *authTestAnalytics) (*AuthService, *authTestThrottleRepo) {
+	t.Helper()
+	repo := &authTestMemberRepo{}
+	m := &domain.Member{ID: "m-1", UserID: "u-1", Username: "Bob", Status: "active", OrgStatus: "active"}
+	m.PasswordHash = authTestHashPwd(t, "right-password")
+	repo.On("GetByUsername", mock.Anything, "Bob").Return(m, nil)
+	repo.On("GetByUsername", mock.Anything, mock.Anything).Return(nil, nil)
+
+	throttles := newAuthTestThrottleRepo()
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), throttles, newAuthTestMFARepo(), AuthConfig{
+		JWTSecret: "secret",
+		Throttle: domain.LoginThrottlePolicy{
+			MaxAccountFailures: 3,
+			MaxIPFailures:      5,
+			Window:             15 * time.Minute,
+			LockoutDuration:    15 * time.Minute,
+		},
+	}, analytics).(*AuthService)
+	return svc, throttles
+}
+
+func TestAuthService_Login_LocksAccountAfterRepeatedFailures(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, throttles := authTestThrottledService(t, analytics)
+	analytics.On("LogActivity", mock.Anything, "u-1", "Account Locked", "user", "m-1", mock.Anything).Return(nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Login(context.Background(), "Bob", "wrong")
+		assert.EqualError(t, err, "invalid credentials")
+	}
+	analytics.AssertNumberOfCalls(t, "LogActivity", 1)
+
+	// The right password is refused while locked
+	_, err := svc.Login(context.Background(), "Bob", "right-password")
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+	var appErr *apperrors.AppError
+	if assert.ErrorAs(t, err, &appErr) {
+		assert.InDelta(t, (15 * time.Minute).Seconds(), appErr.RetryAfter.Seconds(), 5)
+	}
+
+	// Once the lockout expires the account can sign in, which clears the counter
+	expired := time.Now().Add(-time.Second)
+	throttles.rows[domain.ThrottleScopeAccount+"/bob"].LockedUntil = &expired
+	analytics.On("LogActivity", mock.Anything, "u-1", "Login", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	_, err = svc.Login(context.Background(), "Bob", "right-password")
+	assert.NoError(t, err)
+	assert.Empty(t, throttles.rows)
+}
+
+func TestAuthService_Login_LocksSourceIP(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, _ := authTestThrottledService(t, analytics)
+	analytics.On("LogActivity", mock.Anything, "system", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	ctx := context.WithValue(context.Background(), ports.IPAddressKey, "203.0.113.7")
+
+	// Spraying different usernames from one address trips the per-IP limit
+	for i := 0; i < 5; i++ {
+		_, err := svc.Login(ctx, fmt.Sprintf("user%d", i), "guess")
+		assert.EqualError(t, err, "invalid credentials")
+	}
+	analytics.AssertCalled(t, "LogActivity", mock.Anything, "system", "Login Locked", "ip", "203.0.113.7", mock.Anything)
+
+	_, err := svc.Login(ctx, "Bob", "right-password")
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+
+	// Other addresses are unaffected
+	analytics.On("LogActivity", mock.Anything, "u-1", "Login", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	other := context.WithValue(context.Background(), ports.IPAddressKey, "198.51.100.1")
+	_, err = svc.Login(other, "Bob", "right-password")
+	assert.NoError(t, err)
+
+	// A vendor lifts the lockout
+	vendor := context.WithValue(roleContext("vendor-1", domain.RoleManager), ports.IsVendorKey, true)
+	locked, err := svc.ListLockouts(vendor)
+	assert.NoError(t, err)
+	assert.Len(t, locked, 1)
+	assert.NoError(t, svc.Unlock(vendor, domain.ThrottleScopeIP, "203.0.113.7"))
+	assert.ErrorIs(t, svc.Unlock(context.Background(), domain.ThrottleScopeIP, "203.0.113.7"), apperrors.ErrPermissionDenied)
+}
+
+func TestAuthService_Login_ProgressiveDelay(t *testing.T) {
+	analytics := &authTestAnalytics{}
+	svc, _ := authTestThrottledService(t, analytics)
+	svc.cfg.Throttle.BaseDelay = time.Minute
+	svc.cfg.Throttle.MaxDelay = time.Hour
+
+	_, err := svc.Login(context.Background(), "Bob", "wrong")
+	assert.EqualError(t, err, "invalid credentials", "the first failure is not delayed")
+	_, err = svc.Login(context.Background(), "Bob", "wrong")
+	assert.EqualError(t, err, "invalid credentials")
+
+	// The next attempt must wait BaseDelay after the second failure
+	_, err = svc.Login(context.Background(), "Bob", "right-password")
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+	analytics.AssertNotCalled(t, "LogActivity")
+}
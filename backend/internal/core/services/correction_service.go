@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+type CorrectionService struct {
+	repo           ports.CorrectionRepository
+	attendanceRepo ports.AttendanceRepository
+	analytics      ports.AnalyticsService
+}
+
+func NewCorrectionService(repo ports.CorrectionRepository, attendanceRepo ports.AttendanceRepository, analytics ports.AnalyticsService) ports.CorrectionService {
+	return &CorrectionService{
+		repo:           repo,
+		attendanceRepo: attendanceRepo,
+		analytics:      analytics,
+	}
+}
+
+// RequestCorrection validates the proposed times against the record as it is now and stores a
+// pending correction. The record itself is unchanged until the correction is approved.
+func (s *CorrectionService) RequestCorrection(ctx context.Context, userID, attendanceID, reason string, timeIn, timeOut *time.Time) (*domain.AttendanceCorrection, error) {
+	if err := ports.Authorize(ctx, domain.PermAttendanceWrite); err != nil {
+		return nil, err
+	}
+	record, err := s.attendanceRepo.Get(ctx, userID, attendanceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ports.AuthorizeSite(ctx, domain.PermAttendanceWrite, record.SiteID); err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > domain.MaxCorrectionReasonLength {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("reason is required (at most %d characters)", domain.MaxCorrectionReasonLength))
+	}
+	if timeIn == nil {
+		return nil, apperrors.NewValidationError("time_in is required")
+	}
+	// Attendance times are stored to the second
+	timeIn = truncateToSecond(timeIn)
+	timeOut = truncateToSecond(timeOut)
+	if timeOut != nil && !timeOut.After(*timeIn) {
+		return nil, apperrors.NewValidationError("time_out must be after time_in")
+	}
+	if sameTime(record.TimeIn, timeIn) && sameTime(record.TimeOut, timeOut) {
+		return nil, apperrors.NewValidationError("proposed times are the same as the recorded ones")
+	}
+
+	pending, err := s.repo.List(ctx, record.UserID, attendanceID, domain.CorrectionPending)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, apperrors.NewConflict(fmt.Sprintf("correction %s is already awaiting review for this record", pending[0].ID))
+	}
+
+	correction := &domain.AttendanceCorrection{
+		ID:              uuid.NewString(),
+		AttendanceID:    record.ID,
+		UserID:          record.UserID,
+		SiteID:          record.SiteID,
+		WorkerID:        record.WorkerID,
+		Kind:            domain.CorrectionKindFor(record.Status),
+		Reason:          reason,
+		OriginalTimeIn:  record.TimeIn,
+		OriginalTimeOut: record.TimeOut,
+		ProposedTimeIn:  timeIn,
+		ProposedTimeOut: timeOut,
+		Status:          domain.CorrectionPending,
+		RequestedBy:     actorID(ctx),
+		RequestedByName: ports.GetUsername(ctx),
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.Create(ctx, correction); err != nil {
+		return nil, err
+	}
+	s.analytics.LogActivity(ctx, record.UserID, "Attendance Correction Requested", "attendance", record.ID,
+		fmt.Sprintf("%s requested for %s: %s", correction.Kind, record.ID, reason))
+	return correction, nil
+}
+
+func (s *CorrectionService) GetCorrection(ctx context.Context, userID, id string) (*domain.AttendanceCorrection, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+func (s *CorrectionService) ListCorrections(ctx context.Context, userID, attendanceID, status string) ([]domain.AttendanceCorrection, error) {
+	switch status {
+	case "", domain.CorrectionPending, domain.CorrectionApproved, domain.CorrectionRejected:
+	default:
+		return nil, apperrors.NewValidationError("status must be pending, approved or rejected")
+	}
+	return s.repo.List(ctx, userID, attendanceID, status)
+}
+
+// ApproveCorrection applies a pending correction and appends it to the record's revision history.
+// An amendment puts the record back in the submission queue so CPD receives the corrected times.
+// The record must still hold the times the correction was requested against.
+func (s *CorrectionService) ApproveCorrection(ctx context.Context, userID, id, note string) (*domain.AttendanceCorrection, error) {
+	correction, err := s.reviewable(ctx, userID, id, note)
+	if err != nil {
+		return nil, err
+	}
+	record, err := s.attendanceRepo.Get(ctx, correction.UserID, correction.AttendanceID)
+	if err != nil {
+		return nil, err
+	}
+	if !sameTime(record.TimeIn, correction.OriginalTimeIn) || !sameTime(record.TimeOut, correction.OriginalTimeOut) {
+		return nil, apperrors.NewConflict("attendance record changed since the correction was requested; reject it and request a new one")
+	}
+	// A record being sent may land as submitted or go back to pending, so its kind is not known yet
+	if record.Status == domain.SubmissionStatusSubmitting {
+		return nil, apperrors.NewConflict("attendance record is being submitted to CPD; approve the correction once the submission finishes")
+	}
+
+	now := time.Now()
+	// The record may have been submitted while the correction waited for review
+	correction.Kind = domain.CorrectionKindFor(record.Status)
+	correction.Status = domain.CorrectionApproved
+	correction.ReviewedBy = actorID(ctx)
+	correction.ReviewedByName = ports.GetUsername(ctx)
+	correction.ReviewNote = strings.TrimSpace(note)
+	correction.ReviewedAt = &now
+
+	revision := &domain.AttendanceRevision{
+		AttendanceID:    record.ID,
+		Revision:        record.Revision + 1,
+		CorrectionID:    correction.ID,
+		Kind:            correction.Kind,
+		PreviousTimeIn:  record.TimeIn,
+		PreviousTimeOut: record.TimeOut,
+		TimeIn:          correction.ProposedTimeIn,
+		TimeOut:         correction.ProposedTimeOut,
+		PreviousStatus:  record.Status,
+		Reason:          correction.Reason,
+		RequestedBy:     correction.RequestedBy,
+		ApprovedBy:      correction.ReviewedBy,
+		CreatedAt:       now,
+	}
+	if err := s.repo.Apply(ctx, correction, revision); err != nil {
+		return nil, err
+	}
+	s.analytics.LogActivity(ctx, correction.UserID, "Attendance Correction Approved", "attendance", record.ID,
+		fmt.Sprintf("%s %s applied as revision %d of %s", correction.Kind, correction.ID, revision.Revision, record.ID))
+	return correction, nil
+}
+
+// RejectCorrection closes a pending correction without changing the record. The note is required
+// so the requester learns why.
+func (s *CorrectionService) RejectCorrection(ctx context.Context, userID, id, note string) (*domain.AttendanceCorrection, error) {
+	correction, err := s.reviewable(ctx, userID, id, note)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(note) == "" {
+		return nil, apperrors.NewValidationError("note is required when rejecting a correction")
+	}
+
+	now := time.Now()
+	correction.Status = domain.CorrectionRejected
+	correction.ReviewedBy = actorID(ctx)
+	correction.ReviewedByName = ports.GetUsername(ctx)
+	correction.ReviewNote = strings.TrimSpace(note)
+	correction.ReviewedAt = &now
+	if err := s.repo.Reject(ctx, correction); err != nil {
+		return nil, err
+	}
+	s.analytics.LogActivity(ctx, correction.UserID, "Attendance Correction Rejected", "attendance", correction.AttendanceID,
+		fmt.Sprintf("%s %s rejected: %s", correction.Kind, correction.ID, correction.ReviewNote))
+	return correction, nil
+}
+
+func (s *CorrectionService) ListRevisions(ctx context.Context, userID, attendanceID string) ([]domain.AttendanceRevision, error) {
+	if _, err := s.attendanceRepo.Get(ctx, userID, attendanceID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListRevisions(ctx, userID, attendanceID)
+}
+
+// reviewable loads a pending correction the caller may approve or reject. Nobody reviews their own request.
+func (s *CorrectionService) reviewable(ctx context.Context, userID, id, note string) (*domain.AttendanceCorrection, error) {
+	if err := ports.Authorize(ctx, domain.PermAttendanceApprove); err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(note)) > domain.MaxCorrectionReasonLength {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("note must be at most %d characters", domain.MaxCorrectionReasonLength))
+	}
+	correction, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ports.AuthorizeSite(ctx, domain.PermAttendanceApprove, correction.SiteID); err != nil {
+		return nil, err
+	}
+	if correction.Status != domain.CorrectionPending {
+		return nil, apperrors.NewConflict(fmt.Sprintf("correction has already been %s", correction.Status))
+	}
+	if correction.RequestedBy == actorID(ctx) {
+		return nil, apperrors.NewPermissionDenied("a correction must be reviewed by someone other than its requester")
+	}
+	return correction, nil
+}
+
+// actorID identifies who requests or reviews a correction, or enters attendance by hand: the
+// individual login when there is one, else the API key, else the organisation account itself.
+func actorID(ctx context.Context) string {
+	if id := ports.GetMemberID(ctx); id != "" {
+		return id
+	}
+	if id := ports.GetAPIKeyID(ctx); id != "" {
+		return "api_key:" + id
+	}
+	return ports.GetUserID(ctx)
+}
+
+func truncateToSecond(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	v := t.Truncate(time.Second)
+	return &v
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// fakeCorrectionRepo keeps corrections in memory and records what was applied.
+type fakeCorrectionRepo struct {
+	corrections map[string]*domain.AttendanceCorrection
+	revisions   []domain.AttendanceRevision
+}
+
+func newFakeCorrectionRepo() *fakeCorrectionRepo {
+	return &fakeCorrectionRepo{corrections: map[string]*domain.AttendanceCorrection{}}
+}
+
+func (f *fakeCorrectionRepo) Create(ctx context.Context, c *domain.AttendanceCorrection) error {
+	stored := *c
+	f.corrections[c.ID] = &stored
+	return nil
+}
+
+func (f *fakeCorrectionRepo) Get(ctx context.Context, userID, id string) (*domain.AttendanceCorrection, error) {
+	if c, ok := f.corrections[id]; ok {
+		copied := *c
+		return &copied, nil
+	}
+	return nil, apperrors.NewNotFound("correction", id)
+}
+
+func (f *fakeCorrectionRepo) List(ctx context.Context, userID, attendanceID, status string) ([]domain.AttendanceCorrection, error) {
+	var out []domain.AttendanceCorrection
+	for _, c := range f.corrections {
+		if (attendanceID == "" || c.AttendanceID == attendanceID) && (status == "" || c.Status == status) {
+			out = append(out, *c)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCorrectionRepo) Apply(ctx context.Context, c *domain.AttendanceCorrection, rev *domain.AttendanceRevision) error {
+	stored := *c
+	f.corrections[c.ID] = &stored
+	f.revisions = append(f.revisions, *rev)
+	return nil
+}
+
+func (f *fakeCorrectionRepo) Reject(ctx context.Context, c *domain.AttendanceCorrection) error {
+	stored := *c
+	f.corrections[c.ID] = &stored
+	return nil
+}
+
+func (f *fakeCorrectionRepo) ListRevisions(ctx context.Context, userID, attendanceID string) ([]domain.AttendanceRevision, error) {
+	return f.revisions, nil
+}
+
+func siteMemberContext(memberID, role string, siteIDs ...string) context.Context {
+	return context.WithValue(roleContext("user1", role, siteIDs...), ports.MemberIDKey, memberID)
+}
+
+func newTestCorrectionService(record *domain.Attendance) (ports.CorrectionService, *fakeCorrectionRepo) {
+	repo := newFakeCorrectionRepo()
+	attendance := new(MockAttendanceRepository)
+	attendance.On("Get", mock.Anything, mock.Anything, record.ID).Return(record, nil)
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return NewCorrectionService(repo, attendance, analytics), repo
+}
+
+func TestCorrectionService_RequestCorrection_Permissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		allowed bool
+	}{
+		{"manager", roleContext("user1", domain.RoleManager), true},
+		{"pic on own site", roleContext("user1", domain.RolePIC, "s1"), true},
+		{"pic on another site", roleContext("user1", domain.RolePIC, "s2"), false},
+		{"viewer", roleContext("user1", domain.RoleViewer), false},
+		{"worker", roleContext("user1", domain.RoleWorker), false},
+	}
+
+	in := mustTime("2026-03-02T08:00:00+08:00")
+	proposed := mustTime("2026-03-02T07:30:00+08:00")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in, Status: domain.SubmissionStatusPending}
+			svc, repo := newTestCorrectionService(record)
+
+			_, err := svc.RequestCorrection(tt.ctx, "user1", "ATT-1", "Device clock was wrong", &proposed, nil)
+
+			if tt.allowed {
+				assert.NoError(t, err)
+				assert.Len(t, repo.corrections, 1)
+			} else {
+				assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+				assert.Empty(t, repo.corrections)
+			}
+		})
+	}
+}
+
+func TestCorrectionService_RequestCorrection_Validation(t *testing.T) {
+	in, out := mustTime("2026-03-02T08:00:00+08:00"), mustTime("2026-03-02T17:00:00+08:00")
+	before := mustTime("2026-03-02T07:00:00+08:00")
+
+	tests := []struct {
+		name    string
+		reason  string
+		timeIn  *time.Time
+		timeOut *time.Time
+	}{
+		{"no reason", "  ", &in, &before},
+		{"no time_in", "Forgot to punch", nil, &out},
+		{"time_out before time_in", "Forgot to punch", &in, &before},
+		{"unchanged", "Forgot to punch", &in, &out},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in, TimeOut: &out}
+			svc, repo := newTestCorrectionService(record)
+
+			_, err := svc.RequestCorrection(roleContext("user1", domain.RoleManager), "user1", "ATT-1", tt.reason, tt.timeIn, tt.timeOut)
+
+			assert.ErrorIs(t, err, apperrors.ErrValidation)
+			assert.Empty(t, repo.corrections)
+		})
+	}
+}
+
+func TestCorrectionService_RequestCorrection_OnePendingPerRecord(t *testing.T) {
+	in := mustTime("2026-03-02T08:00:00+08:00")
+	out := mustTime("2026-03-02T17:00:00+08:00")
+	record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in}
+	svc, _ := newTestCorrectionService(record)
+	ctx := roleContext("user1", domain.RoleManager)
+
+	_, err := svc.RequestCorrection(ctx, "user1", "ATT-1", "Forgot to punch out", &in, &out)
+	assert.NoError(t, err)
+
+	_, err = svc.RequestCorrection(ctx, "user1", "ATT-1", "Forgot to punch out", &in, &out)
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+}
+
+func TestCorrectionService_SubmittedRecordNeedsAmendment(t *testing.T) {
+	in := mustTime("2026-03-02T08:00:00+08:00")
+	out := mustTime("2026-03-02T17:00:00+08:00")
+	record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in, Status: domain.SubmissionStatusSubmitted, Revision: 1}
+	svc, repo := newTestCorrectionService(record)
+
+	requested, err := svc.RequestCorrection(siteMemberContext("m-pic", domain.RolePIC, "s1"), "user1", "ATT-1", "Left at 17:00", &in, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CorrectionKindAmendment, requested.Kind)
+
+	approved, err := svc.ApproveCorrection(siteMemberContext("m-manager", domain.RoleManager), "user1", requested.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CorrectionApproved, approved.Status)
+	assert.Equal(t, "m-manager", approved.ReviewedBy)
+	if assert.Len(t, repo.revisions, 1) {
+		rev := repo.revisions[0]
+		assert.Equal(t, 2, rev.Revision)
+		assert.Equal(t, domain.CorrectionKindAmendment, rev.Kind)
+		assert.Equal(t, domain.SubmissionStatusSubmitted, rev.PreviousStatus)
+		assert.Nil(t, rev.PreviousTimeOut)
+		assert.True(t, rev.TimeOut.Equal(out))
+		assert.Equal(t, "m-pic", rev.RequestedBy)
+		assert.Equal(t, "m-manager", rev.ApprovedBy)
+	}
+}
+
+func TestCorrectionService_ApproveCorrection_Rules(t *testing.T) {
+	in := mustTime("2026-03-02T08:00:00+08:00")
+	proposed := mustTime("2026-03-02T07:30:00+08:00")
+
+	request := func(t *testing.T) (ports.CorrectionService, *fakeCorrectionRepo, string) {
+		record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in}
+		svc, repo := newTestCorrectionService(record)
+		c, err := svc.RequestCorrection(siteMemberContext("m-manager", domain.RoleManager), "user1", "ATT-1", "Device clock was wrong", &proposed, nil)
+		assert.NoError(t, err)
+		return svc, repo, c.ID
+	}
+
+	t.Run("requester cannot approve", func(t *testing.T) {
+		svc, repo, id := request(t)
+		_, err := svc.ApproveCorrection(siteMemberContext("m-manager", domain.RoleManager), "user1", id, "")
+		assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+		assert.Empty(t, repo.revisions)
+	})
+
+	t.Run("pic cannot approve", func(t *testing.T) {
+		svc, repo, id := request(t)
+		_, err := svc.ApproveCorrection(siteMemberContext("m-pic", domain.RolePIC, "s1"), "user1", id, "")
+		assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+		assert.Empty(t, repo.revisions)
+	})
+
+	t.Run("reviewed once", func(t *testing.T) {
+		svc, repo, id := request(t)
+		_, err := svc.RejectCorrection(siteMemberContext("m-other", domain.RoleManager), "user1", id, "Clock was right")
+		assert.NoError(t, err)
+		_, err = svc.ApproveCorrection(siteMemberContext("m-other", domain.RoleManager), "user1", id, "")
+		assert.ErrorIs(t, err, apperrors.ErrConflict)
+		assert.Empty(t, repo.revisions)
+	})
+
+	t.Run("reject needs a note", func(t *testing.T) {
+		svc, _, id := request(t)
+		_, err := svc.RejectCorrection(siteMemberContext("m-other", domain.RoleManager), "user1", id, " ")
+		assert.ErrorIs(t, err, apperrors.ErrValidation)
+	})
+}
+
+func TestCorrectionService_ApproveCorrection_RecordChangedSinceRequest(t *testing.T) {
+	in := mustTime("2026-03-02T08:00:00+08:00")
+	proposed := mustTime("2026-03-02T07:30:00+08:00")
+	record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in}
+	svc, repo := newTestCorrectionService(record)
+
+	c, err := svc.RequestCorrection(siteMemberContext("m-pic", domain.RolePIC, "s1"), "user1", "ATT-1", "Device clock was wrong", &proposed, nil)
+	assert.NoError(t, err)
+
+	// Re-derived with a new time_out before the review
+	out := mustTime("2026-03-02T17:00:00+08:00")
+	record.TimeOut = &out
+
+	_, err = svc.ApproveCorrection(siteMemberContext("m-manager", domain.RoleManager), "user1", c.ID, "")
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+	assert.Empty(t, repo.revisions)
+}
+
+func TestCorrectionService_ApproveCorrection_RecordBeingSubmitted(t *testing.T) {
+	in := mustTime("2026-03-02T08:00:00+08:00")
+	proposed := mustTime("2026-03-02T07:30:00+08:00")
+	record := &domain.Attendance{ID: "ATT-1", SiteID: "s1", UserID: "user1", TimeIn: &in}
+	svc, repo := newTestCorrectionService(record)
+
+	c, err := svc.RequestCorrection(siteMemberContext("m-pic", domain.RolePIC, "s1"), "user1", "ATT-1", "Device clock was wrong", &proposed, nil)
+	assert.NoError(t, err)
+
+	// Claimed by a submission run before the review
+	record.Status = domain.SubmissionStatusSubmitting
+	_, err = svc.ApproveCorrection(siteMemberContext("m-manager", domain.RoleManager), "user1", c.ID, "")
+	assert.ErrorIs(t, err, apperrors.ErrConflict)
+	assert.Empty(t, repo.revisions)
+
+	// Once CPD has it, the correction is approved as an amendment
+	record.Status = domain.SubmissionStatusSubmitted
+	approved, err := svc.ApproveCorrection(siteMemberContext("m-manager", domain.RoleManager), "user1", c.ID, "")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CorrectionKindAmendment, approved.Kind)
+	assert.Len(t, repo.revisions, 1)
+}
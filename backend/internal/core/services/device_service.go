@@ -35,9 +35,15 @@ func (s *DeviceService) RegisterDevice(ctx context.Context, sn, model, userID st
 		return nil, fmt.Errorf("serial number and model are required")
 	}
 
-	// Default user if missing (legacy behavior restored per user request)
+	// Devices registered without an owner belong to the caller's own organisation
 	if userID == "" {
-		userID = "tenant-vendor-1"
+		userID = ports.GetUserID(ctx)
+	}
+	if userID == "" {
+		return nil, apperrors.NewValidationError("user_id is required")
+	}
+	if err := ports.AuthorizeTenant(ctx, userID); err != nil {
+		return nil, err
 	}
 
 	d := &domain.Device{
@@ -86,6 +92,10 @@ func (s *DeviceService) UpdateDevice(ctx context.Context, userID, id string, par
 	}
 	if v, ok := params["user_id"].(string); ok {
 		if v != d.UserID {
+			// A device may only move to an organisation the caller also manages
+			if err := ports.AuthorizeTenant(ctx, v); err != nil {
+				return err
+			}
 			d.UserID = v
 			// Clear site association if owner changes to maintain integrity
 			d.SiteID = nil
@@ -114,6 +124,9 @@ func (s *DeviceService) AssignDevicesToUser(ctx context.Context, userID string,
 	if len(deviceIDs) == 0 {
 		return nil
 	}
+	if err := ports.AuthorizeTenant(ctx, userID); err != nil {
+		return err
+	}
 	err := s.repo.AssignToUser(ctx, userID, deviceIDs)
 	if err == nil {
 		actorUserID := ports.GetUserID(ctx)
@@ -134,3 +147,87 @@ func (s *DeviceService) AssignDevicesToSite(ctx context.Context, siteID string,
 	}
 	return err
 }
+
+// RecordHeartbeat applies a bridge telemetry report to the device with the given SN.
+// The device must belong to the tenant whose bridge sent the report. Decommissioned
+// devices keep their inactive status but still record battery and heartbeat.
+func (s *DeviceService) RecordHeartbeat(ctx context.Context, ownerID string, hb domain.DeviceHeartbeat) error {
+	if hb.SN == "" {
+		return apperrors.NewValidationError("sn is required")
+	}
+	if hb.Battery != nil && (*hb.Battery < 0 || *hb.Battery > 100) {
+		return apperrors.NewValidationError(fmt.Sprintf("battery out of range: %d", *hb.Battery))
+	}
+
+	next := hb.Status
+	reason := domain.DeviceStatusReasonReported
+	switch next {
+	case "":
+		next = domain.DeviceStatusOnline
+		reason = domain.DeviceStatusReasonHeartbeat
+	case domain.DeviceStatusOnline, domain.DeviceStatusOffline, domain.DeviceStatusUnknown:
+	default:
+		return apperrors.NewValidationError(fmt.Sprintf("invalid device status: %s", next))
+	}
+
+	d, err := s.repo.GetBySN(ctx, hb.SN)
+	if err != nil {
+		return err
+	}
+	if d == nil {
+		return apperrors.NewNotFound("device", hb.SN)
+	}
+	if d.UserID != ownerID {
+		return apperrors.NewPermissionDenied(fmt.Sprintf("device %s is not assigned to %s", hb.SN, ownerID))
+	}
+	if d.Status == domain.DeviceStatusInactive {
+		next = domain.DeviceStatusInactive
+	}
+
+	now := time.Now()
+	if err := s.repo.UpdateTelemetry(ctx, d.ID, next, hb.Battery, now); err != nil {
+		return err
+	}
+	if next != d.Status {
+		return s.repo.RecordStatusChange(ctx, &domain.DeviceStatusChange{
+			DeviceID:   d.ID,
+			FromStatus: d.Status,
+			ToStatus:   next,
+			Reason:     reason,
+			ChangedAt:  now,
+		})
+	}
+	return nil
+}
+
+// MarkSilentDevicesOffline flips online devices to offline when no heartbeat has arrived within silence.
+func (s *DeviceService) MarkSilentDevicesOffline(ctx context.Context, silence time.Duration) (int, error) {
+	now := time.Now()
+	cutoff := now.Add(-silence)
+	devices, err := s.repo.ListSilentDevices(ctx, cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	flipped := 0
+	for _, d := range devices {
+		changed, err := s.repo.MarkOfflineIfSilent(ctx, d.ID, cutoff)
+		if err != nil {
+			return flipped, err
+		}
+		if !changed {
+			continue
+		}
+		flipped++
+		if err := s.repo.RecordStatusChange(ctx, &domain.DeviceStatusChange{
+			DeviceID:   d.ID,
+			FromStatus: d.Status,
+			ToStatus:   domain.DeviceStatusOffline,
+			Reason:     domain.DeviceStatusReasonSilence,
+			ChangedAt:  now,
+		}); err != nil {
+			return flipped, err
+		}
+	}
+	return flipped, nil
+}
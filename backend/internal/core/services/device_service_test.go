@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockDeviceRepository struct {
+	mock.Mock
+}
+
+func (m *MockDeviceRepository) Get(ctx context.Context, userID, id string) (*domain.Device, error) {
+	args := m.Called(ctx, userID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) GetBySN(ctx context.Context, sn string) (*domain.Device, error) {
+	args := m.Called(ctx, sn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) List(ctx context.Context, userID, siteID string) ([]domain.Device, error) {
+	args := m.Called(ctx, userID, siteID)
+	return args.Get(0).([]domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) ListSNsBySiteID(ctx context.Context, userID, siteID string) ([]string, error) {
+	args := m.Called(ctx, userID, siteID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDeviceRepository) Create(ctx context.Context, d *domain.Device) error {
+	return m.Called(ctx, d).Error(0)
+}
+
+func (m *MockDeviceRepository) Update(ctx context.Context, d *domain.Device) error {
+	return m.Called(ctx, d).Error(0)
+}
+
+func (m *MockDeviceRepository) Delete(ctx context.Context, userID, id string) error {
+	return m.Called(ctx, userID, id).Error(0)
+}
+
+func (m *MockDeviceRepository) AssignToUser(ctx context.Context, userID string, deviceIDs []string) error {
+	return m.Called(ctx, userID, deviceIDs).Error(0)
+}
+
+func (m *MockDeviceRepository) AssignToSite(ctx context.Context, siteID string, deviceIDs []string) error {
+	return m.Called(ctx, siteID, deviceIDs).Error(0)
+}
+
+func (m *MockDeviceRepository) UpdateTelemetry(ctx context.Context, deviceID string, status domain.DeviceStatus, battery *int, heartbeat time.Time) error {
+	return m.Called(ctx, deviceID, status, battery, heartbeat).Error(0)
+}
+
+func (m *MockDeviceRepository) ListSilentDevices(ctx context.Context, cutoff time.Time) ([]domain.Device, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).([]domain.Device), args.Error(1)
+}
+
+func (m *MockDeviceRepository) MarkOfflineIfSilent(ctx context.Context, deviceID string, cutoff time.Time) (bool, error) {
+	args := m.Called(ctx, deviceID, cutoff)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDeviceRepository) RecordStatusChange(ctx context.Context, c *domain.DeviceStatusChange) error {
+	return m.Called(ctx, c).Error(0)
+}
+
+func TestDeviceService_RecordHeartbeat_TransitionRecorded(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	svc := NewDeviceService(repo, new(MockAnalyticsService))
+	ctx := context.Background()
+	battery := 80
+
+	repo.On("GetBySN", ctx, "SN-1").Return(&domain.Device{ID: "d1", SN: "SN-1", UserID: "owner1", Status: domain.DeviceStatusOffline}, nil)
+	repo.On("UpdateTelemetry", ctx, "d1", domain.DeviceStatusOnline, &battery, mock.Anything).Return(nil)
+	repo.On("RecordStatusChange", ctx, mock.MatchedBy(func(c *domain.DeviceStatusChange) bool {
+		return c.DeviceID == "d1" && c.FromStatus == domain.DeviceStatusOffline && c.ToStatus == domain.DeviceStatusOnline && c.Reason == domain.DeviceStatusReasonHeartbeat
+	})).Return(nil)
+
+	err := svc.RecordHeartbeat(ctx, "owner1", domain.DeviceHeartbeat{SN: "SN-1", Battery: &battery})
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestDeviceService_RecordHeartbeat_NoTransitionNoHistory(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	svc := NewDeviceService(repo, new(MockAnalyticsService))
+	ctx := context.Background()
+
+	repo.On("GetBySN", ctx, "SN-1").Return(&domain.Device{ID: "d1", SN: "SN-1", UserID: "owner1", Status: domain.DeviceStatusOnline}, nil)
+	repo.On("UpdateTelemetry", ctx, "d1", domain.DeviceStatusOnline, (*int)(nil), mock.Anything).Return(nil)
+
+	err := svc.RecordHeartbeat(ctx, "owner1", domain.DeviceHeartbeat{SN: "SN-1"})
+	assert.NoError(t, err)
+	repo.AssertNotCalled(t, "RecordStatusChange", mock.Anything, mock.Anything)
+}
+
+func TestDeviceService_RecordHeartbeat_RejectsForeignDevice(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	svc := NewDeviceService(repo, new(MockAnalyticsService))
+	ctx := context.Background()
+
+	repo.On("GetBySN", ctx, "SN-1").Return(&domain.Device{ID: "d1", SN: "SN-1", UserID: "owner2", Status: domain.DeviceStatusOnline}, nil)
+
+	err := svc.RecordHeartbeat(ctx, "owner1", domain.DeviceHeartbeat{SN: "SN-1"})
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "UpdateTelemetry", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeviceService_MarkSilentDevicesOffline(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	svc := NewDeviceService(repo, new(MockAnalyticsService))
+	ctx := context.Background()
+
+	silent := []domain.Device{
+		{ID: "d1", Status: domain.DeviceStatusOnline},
+		{ID: "d2", Status: domain.DeviceStatusOnline},
+	}
+	repo.On("ListSilentDevices", ctx, mock.Anything).Return(silent, nil)
+	repo.On("MarkOfflineIfSilent", ctx, "d1", mock.Anything).Return(true, nil)
+	// d2 reported in between the list and the update
+	repo.On("MarkOfflineIfSilent", ctx, "d2", mock.Anything).Return(false, nil)
+	repo.On("RecordStatusChange", ctx, mock.MatchedBy(func(c *domain.DeviceStatusChange) bool {
+		return c.DeviceID == "d1" && c.ToStatus == domain.DeviceStatusOffline && c.Reason == domain.DeviceStatusReasonSilence
+	})).Return(nil).Once()
+
+	n, err := svc.MarkSilentDevicesOffline(ctx, 5*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	repo.AssertExpectations(t)
+}
+
+func TestDeviceService_RegisterDevice_DefaultsToCallerOrganisation(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	analytics := new(MockAnalyticsService)
+	svc := NewDeviceService(repo, analytics)
+	ctx := context.WithValue(context.Background(), ports.UserIDKey, "user-1")
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantScope("user-1"))
+
+	repo.On("Create", ctx, mock.MatchedBy(func(d *domain.Device) bool { return d.UserID == "user-1" })).Return(nil)
+	analytics.On("LogActivity", ctx, "user-1", "Device Registered", "device", mock.Anything, mock.Anything).Return(nil)
+
+	d, err := svc.RegisterDevice(ctx, "SN-1", "FaceID", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", d.UserID)
+	repo.AssertExpectations(t)
+}
+
+func TestDeviceService_RegisterDevice_RejectsTenantOutsideScope(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	svc := NewDeviceService(repo, new(MockAnalyticsService))
+	ctx := context.WithValue(context.Background(), ports.UserIDKey, "vendor-1")
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantsScope("vendor-1", "user-1"))
+
+	_, err := svc.RegisterDevice(ctx, "SN-1", "FaceID", "user-2")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestDeviceService_UpdateDevice_RejectsOwnerOutsideScope(t *testing.T) {
+	repo := new(MockDeviceRepository)
+	svc := NewDeviceService(repo, new(MockAnalyticsService))
+	ctx := context.WithValue(context.Background(), ports.UserIDKey, "user-1")
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantScope("user-1"))
+
+	repo.On("Get", ctx, "user-1", "d1").Return(&domain.Device{ID: "d1", UserID: "user-1"}, nil)
+
+	err := svc.UpdateDevice(ctx, "user-1", "d1", map[string]interface{}{"user_id": "user-2"})
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
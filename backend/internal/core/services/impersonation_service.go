@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Default impersonation lifetimes. Sessions are short so a forgotten support tab does not stay
+// signed in to a client's data.
+const (
+	DefaultImpersonationTTL    = 30 * time.Minute
+	DefaultMaxImpersonationTTL = 4 * time.Hour
+)
+
+// ImpersonationConfig holds the token settings of the ImpersonationService. Zero TTLs fall back to the defaults.
+type ImpersonationConfig struct {
+	JWTSecret  string
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// ImpersonationService issues "act as tenant" tokens to vendor support staff. The token is an access
+// token of the tenant that also names the vendor actor, and it is read-only unless writes are requested.
+type ImpersonationService struct {
+	repo      ports.ImpersonationRepository
+	users     ports.UserRepository
+	members   ports.MemberRepository
+	cfg       ImpersonationConfig
+	analytics ports.AnalyticsService
+}
+
+func NewImpersonationService(repo ports.ImpersonationRepository, users ports.UserRepository, members ports.MemberRepository, cfg ImpersonationConfig, analytics ports.AnalyticsService) ports.ImpersonationService {
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = DefaultImpersonationTTL
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = DefaultMaxImpersonationTTL
+	}
+	return &ImpersonationService{repo: repo, users: users, members: members, cfg: cfg, analytics: analytics}
+}
+
+// Start opens an impersonation session on a tenant in the vendor's scope. A reason is mandatory
+// because it is the first thing a client asks about when reviewing its activity log.
+func (s *ImpersonationService) Start(ctx context.Context, tenantUserID, reason string, allowWrite bool, ttlMinutes int) (*domain.ImpersonationTicket, error) {
+	if !ports.IsVendorAdmin(ctx) || ports.GetImpersonation(ctx) != nil {
+		return nil, apperrors.NewPermissionDenied("only vendor managers may impersonate an organisation")
+	}
+	if err := ports.AuthorizeTenant(ctx, tenantUserID); err != nil {
+		return nil, err
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > 500 {
+		return nil, apperrors.NewValidationError("reason is required and must be at most 500 characters")
+	}
+	ttl := s.cfg.DefaultTTL
+	if ttlMinutes < 0 {
+		return nil, apperrors.NewValidationError("duration_minutes must be positive")
+	} else if ttlMinutes > 0 {
+		ttl = time.Duration(ttlMinutes) * time.Minute
+	}
+	if ttl > s.cfg.MaxTTL {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("duration_minutes must be at most %d", int(s.cfg.MaxTTL.Minutes())))
+	}
+
+	tenant, err := s.users.Get(ctx, tenantUserID)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, apperrors.NewNotFound("user", tenantUserID)
+	}
+	if tenant.UserType == domain.UserTypeVendor {
+		return nil, apperrors.NewValidationError("vendor organisations cannot be impersonated")
+	}
+	if tenant.Status != domain.StatusActive {
+		return nil, apperrors.NewValidationError("organisation is not active")
+	}
+
+	actor, err := s.members.Get(ctx, ports.GetMemberID(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if actor == nil {
+		return nil, apperrors.NewPermissionDenied("no member login in context")
+	}
+
+	now := time.Now()
+	imp := &domain.Impersonation{
+		ID:            uuid.NewString(),
+		ActorUserID:   actor.UserID,
+		ActorMemberID: actor.ID,
+		ActorUsername: actor.Username,
+		TenantUserID:  tenant.ID,
+		TenantName:    tenant.Name,
+		Reason:        reason,
+		ReadOnly:      !allowWrite,
+		ExpiresAt:     now.Add(ttl),
+		IPAddress:     ports.GetIPAddress(ctx),
+		CreatedAt:     now,
+	}
+	if err := s.repo.Create(ctx, imp); err != nil {
+		return nil, err
+	}
+
+	// "act" follows RFC 8693: the token's subject is the tenant, the actor is the vendor member
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id":   tenant.ID,
+		"member_id": actor.ID,
+		"username":  actor.Username,
+		"imp":       imp.ID,
+		"act":       map[string]string{"user_id": actor.UserID, "member_id": actor.ID},
+		"exp":       imp.ExpiresAt.Unix(),
+		"iat":       now.Unix(),
+	})
+	signed, err := token.SignedString([]byte(s.cfg.JWTSecret))
+	if err != nil {
+		return nil, errors.New("failed to issue token")
+	}
+
+	mode := "read-only"
+	if allowWrite {
+		mode = "read-write"
+	}
+	s.analytics.LogActivity(context.WithValue(ctx, ports.ImpersonationKey, imp), tenant.ID, "Impersonation Started", "impersonation", imp.ID,
+		fmt.Sprintf("%s started a %s session as %s for %d minutes: %s", actor.Username, mode, tenant.Name, int(ttl.Minutes()), reason))
+	return &domain.ImpersonationTicket{Impersonation: *imp, Token: signed}, nil
+}
+
+// End closes the session of the caller's impersonation token; the token is rejected from the next request on.
+func (s *ImpersonationService) End(ctx context.Context) error {
+	imp := ports.GetImpersonation(ctx)
+	if imp == nil {
+		return apperrors.NewValidationError("the request is not made under an impersonation session")
+	}
+	ended, err := s.repo.End(ctx, imp.ID)
+	if err != nil {
+		return err
+	}
+	if ended {
+		s.analytics.LogActivity(ctx, imp.TenantUserID, "Impersonation Ended", "impersonation", imp.ID,
+			fmt.Sprintf("%s ended the session as %s", imp.ActorUsername, imp.TenantName))
+	}
+	return nil
+}
+
+// List returns the latest impersonation sessions on a tenant. Vendor only.
+func (s *ImpersonationService) List(ctx context.Context, tenantUserID string) ([]domain.Impersonation, error) {
+	if !ports.IsVendorAdmin(ctx) {
+		return nil, apperrors.NewPermissionDenied("vendor privileges required")
+	}
+	if err := ports.AuthorizeTenant(ctx, tenantUserID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTenant(ctx, tenantUserID, 100)
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const impersonationTestSecret = "impersonation-test-secret-0123456789abcdef"
+
+// impersonationTestRepo is an in-memory ImpersonationRepository
+type impersonationTestRepo struct {
+	sessions map[string]*domain.Impersonation
+}
+
+func (r *impersonationTestRepo) Create(ctx context.Context, i *domain.Impersonation) error {
+	cp := *i
+	r.sessions[i.ID] = &cp
+	return nil
+}
+
+func (r *impersonationTestRepo) Get(ctx context.Context, id string) (*domain.Impersonation, error) {
+	return r.sessions[id], nil
+}
+
+func (r *impersonationTestRepo) ListByTenant(ctx context.Context, tenantUserID string, limit int) ([]domain.Impersonation, error) {
+	list := []domain.Impersonation{}
+	for _, i := range r.sessions {
+		if i.TenantUserID == tenantUserID {
+			list = append(list, *i)
+		}
+	}
+	return list, nil
+}
+
+func (r *impersonationTestRepo) End(ctx context.Context, id string) (bool, error) {
+	i := r.sessions[id]
+	if i == nil || i.EndedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	i.EndedAt = &now
+	return true, nil
+}
+
+// impersonationTestUsers serves Get from a map; the other UserRepository methods are not used
+type impersonationTestUsers struct {
+	ports.UserRepository
+	users map[string]*domain.User
+}
+
+func (r *impersonationTestUsers) Get(ctx context.Context, id string) (*domain.User, error) {
+	return r.users[id], nil
+}
+
+func newImpersonationTestService(analytics ports.AnalyticsService) (*impersonationTestRepo, ports.ImpersonationService) {
+	repo := &impersonationTestRepo{sessions: map[string]*domain.Impersonation{}}
+	users := &impersonationTestUsers{users: map[string]*domain.User{
+		"tenant-1": {ID: "tenant-1", Name: "Acme Builders", UserType: "client", Status: domain.StatusActive},
+		"vendor-1": {ID: "vendor-1", Name: "Vendor", UserType: domain.UserTypeVendor, Status: domain.StatusActive},
+	}}
+	members := new(MockMemberRepository)
+	members.On("Get", mock.Anything, "m-support").Return(&domain.Member{ID: "m-support", UserID: "vendor-1", Username: "support", Status: domain.StatusActive}, nil)
+	return repo, NewImpersonationService(repo, users, members, ImpersonationConfig{JWTSecret: impersonationTestSecret}, analytics)
+}
+
+func vendorMemberContext(scope domain.Scope) context.Context {
+	ctx := memberContext("vendor-1", "m-support", domain.RoleManager)
+	ctx = context.WithValue(ctx, ports.IsVendorKey, true)
+	return context.WithValue(ctx, ports.ScopeKey, scope)
+}
+
+func TestImpersonationService_Start_IsReadOnlyByDefault(t *testing.T) {
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.MatchedBy(func(ctx context.Context) bool {
+		return ports.GetImpersonation(ctx) != nil
+	}), "tenant-1", "Impersonation Started", "impersonation", mock.Anything, mock.Anything).Return(nil)
+	repo, svc := newImpersonationTestService(analytics)
+
+	ticket, err := svc.Start(vendorMemberContext(domain.GlobalScope()), "tenant-1", " ticket #42 ", false, 0)
+	require.NoError(t, err)
+
+	stored := repo.sessions[ticket.ID]
+	require.NotNil(t, stored)
+	assert.True(t, stored.ReadOnly)
+	assert.Equal(t, "ticket #42", stored.Reason)
+	assert.Equal(t, "vendor-1", stored.ActorUserID)
+	assert.Equal(t, "m-support", stored.ActorMemberID)
+	assert.WithinDuration(t, time.Now().Add(DefaultImpersonationTTL), stored.ExpiresAt, time.Minute)
+
+	claims := authTestParseJWT(t, ticket.Token, impersonationTestSecret)
+	assert.Equal(t, "tenant-1", claims["user_id"])
+	assert.Equal(t, "m-support", claims["member_id"])
+	assert.Equal(t, ticket.ID, claims["imp"])
+	assert.Equal(t, map[string]interface{}{"user_id": "vendor-1", "member_id": "m-support"}, claims["act"])
+	analytics.AssertExpectations(t)
+}
+
+func TestImpersonationService_Start_Rejections(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		tenantID string
+		reason   string
+		minutes  int
+		wantErr  error
+	}{
+		{"tenant login", memberContext("tenant-1", "m-1", domain.RoleManager), "tenant-1", "help", 0, apperrors.ErrPermissionDenied},
+		{"vendor viewer", context.WithValue(vendorMemberContext(domain.GlobalScope()), ports.RoleKey, domain.RoleViewer), "tenant-1", "help", 0, apperrors.ErrPermissionDenied},
+		{"tenant outside scope", vendorMemberContext(domain.TenantsScope("vendor-1", "tenant-2")), "tenant-1", "help", 0, apperrors.ErrPermissionDenied},
+		{"missing reason", vendorMemberContext(domain.GlobalScope()), "tenant-1", "  ", 0, apperrors.ErrValidation},
+		{"too long", vendorMemberContext(domain.GlobalScope()), "tenant-1", "help", 24 * 60, apperrors.ErrValidation},
+		{"vendor organisation", vendorMemberContext(domain.GlobalScope()), "vendor-1", "help", 0, apperrors.ErrValidation},
+		{"unknown organisation", vendorMemberContext(domain.GlobalScope()), "tenant-9", "help", 0, apperrors.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, svc := newImpersonationTestService(new(MockAnalyticsService))
+			_, err := svc.Start(tt.ctx, tt.tenantID, tt.reason, false, tt.minutes)
+			assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+			assert.Empty(t, repo.sessions)
+		})
+	}
+}
+
+func TestImpersonationService_End(t *testing.T) {
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, "tenant-1", "Impersonation Ended", "impersonation", "imp-1", mock.Anything).Return(nil).Once()
+	repo, svc := newImpersonationTestService(analytics)
+	imp := &domain.Impersonation{ID: "imp-1", TenantUserID: "tenant-1", ExpiresAt: time.Now().Add(time.Hour)}
+	repo.sessions[imp.ID] = imp
+
+	ctx := context.WithValue(context.Background(), ports.ImpersonationKey, imp)
+	require.NoError(t, svc.End(ctx))
+	require.NoError(t, svc.End(ctx)) // ending twice is not logged again
+	assert.False(t, repo.sessions["imp-1"].Active(time.Now()))
+	analytics.AssertExpectations(t)
+
+	err := svc.End(vendorMemberContext(domain.GlobalScope()))
+	assert.True(t, errors.Is(err, apperrors.ErrValidation))
+}
+
+// activityTestRepo records logged activity; the query methods are not used
+type activityTestRepo struct {
+	ports.AnalyticsRepository
+	logged []map[string]interface{}
+}
+
+func (r *activityTestRepo) LogActivity(ctx context.Context, log map[string]interface{}) error {
+	r.logged = append(r.logged, log)
+	return nil
+}
+
+func TestAnalyticsService_LogActivity_RecordsImpersonator(t *testing.T) {
+	repo := &activityTestRepo{}
+	svc := NewAnalyticsService(repo)
+
+	imp := &domain.Impersonation{ID: "imp-1", ActorUserID: "vendor-1", ActorMemberID: "m-support", TenantUserID: "tenant-1"}
+	ctx := memberContext("tenant-1", "m-support", domain.RoleManager)
+	ctx = context.WithValue(ctx, ports.UsernameKey, "support")
+	ctx = context.WithValue(ctx, ports.ImpersonationKey, imp)
+
+	require.NoError(t, svc.LogActivity(ctx, "", "Worker Updated", "worker", "W-1", "Updated worker"))
+	require.NoError(t, svc.LogActivity(memberContext("tenant-1", "m-1", domain.RoleManager), "", "Worker Updated", "worker", "W-1", "Updated worker"))
+
+	require.Len(t, repo.logged, 2)
+	assert.Equal(t, "tenant-1", repo.logged[0]["user_id"])
+	assert.Equal(t, "m-support", repo.logged[0]["member_id"])
+	assert.Equal(t, "vendor-1", repo.logged[0]["impersonator_user_id"])
+	assert.Equal(t, "imp-1", repo.logged[0]["impersonation_id"])
+	assert.NotContains(t, repo.logged[1], "impersonation_id")
+}
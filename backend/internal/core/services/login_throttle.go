@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/logger"
+)
+
+// checkThrottle rejects a login attempt while the account or source IP is locked out, or while
+// the account's progressive delay since its last failure has not yet passed.
+func (s *AuthService) checkThrottle(ctx context.Context, accountKey string) error {
+	now := time.Now()
+	for _, k := range s.throttleKeys(ctx, accountKey) {
+		t, err := s.throttles.Get(ctx, k.scope, k.key)
+		if err != nil {
+			return err
+		}
+		wait, locked := s.cfg.Throttle.RetryAfter(t, now)
+		if wait <= 0 {
+			continue
+		}
+		if locked {
+			return apperrors.NewTooManyRequests("too many failed login attempts; try again later", wait)
+		}
+		return apperrors.NewTooManyRequests("please wait before trying again", wait)
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed attempt against the account and the source IP and locks
+// whichever reached its threshold. Tracking errors are logged; the login has failed either way.
+// member is nil when the username does not exist.
+func (s *AuthService) recordLoginFailure(ctx context.Context, accountKey string, member *domain.Member) {
+	now := time.Now()
+	for _, k := range s.throttleKeys(ctx, accountKey) {
+		t, err := s.throttles.RecordFailure(ctx, k.scope, k.key, now, s.cfg.Throttle.Window)
+		if err != nil {
+			logger.Errorf("[AuthService] Failed to record login failure for %s %s: %v", k.scope, k.key, err)
+			continue
+		}
+		max := s.cfg.Throttle.MaxFailures(k.scope)
+		if t == nil || max <= 0 || t.Failures < max || t.LockedUntil != nil {
+			continue
+		}
+
+		until := now.Add(s.cfg.Throttle.LockoutDuration)
+		if err := s.throttles.Lock(ctx, k.scope, k.key, until); err != nil {
+			logger.Errorf("[AuthService] Failed to lock %s %s: %v", k.scope, k.key, err)
+			continue
+		}
+		s.logLockout(ctx, k.scope, k.key, member, t.Failures, until)
+	}
+}
+
+func (s *AuthService) logLockout(ctx context.Context, scope, key string, member *domain.Member, failures int, until time.Time) {
+	logger.Infof("[AuthService] Locked login %s %s until %s after %d failed attempts from %s", scope, key, until.Format(time.RFC3339), failures, attemptSource(ctx))
+
+	if scope == domain.ThrottleScopeIP {
+		s.analyticsService.LogActivity(ctx, "system", "Login Locked", "ip", key,
+			fmt.Sprintf("Source IP %s locked until %s after %d failed login attempts from %s", key, until.Format(time.RFC3339), failures, attemptSource(ctx)))
+		return
+	}
+
+	// Unknown usernames are locked as well, so probing cannot tell them apart from real ones
+	ownerID, targetID := "system", key
+	if member != nil {
+		ownerID, targetID = member.UserID, member.ID
+		ctx = loginContext(ctx, member)
+	}
+	s.analyticsService.LogActivity(ctx, ownerID, "Account Locked", "user", targetID,
+		fmt.Sprintf("Login %s locked until %s after %d failed attempts from %s", key, until.Format(time.RFC3339), failures, attemptSource(ctx)))
+}
+
+// attemptSource describes where a login attempt came from: the client address, followed by the
+// proxy that reported it when the request came through a trusted proxy.
+func attemptSource(ctx context.Context) string {
+	ip, peer := ports.GetIPAddress(ctx), ports.GetPeerAddress(ctx)
+	if peer == "" || peer == ip {
+		return ip
+	}
+	return fmt.Sprintf("%s via %s", ip, peer)
+}
+
+func (s *AuthService) clearThrottle(ctx context.Context, scope, key string) {
+	if _, err := s.throttles.Clear(ctx, scope, key); err != nil {
+		logger.Errorf("[AuthService] Failed to clear login failures for %s %s: %v", scope, key, err)
+	}
+}
+
+type throttleKey struct {
+	scope, key string
+}
+
+// throttleKeys lists the counters an attempt is checked against. Internal callers without a
+// source IP are only tracked per account.
+func (s *AuthService) throttleKeys(ctx context.Context, accountKey string) []throttleKey {
+	keys := []throttleKey{{domain.ThrottleScopeAccount, accountKey}}
+	if ip := ports.GetIPAddress(ctx); ip != "" {
+		keys = append(keys, throttleKey{domain.ThrottleScopeIP, ip})
+	}
+	return keys
+}
+
+// ListLockouts returns the accounts and source IPs that are currently locked. Vendor only.
+func (s *AuthService) ListLockouts(ctx context.Context) ([]domain.LoginThrottle, error) {
+	if !ports.IsVendorAdmin(ctx) {
+		return nil, apperrors.NewPermissionDenied("only administrators can view login lockouts")
+	}
+	return s.throttles.ListLocked(ctx, time.Now())
+}
+
+// Unlock lifts a lockout and forgets the failures counted so far. Vendor only; tenant managers
+// unlock their own members through MemberService.UnlockMember.
+func (s *AuthService) Unlock(ctx context.Context, scope, key string) error {
+	if !ports.IsVendorAdmin(ctx) {
+		return apperrors.NewPermissionDenied("only administrators can lift login lockouts")
+	}
+	if scope != domain.ThrottleScopeAccount && scope != domain.ThrottleScopeIP {
+		return apperrors.NewValidationError(fmt.Sprintf("invalid lockout scope %q", scope))
+	}
+
+	cleared, err := s.throttles.Clear(ctx, scope, key)
+	if err != nil {
+		return err
+	}
+	if !cleared {
+		return apperrors.NewNotFound("lockout", key)
+	}
+	s.analyticsService.LogActivity(ctx, "system", "Login Unlocked", scope, key, fmt.Sprintf("Lifted login lockout of %s %s", scope, key))
+	return nil
+}
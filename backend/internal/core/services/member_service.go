@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/logger"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MemberService manages the individual logins of a tenant organisation.
+// Every method requires members:manage; vendors may manage any organisation.
+type MemberService struct {
+	repo      ports.MemberRepository
+	sessions  ports.SessionRepository
+	throttles ports.LoginThrottleRepository
+	analytics ports.AnalyticsService
+	passwords PasswordConfig
+}
+
+func NewMemberService(repo ports.MemberRepository, sessions ports.SessionRepository, throttles ports.LoginThrottleRepository, analytics ports.AnalyticsService, passwords PasswordConfig) ports.MemberService {
+	if passwords.ResetTTL <= 0 {
+		passwords.ResetTTL = DefaultPasswordResetTTL
+	}
+	return &MemberService{repo: repo, sessions: sessions, throttles: throttles, analytics: analytics, passwords: passwords}
+}
+
+func (s *MemberService) ListMembers(ctx context.Context, userID string) ([]domain.Member, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByUser(ctx, userID)
+}
+
+func (s *MemberService) GetMember(ctx context.Context, userID, id string) (*domain.Member, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.load(ctx, userID, id)
+}
+
+func (s *MemberService) CreateMember(ctx context.Context, userID string, m *domain.Member, password string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	m.Username = strings.TrimSpace(m.Username)
+	m.Name = strings.TrimSpace(m.Name)
+	if m.Username == "" || m.Name == "" {
+		return apperrors.NewValidationError("username and name are required")
+	}
+	if err := s.passwords.validatePassword(m.Username, password); err != nil {
+		return err
+	}
+	if m.Role == "" {
+		m.Role = domain.RoleViewer
+	}
+	if err := validateRoleAssignment(m.Role, m.SiteIDs); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	m.ID = ""
+	m.UserID = userID
+	m.Status = domain.StatusActive
+	m.PasswordHash = string(hash)
+	// The manager knows this password, so the member replaces it at first login
+	m.MustChangePassword = true
+
+	if err := s.repo.Create(ctx, m); err != nil {
+		return err
+	}
+	if domain.RoleIsSiteScoped(m.Role) {
+		if err := s.repo.SetRole(ctx, m.ID, m.Role, m.SiteIDs); err != nil {
+			return err
+		}
+	}
+
+	s.analytics.LogActivity(ctx, userID, "Member Created", "member", m.ID, fmt.Sprintf("Added %s (%s) as %s", m.Name, m.Username, m.Role))
+	return nil
+}
+
+func (s *MemberService) UpdateMember(ctx context.Context, userID, id string, payload map[string]interface{}) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	m, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if name, ok := payload["name"].(string); ok && strings.TrimSpace(name) != "" {
+		m.Name = strings.TrimSpace(name)
+	}
+	if username, ok := payload["username"].(string); ok && strings.TrimSpace(username) != "" {
+		m.Username = strings.TrimSpace(username)
+	}
+	if email, ok := payload["email"].(string); ok {
+		m.Email = email
+	}
+	if status, ok := payload["status"].(string); ok {
+		if status != domain.StatusActive && status != domain.StatusInactive {
+			return apperrors.NewValidationError(fmt.Sprintf("invalid status %q", status))
+		}
+		if status == domain.StatusInactive && id == ports.GetMemberID(ctx) {
+			return apperrors.NewValidationError("you cannot deactivate your own login")
+		}
+		m.Status = status
+	}
+	signOut := ""
+	if m.Status == domain.StatusInactive {
+		signOut = "login deactivated"
+	}
+	if pwd, ok := payload["password"].(string); ok && pwd != "" {
+		if err := s.passwords.validatePassword(m.Username, pwd); err != nil {
+			return err
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		now := time.Now()
+		m.PasswordHash = string(hash)
+		m.PasswordChangedAt = &now
+		m.MustChangePassword = id != ports.GetMemberID(ctx)
+		if signOut == "" {
+			signOut = "password changed"
+		}
+	}
+
+	if err := s.repo.Update(ctx, m); err != nil {
+		return err
+	}
+	if signOut != "" {
+		s.revokeSessions(ctx, id, signOut)
+	}
+	s.analytics.LogActivity(ctx, userID, "Member Updated", "member", id, fmt.Sprintf("Updated login %s", m.Username))
+	return nil
+}
+
+func (s *MemberService) DeactivateMember(ctx context.Context, userID, id string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	if id == ports.GetMemberID(ctx) {
+		return apperrors.NewValidationError("you cannot deactivate your own login")
+	}
+	m, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	m.Status = domain.StatusInactive
+	if err := s.repo.Update(ctx, m); err != nil {
+		return err
+	}
+	s.revokeSessions(ctx, id, "login deactivated")
+	s.analytics.LogActivity(ctx, userID, "Member Deactivated", "member", id, fmt.Sprintf("Deactivated login %s", m.Username))
+	return nil
+}
+
+// AssignRole sets a member's role and, for PICs, the sites they are responsible for.
+func (s *MemberService) AssignRole(ctx context.Context, userID, id, role string, siteIDs []string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	if err := validateRoleAssignment(role, siteIDs); err != nil {
+		return err
+	}
+	if id == ports.GetMemberID(ctx) {
+		return apperrors.NewValidationError("you cannot change your own role")
+	}
+	m, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.SetRole(ctx, id, role, siteIDs); err != nil {
+		return err
+	}
+
+	details := fmt.Sprintf("Role of %s changed from %s to %s", m.Username, m.Role, role)
+	if domain.RoleIsSiteScoped(role) {
+		details += fmt.Sprintf(" for sites %s", strings.Join(siteIDs, ", "))
+	}
+	s.analytics.LogActivity(ctx, userID, "Role Assigned", "member", id, details)
+	return nil
+}
+
+// IssuePasswordReset creates a one-time token for a member who cannot sign in. The manager passes
+// it on; the member redeems it at POST /api/auth/password/reset to choose a new password.
+func (s *MemberService) IssuePasswordReset(ctx context.Context, userID, id string) (*domain.PasswordResetTicket, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	m, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if m.Status != domain.StatusActive {
+		return nil, apperrors.NewValidationError("cannot reset the password of an inactive login")
+	}
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+	reset := &domain.PasswordReset{
+		TokenHash: hashToken(token),
+		MemberID:  id,
+		CreatedBy: ports.GetMemberID(ctx),
+		ExpiresAt: time.Now().Add(s.passwords.ResetTTL),
+	}
+	if err := s.repo.CreatePasswordReset(ctx, reset); err != nil {
+		return nil, err
+	}
+
+	s.analytics.LogActivity(ctx, userID, "Password Reset Issued", "member", id, fmt.Sprintf("Issued a password reset token for %s", m.Username))
+	return &domain.PasswordResetTicket{MemberID: id, Token: token, ExpiresAt: reset.ExpiresAt}, nil
+}
+
+// UnlockMember lifts a login lockout of one of the organisation's members before it expires.
+// Lockouts of source IPs are lifted by vendors through AuthService.Unlock.
+func (s *MemberService) UnlockMember(ctx context.Context, userID, id string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	m, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	cleared, err := s.throttles.Clear(ctx, domain.ThrottleScopeAccount, strings.ToLower(m.Username))
+	if err != nil {
+		return err
+	}
+	if cleared {
+		s.analytics.LogActivity(ctx, userID, "Account Unlocked", "member", id, fmt.Sprintf("Lifted login lockout of %s", m.Username))
+	}
+	return nil
+}
+
+// revokeSessions signs a member out everywhere after a password change or deactivation.
+// The change itself has been saved, so a failure is logged rather than returned.
+func (s *MemberService) revokeSessions(ctx context.Context, id, reason string) {
+	if _, err := s.sessions.RevokeMember(ctx, id, reason); err != nil {
+		logger.Errorf("[MemberService] Failed to revoke sessions of member %s: %v", id, err)
+	}
+}
+
+func (s *MemberService) authorize(ctx context.Context, userID string) error {
+	if userID == "" {
+		return apperrors.NewValidationError("user_id is required")
+	}
+	return ports.Authorize(ctx, domain.PermMembersManage)
+}
+
+// load fetches a member and hides members of other organisations
+func (s *MemberService) load(ctx context.Context, userID, id string) (*domain.Member, error) {
+	m, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil || m.UserID != userID {
+		return nil, apperrors.NewNotFound("member", id)
+	}
+	return m, nil
+}
+
+func validateRoleAssignment(role string, siteIDs []string) error {
+	if !domain.ValidRole(role) {
+		return apperrors.NewValidationError(fmt.Sprintf("invalid role %q", role))
+	}
+	if domain.RoleIsSiteScoped(role) && len(siteIDs) == 0 {
+		return apperrors.NewValidationError("site_ids is required for the pic role")
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockMemberRepository struct {
+	mock.Mock
+}
+
+func (m *MockMemberRepository) Get(ctx context.Context, id string) (*domain.Member, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) GetByUsername(ctx context.Context, username string) (*domain.Member, error) {
+	args := m.Called(ctx, username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) ListByUser(ctx context.Context, userID string) ([]domain.Member, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.Member), args.Error(1)
+}
+
+func (m *MockMemberRepository) Create(ctx context.Context, mem *domain.Member) error {
+	args := m.Called(ctx, mem)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) Update(ctx context.Context, mem *domain.Member) error {
+	args := m.Called(ctx, mem)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) SetRole(ctx context.Context, id, role string, siteIDs []string) error {
+	args := m.Called(ctx, id, role, siteIDs)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) TouchLastLogin(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) CreatePasswordReset(ctx context.Context, r *domain.PasswordReset) error {
+	args := m.Called(ctx, r)
+	return args.Error(0)
+}
+
+func (m *MockMemberRepository) GetPasswordReset(ctx context.Context, tokenHash string) (*domain.PasswordReset, error) {
+	args := m.Called(ctx, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PasswordReset), args.Error(1)
+}
+
+func (m *MockMemberRepository) UsePasswordReset(ctx context.Context, tokenHash string) (bool, error) {
+	args := m.Called(ctx, tokenHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func memberContext(userID, memberID, role string) context.Context {
+	ctx := roleContext(userID, role)
+	return context.WithValue(ctx, ports.MemberIDKey, memberID)
+}
+
+func TestMemberService_CreateMember(t *testing.T) {
+	repo := new(MockMemberRepository)
+	analytics := new(MockAnalyticsService)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), analytics, PasswordConfig{})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	repo.On("Create", ctx, mock.MatchedBy(func(m *domain.Member) bool {
+		return m.UserID == "org1" && m.Status == domain.StatusActive && m.Role == domain.RolePIC && m.MustChangePassword &&
+			bcrypt.CompareHashAndPassword([]byte(m.PasswordHash), []byte("s3cret-pass")) == nil
+	})).Run(func(args mock.Arguments) {
+		args.Get(1).(*domain.Member).ID = "m1"
+	}).Return(nil)
+	repo.On("SetRole", ctx, "m1", domain.RolePIC, []string{"s1"}).Return(nil)
+	analytics.On("LogActivity", ctx, "org1", "Member Created", "member", "m1", mock.Anything).Return(nil)
+
+	m := &domain.Member{Username: "pic.one", Name: "PIC One", Role: domain.RolePIC, SiteIDs: []string{"s1"}}
+	err := svc.CreateMember(ctx, "org1", m, "s3cret-pass")
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	analytics.AssertExpectations(t)
+}
+
+func TestMemberService_CreateMember_Validation(t *testing.T) {
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	tests := []struct {
+		name     string
+		member   domain.Member
+		password string
+	}{
+		{"missing username", domain.Member{Name: "A"}, "pw"},
+		{"missing password", domain.Member{Username: "a", Name: "A"}, ""},
+		{"unknown role", domain.Member{Username: "a", Name: "A", Role: "owner"}, "pw"},
+		{"pic without sites", domain.Member{Username: "a", Name: "A", Role: domain.RolePIC}, "pw"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(MockMemberRepository)
+			svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), new(MockAnalyticsService), PasswordConfig{})
+
+			err := svc.CreateMember(ctx, "org1", &tt.member, tt.password)
+
+			assert.ErrorIs(t, err, apperrors.ErrValidation)
+			repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMemberService_RequiresManagePermission(t *testing.T) {
+	repo := new(MockMemberRepository)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), new(MockAnalyticsService), PasswordConfig{})
+
+	for _, role := range []string{domain.RolePIC, domain.RoleViewer} {
+		ctx := memberContext("org1", "m9", role)
+
+		_, err := svc.ListMembers(ctx, "org1")
+		assert.ErrorIs(t, err, apperrors.ErrPermissionDenied, role)
+
+		err = svc.AssignRole(ctx, "org1", "m2", domain.RoleManager, nil)
+		assert.ErrorIs(t, err, apperrors.ErrPermissionDenied, role)
+	}
+	repo.AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMemberService_OtherOrganisationIsHidden(t *testing.T) {
+	repo := new(MockMemberRepository)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), new(MockAnalyticsService), PasswordConfig{})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	repo.On("Get", ctx, "m-other").Return(&domain.Member{ID: "m-other", UserID: "org2"}, nil)
+
+	_, err := svc.GetMember(ctx, "org1", "m-other")
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+
+	err = svc.AssignRole(ctx, "org1", "m-other", domain.RoleViewer, nil)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound)
+	repo.AssertNotCalled(t, "SetRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMemberService_CannotLockOutSelf(t *testing.T) {
+	repo := new(MockMemberRepository)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), new(MockAnalyticsService), PasswordConfig{})
+	ctx := memberContext("org1", "m1", domain.RoleManager)
+
+	err := svc.AssignRole(ctx, "org1", "m1", domain.RoleViewer, nil)
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	err = svc.DeactivateMember(ctx, "org1", "m1")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestMemberService_AssignRole(t *testing.T) {
+	repo := new(MockMemberRepository)
+	analytics := new(MockAnalyticsService)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), analytics, PasswordConfig{})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	repo.On("Get", ctx, "m2").Return(&domain.Member{ID: "m2", UserID: "org1", Username: "bob", Role: domain.RoleViewer}, nil)
+	repo.On("SetRole", ctx, "m2", domain.RolePIC, []string{"s1", "s2"}).Return(nil)
+	analytics.On("LogActivity", ctx, "org1", "Role Assigned", "member", "m2", "Role of bob changed from viewer to pic for sites s1, s2").Return(nil)
+
+	err := svc.AssignRole(ctx, "org1", "m2", domain.RolePIC, []string{"s1", "s2"})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+	analytics.AssertExpectations(t)
+}
+
+func TestMemberService_DeactivateMember_RevokesSessions(t *testing.T) {
+	repo := new(MockMemberRepository)
+	analytics := new(MockAnalyticsService)
+	sessions := newAuthTestSessionRepo()
+	sessions.Create(context.Background(), &domain.AuthSession{ID: "s1", FamilyID: "f1", MemberID: "m2", UserID: "org1", AccessJTI: "j1", AccessExpiresAt: time.Now().Add(time.Hour), Status: domain.SessionActive})
+	svc := NewMemberService(repo, sessions, newAuthTestThrottleRepo(), analytics, PasswordConfig{})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	repo.On("Get", ctx, "m2").Return(&domain.Member{ID: "m2", UserID: "org1", Username: "bob", Status: domain.StatusActive}, nil)
+	repo.On("Update", ctx, mock.Anything).Return(nil)
+	analytics.On("LogActivity", ctx, "org1", "Member Deactivated", "member", "m2", mock.Anything).Return(nil)
+
+	err := svc.DeactivateMember(ctx, "org1", "m2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.SessionRevoked, sessions.byID["s1"].Status)
+	assert.True(t, sessions.revoked["j1"], "access token of the deactivated login is revoked")
+}
+
+func TestMemberService_PasswordPolicy(t *testing.T) {
+	repo := new(MockMemberRepository)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), new(MockAnalyticsService), PasswordConfig{
+		Policy:          domain.DefaultPasswordPolicy,
+		DefaultPassword: "Nexus@2026!ChangeMe",
+	})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	for _, pwd := range []string{"short1", "no-digits-here", "pic.one12345", "Nexus@2026!ChangeMe"} {
+		err := svc.CreateMember(ctx, "org1", &domain.Member{Username: "pic.one12345", Name: "PIC"}, pwd)
+		assert.ErrorIs(t, err, apperrors.ErrValidation, pwd)
+	}
+
+	repo.On("Get", ctx, "m2").Return(&domain.Member{ID: "m2", UserID: "org1", Username: "bob"}, nil)
+	err := svc.UpdateMember(ctx, "org1", "m2", map[string]interface{}{"password": "weak"})
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestMemberService_UpdateMember_PasswordForcesRotation(t *testing.T) {
+	repo := new(MockMemberRepository)
+	analytics := new(MockAnalyticsService)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), analytics, PasswordConfig{Policy: domain.DefaultPasswordPolicy})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	repo.On("Get", ctx, "m2").Return(&domain.Member{ID: "m2", UserID: "org1", Username: "bob", Status: domain.StatusActive}, nil)
+	repo.On("Update", ctx, mock.MatchedBy(func(m *domain.Member) bool {
+		return m.MustChangePassword && m.PasswordChangedAt != nil
+	})).Return(nil)
+	analytics.On("LogActivity", ctx, "org1", "Member Updated", "member", "m2", mock.Anything).Return(nil)
+
+	err := svc.UpdateMember(ctx, "org1", "m2", map[string]interface{}{"password": "temporary-2026"})
+
+	assert.NoError(t, err)
+	repo.AssertExpectations(t)
+}
+
+func TestMemberService_IssuePasswordReset(t *testing.T) {
+	repo := new(MockMemberRepository)
+	analytics := new(MockAnalyticsService)
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), analytics, PasswordConfig{ResetTTL: 30 * time.Minute})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	var stored *domain.PasswordReset
+	repo.On("Get", ctx, "m2").Return(&domain.Member{ID: "m2", UserID: "org1", Username: "bob", Status: domain.StatusActive}, nil)
+	repo.On("CreatePasswordReset", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*domain.PasswordReset)
+	}).Return(nil)
+	analytics.On("LogActivity", ctx, "org1", "Password Reset Issued", "member", "m2", mock.Anything).Return(nil)
+
+	ticket, err := svc.IssuePasswordReset(ctx, "org1", "m2")
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ticket.Token)
+	assert.Equal(t, hashToken(ticket.Token), stored.TokenHash, "only the hash is stored")
+	assert.Equal(t, "m2", stored.MemberID)
+	assert.Equal(t, "org1", stored.CreatedBy)
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), ticket.ExpiresAt, time.Minute)
+}
+
+func TestMemberService_UnlockMember(t *testing.T) {
+	repo := new(MockMemberRepository)
+	analytics := new(MockAnalyticsService)
+	throttles := newAuthTestThrottleRepo()
+	locked := time.Now().Add(time.Hour)
+	throttles.rows[domain.ThrottleScopeAccount+"/bob"] = &domain.LoginThrottle{Scope: domain.ThrottleScopeAccount, Key: "bob", Failures: 5, LockedUntil: &locked}
+	svc := NewMemberService(repo, newAuthTestSessionRepo(), throttles, analytics, PasswordConfig{})
+	ctx := memberContext("org1", "org1", domain.RoleManager)
+
+	repo.On("Get", ctx, "m2").Return(&domain.Member{ID: "m2", UserID: "org1", Username: "Bob"}, nil)
+	analytics.On("LogActivity", ctx, "org1", "Account Unlocked", "member", "m2", mock.Anything).Return(nil)
+
+	err := svc.UnlockMember(ctx, "org1", "m2")
+
+	assert.NoError(t, err)
+	assert.Empty(t, throttles.rows)
+	analytics.AssertExpectations(t)
+
+	viewer := memberContext("org1", "m3", domain.RoleViewer)
+	assert.ErrorIs(t, svc.UnlockMember(viewer, "org1", "m2"), apperrors.ErrPermissionDenied)
+}
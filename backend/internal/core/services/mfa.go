@@ -0,0 +1,389 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/totp"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultMFAChallengeTTL is how long a member has to enter their code after the password step
+	DefaultMFAChallengeTTL = 5 * time.Minute
+	recoveryCodeCount      = 10
+)
+
+var (
+	errInvalidChallenge = apperrors.NewUnauthorized("invalid or expired MFA challenge")
+	errInvalidMFACode   = apperrors.NewUnauthorized("invalid MFA code")
+)
+
+// MFAConfig controls TOTP multi-factor authentication
+type MFAConfig struct {
+	Issuer string // shown in authenticator apps
+	// RequiredFor lists organisation user types (e.g. "vendor") whose members must use MFA
+	RequiredFor []string
+	// SecretKey seals the TOTP secrets at rest; it falls back to the JWT secret
+	SecretKey    string
+	ChallengeTTL time.Duration
+}
+
+func (c MFAConfig) required(member *domain.Member) bool {
+	for _, t := range c.RequiredFor {
+		if strings.EqualFold(t, member.OrgType) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyMFA completes a login with a TOTP or recovery code. Wrong codes count towards the
+// account's login lockout, so codes cannot be guessed faster than passwords.
+func (s *AuthService) VerifyMFA(ctx context.Context, challengeToken, code string) (*domain.TokenPair, *domain.Member, error) {
+	member, err := s.parseChallenge(ctx, challengeToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !member.MFAEnabled {
+		return nil, nil, apperrors.NewValidationError("MFA enrolment required")
+	}
+
+	if err := s.checkCode(ctx, member, code, true); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.completeLogin(ctx, member)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokens, member, nil
+}
+
+// StartMFAEnrolment generates a new secret for the signed-in member, or for a member whose login
+// is waiting on mandatory enrolment (challengeToken set). MFA stays off until the first code is confirmed.
+func (s *AuthService) StartMFAEnrolment(ctx context.Context, challengeToken string) (*domain.MFAEnrolment, error) {
+	member, err := s.mfaSubject(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if member.MFAEnabled {
+		return nil, apperrors.NewValidationError("MFA is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate MFA secret: %w", err)
+	}
+	sealed, err := s.sealSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfa.SetSecret(ctx, member.ID, sealed, false); err != nil {
+		return nil, err
+	}
+
+	return &domain.MFAEnrolment{Secret: secret, URI: totp.URI(s.cfg.MFA.Issuer, member.Username, secret)}, nil
+}
+
+// ConfirmMFAEnrolment enables MFA once the member proves their authenticator produces valid codes,
+// and issues recovery codes. During a login the session tokens are issued as well.
+func (s *AuthService) ConfirmMFAEnrolment(ctx context.Context, challengeToken, code string) (*domain.MFAConfirmation, error) {
+	member, err := s.mfaSubject(ctx, challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	if member.MFAEnabled {
+		return nil, apperrors.NewValidationError("MFA is already enabled")
+	}
+	if member.MFASecret == "" {
+		return nil, apperrors.NewValidationError("start MFA enrolment first")
+	}
+
+	if err := s.checkCode(ctx, member, code, false); err != nil {
+		return nil, err
+	}
+
+	if err := s.mfa.SetSecret(ctx, member.ID, member.MFASecret, true); err != nil {
+		return nil, err
+	}
+	member.MFAEnabled = true
+	codes, err := s.replaceRecoveryCodes(ctx, member.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.analyticsService.LogActivity(loginContext(ctx, member), member.UserID, "MFA Enabled", "member", member.ID, "Authenticator app enrolled for multi-factor authentication")
+
+	result := &domain.MFAConfirmation{RecoveryCodes: codes, Member: member}
+	if challengeToken != "" {
+		if result.Tokens, err = s.completeLogin(ctx, member); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes. It needs a current TOTP code; wrong codes
+// count towards the account's login lockout.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, code string) ([]string, error) {
+	member, err := s.CurrentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !member.MFAEnabled {
+		return nil, apperrors.NewValidationError("MFA is not enabled")
+	}
+	if err := s.checkCode(ctx, member, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, member.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.analyticsService.LogActivity(ctx, member.UserID, "Recovery Codes Regenerated", "member", member.ID, "MFA recovery codes replaced")
+	return codes, nil
+}
+
+// DisableMFA turns MFA off for the signed-in member, unless the policy requires it for their organisation.
+// Wrong codes count towards the account's login lockout.
+func (s *AuthService) DisableMFA(ctx context.Context, code string) error {
+	member, err := s.CurrentMember(ctx)
+	if err != nil {
+		return err
+	}
+	if !member.MFAEnabled {
+		return apperrors.NewValidationError("MFA is not enabled")
+	}
+	if s.cfg.MFA.required(member) {
+		return apperrors.NewPermissionDenied(fmt.Sprintf("MFA is mandatory for %s accounts", member.OrgType))
+	}
+	if err := s.checkCode(ctx, member, code, true); err != nil {
+		return err
+	}
+
+	if err := s.mfa.SetSecret(ctx, member.ID, "", false); err != nil {
+		return err
+	}
+	s.analyticsService.LogActivity(ctx, member.UserID, "MFA Disabled", "member", member.ID, "Multi-factor authentication turned off")
+	return nil
+}
+
+// ResetMFA removes a member's authenticator, e.g. after a lost phone, and signs them out. Vendor only.
+// Members whose organisation requires MFA enrol again at their next login.
+func (s *AuthService) ResetMFA(ctx context.Context, userID, memberID string) error {
+	if !ports.IsVendorAdmin(ctx) {
+		return apperrors.NewPermissionDenied("only administrators can reset MFA")
+	}
+	member, err := s.members.Get(ctx, memberID)
+	if err != nil {
+		return err
+	}
+	if member == nil || member.UserID != userID {
+		return apperrors.NewNotFound("member", memberID)
+	}
+
+	if err := s.mfa.SetSecret(ctx, member.ID, "", false); err != nil {
+		return err
+	}
+	if _, err := s.sessions.RevokeMember(ctx, member.ID, "MFA reset"); err != nil {
+		logger.Errorf("[AuthService] Failed to revoke sessions of member %s: %v", member.ID, err)
+	}
+	s.analyticsService.LogActivity(ctx, userID, "MFA Reset", "member", member.ID, fmt.Sprintf("Removed the authenticator of %s", member.Username))
+	return nil
+}
+
+// issueChallenge signs a challenge with a key derived from the JWT secret, so it can never be
+// accepted as an access token.
+func (s *AuthService) issueChallenge(member *domain.Member) (*domain.MFAChallenge, error) {
+	expires := time.Now().Add(s.cfg.MFA.ChallengeTTL)
+	enrol := !member.MFAEnabled
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"member_id": member.ID,
+		"enrol":     enrol,
+		"exp":       expires.Unix(),
+		"iat":       time.Now().Unix(),
+	})
+	signed, err := token.SignedString(s.challengeKey())
+	if err != nil {
+		return nil, errors.New("failed to issue token")
+	}
+	return &domain.MFAChallenge{Token: signed, ExpiresAt: expires, EnrolmentRequired: enrol}, nil
+}
+
+// parseChallenge validates a challenge and reloads the member, who must still be able to sign in.
+func (s *AuthService) parseChallenge(ctx context.Context, challengeToken string) (*domain.Member, error) {
+	if challengeToken == "" {
+		return nil, errInvalidChallenge
+	}
+	token, err := jwt.Parse(challengeToken, func(t *jwt.Token) (interface{}, error) {
+		return s.challengeKey(), nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil || !token.Valid {
+		return nil, errInvalidChallenge
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	memberID, _ := claims["member_id"].(string)
+
+	member, err := s.members.Get(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member == nil || member.Status != domain.StatusActive || member.OrgStatus != domain.StatusActive {
+		return nil, errInvalidChallenge
+	}
+	return member, nil
+}
+
+// mfaSubject resolves whose enrolment is being managed: the member behind an enrolment challenge,
+// or the signed-in member.
+func (s *AuthService) mfaSubject(ctx context.Context, challengeToken string) (*domain.Member, error) {
+	if challengeToken != "" {
+		return s.parseChallenge(ctx, challengeToken)
+	}
+	return s.CurrentMember(ctx)
+}
+
+func (s *AuthService) challengeKey() []byte {
+	return []byte(s.cfg.JWTSecret + ":mfa-challenge")
+}
+
+// checkCode verifies a code through the login throttle: it is refused while the account or source
+// IP is locked out, and a wrong code counts as a failed login attempt.
+func (s *AuthService) checkCode(ctx context.Context, member *domain.Member, code string, allowRecovery bool) error {
+	accountKey := strings.ToLower(member.Username)
+	if err := s.checkThrottle(ctx, accountKey); err != nil {
+		return err
+	}
+	ok, err := s.verifyCode(ctx, member, code, allowRecovery)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		s.recordLoginFailure(ctx, accountKey, member)
+		return errInvalidMFACode
+	}
+	return nil
+}
+
+// verifyCode accepts a TOTP code, each time step at most once, or when allowRecovery is set an unused recovery code.
+func (s *AuthService) verifyCode(ctx context.Context, member *domain.Member, code string, allowRecovery bool) (bool, error) {
+	secret, err := s.openSecret(member.MFASecret)
+	if err != nil {
+		return false, err
+	}
+	if step, ok := totp.Verify(secret, code, time.Now(), 1); ok {
+		return s.mfa.UseStep(ctx, member.ID, step)
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+
+	used, err := s.mfa.UseRecoveryCode(ctx, member.ID, hashToken(normalizeRecoveryCode(code)))
+	if err != nil || !used {
+		return false, err
+	}
+	remaining, _ := s.mfa.CountRecoveryCodes(ctx, member.ID)
+	s.analyticsService.LogActivity(loginContext(ctx, member), member.UserID, "Recovery Code Used", "member", member.ID,
+		fmt.Sprintf("Signed in with a recovery code; %d remaining", remaining))
+	return true, nil
+}
+
+func (s *AuthService) replaceRecoveryCodes(ctx context.Context, memberID string) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery codes: %w", err)
+		}
+		raw := recoveryCodeEncoding.EncodeToString(b) // 8 characters
+		codes[i] = raw[:4] + "-" + raw[4:]
+		hashes[i] = hashToken(raw)
+	}
+	if err := s.mfa.ReplaceRecoveryCodes(ctx, memberID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// recoveryCodeEncoding leaves out characters that are easily confused when typed from paper (0/o, 1/l)
+var recoveryCodeEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+// sealSecret encrypts a TOTP secret so a database dump alone cannot generate codes.
+func (s *AuthService) sealSecret(secret string) (string, error) {
+	return seal(s.sealingKey(), secret)
+}
+
+func (s *AuthService) openSecret(sealed string) (string, error) {
+	if sealed == "" {
+		return "", apperrors.NewValidationError("MFA is not set up")
+	}
+	return unseal(s.sealingKey(), sealed)
+}
+
+func (s *AuthService) sealingKey() string {
+	if s.cfg.MFA.SecretKey != "" {
+		return s.cfg.MFA.SecretKey
+	}
+	return s.cfg.JWTSecret
+}
+
+// seal encrypts a value with AES-256-GCM under a key derived from the given secret.
+func seal(key, plain string) (string, error) {
+	gcm, err := sealingCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plain), nil)), nil
+}
+
+func unseal(key, sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", fmt.Errorf("corrupt sealed value: %w", err)
+	}
+	gcm, err := sealingCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", errors.New("corrupt sealed value")
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("cannot decrypt sealed value: %w", err)
+	}
+	return string(plain), nil
+}
+
+func sealingCipher(key string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
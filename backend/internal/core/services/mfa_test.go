@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/totp"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// authTestMFARepo keeps MFA state in memory and writes it through to the member it tracks,
+// standing in for the columns the member repository reads back
+type authTestMFARepo struct {
+	member   *domain.Member
+	lastStep map[string]int64
+	codes    map[string]map[string]bool // member -> code hash -> used
+}
+
+func newAuthTestMFARepo() *authTestMFARepo {
+	return &authTestMFARepo{lastStep: map[string]int64{}, codes: map[string]map[string]bool{}}
+}
+
+func (r *authTestMFARepo) SetSecret(ctx context.Context, memberID, sealedSecret string, enabled bool) error {
+	if r.member != nil && r.member.ID == memberID {
+		if r.member.MFASecret != sealedSecret {
+			delete(r.lastStep, memberID)
+		}
+		r.member.MFASecret = sealedSecret
+		r.member.MFAEnabled = enabled
+	}
+	if sealedSecret == "" {
+		delete(r.codes, memberID)
+	}
+	return nil
+}
+
+func (r *authTestMFARepo) UseStep(ctx context.Context, memberID string, step int64) (bool, error) {
+	if last, ok := r.lastStep[memberID]; ok && step <= last {
+		return false, nil
+	}
+	r.lastStep[memberID] = step
+	return true, nil
+}
+
+func (r *authTestMFARepo) ReplaceRecoveryCodes(ctx context.Context, memberID string, codeHashes []string) error {
+	r.codes[memberID] = map[string]bool{}
+	for _, h := range codeHashes {
+		r.codes[memberID][h] = false
+	}
+	return nil
+}
+
+func (r *authTestMFARepo) UseRecoveryCode(ctx context.Context, memberID, codeHash string) (bool, error) {
+	used, ok := r.codes[memberID][codeHash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[memberID][codeHash] = true
+	return true, nil
+}
+
+func (r *authTestMFARepo) CountRecoveryCodes(ctx context.Context, memberID string) (int, error) {
+	n := 0
+	for _, used := range r.codes[memberID] {
+		if !used {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// authTestMFAService signs in vendor member bob/pw; MFA is mandatory for vendors
+func authTestMFAService(t *testing.T) (*AuthService, *authTestMFARepo, *domain.Member) {
+	t.Helper()
+	repo := &authTestMemberRepo{}
+	m := &domain.Member{ID: "m-1", UserID: "u-1", Username: "bob", OrgType: "vendor", Status: "active", OrgStatus: "active"}
+	m.PasswordHash = authTestHashPwd(t, "pw")
+	repo.On("GetByUsername", mock.Anything, "bob").Return(m, nil)
+	repo.On("Get", mock.Anything, "m-1").Return(m, nil)
+
+	analytics := &authTestAnalytics{}
+	analytics.On("LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	mfa := newAuthTestMFARepo()
+	mfa.member = m
+	svc := NewAuthService(repo, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), mfa, AuthConfig{
+		JWTSecret: "secret",
+		MFA:       MFAConfig{Issuer: "CPD-Nexus", RequiredFor: []string{"vendor"}},
+		Throttle: domain.LoginThrottlePolicy{
+			MaxAccountFailures: 3,
+			MaxIPFailures:      50,
+			Window:             15 * time.Minute,
+			LockoutDuration:    15 * time.Minute,
+		},
+	}, analytics).(*AuthService)
+	return svc, mfa, m
+}
+
+// authTestEnrol gives the member an enabled authenticator and returns its plain secret
+func authTestEnrol(t *testing.T, svc *AuthService, m *domain.Member) string {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	m.MFASecret, err = svc.sealSecret(secret)
+	require.NoError(t, err)
+	m.MFAEnabled = true
+	return secret
+}
+
+func authTestCode(t *testing.T, secret string) string {
+	t.Helper()
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+func TestAuthService_MFA_VendorMustEnrolAtLogin(t *testing.T) {
+	svc, _, m := authTestMFAService(t)
+	ctx := context.Background()
+
+	res, err := svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	assert.Nil(t, res.Tokens)
+	require.NotNil(t, res.Challenge)
+	assert.True(t, res.Challenge.EnrolmentRequired)
+
+	// The challenge is not an access token
+	_, err = jwt.Parse(res.Challenge.Token, func(tk *jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	assert.Error(t, err)
+
+	_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, "123456")
+	assert.ErrorIs(t, err, apperrors.ErrValidation)
+
+	enrolment, err := svc.StartMFAEnrolment(ctx, res.Challenge.Token)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(enrolment.URI, "otpauth://totp/"))
+	assert.NotContains(t, m.MFASecret, enrolment.Secret, "the secret is stored sealed")
+	assert.False(t, m.MFAEnabled, "MFA stays off until a code is confirmed")
+
+	confirmation, err := svc.ConfirmMFAEnrolment(ctx, res.Challenge.Token, authTestCode(t, enrolment.Secret))
+	require.NoError(t, err)
+	assert.True(t, m.MFAEnabled)
+	assert.Len(t, confirmation.RecoveryCodes, recoveryCodeCount)
+	require.NotNil(t, confirmation.Tokens, "enrolling during login completes the login")
+
+	claims := authTestParseJWT(t, confirmation.Tokens.AccessToken, "secret")
+	assert.Equal(t, "m-1", claims["member_id"])
+
+	// The confirming code cannot be used again for the next login
+	res, err = svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, authTestCode(t, enrolment.Secret))
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_MFA_CodeCannotBeReplayed(t *testing.T) {
+	svc, _, m := authTestMFAService(t)
+	secret := authTestEnrol(t, svc, m)
+	ctx := context.Background()
+
+	res, err := svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	require.NotNil(t, res.Challenge)
+	assert.False(t, res.Challenge.EnrolmentRequired)
+
+	code := authTestCode(t, secret)
+	tokens, member, err := svc.VerifyMFA(ctx, res.Challenge.Token, code)
+	require.NoError(t, err)
+	assert.NotEmpty(t, tokens.AccessToken)
+	assert.Equal(t, "m-1", member.ID)
+
+	res, err = svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, code)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_MFA_RecoveryCodeIsSingleUse(t *testing.T) {
+	svc, _, m := authTestMFAService(t)
+	authTestEnrol(t, svc, m)
+	ctx := context.Background()
+
+	codes, err := svc.replaceRecoveryCodes(ctx, m.ID)
+	require.NoError(t, err)
+
+	res, err := svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, strings.ToUpper(codes[0]))
+	assert.NoError(t, err, "recovery codes ignore case")
+
+	res, err = svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, codes[0])
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestAuthService_MFA_WrongCodesLockAccount(t *testing.T) {
+	svc, _, m := authTestMFAService(t)
+	secret := authTestEnrol(t, svc, m)
+	ctx := context.Background()
+
+	res, err := svc.Login(ctx, "bob", "pw")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, "not-a-code")
+		assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	}
+
+	_, _, err = svc.VerifyMFA(ctx, res.Challenge.Token, authTestCode(t, secret))
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+}
+
+func TestAuthService_MFA_SignedInCodeChecksAreThrottled(t *testing.T) {
+	svc, _, m := authTestMFAService(t)
+	secret := authTestEnrol(t, svc, m)
+	svc.cfg.MFA.RequiredFor = nil
+	self := memberContext("u-1", "m-1", domain.RoleManager)
+
+	// Guesses made while signed in count towards the same lockout as login attempts
+	for i := 0; i < 3; i++ {
+		_, err := svc.RegenerateRecoveryCodes(self, "not-a-code")
+		assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	}
+
+	_, err := svc.RegenerateRecoveryCodes(self, authTestCode(t, secret))
+	assert.ErrorIs(t, err, apperrors.ErrTooManyRequests)
+	assert.ErrorIs(t, svc.DisableMFA(self, authTestCode(t, secret)), apperrors.ErrTooManyRequests)
+	assert.True(t, m.MFAEnabled)
+}
+
+func TestAuthService_MFA_DisableAndReset(t *testing.T) {
+	svc, mfa, m := authTestMFAService(t)
+	secret := authTestEnrol(t, svc, m)
+	self := memberContext("u-1", "m-1", domain.RoleManager)
+
+	err := svc.DisableMFA(self, authTestCode(t, secret))
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied, "vendors cannot opt out")
+
+	svc.cfg.MFA.RequiredFor = nil
+	assert.NoError(t, svc.DisableMFA(self, authTestCode(t, secret)))
+	assert.False(t, m.MFAEnabled)
+	assert.Empty(t, m.MFASecret)
+
+	authTestEnrol(t, svc, m)
+	_, err = svc.replaceRecoveryCodes(context.Background(), m.ID)
+	require.NoError(t, err)
+	assert.ErrorIs(t, svc.ResetMFA(self, "u-1", "m-1"), apperrors.ErrPermissionDenied)
+
+	vendor := context.WithValue(roleContext("vendor-1", domain.RoleManager), ports.IsVendorKey, true)
+	assert.NoError(t, svc.ResetMFA(vendor, "u-1", "m-1"))
+	assert.False(t, m.MFAEnabled)
+	assert.Empty(t, mfa.codes[m.ID])
+}
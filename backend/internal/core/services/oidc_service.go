@@ -0,0 +1,487 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// DefaultOIDCStateTTL is how long a user may take at the identity provider before the login expires
+const DefaultOIDCStateTTL = 10 * time.Minute
+
+var errInvalidSSOLogin = apperrors.NewUnauthorized("invalid or expired sign-in request")
+
+// OIDCConfig configures single sign-on
+type OIDCConfig struct {
+	// RedirectURL is the callback URL registered with every identity provider
+	RedirectURL string
+	// SecretKey seals client secrets at rest, like MFAConfig.SecretKey
+	SecretKey string
+	StateTTL  time.Duration
+}
+
+// OIDCService runs OpenID Connect single sign-on. Managing an organisation's providers requires
+// members:manage; vendors may manage any organisation.
+type OIDCService struct {
+	repo      ports.OIDCRepository
+	members   ports.MemberRepository
+	auth      ports.AuthService
+	client    ports.OIDCClient
+	cfg       OIDCConfig
+	analytics ports.AnalyticsService
+}
+
+func NewOIDCService(repo ports.OIDCRepository, members ports.MemberRepository, auth ports.AuthService, client ports.OIDCClient, cfg OIDCConfig, analytics ports.AnalyticsService) ports.OIDCService {
+	if cfg.StateTTL <= 0 {
+		cfg.StateTTL = DefaultOIDCStateTTL
+	}
+	return &OIDCService{repo: repo, members: members, auth: auth, client: client, cfg: cfg, analytics: analytics}
+}
+
+func (s *OIDCService) ListProviders(ctx context.Context, userID string) ([]domain.OIDCProvider, error) {
+	if err := s.authorize(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListProviders(ctx, userID)
+}
+
+func (s *OIDCService) CreateProvider(ctx context.Context, userID string, p *domain.OIDCProvider, clientSecret string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	p.ID = uuid.NewString()
+	p.UserID = userID
+	if err := s.validateProvider(ctx, p); err != nil {
+		return err
+	}
+	if err := s.setClientSecret(p, clientSecret); err != nil {
+		return err
+	}
+	if err := s.repo.CreateProvider(ctx, p); err != nil {
+		return err
+	}
+
+	s.analytics.LogActivity(ctx, userID, "SSO Provider Created", "oidc_provider", p.ID, fmt.Sprintf("Added identity provider %s (%s)", p.Name, p.Issuer))
+	return nil
+}
+
+func (s *OIDCService) UpdateProvider(ctx context.Context, userID, id string, p *domain.OIDCProvider, clientSecret string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	existing, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	p.ID = existing.ID
+	p.UserID = existing.UserID
+	p.CreatedAt = existing.CreatedAt
+	p.ClientSecret = existing.ClientSecret
+	p.HasClientSecret = existing.HasClientSecret
+	if err := s.validateProvider(ctx, p); err != nil {
+		return err
+	}
+	if clientSecret != "" {
+		if err := s.setClientSecret(p, clientSecret); err != nil {
+			return err
+		}
+	}
+	if err := s.repo.UpdateProvider(ctx, p); err != nil {
+		return err
+	}
+
+	s.analytics.LogActivity(ctx, userID, "SSO Provider Updated", "oidc_provider", p.ID, fmt.Sprintf("Updated identity provider %s", p.Name))
+	return nil
+}
+
+// DeleteProvider also unlinks every identity; members keep their logins but can no longer use SSO.
+func (s *OIDCService) DeleteProvider(ctx context.Context, userID, id string) error {
+	if err := s.authorize(ctx, userID); err != nil {
+		return err
+	}
+	p, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteProvider(ctx, id); err != nil {
+		return err
+	}
+
+	s.analytics.LogActivity(ctx, userID, "SSO Provider Deleted", "oidc_provider", id, fmt.Sprintf("Removed identity provider %s", p.Name))
+	return nil
+}
+
+func (s *OIDCService) Discover(ctx context.Context, email string) (*domain.OIDCProviderInfo, error) {
+	at := strings.LastIndex(email, "@")
+	if at < 0 || at == len(email)-1 {
+		return nil, apperrors.NewValidationError("a valid e-mail address is required")
+	}
+	emailDomain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	p, err := s.repo.GetProviderByDomain(ctx, emailDomain)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, apperrors.NewNotFound("identity provider", emailDomain)
+	}
+	return &domain.OIDCProviderInfo{ID: p.ID, Name: p.Name}, nil
+}
+
+// StartLogin records a single-use state with a fresh PKCE verifier and nonce, and returns the
+// provider's authorization URL. returnTo must be a path within the application.
+func (s *OIDCService) StartLogin(ctx context.Context, providerID, returnTo string) (string, error) {
+	p, err := s.repo.GetProvider(ctx, providerID)
+	if err != nil {
+		return "", err
+	}
+	if p == nil || !p.Enabled {
+		return "", apperrors.NewNotFound("identity provider", providerID)
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		if secrets[i], err = newOpaqueToken(); err != nil {
+			return "", fmt.Errorf("failed to generate login state: %w", err)
+		}
+	}
+	state, verifier, nonce := secrets[0], secrets[1], secrets[2]
+
+	if err := s.repo.SaveState(ctx, &domain.OIDCLoginState{
+		StateHash:    hashToken(state),
+		ProviderID:   p.ID,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ReturnTo:     safeReturnTo(returnTo),
+		ExpiresAt:    time.Now().Add(s.cfg.StateTTL),
+	}); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	return s.client.AuthorizationURL(ctx, p.Issuer, domain.OIDCAuthRequest{
+		ClientID:      p.ClientID,
+		RedirectURI:   s.cfg.RedirectURL,
+		Scopes:        p.Scopes,
+		State:         state,
+		Nonce:         nonce,
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(challenge[:]),
+	})
+}
+
+// CompleteLogin redeems the callback's code, maps the verified identity to a member and signs
+// them in through the AuthService, so SSO logins get the same tokens (and MFA) as passwords.
+func (s *OIDCService) CompleteLogin(ctx context.Context, state, code string) (*domain.OIDCLogin, error) {
+	if state == "" || code == "" {
+		return nil, errInvalidSSOLogin
+	}
+	st, err := s.repo.TakeState(ctx, hashToken(state))
+	if err != nil {
+		return nil, err
+	}
+	if st == nil || time.Now().After(st.ExpiresAt) {
+		return nil, errInvalidSSOLogin
+	}
+	p, err := s.repo.GetProvider(ctx, st.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil || !p.Enabled {
+		return nil, errInvalidSSOLogin
+	}
+
+	clientSecret := ""
+	if p.ClientSecret != "" {
+		if clientSecret, err = unseal(s.cfg.SecretKey, p.ClientSecret); err != nil {
+			return nil, err
+		}
+	}
+	claims, err := s.client.Exchange(ctx, p.Issuer, domain.OIDCTokenRequest{
+		ClientID:     p.ClientID,
+		ClientSecret: clientSecret,
+		Code:         code,
+		CodeVerifier: st.CodeVerifier,
+		RedirectURI:  s.cfg.RedirectURL,
+		Nonce:        st.Nonce,
+	})
+	if err != nil {
+		logger.Errorf("[OIDCService] Token exchange with %s failed: %v", p.Issuer, err)
+		return nil, apperrors.NewUnauthorized("sign-in with the identity provider failed")
+	}
+
+	member, err := s.resolveMember(ctx, p, claims)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.auth.CompleteExternalLogin(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OIDCLogin{Result: result, ReturnTo: st.ReturnTo}, nil
+}
+
+// resolveMember finds the member an ID token signs in as: the member already linked to the subject,
+// else the organisation's member whose username matches the username claim, else (with
+// auto-provisioning) a new member. Mapped roles are applied on every login.
+func (s *OIDCService) resolveMember(ctx context.Context, p *domain.OIDCProvider, claims map[string]interface{}) (*domain.Member, error) {
+	subject, _ := claims["sub"].(string)
+	if verified, ok := claims["email_verified"].(bool); ok && !verified &&
+		(p.Mapping.UsernameClaim == "email" || p.Mapping.EmailClaim == "email") {
+		return nil, apperrors.NewUnauthorized("the identity provider has not verified this e-mail address")
+	}
+	role := mapRole(p.Mapping, claims)
+
+	memberID, err := s.repo.GetIdentity(ctx, p.ID, subject)
+	if err != nil {
+		return nil, err
+	}
+	if memberID != "" {
+		m, err := s.members.Get(ctx, memberID)
+		if err != nil {
+			return nil, err
+		}
+		if m == nil || m.UserID != p.UserID {
+			return nil, apperrors.NewUnauthorized("no CPD-Nexus login is linked to this account")
+		}
+		return s.syncRole(ctx, m, role)
+	}
+
+	username := claimString(claims, p.Mapping.UsernameClaim)
+	if username == "" {
+		return nil, apperrors.NewUnauthorized(fmt.Sprintf("the ID token has no %s claim", p.Mapping.UsernameClaim))
+	}
+	m, err := s.members.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case m != nil && m.UserID != p.UserID:
+		return nil, apperrors.NewUnauthorized("this login belongs to another organisation")
+	case m != nil:
+		if m, err = s.syncRole(ctx, m, role); err != nil {
+			return nil, err
+		}
+		s.analytics.LogActivity(loginContext(ctx, m), p.UserID, "SSO Identity Linked", "member", m.ID,
+			fmt.Sprintf("Linked %s to identity provider %s", m.Username, p.Name))
+	case p.AutoProvision:
+		if role == "" {
+			role = p.DefaultRole
+		}
+		m = &domain.Member{
+			UserID:   p.UserID,
+			Username: username,
+			Name:     claimString(claims, p.Mapping.NameClaim),
+			Email:    claimString(claims, p.Mapping.EmailClaim),
+			Role:     role,
+			Status:   domain.StatusActive,
+			// No password: the member can only sign in through the identity provider
+		}
+		if m.Name == "" {
+			m.Name = username
+		}
+		if err := s.members.Create(ctx, m); err != nil {
+			return nil, err
+		}
+		if m, err = s.members.Get(ctx, m.ID); err != nil {
+			return nil, err
+		}
+		s.analytics.LogActivity(loginContext(ctx, m), p.UserID, "Member Provisioned", "member", m.ID,
+			fmt.Sprintf("Created %s (%s) as %s on first sign-in with %s", m.Name, m.Username, m.Role, p.Name))
+	default:
+		return nil, apperrors.NewUnauthorized("no CPD-Nexus login matches this account")
+	}
+
+	if err := s.repo.LinkIdentity(ctx, p.ID, subject, m.ID); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// syncRole applies the role mapped from the ID token. The organisation's primary login keeps its role.
+func (s *OIDCService) syncRole(ctx context.Context, m *domain.Member, role string) (*domain.Member, error) {
+	if role == "" || role == m.Role || m.IsPrimary() {
+		return m, nil
+	}
+	if err := s.members.SetRole(ctx, m.ID, role, nil); err != nil {
+		return nil, err
+	}
+	m.Role = role
+	m.SiteIDs = nil
+	return m, nil
+}
+
+// mapRole returns the most privileged role any value of the role claim maps to, or "" when none does
+func mapRole(mapping domain.OIDCClaimMapping, claims map[string]interface{}) string {
+	if mapping.RoleClaim == "" {
+		return ""
+	}
+	var values []string
+	switch v := claims[mapping.RoleClaim].(type) {
+	case string:
+		values = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				values = append(values, str)
+			}
+		}
+	}
+
+	mapped := map[string]bool{}
+	for _, v := range values {
+		if role, ok := mapping.RoleValues[v]; ok {
+			mapped[role] = true
+		}
+	}
+	for _, role := range domain.Roles {
+		if mapped[role] {
+			return role
+		}
+	}
+	return ""
+}
+
+func claimString(claims map[string]interface{}, name string) string {
+	v, _ := claims[name].(string)
+	return strings.TrimSpace(v)
+}
+
+// safeReturnTo only allows paths on this site, so the login cannot be used as an open redirect
+func safeReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.ContainsAny(returnTo, "\\\r\n") {
+		return ""
+	}
+	return returnTo
+}
+
+func (s *OIDCService) validateProvider(ctx context.Context, p *domain.OIDCProvider) error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Issuer = strings.TrimSpace(p.Issuer)
+	p.ClientID = strings.TrimSpace(p.ClientID)
+	if p.Name == "" || len(p.Name) > 100 {
+		return apperrors.NewValidationError("name is required and must be at most 100 characters")
+	}
+	if p.ClientID == "" {
+		return apperrors.NewValidationError("client_id is required")
+	}
+	issuer, err := url.Parse(p.Issuer)
+	if err != nil || issuer.Host == "" || issuer.RawQuery != "" || issuer.Fragment != "" {
+		return apperrors.NewValidationError("issuer must be an absolute URL")
+	}
+	if issuer.Scheme != "https" && !(issuer.Scheme == "http" && isLoopback(issuer.Hostname())) {
+		return apperrors.NewValidationError("issuer must use https")
+	}
+
+	if len(p.Scopes) == 0 {
+		p.Scopes = domain.DefaultOIDCScopes
+	}
+	hasOpenID := false
+	for _, scope := range p.Scopes {
+		if scope == "openid" {
+			hasOpenID = true
+		}
+		if scope == "" || strings.ContainsAny(scope, " ,") {
+			return apperrors.NewValidationError(fmt.Sprintf("invalid scope %q", scope))
+		}
+	}
+	if !hasOpenID {
+		p.Scopes = append([]string{"openid"}, p.Scopes...)
+	}
+
+	if p.Mapping.UsernameClaim == "" {
+		p.Mapping.UsernameClaim = domain.DefaultOIDCClaimMapping.UsernameClaim
+	}
+	if p.Mapping.NameClaim == "" {
+		p.Mapping.NameClaim = domain.DefaultOIDCClaimMapping.NameClaim
+	}
+	if p.Mapping.EmailClaim == "" {
+		p.Mapping.EmailClaim = domain.DefaultOIDCClaimMapping.EmailClaim
+	}
+	if p.DefaultRole == "" {
+		p.DefaultRole = domain.RoleViewer
+	}
+	// Site-scoped roles need sites, which an ID token cannot carry
+	roles := []string{p.DefaultRole}
+	for _, role := range p.Mapping.RoleValues {
+		roles = append(roles, role)
+	}
+	for _, role := range roles {
+		if !domain.ValidRole(role) || domain.RoleIsSiteScoped(role) {
+			return apperrors.NewValidationError(fmt.Sprintf("role %q cannot be assigned through single sign-on", role))
+		}
+	}
+
+	domains := []string{}
+	for _, d := range p.Domains {
+		d = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(d), "@"))
+		if d == "" {
+			continue
+		}
+		if !strings.Contains(d, ".") || strings.ContainsAny(d, " ,@/") {
+			return apperrors.NewValidationError(fmt.Sprintf("invalid e-mail domain %q", d))
+		}
+		other, err := s.repo.GetProviderByDomain(ctx, d)
+		if err != nil {
+			return err
+		}
+		if other != nil && other.ID != p.ID {
+			return apperrors.NewConflict(fmt.Sprintf("e-mail domain %s is already used by another identity provider", d))
+		}
+		domains = append(domains, d)
+	}
+	p.Domains = domains
+	return nil
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *OIDCService) setClientSecret(p *domain.OIDCProvider, clientSecret string) error {
+	if clientSecret == "" {
+		return nil
+	}
+	sealed, err := seal(s.cfg.SecretKey, clientSecret)
+	if err != nil {
+		return err
+	}
+	p.ClientSecret = sealed
+	p.HasClientSecret = true
+	return nil
+}
+
+func (s *OIDCService) authorize(ctx context.Context, userID string) error {
+	if userID == "" {
+		return apperrors.NewValidationError("user_id is required")
+	}
+	return ports.Authorize(ctx, domain.PermMembersManage)
+}
+
+// load fetches a provider and hides providers of other organisations
+func (s *OIDCService) load(ctx context.Context, userID, id string) (*domain.OIDCProvider, error) {
+	p, err := s.repo.GetProvider(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil || p.UserID != userID {
+		return nil, apperrors.NewNotFound("identity provider", id)
+	}
+	return p, nil
+}
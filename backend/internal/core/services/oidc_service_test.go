@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cpd-nexus/internal/adapters/external/oidc"
+	"cpd-nexus/internal/adapters/external/oidc/oidctest"
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const oidcTestRedirect = "https://nexus.example.com/api/auth/oidc/callback"
+
+// oidcTestRepo is an in-memory OIDCRepository
+type oidcTestRepo struct {
+	providers  map[string]*domain.OIDCProvider
+	states     map[string]*domain.OIDCLoginState
+	identities map[string]string // provider|subject -> member
+}
+
+func newOIDCTestRepo() *oidcTestRepo {
+	return &oidcTestRepo{providers: map[string]*domain.OIDCProvider{}, states: map[string]*domain.OIDCLoginState{}, identities: map[string]string{}}
+}
+
+func (r *oidcTestRepo) GetProvider(ctx context.Context, id string) (*domain.OIDCProvider, error) {
+	if p := r.providers[id]; p != nil {
+		cp := *p
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *oidcTestRepo) GetProviderByDomain(ctx context.Context, emailDomain string) (*domain.OIDCProvider, error) {
+	for _, p := range r.providers {
+		for _, d := range p.Domains {
+			if p.Enabled && d == emailDomain {
+				return r.GetProvider(ctx, p.ID)
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (r *oidcTestRepo) ListProviders(ctx context.Context, userID string) ([]domain.OIDCProvider, error) {
+	providers := []domain.OIDCProvider{}
+	for _, p := range r.providers {
+		if p.UserID == userID {
+			providers = append(providers, *p)
+		}
+	}
+	return providers, nil
+}
+
+func (r *oidcTestRepo) CreateProvider(ctx context.Context, p *domain.OIDCProvider) error {
+	cp := *p
+	r.providers[p.ID] = &cp
+	return nil
+}
+
+func (r *oidcTestRepo) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) error {
+	return r.CreateProvider(ctx, p)
+}
+
+func (r *oidcTestRepo) DeleteProvider(ctx context.Context, id string) error {
+	delete(r.providers, id)
+	return nil
+}
+
+func (r *oidcTestRepo) SaveState(ctx context.Context, s *domain.OIDCLoginState) error {
+	cp := *s
+	r.states[s.StateHash] = &cp
+	return nil
+}
+
+func (r *oidcTestRepo) TakeState(ctx context.Context, stateHash string) (*domain.OIDCLoginState, error) {
+	s := r.states[stateHash]
+	delete(r.states, stateHash)
+	return s, nil
+}
+
+func (r *oidcTestRepo) PurgeStates(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *oidcTestRepo) GetIdentity(ctx context.Context, providerID, subject string) (string, error) {
+	return r.identities[providerID+"|"+subject], nil
+}
+
+func (r *oidcTestRepo) LinkIdentity(ctx context.Context, providerID, subject, memberID string) error {
+	r.identities[providerID+"|"+subject] = memberID
+	return nil
+}
+
+// oidcTestMemberRepo keeps members in memory; organisations u-1 (contractor) and u-2 exist
+type oidcTestMemberRepo struct {
+	ports.MemberRepository
+	members map[string]*domain.Member
+	orgType string
+}
+
+func (r *oidcTestMemberRepo) Get(ctx context.Context, id string) (*domain.Member, error) {
+	if m := r.members[id]; m != nil {
+		cp := *m
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *oidcTestMemberRepo) GetByUsername(ctx context.Context, username string) (*domain.Member, error) {
+	for _, m := range r.members {
+		if m.Username == username {
+			return r.Get(ctx, m.ID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *oidcTestMemberRepo) Create(ctx context.Context, m *domain.Member) error {
+	m.ID = fmt.Sprintf("m-%d", len(r.members)+100)
+	cp := *m
+	cp.OrgType, cp.OrgStatus = r.orgType, domain.StatusActive
+	r.members[m.ID] = &cp
+	return nil
+}
+
+func (r *oidcTestMemberRepo) SetRole(ctx context.Context, id, role string, siteIDs []string) error {
+	r.members[id].Role = role
+	return nil
+}
+
+func (r *oidcTestMemberRepo) TouchLastLogin(ctx context.Context, id string) error { return nil }
+
+type oidcTestEnv struct {
+	svc     *OIDCService
+	repo    *oidcTestRepo
+	members *oidcTestMemberRepo
+	idp     *oidctest.Provider
+	auth    *AuthService
+}
+
+// newOIDCTestEnv wires the service to a real client talking to a mock IdP. Organisation u-1 has
+// member jane@example.com and a provider for example.com.
+func newOIDCTestEnv(t *testing.T, orgType string) *oidcTestEnv {
+	t.Helper()
+	idp, err := oidctest.Start()
+	require.NoError(t, err)
+	t.Cleanup(idp.Close)
+	idp.AddClient(oidctest.Client{ID: "nexus", Secret: "idp-secret", RedirectURIs: []string{oidcTestRedirect}})
+
+	members := &oidcTestMemberRepo{orgType: orgType, members: map[string]*domain.Member{
+		"m-1": {ID: "m-1", UserID: "u-1", Username: "jane@example.com", Name: "Jane", Role: domain.RoleViewer,
+			Status: domain.StatusActive, OrgType: orgType, OrgStatus: domain.StatusActive},
+	}}
+	analytics := &authTestAnalytics{}
+	analytics.On("LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	auth := NewAuthService(members, newAuthTestSessionRepo(), newAuthTestThrottleRepo(), newAuthTestMFARepo(), AuthConfig{
+		JWTSecret: "secret",
+		MFA:       MFAConfig{RequiredFor: []string{"vendor"}},
+	}, analytics).(*AuthService)
+	repo := newOIDCTestRepo()
+	svc := NewOIDCService(repo, members, auth, oidc.NewClient(), OIDCConfig{RedirectURL: oidcTestRedirect, SecretKey: "seal"}, analytics).(*OIDCService)
+
+	manager := memberContext("u-1", "m-1", domain.RoleManager)
+	require.NoError(t, svc.CreateProvider(manager, "u-1", &domain.OIDCProvider{
+		Name: "Example SSO", Issuer: idp.Issuer(), ClientID: "nexus", Domains: []string{"Example.com"}, Enabled: true,
+		Mapping: domain.OIDCClaimMapping{RoleClaim: "groups", RoleValues: map[string]string{"nexus-admins": domain.RoleManager}},
+	}, "idp-secret"))
+
+	return &oidcTestEnv{svc: svc, repo: repo, members: members, idp: idp, auth: auth}
+}
+
+func (e *oidcTestEnv) providerID(t *testing.T) string {
+	t.Helper()
+	info, err := e.svc.Discover(context.Background(), "someone@example.com")
+	require.NoError(t, err)
+	return info.ID
+}
+
+// signIn runs the browser part of the flow and hands the callback to the service
+func (e *oidcTestEnv) signIn(t *testing.T, returnTo string) (*domain.OIDCLogin, error) {
+	t.Helper()
+	authURL, err := e.svc.StartLogin(context.Background(), e.providerID(t), returnTo)
+	require.NoError(t, err)
+	code, state, err := e.idp.Authorize(authURL)
+	require.NoError(t, err)
+	return e.svc.CompleteLogin(context.Background(), state, code)
+}
+
+func TestOIDCService_SignsInExistingMember(t *testing.T) {
+	env := newOIDCTestEnv(t, "contractor")
+	env.idp.SetUser(map[string]interface{}{"sub": "idp-42", "email": "jane@example.com", "email_verified": true})
+
+	login, err := env.signIn(t, "/attendance?site=1")
+	require.NoError(t, err)
+	require.NotNil(t, login.Result.Tokens)
+	assert.Equal(t, "/attendance?site=1", login.ReturnTo)
+
+	claims := authTestParseJWT(t, login.Result.Tokens.AccessToken, "secret")
+	assert.Equal(t, "m-1", claims["member_id"])
+	assert.Equal(t, "u-1", claims["user_id"])
+
+	// The subject is now linked, so a changed e-mail address still signs in the same member
+	env.idp.SetUser(map[string]interface{}{"sub": "idp-42", "email": "jane.doe@example.com", "groups": []interface{}{"nexus-admins"}})
+	login, err = env.signIn(t, "")
+	require.NoError(t, err)
+	assert.Equal(t, "m-1", login.Result.Member.ID)
+	assert.Equal(t, domain.RoleManager, env.members.members["m-1"].Role, "mapped roles are applied at login")
+}
+
+func TestOIDCService_AutoProvision(t *testing.T) {
+	env := newOIDCTestEnv(t, "contractor")
+	env.idp.SetUser(map[string]interface{}{"sub": "idp-7", "email": "sam@example.com", "name": "Sam"})
+
+	_, err := env.signIn(t, "")
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized, "unknown users are refused without auto-provisioning")
+
+	p := env.repo.providers[env.providerID(t)]
+	p.AutoProvision = true
+	login, err := env.signIn(t, "")
+	require.NoError(t, err)
+	m := login.Result.Member
+	assert.Equal(t, "u-1", m.UserID)
+	assert.Equal(t, "Sam", m.Name)
+	assert.Equal(t, domain.RoleViewer, m.Role)
+	assert.Empty(t, m.PasswordHash, "provisioned members have no password")
+}
+
+func TestOIDCService_RejectsLogins(t *testing.T) {
+	tests := []struct {
+		name  string
+		user  map[string]interface{}
+		setup func(env *oidcTestEnv)
+	}{
+		{"unverified e-mail", map[string]interface{}{"sub": "x", "email": "jane@example.com", "email_verified": false}, nil},
+		{"member of another organisation", map[string]interface{}{"sub": "x", "email": "bob@example.com"}, func(env *oidcTestEnv) {
+			env.members.members["m-9"] = &domain.Member{ID: "m-9", UserID: "u-2", Username: "bob@example.com", Status: domain.StatusActive, OrgStatus: domain.StatusActive}
+		}},
+		{"inactive member", map[string]interface{}{"sub": "x", "email": "jane@example.com"}, func(env *oidcTestEnv) {
+			env.members.members["m-1"].Status = domain.StatusInactive
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t, "contractor")
+			if tt.setup != nil {
+				tt.setup(env)
+			}
+			env.idp.SetUser(tt.user)
+			_, err := env.signIn(t, "")
+			assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+		})
+	}
+}
+
+func TestOIDCService_StateIsSingleUse(t *testing.T) {
+	env := newOIDCTestEnv(t, "contractor")
+	authURL, err := env.svc.StartLogin(context.Background(), env.providerID(t), "https://evil.example.com/")
+	require.NoError(t, err)
+	code, state, err := env.idp.Authorize(authURL)
+	require.NoError(t, err)
+
+	login, err := env.svc.CompleteLogin(context.Background(), state, code)
+	require.NoError(t, err)
+	assert.Empty(t, login.ReturnTo, "absolute return URLs are dropped")
+
+	_, err = env.svc.CompleteLogin(context.Background(), state, code)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+	_, err = env.svc.CompleteLogin(context.Background(), "forged", code)
+	assert.ErrorIs(t, err, apperrors.ErrUnauthorized)
+}
+
+func TestOIDCService_MFAStillRequired(t *testing.T) {
+	env := newOIDCTestEnv(t, "vendor")
+
+	login, err := env.signIn(t, "")
+	require.NoError(t, err)
+	assert.Nil(t, login.Result.Tokens)
+	require.NotNil(t, login.Result.Challenge)
+	assert.True(t, login.Result.Challenge.EnrolmentRequired)
+}
+
+func TestOIDCService_ProviderManagement(t *testing.T) {
+	env := newOIDCTestEnv(t, "contractor")
+	manager := memberContext("u-1", "m-1", domain.RoleManager)
+
+	providers, err := env.svc.ListProviders(manager, "u-1")
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+	p := providers[0]
+	assert.True(t, p.HasClientSecret)
+	assert.NotContains(t, p.ClientSecret, "idp-secret", "client secrets are sealed")
+	assert.Equal(t, []string{"example.com"}, p.Domains)
+	assert.Equal(t, domain.DefaultOIDCScopes, p.Scopes)
+
+	// An update without a secret keeps the sealed one
+	update := p
+	update.Name = "Renamed"
+	require.NoError(t, env.svc.UpdateProvider(manager, "u-1", p.ID, &update, ""))
+	assert.Equal(t, p.ClientSecret, env.repo.providers[p.ID].ClientSecret)
+
+	_, err = env.svc.ListProviders(memberContext("u-1", "m-2", domain.RoleViewer), "u-1")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	err = env.svc.DeleteProvider(memberContext("u-2", "m-9", domain.RoleManager), "u-2", p.ID)
+	assert.ErrorIs(t, err, apperrors.ErrNotFound, "providers of other organisations are hidden")
+}
+
+func TestOIDCService_ProviderValidation(t *testing.T) {
+	valid := func() *domain.OIDCProvider {
+		return &domain.OIDCProvider{Name: "Corp", Issuer: "https://login.corp.example", ClientID: "nexus"}
+	}
+	tests := []struct {
+		name    string
+		modify  func(p *domain.OIDCProvider)
+		wantErr error
+	}{
+		{"missing client id", func(p *domain.OIDCProvider) { p.ClientID = "" }, apperrors.ErrValidation},
+		{"plain http issuer", func(p *domain.OIDCProvider) { p.Issuer = "http://login.corp.example" }, apperrors.ErrValidation},
+		{"site-scoped default role", func(p *domain.OIDCProvider) { p.DefaultRole = domain.RolePIC }, apperrors.ErrValidation},
+		{"unknown mapped role", func(p *domain.OIDCProvider) {
+			p.Mapping.RoleValues = map[string]string{"admins": "superuser"}
+		}, apperrors.ErrValidation},
+		{"domain of another provider", func(p *domain.OIDCProvider) { p.Domains = []string{"@example.com"} }, apperrors.ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newOIDCTestEnv(t, "contractor")
+			p := valid()
+			tt.modify(p)
+			err := env.svc.CreateProvider(memberContext("u-2", "m-9", domain.RoleManager), "u-2", p, "")
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	// Local issuers are allowed for development against the mock IdP
+	env := newOIDCTestEnv(t, "contractor")
+	p := valid()
+	p.Issuer = "http://127.0.0.1:8091"
+	assert.NoError(t, env.svc.CreateProvider(memberContext("u-2", "m-9", domain.RoleManager), "u-2", p, ""))
+	assert.True(t, strings.HasPrefix(p.Scopes[0], "openid"))
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// DefaultPasswordResetTTL is how long an administrator-issued reset token stays valid
+const DefaultPasswordResetTTL = time.Hour
+
+// PasswordConfig is shared by every service that sets passwords
+type PasswordConfig struct {
+	Policy domain.PasswordPolicy
+	// DefaultPassword is given to organisations created without one. It never satisfies the
+	// policy as a chosen password, and logging in with it forces a change.
+	DefaultPassword string
+	ResetTTL        time.Duration
+}
+
+// validatePassword applies the policy to a password a person is choosing for username.
+func (c PasswordConfig) validatePassword(username, password string) error {
+	if password == "" {
+		return apperrors.NewValidationError("password is required")
+	}
+	violations := c.Policy.Violations(password)
+	if username != "" && strings.EqualFold(password, username) {
+		violations = append(violations, "differ from the username")
+	}
+	if c.DefaultPassword != "" && password == c.DefaultPassword {
+		violations = append(violations, "differ from the default password")
+	}
+	if len(violations) > 0 {
+		return apperrors.NewValidationError("password must " + strings.Join(violations, ", "))
+	}
+	return nil
+}
@@ -3,9 +3,12 @@ package services
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+	"cpd-nexus/internal/pkg/logger"
 	"time"
 )
 
@@ -45,6 +48,11 @@ func (s *PitstopService) GetAuthorisations(ctx context.Context, userID string) (
 
 // SyncConfig fetches the newest configs from the Pitstop API and upserts them
 func (s *PitstopService) SyncConfig(ctx context.Context, userID string) error {
+	// The authorisation catalogue spans every tenant, so only callers with a global scope may resync it
+	if err := requireGlobalScope(ctx, "sync Pitstop authorisations"); err != nil {
+		return err
+	}
+
 	// 1. Fetch from Pitstop API via the port interface — no concrete adapter type referenced
 	cfgResponse, err := s.externalClient.FetchPitstopConfig(ctx)
 	if err != nil {
@@ -149,6 +157,10 @@ func (s *PitstopService) GetProjectsWithPendingAttendance(ctx context.Context, u
 
 // TestSubmission extracts pending attendance for a given project and immediately pushes it
 func (s *PitstopService) TestSubmission(ctx context.Context, userID, projectID string) (submittedCount int, failedCount int, err error) {
+	if err := ports.Authorize(ctx, domain.PermSubmissionsRun); err != nil {
+		return 0, 0, err
+	}
+
 	settings, err := s.loadSettings(ctx)
 	if err != nil {
 		return 0, 0, err
@@ -158,14 +170,23 @@ func (s *PitstopService) TestSubmission(ctx context.Context, userID, projectID s
 	if err != nil {
 		return 0, 0, fmt.Errorf("failed to extract project attendance: %w", err)
 	}
+	rows, nonDevice, policy := applyManualPolicy(settings, rows)
 
+	rows, err = s.claimRows(ctx, rows)
+	if err != nil {
+		return 0, 0, err
+	}
 	if len(rows) == 0 {
 		return 0, 0, nil
 	}
+	defer s.releaseClaims(ctx, rows)
+	if err := s.flagRows(ctx, rows, nonDevice, policy); err != nil {
+		return 0, 0, err
+	}
 
 	submittedCount, failedCount, err = s.externalClient.SubmitManpowerUtilization(ctx, s.submissionRepo, settings, rows)
 
-	details := fmt.Sprintf("Submitted %d records (%d validation failed) for project %s", submittedCount, failedCount, projectID)
+	details := fmt.Sprintf("Submitted %d records (%d validation failed) for project %s", submittedCount, failedCount, projectID) + manualPolicyNote(nonDevice, policy)
 	if err != nil {
 		details = fmt.Sprintf("Submission failed: %v. Records processed: %d", err, submittedCount)
 	}
@@ -178,6 +199,42 @@ func (s *PitstopService) TestSubmission(ctx context.Context, userID, projectID s
 	return submittedCount, failedCount, nil
 }
 
+// PreviewSubmission runs the mapping and batching for a project's pending attendance without
+// calling Pitstop or changing any attendance status. Used to inspect what TestSubmission would send.
+func (s *PitstopService) PreviewSubmission(ctx context.Context, userID, projectID string) (*ports.SubmissionPreview, error) {
+	if err := ports.Authorize(ctx, domain.PermSubmissionsRead); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.loadSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.attendanceRepo.ExtractPendingAttendanceByProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract project attendance: %w", err)
+	}
+
+	total := len(rows)
+	rows, nonDevice, policy := applyManualPolicy(settings, rows)
+
+	preview, err := s.externalClient.PreviewManpowerUtilization(ctx, settings, rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build submission preview: %w", err)
+	}
+	preview.TotalRows = total
+	preview.Flagged = []string{}
+	if policy == domain.ManualPolicyExclude {
+		for _, id := range nonDevice {
+			preview.Skipped = append(preview.Skipped, ports.SubmissionPreviewFailure{AttendanceID: id, Error: "attendance entered manually or imported is excluded from submission"})
+		}
+	} else {
+		preview.Flagged = append(preview.Flagged, nonDevice...)
+	}
+	return preview, nil
+}
+
 // SubmitPendingAttendance extracts all non-submitted attendance and pushes it to SGBuildex.
 // This is the method called by the scheduled task in main.go.
 func (s *PitstopService) SubmitPendingAttendance(ctx context.Context) error {
@@ -190,24 +247,88 @@ func (s *PitstopService) SubmitPendingAttendance(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to extract pending attendance: %w", err)
 	}
+	rows, nonDevice, policy := applyManualPolicy(settings, rows)
 
+	rows, err = s.claimRows(ctx, rows)
+	if err != nil {
+		return err
+	}
 	if len(rows) == 0 {
 		return nil
 	}
+	defer s.releaseClaims(ctx, rows)
+	if err := s.flagRows(ctx, rows, nonDevice, policy); err != nil {
+		return err
+	}
 
 	// Submit via the port interface — no concrete adapter type referenced
 	_, _, err = s.externalClient.SubmitManpowerUtilization(ctx, s.submissionRepo, settings, rows)
 	if err == nil {
-		s.analytics.LogActivity(ctx, "system", "Scheduled CPD Submission", "system", "pitstop", fmt.Sprintf("Automatically submitted %d pending attendance records to SGBuildex", len(rows)))
+		s.analytics.LogActivity(ctx, "system", "Scheduled CPD Submission", "system", "pitstop", fmt.Sprintf("Automatically submitted %d pending attendance records to SGBuildex", len(rows))+manualPolicyNote(nonDevice, policy))
 	}
 	return err
 }
 
+// RetryFailedSubmissions resubmits rows whose retry backoff has elapsed. Returns the number of rows attempted.
+func (s *PitstopService) RetryFailedSubmissions(ctx context.Context) (int, error) {
+	settings, err := s.loadSettings(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.attendanceRepo.ExtractDueRetries(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract due retries: %w", err)
+	}
+	rows, nonDevice, policy := applyManualPolicy(settings, rows)
+	rows, err = s.claimRows(ctx, rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	defer s.releaseClaims(ctx, rows)
+	if err := s.flagRows(ctx, rows, nonDevice, policy); err != nil {
+		return 0, err
+	}
+
+	_, _, err = s.externalClient.SubmitManpowerUtilization(ctx, s.submissionRepo, settings, rows)
+	return len(rows), err
+}
+
+// ListDeadLetterSubmissions returns attendance rows that will not be retried automatically.
+func (s *PitstopService) ListDeadLetterSubmissions(ctx context.Context, userID string) ([]domain.DeadLetterSubmission, error) {
+	if err := ports.Authorize(ctx, domain.PermSubmissionsRead); err != nil {
+		return nil, err
+	}
+	return s.submissionRepo.ListDeadLetterAttendance(ctx, userID)
+}
+
+// RequeueSubmissions moves dead-lettered attendance back to pending with a fresh retry budget.
+func (s *PitstopService) RequeueSubmissions(ctx context.Context, userID string, attendanceIDs []string) (int64, error) {
+	if len(attendanceIDs) == 0 {
+		return 0, apperrors.NewValidationError("attendance_ids is required")
+	}
+	if err := ports.Authorize(ctx, domain.PermSubmissionsRun); err != nil {
+		return 0, err
+	}
+	n, err := s.submissionRepo.RequeueAttendance(ctx, userID, attendanceIDs)
+	if err == nil && n > 0 {
+		actorUserID := ports.GetUserID(ctx)
+		s.analytics.LogActivity(ctx, actorUserID, "CPD Submission Requeued", "attendance", strings.Join(attendanceIDs, ","), fmt.Sprintf("Requeued %d dead-lettered attendance records for submission", n))
+	}
+	return n, err
+}
+
 // AssignOnBehalfOfToUser assigns a set of contractor names to a specific UserID for pitstop authorisations
 func (s *PitstopService) AssignOnBehalfOfToUser(ctx context.Context, userID string, onBehalfOfNames []string) error {
 	if userID == "" {
 		return fmt.Errorf("user ID cannot be empty")
 	}
+	if err := ports.AuthorizeTenant(ctx, userID); err != nil {
+		return err
+	}
 	err := s.pitstopRepo.AssignOnBehalfOfToUser(ctx, userID, onBehalfOfNames)
 	if err == nil {
 		actorUserID := ports.GetUserID(ctx)
@@ -227,7 +348,111 @@ func (s *PitstopService) loadSettings(ctx context.Context) (*domain.SystemSettin
 			MaxWorkersPerRequest: 100,
 			MaxPayloadSizeKB:     256,
 			MaxRequestsPerMinute: 150,
+			// Report attendance not recorded by a device, the migration default
+			ManualAttendancePolicy: domain.ManualPolicyFlag,
 		}, nil
 	}
 	return settings, nil
 }
+
+// claimRows claims rows for this submission run and drops those another run claimed first.
+func (s *PitstopService) claimRows(ctx context.Context, rows []domain.AttendanceRow) ([]domain.AttendanceRow, error) {
+	if len(rows) == 0 {
+		return rows, nil
+	}
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.AttendanceID
+	}
+	claimed, err := s.attendanceRepo.ClaimForSubmission(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim attendance for submission: %w", err)
+	}
+
+	held := make(map[string]bool, len(claimed))
+	for _, id := range claimed {
+		held[id] = true
+	}
+	kept := rows[:0:0]
+	for _, row := range rows {
+		if held[row.AttendanceID] {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// releaseClaims hands back claimed rows the run did not record an outcome for, such as rows left
+// out of every batch or not reached before an error.
+func (s *PitstopService) releaseClaims(ctx context.Context, rows []domain.AttendanceRow) {
+	ids := make([]string, len(rows))
+	for i, row := range rows {
+		ids[i] = row.AttendanceID
+	}
+	if err := s.attendanceRepo.ReleaseSubmissionClaims(ctx, ids); err != nil {
+		logger.Errorf("[PitstopService] Failed to release submission claims: %v", err)
+	}
+}
+
+// flagRows stores the flag on the claimed rows that ManualPolicyFlag flagged, before they are sent.
+func (s *PitstopService) flagRows(ctx context.Context, claimed []domain.AttendanceRow, nonDevice []string, policy string) error {
+	if policy != domain.ManualPolicyFlag || len(nonDevice) == 0 {
+		return nil
+	}
+	held := make(map[string]bool, len(claimed))
+	for _, row := range claimed {
+		held[row.AttendanceID] = true
+	}
+	var ids []string
+	for _, id := range nonDevice {
+		if held[id] {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.attendanceRepo.MarkFlagged(ctx, ids); err != nil {
+		return fmt.Errorf("failed to flag attendance entered manually or imported: %w", err)
+	}
+	return nil
+}
+
+// applyManualPolicy applies the manual attendance policy to rows about to be submitted. It returns
+// the rows to submit, the IDs of those not recorded by a device and the policy applied; under
+// ManualPolicyExclude the latter are left out of the rows and stay pending.
+func applyManualPolicy(settings *domain.SystemSettings, rows []domain.AttendanceRow) ([]domain.AttendanceRow, []string, string) {
+	policy := settings.ManualAttendancePolicy
+	if !domain.IsValidManualPolicy(policy) {
+		policy = domain.ManualPolicyFlag
+	}
+	if policy == domain.ManualPolicySubmit {
+		return rows, nil, policy
+	}
+
+	var nonDevice []string
+	kept := rows[:0:0]
+	for _, row := range rows {
+		if domain.IsDeviceSource(row.Source) {
+			kept = append(kept, row)
+			continue
+		}
+		nonDevice = append(nonDevice, row.AttendanceID)
+		if policy == domain.ManualPolicyFlag {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nonDevice, policy
+}
+
+// manualPolicyNote describes for the activity log what the policy did with attendance not recorded by a device.
+func manualPolicyNote(nonDevice []string, policy string) string {
+	switch {
+	case len(nonDevice) == 0:
+		return ""
+	case policy == domain.ManualPolicyExclude:
+		return fmt.Sprintf("; withheld %d record(s) entered manually or imported", len(nonDevice))
+	default:
+		return fmt.Sprintf("; flagged %d of them as entered manually or imported", len(nonDevice))
+	}
+}
@@ -3,10 +3,12 @@ package services
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -63,13 +65,23 @@ func (m *MockAttendanceRepository) ExtractPendingAttendance(ctx context.Context)
 	return args.Get(0).([]domain.AttendanceRow), args.Error(1)
 }
 
+func (m *MockAttendanceRepository) ExtractDueRetries(ctx context.Context) ([]domain.AttendanceRow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.AttendanceRow), args.Error(1)
+}
+
 func (m *MockAttendanceRepository) ExtractPendingAttendanceByProject(ctx context.Context, userID, projectID string) ([]domain.AttendanceRow, error) {
 	args := m.Called(ctx, userID, projectID)
 	return args.Get(0).([]domain.AttendanceRow), args.Error(1)
 }
 
-func (m *MockAttendanceRepository) Create(ctx context.Context, a *domain.Attendance) error {
+func (m *MockAttendanceRepository) Create(ctx context.Context, a *domain.Attendance) (bool, error) {
 	args := m.Called(ctx, a)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAttendanceRepository) CreateBatch(ctx context.Context, records []*domain.Attendance) error {
+	args := m.Called(ctx, records)
 	return args.Error(0)
 }
 
@@ -78,13 +90,39 @@ func (m *MockAttendanceRepository) GetMaxID(ctx context.Context, pattern string)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockAttendanceRepository) GenerateNextID(ctx context.Context) (string, error) {
-	args := m.Called(ctx)
-	return args.String(0), args.Error(1)
+func (m *MockAttendanceRepository) ListByWorkerDates(ctx context.Context, workerID string, dates []string) ([]domain.Attendance, error) {
+	args := m.Called(ctx, workerID, dates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Attendance), args.Error(1)
+}
+
+func (m *MockAttendanceRepository) UpdateSession(ctx context.Context, id string, timeOut, openUntil *time.Time, direction string) error {
+	args := m.Called(ctx, id, timeOut, openUntil, direction)
+	return args.Error(0)
+}
+
+func (m *MockAttendanceRepository) Delete(ctx context.Context, id string) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAttendanceRepository) ClaimForSubmission(ctx context.Context, attendanceIDs []string) ([]string, error) {
+	args := m.Called(ctx, attendanceIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAttendanceRepository) ReleaseSubmissionClaims(ctx context.Context, attendanceIDs []string) error {
+	args := m.Called(ctx, attendanceIDs)
+	return args.Error(0)
 }
 
-func (m *MockAttendanceRepository) Update(ctx context.Context, userID, id string, timeIn, timeOut *time.Time) error {
-	args := m.Called(ctx, userID, id, timeIn, timeOut)
+func (m *MockAttendanceRepository) MarkFlagged(ctx context.Context, attendanceIDs []string) error {
+	args := m.Called(ctx, attendanceIDs)
 	return args.Error(0)
 }
 
@@ -102,6 +140,21 @@ func (m *MockSubmissionRepository) LogSubmission(ctx context.Context, refType, r
 	return args.Error(0)
 }
 
+func (m *MockSubmissionRepository) RecordAttendanceFailure(ctx context.Context, attendanceID, responsePayload, errorMessage string, retryable bool) error {
+	args := m.Called(ctx, attendanceID, responsePayload, errorMessage, retryable)
+	return args.Error(0)
+}
+
+func (m *MockSubmissionRepository) ListDeadLetterAttendance(ctx context.Context, userID string) ([]domain.DeadLetterSubmission, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]domain.DeadLetterSubmission), args.Error(1)
+}
+
+func (m *MockSubmissionRepository) RequeueAttendance(ctx context.Context, userID string, attendanceIDs []string) (int64, error) {
+	args := m.Called(ctx, userID, attendanceIDs)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 type MockSettingsRepository struct {
 	mock.Mock
 }
@@ -133,6 +186,14 @@ func (m *MockExternalSubmitter) SubmitManpowerUtilization(ctx context.Context, r
 	return args.Int(0), args.Int(1), args.Error(2)
 }
 
+func (m *MockExternalSubmitter) PreviewManpowerUtilization(ctx context.Context, settings *domain.SystemSettings, rows []domain.AttendanceRow) (*ports.SubmissionPreview, error) {
+	args := m.Called(ctx, settings, rows)
+	if args.Get(0) != nil {
+		return args.Get(0).(*ports.SubmissionPreview), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
 func (m *MockExternalSubmitter) FetchPitstopConfig(ctx context.Context) (*ports.PitstopConfigResponse, error) {
 	args := m.Called(ctx)
 	if args.Get(0) != nil {
@@ -153,7 +214,7 @@ func TestPitstopService_TestSubmission_SuccessAndFailures(t *testing.T) {
 
 	svc := NewPitstopService(mockPitstopRepo, mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
 
-	ctx := context.Background()
+	ctx := ports.WithSystemCaller(context.Background())
 	userID := "user123"
 	projectID := "proj123"
 
@@ -184,6 +245,8 @@ func TestPitstopService_TestSubmission_SuccessAndFailures(t *testing.T) {
 		},
 	}
 	mockAttendanceRepo.On("ExtractPendingAttendanceByProject", ctx, userID, projectID).Return(rows, nil)
+	mockAttendanceRepo.On("ClaimForSubmission", ctx, []string{"ATT-1", "ATT-2"}).Return([]string{"ATT-1", "ATT-2"}, nil)
+	mockAttendanceRepo.On("ReleaseSubmissionClaims", ctx, []string{"ATT-1", "ATT-2"}).Return(nil)
 
 	// Since ATT-2 is invalid, the adapter will update it as failed in the DB.
 	// We mock the adapter to return 1 submitted and 1 failed.
@@ -218,7 +281,7 @@ func TestPitstopService_TestSubmission_ExtractError(t *testing.T) {
 
 	svc := NewPitstopService(mockPitstopRepo, mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
 
-	ctx := context.Background()
+	ctx := ports.WithSystemCaller(context.Background())
 
 	mockSettingsRepo.On("GetSettings", ctx).Return(&domain.SystemSettings{}, nil)
 
@@ -243,7 +306,7 @@ func TestPitstopService_TestSubmission_NoRows(t *testing.T) {
 
 	svc := NewPitstopService(mockPitstopRepo, mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
 
-	ctx := context.Background()
+	ctx := ports.WithSystemCaller(context.Background())
 
 	mockSettingsRepo.On("GetSettings", ctx).Return(&domain.SystemSettings{}, nil)
 	mockAttendanceRepo.On("ExtractPendingAttendanceByProject", ctx, "user123", "proj123").Return([]domain.AttendanceRow{}, nil)
@@ -254,3 +317,253 @@ func TestPitstopService_TestSubmission_NoRows(t *testing.T) {
 	assert.Equal(t, 0, submittedCount)
 	assert.Equal(t, 0, failedCount)
 }
+
+func TestPitstopService_RetryFailedSubmissions_SubmitsDueRows(t *testing.T) {
+	mockAttendanceRepo := new(MockAttendanceRepository)
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	mockSettingsRepo := new(MockSettingsRepository)
+	mockExternalSubmitter := new(MockExternalSubmitter)
+
+	svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, new(MockAnalyticsService))
+	ctx := ports.WithSystemCaller(context.Background())
+
+	settings := &domain.SystemSettings{MaxWorkersPerRequest: 100}
+	rows := []domain.AttendanceRow{{AttendanceID: "ATT-1"}, {AttendanceID: "ATT-2"}}
+	mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+	mockAttendanceRepo.On("ExtractDueRetries", ctx).Return(rows, nil)
+	mockAttendanceRepo.On("ClaimForSubmission", ctx, []string{"ATT-1", "ATT-2"}).Return([]string{"ATT-1", "ATT-2"}, nil)
+	mockAttendanceRepo.On("ReleaseSubmissionClaims", ctx, []string{"ATT-1", "ATT-2"}).Return(nil)
+	mockExternalSubmitter.On("SubmitManpowerUtilization", ctx, mockSubmissionRepo, settings, rows).Return(2, 0, nil)
+
+	n, err := svc.RetryFailedSubmissions(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	mockExternalSubmitter.AssertExpectations(t)
+	mockAttendanceRepo.AssertExpectations(t)
+}
+
+func TestPitstopService_SubmitPendingAttendance_SkipsRowsClaimedElsewhere(t *testing.T) {
+	mockAttendanceRepo := new(MockAttendanceRepository)
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	mockSettingsRepo := new(MockSettingsRepository)
+	mockExternalSubmitter := new(MockExternalSubmitter)
+	mockAnalytics := new(MockAnalyticsService)
+
+	svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
+	ctx := ports.WithSystemCaller(context.Background())
+
+	settings := &domain.SystemSettings{MaxWorkersPerRequest: 100, ManualAttendancePolicy: domain.ManualPolicySubmit}
+	rows := []domain.AttendanceRow{{AttendanceID: "ATT-1"}, {AttendanceID: "ATT-2"}}
+	mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+	mockAttendanceRepo.On("ExtractPendingAttendance", ctx).Return(rows, nil)
+
+	// The retry loop already holds ATT-1, so only ATT-2 is sent by this run
+	mockAttendanceRepo.On("ClaimForSubmission", ctx, []string{"ATT-1", "ATT-2"}).Return([]string{"ATT-2"}, nil).Once()
+	mockAttendanceRepo.On("ReleaseSubmissionClaims", ctx, []string{"ATT-2"}).Return(nil).Once()
+	mockExternalSubmitter.On("SubmitManpowerUtilization", ctx, mockSubmissionRepo, settings, rows[1:]).Return(1, 0, nil).Once()
+	mockAnalytics.On("LogActivity", ctx, "system", "Scheduled CPD Submission", "system", "pitstop", mock.Anything).Return(nil)
+
+	assert.NoError(t, svc.SubmitPendingAttendance(ctx))
+
+	// A run that claims nothing sends nothing
+	mockAttendanceRepo.On("ClaimForSubmission", ctx, []string{"ATT-1", "ATT-2"}).Return(nil, nil).Once()
+
+	assert.NoError(t, svc.SubmitPendingAttendance(ctx))
+	mockExternalSubmitter.AssertNumberOfCalls(t, "SubmitManpowerUtilization", 1)
+	mockAttendanceRepo.AssertExpectations(t)
+}
+
+func TestPitstopService_RequeueSubmissions(t *testing.T) {
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	mockAnalytics := new(MockAnalyticsService)
+
+	svc := NewPitstopService(new(MockPitstopRepository), new(MockExternalSubmitter), new(MockAttendanceRepository), mockSubmissionRepo, new(MockSettingsRepository), mockAnalytics)
+	ctx := ports.WithSystemCaller(context.Background())
+
+	// Empty selection is rejected without touching the repository
+	_, err := svc.RequeueSubmissions(ctx, "", nil)
+	assert.Error(t, err)
+	mockSubmissionRepo.AssertNotCalled(t, "RequeueAttendance", mock.Anything, mock.Anything, mock.Anything)
+
+	ids := []string{"ATT-1", "ATT-2"}
+	mockSubmissionRepo.On("RequeueAttendance", ctx, "", ids).Return(int64(2), nil)
+	mockAnalytics.On("LogActivity", ctx, "", "CPD Submission Requeued", "attendance", "ATT-1,ATT-2", mock.Anything).Return(nil)
+
+	n, err := svc.RequeueSubmissions(ctx, "", ids)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	mockAnalytics.AssertExpectations(t)
+}
+
+func TestPitstopService_ListDeadLetterSubmissions_Permissions(t *testing.T) {
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	svc := NewPitstopService(new(MockPitstopRepository), new(MockExternalSubmitter), new(MockAttendanceRepository), mockSubmissionRepo, new(MockSettingsRepository), new(MockAnalyticsService))
+
+	_, err := svc.ListDeadLetterSubmissions(roleContext("user123", domain.RoleWorker), "user123")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	_, err = svc.ListDeadLetterSubmissions(context.Background(), "user123")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	mockSubmissionRepo.AssertNotCalled(t, "ListDeadLetterAttendance", mock.Anything, mock.Anything)
+
+	ctx := roleContext("user123", domain.RoleViewer)
+	dead := []domain.DeadLetterSubmission{{AttendanceID: "ATT-1"}}
+	mockSubmissionRepo.On("ListDeadLetterAttendance", ctx, "user123").Return(dead, nil)
+	got, err := svc.ListDeadLetterSubmissions(ctx, "user123")
+	assert.NoError(t, err)
+	assert.Equal(t, dead, got)
+}
+
+func TestPitstopService_PreviewSubmission_NoSideEffects(t *testing.T) {
+	mockPitstopRepo := new(MockPitstopRepository)
+	mockAttendanceRepo := new(MockAttendanceRepository)
+	mockSubmissionRepo := new(MockSubmissionRepository)
+	mockSettingsRepo := new(MockSettingsRepository)
+	mockExternalSubmitter := new(MockExternalSubmitter)
+	mockAnalytics := new(MockAnalyticsService)
+
+	svc := NewPitstopService(mockPitstopRepo, mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
+
+	ctx := ports.WithSystemCaller(context.Background())
+	settings := &domain.SystemSettings{MaxWorkersPerRequest: 100, MaxPayloadSizeKB: 256}
+	mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+
+	rows := []domain.AttendanceRow{{AttendanceID: "ATT-1"}, {AttendanceID: "ATT-2"}}
+	mockAttendanceRepo.On("ExtractPendingAttendanceByProject", ctx, "user123", "proj123").Return(rows, nil)
+
+	expected := &ports.SubmissionPreview{
+		DataElementID: "manpower_utilization",
+		TotalRows:     2,
+		ValidRows:     1,
+		Failures:      []ports.SubmissionPreviewFailure{{AttendanceID: "ATT-2", Error: "person_id_no"}},
+	}
+	mockExternalSubmitter.On("PreviewManpowerUtilization", ctx, settings, rows).Return(expected, nil)
+
+	preview, err := svc.PreviewSubmission(ctx, "user123", "proj123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, preview)
+	mockExternalSubmitter.AssertNotCalled(t, "SubmitManpowerUtilization", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockSubmissionRepo.AssertNotCalled(t, "UpdateAttendanceStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockSubmissionRepo.AssertNotCalled(t, "RecordAttendanceFailure", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockAnalytics.AssertNotCalled(t, "LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPitstopService_TestSubmission_RequiresTriggerPermission(t *testing.T) {
+	mockAttendanceRepo := new(MockAttendanceRepository)
+	mockSettingsRepo := new(MockSettingsRepository)
+	mockExternalSubmitter := new(MockExternalSubmitter)
+
+	svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, new(MockSubmissionRepository), mockSettingsRepo, new(MockAnalyticsService))
+
+	for _, role := range []string{domain.RolePIC, domain.RoleViewer} {
+		ctx := roleContext("user123", role)
+
+		_, _, err := svc.TestSubmission(ctx, "user123", "proj123")
+
+		assert.ErrorIs(t, err, apperrors.ErrPermissionDenied, role)
+	}
+	// A context with neither a user nor the system-caller mark holds no permissions
+	_, _, err := svc.TestSubmission(context.Background(), "user123", "proj123")
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	mockSettingsRepo.AssertNotCalled(t, "GetSettings", mock.Anything)
+	mockExternalSubmitter.AssertNotCalled(t, "SubmitManpowerUtilization", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPitstopService_ManualAttendancePolicy(t *testing.T) {
+	rows := []domain.AttendanceRow{
+		{AttendanceID: "ATT-1", Source: domain.AttendanceSourceDevice},
+		{AttendanceID: "ATT-2", Source: domain.AttendanceSourceManual},
+		{AttendanceID: "ATT-3", Source: domain.AttendanceSourceImport},
+	}
+
+	t.Run("exclude withholds non-device rows", func(t *testing.T) {
+		mockAttendanceRepo := new(MockAttendanceRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		mockSettingsRepo := new(MockSettingsRepository)
+		mockExternalSubmitter := new(MockExternalSubmitter)
+		mockAnalytics := new(MockAnalyticsService)
+		svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
+		ctx := ports.WithSystemCaller(context.Background())
+
+		settings := &domain.SystemSettings{MaxWorkersPerRequest: 100, ManualAttendancePolicy: domain.ManualPolicyExclude}
+		mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+		mockAttendanceRepo.On("ExtractPendingAttendance", ctx).Return(rows, nil)
+		mockAttendanceRepo.On("ClaimForSubmission", ctx, []string{"ATT-1"}).Return([]string{"ATT-1"}, nil)
+		mockAttendanceRepo.On("ReleaseSubmissionClaims", ctx, []string{"ATT-1"}).Return(nil)
+		mockExternalSubmitter.On("SubmitManpowerUtilization", ctx, mockSubmissionRepo, settings, rows[:1]).Return(1, 0, nil)
+		mockAnalytics.On("LogActivity", ctx, "system", "Scheduled CPD Submission", "system", "pitstop",
+			mock.MatchedBy(func(details string) bool { return strings.Contains(details, "withheld 2 record(s)") })).Return(nil)
+
+		assert.NoError(t, svc.SubmitPendingAttendance(ctx))
+		mockExternalSubmitter.AssertExpectations(t)
+		mockAnalytics.AssertExpectations(t)
+	})
+
+	t.Run("exclude skips them in the preview", func(t *testing.T) {
+		mockAttendanceRepo := new(MockAttendanceRepository)
+		mockSettingsRepo := new(MockSettingsRepository)
+		mockExternalSubmitter := new(MockExternalSubmitter)
+		svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, new(MockSubmissionRepository), mockSettingsRepo, new(MockAnalyticsService))
+		ctx := ports.WithSystemCaller(context.Background())
+
+		settings := &domain.SystemSettings{ManualAttendancePolicy: domain.ManualPolicyExclude}
+		mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+		mockAttendanceRepo.On("ExtractPendingAttendanceByProject", ctx, "user123", "proj123").Return(rows, nil)
+		mockExternalSubmitter.On("PreviewManpowerUtilization", ctx, settings, rows[:1]).Return(&ports.SubmissionPreview{TotalRows: 1, ValidRows: 1}, nil)
+
+		preview, err := svc.PreviewSubmission(ctx, "user123", "proj123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, preview.TotalRows)
+		assert.Empty(t, preview.Flagged)
+		if assert.Len(t, preview.Skipped, 2) {
+			assert.Equal(t, "ATT-2", preview.Skipped[0].AttendanceID)
+			assert.Equal(t, "ATT-3", preview.Skipped[1].AttendanceID)
+		}
+	})
+
+	t.Run("flag submits and reports them", func(t *testing.T) {
+		mockAttendanceRepo := new(MockAttendanceRepository)
+		mockSettingsRepo := new(MockSettingsRepository)
+		mockExternalSubmitter := new(MockExternalSubmitter)
+		svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, new(MockSubmissionRepository), mockSettingsRepo, new(MockAnalyticsService))
+		ctx := ports.WithSystemCaller(context.Background())
+
+		// Unset policy behaves like the default, flag
+		settings := &domain.SystemSettings{}
+		mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+		mockAttendanceRepo.On("ExtractPendingAttendanceByProject", ctx, "user123", "proj123").Return(rows, nil)
+		mockExternalSubmitter.On("PreviewManpowerUtilization", ctx, settings, rows).Return(&ports.SubmissionPreview{TotalRows: 3, ValidRows: 3}, nil)
+
+		preview, err := svc.PreviewSubmission(ctx, "user123", "proj123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ATT-2", "ATT-3"}, preview.Flagged)
+		assert.Empty(t, preview.Skipped)
+	})
+
+	t.Run("flag is stored on the rows submitted", func(t *testing.T) {
+		mockAttendanceRepo := new(MockAttendanceRepository)
+		mockSubmissionRepo := new(MockSubmissionRepository)
+		mockSettingsRepo := new(MockSettingsRepository)
+		mockExternalSubmitter := new(MockExternalSubmitter)
+		mockAnalytics := new(MockAnalyticsService)
+		svc := NewPitstopService(new(MockPitstopRepository), mockExternalSubmitter, mockAttendanceRepo, mockSubmissionRepo, mockSettingsRepo, mockAnalytics)
+		ctx := ports.WithSystemCaller(context.Background())
+
+		settings := &domain.SystemSettings{MaxWorkersPerRequest: 100, ManualAttendancePolicy: domain.ManualPolicyFlag}
+		mockSettingsRepo.On("GetSettings", ctx).Return(settings, nil)
+		mockAttendanceRepo.On("ExtractPendingAttendance", ctx).Return(rows, nil)
+		// ATT-3 was claimed by another run, so only ATT-2 is flagged here
+		mockAttendanceRepo.On("ClaimForSubmission", ctx, []string{"ATT-1", "ATT-2", "ATT-3"}).Return([]string{"ATT-1", "ATT-2"}, nil)
+		mockAttendanceRepo.On("MarkFlagged", ctx, []string{"ATT-2"}).Return(nil)
+		mockAttendanceRepo.On("ReleaseSubmissionClaims", ctx, []string{"ATT-1", "ATT-2"}).Return(nil)
+		mockExternalSubmitter.On("SubmitManpowerUtilization", ctx, mockSubmissionRepo, settings, rows[:2]).Return(2, 0, nil)
+		mockAnalytics.On("LogActivity", ctx, "system", "Scheduled CPD Submission", "system", "pitstop", mock.Anything).Return(nil)
+
+		assert.NoError(t, svc.SubmitPendingAttendance(ctx))
+		mockAttendanceRepo.AssertExpectations(t)
+		mockExternalSubmitter.AssertExpectations(t)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
 	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/timeutil"
 	"time"
 )
 
@@ -53,7 +54,7 @@ func (s *DailyScheduler) Start(ctx context.Context) {
 		}
 
 		scheduledTimeStr := s.timeExtractor(settings) // Format should be "HH:MM:SS"
-		now := time.Now()
+		now := timeutil.BusinessNow() // schedule times are business wall-clock times, whatever the host's zone
 
 		// Parse the HH:MM:SS
 		var hour, min, sec int
@@ -68,14 +69,7 @@ func (s *DailyScheduler) Start(ctx context.Context) {
 			}
 		}
 
-		// Calculate when the next run should be (today)
-		nextRun := time.Date(now.Year(), now.Month(), now.Day(), hour, min, sec, 0, now.Location())
-
-		// If it's already past this time today, schedule for tomorrow
-		if !nextRun.After(now) {
-			nextRun = nextRun.Add(24 * time.Hour)
-		}
-
+		nextRun := nextDailyRun(now, hour, min, sec)
 		durationUntilNext := time.Until(nextRun)
 		logger.Infof("[%s] Scheduler: Next run scheduled for %v (in %v)", s.name, nextRun.Format(time.RFC3339), durationUntilNext.Truncate(time.Second))
 
@@ -106,3 +100,13 @@ func (s *DailyScheduler) Start(ctx context.Context) {
 		}
 	}
 }
+
+// nextDailyRun returns the next time after now at which the clock in now's location reads
+// hour:min:sec: today if that is still ahead, otherwise tomorrow.
+func nextDailyRun(now time.Time, hour, min, sec int) time.Time {
+	nextRun := time.Date(now.Year(), now.Month(), now.Day(), hour, min, sec, 0, now.Location())
+	if !nextRun.After(now) {
+		nextRun = time.Date(now.Year(), now.Month(), now.Day()+1, hour, min, sec, 0, now.Location())
+	}
+	return nextRun
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDailyRun_BusinessDayBoundary(t *testing.T) {
+	sgt := time.FixedZone("SGT", 8*60*60)
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		// 23:30 UTC is 07:30 the next morning in Singapore, so an 08:00 run is half an hour away
+		{"later today in business time", time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC).In(sgt), time.Date(2026, 3, 2, 8, 0, 0, 0, sgt)},
+		{"already past today", time.Date(2026, 3, 2, 9, 0, 0, 0, sgt), time.Date(2026, 3, 3, 8, 0, 0, 0, sgt)},
+		{"exactly due runs tomorrow", time.Date(2026, 3, 2, 8, 0, 0, 0, sgt), time.Date(2026, 3, 3, 8, 0, 0, 0, sgt)},
+		{"month end", time.Date(2026, 3, 31, 23, 0, 0, 0, sgt), time.Date(2026, 4, 1, 8, 0, 0, 0, sgt)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextDailyRun(tt.now, 8, 0, 0)
+			assert.True(t, got.Equal(tt.want), "nextDailyRun(%s) = %s; want %s", tt.now, got, tt.want)
+		})
+	}
+}
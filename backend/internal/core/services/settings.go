@@ -4,6 +4,7 @@ import (
 	"context"
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
 	"cpd-nexus/internal/pkg/logger"
 )
 
@@ -42,6 +43,16 @@ func (s *SettingsService) GetSettings(ctx context.Context) (*domain.SystemSettin
 }
 
 func (s *SettingsService) UpdateSettings(ctx context.Context, settings domain.SystemSettings) error {
+	if err := ports.Authorize(ctx, domain.PermSettingsWrite); err != nil {
+		return err
+	}
+	if err := s.applyPairingRules(ctx, &settings); err != nil {
+		return err
+	}
+	if err := s.applyManualAttendancePolicy(ctx, &settings); err != nil {
+		return err
+	}
+
 	logger.Infof("[SettingsService] Updating system settings in database...")
 	if err := s.repo.UpdateSettings(ctx, settings); err != nil {
 		return err
@@ -65,3 +76,47 @@ func (s *SettingsService) UpdateSettings(ctx context.Context, settings domain.Sy
 
 	return nil
 }
+
+// applyPairingRules keeps the stored pairing rules for fields the update leaves empty, so older
+// clients that do not send them cannot reset them, and validates the result.
+func (s *SettingsService) applyPairingRules(ctx context.Context, settings *domain.SystemSettings) error {
+	if settings.AttendancePairingMode == "" || settings.MaxSessionHours == 0 {
+		current, err := s.repo.GetSettings(ctx)
+		if err != nil {
+			return err
+		}
+		if settings.AttendancePairingMode == "" {
+			settings.AttendancePairingMode = current.AttendancePairingMode
+			settings.PunchDebounceSeconds = current.PunchDebounceSeconds
+		}
+		if settings.MaxSessionHours == 0 {
+			settings.MaxSessionHours = current.MaxSessionHours
+		}
+	}
+
+	if !domain.IsValidPairingMode(settings.AttendancePairingMode) {
+		return apperrors.NewValidationError("attendance_pairing_mode must be first_in_last_out or paired")
+	}
+	if settings.PunchDebounceSeconds < 0 || settings.PunchDebounceSeconds > 3600 {
+		return apperrors.NewValidationError("punch_debounce_seconds must be between 0 and 3600")
+	}
+	if settings.MaxSessionHours < 1 || settings.MaxSessionHours > 48 {
+		return apperrors.NewValidationError("max_session_hours must be between 1 and 48")
+	}
+	return nil
+}
+
+// applyManualAttendancePolicy keeps the stored policy when the update leaves it empty and validates it.
+func (s *SettingsService) applyManualAttendancePolicy(ctx context.Context, settings *domain.SystemSettings) error {
+	if settings.ManualAttendancePolicy == "" {
+		current, err := s.repo.GetSettings(ctx)
+		if err != nil {
+			return err
+		}
+		settings.ManualAttendancePolicy = current.ManualAttendancePolicy
+	}
+	if !domain.IsValidManualPolicy(settings.ManualAttendancePolicy) {
+		return apperrors.NewValidationError("manual_attendance_policy must be submit, flag or exclude")
+	}
+	return nil
+}
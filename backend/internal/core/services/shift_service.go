@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+)
+
+// maxShiftGraceMinutes bounds the grace windows so consecutive shifts cannot swallow each other.
+const maxShiftGraceMinutes = 240
+
+type ShiftService struct {
+	repo        ports.ShiftRepository
+	siteRepo    ports.SiteRepository
+	projectRepo ports.ProjectRepository
+	analytics   ports.AnalyticsService
+}
+
+func NewShiftService(repo ports.ShiftRepository, siteRepo ports.SiteRepository, projectRepo ports.ProjectRepository, analytics ports.AnalyticsService) ports.ShiftService {
+	return &ShiftService{
+		repo:        repo,
+		siteRepo:    siteRepo,
+		projectRepo: projectRepo,
+		analytics:   analytics,
+	}
+}
+
+func (s *ShiftService) GetShift(ctx context.Context, userID, id string) (*domain.Shift, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+func (s *ShiftService) ListShifts(ctx context.Context, userID, siteID, projectID string) ([]domain.Shift, error) {
+	return s.repo.List(ctx, userID, siteID, projectID)
+}
+
+// CreateShift defines a shift for a project, when ProjectID is set, or else for a site.
+// The shift belongs to the organisation owning that site or project.
+func (s *ShiftService) CreateShift(ctx context.Context, userID string, shift *domain.Shift) error {
+	if err := ports.Authorize(ctx, domain.PermSitesWrite); err != nil {
+		return err
+	}
+	if err := validateShift(shift); err != nil {
+		return err
+	}
+
+	switch {
+	case shift.ProjectID != "":
+		project, err := s.projectRepo.Get(ctx, userID, shift.ProjectID)
+		if err != nil {
+			return err
+		}
+		if shift.SiteID != "" && shift.SiteID != project.SiteID {
+			return apperrors.NewValidationError("project_id does not belong to site_id")
+		}
+		shift.SiteID = project.SiteID
+		shift.UserID = project.UserID
+	case shift.SiteID != "":
+		site, err := s.siteRepo.Get(ctx, userID, shift.SiteID)
+		if err != nil {
+			return err
+		}
+		shift.UserID = site.UserID
+	default:
+		return apperrors.NewValidationError("site_id or project_id is required")
+	}
+
+	shift.ID = uuid.NewString()
+	if err := s.repo.Create(ctx, shift); err != nil {
+		return err
+	}
+	s.analytics.LogActivity(ctx, shift.UserID, "Shift Created", "shift", shift.ID, fmt.Sprintf("Shift %s (%s-%s) defined", shift.Name, shift.StartTime, shift.EndTime))
+	return nil
+}
+
+// UpdateShift changes a shift's times, grace windows, name or status. The site or project it is
+// defined for cannot be changed. Attendance already derived keeps its dates until re-derived.
+func (s *ShiftService) UpdateShift(ctx context.Context, userID, id string, shift *domain.Shift) error {
+	if err := ports.Authorize(ctx, domain.PermSitesWrite); err != nil {
+		return err
+	}
+	existing, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := validateShift(shift); err != nil {
+		return err
+	}
+
+	existing.Name = shift.Name
+	existing.StartTime = shift.StartTime
+	existing.EndTime = shift.EndTime
+	existing.GraceBeforeMinutes = shift.GraceBeforeMinutes
+	existing.GraceAfterMinutes = shift.GraceAfterMinutes
+	existing.Status = shift.Status
+	if err := s.repo.Update(ctx, existing); err != nil {
+		return err
+	}
+	*shift = *existing
+	s.analytics.LogActivity(ctx, existing.UserID, "Shift Updated", "shift", id, fmt.Sprintf("Shift %s set to %s-%s", existing.Name, existing.StartTime, existing.EndTime))
+	return nil
+}
+
+func (s *ShiftService) DeleteShift(ctx context.Context, userID, id string) error {
+	if err := ports.Authorize(ctx, domain.PermSitesWrite); err != nil {
+		return err
+	}
+	existing, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, existing.UserID, id); err != nil {
+		return err
+	}
+	s.analytics.LogActivity(ctx, existing.UserID, "Shift Deleted", "shift", id, fmt.Sprintf("Shift %s removed", existing.Name))
+	return nil
+}
+
+// validateShift checks and normalises the caller-supplied fields of a shift.
+func validateShift(shift *domain.Shift) error {
+	shift.Name = strings.TrimSpace(shift.Name)
+	if shift.Name == "" || len(shift.Name) > 100 {
+		return apperrors.NewValidationError("name is required (at most 100 characters)")
+	}
+	start, err := domain.ParseShiftClock(shift.StartTime)
+	if err != nil {
+		return apperrors.NewValidationError("start_time must be HH:MM")
+	}
+	end, err := domain.ParseShiftClock(shift.EndTime)
+	if err != nil {
+		return apperrors.NewValidationError("end_time must be HH:MM")
+	}
+	if start == end {
+		return apperrors.NewValidationError("end_time must differ from start_time")
+	}
+	shift.StartTime = formatShiftClock(start)
+	shift.EndTime = formatShiftClock(end)
+	if shift.GraceBeforeMinutes < 0 || shift.GraceBeforeMinutes > maxShiftGraceMinutes ||
+		shift.GraceAfterMinutes < 0 || shift.GraceAfterMinutes > maxShiftGraceMinutes {
+		return apperrors.NewValidationError(fmt.Sprintf("grace windows must be between 0 and %d minutes", maxShiftGraceMinutes))
+	}
+	switch shift.Status {
+	case "":
+		shift.Status = domain.StatusActive
+	case domain.StatusActive, domain.StatusInactive:
+	default:
+		return apperrors.NewValidationError("status must be active or inactive")
+	}
+	return nil
+}
+
+func formatShiftClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type recordingShiftRepo struct {
+	ports.ShiftRepository
+	created []domain.Shift
+}
+
+func (r *recordingShiftRepo) Create(ctx context.Context, s *domain.Shift) error {
+	r.created = append(r.created, *s)
+	return nil
+}
+
+type stubSiteRepo struct {
+	ports.SiteRepository
+	sites map[string]*domain.Site
+}
+
+func (r *stubSiteRepo) Get(ctx context.Context, userID, id string) (*domain.Site, error) {
+	if site, ok := r.sites[id]; ok {
+		return site, nil
+	}
+	return nil, apperrors.NewNotFound("site", id)
+}
+
+type stubProjectRepo struct {
+	ports.ProjectRepository
+	projects map[string]*domain.Project
+}
+
+func (r *stubProjectRepo) Get(ctx context.Context, userID, id string) (*domain.Project, error) {
+	if project, ok := r.projects[id]; ok {
+		return project, nil
+	}
+	return nil, apperrors.NewNotFound("project", id)
+}
+
+func newTestShiftService() (ports.ShiftService, *recordingShiftRepo, *MockAnalyticsService) {
+	repo := &recordingShiftRepo{}
+	sites := &stubSiteRepo{sites: map[string]*domain.Site{"s1": {ID: "s1", UserID: "user1"}}}
+	projects := &stubProjectRepo{projects: map[string]*domain.Project{"p1": {ID: "p1", SiteID: "s1", UserID: "user1"}}}
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return NewShiftService(repo, sites, projects, analytics), repo, analytics
+}
+
+func TestShiftService_CreateShift_ForProjectTakesItsSite(t *testing.T) {
+	svc, repo, _ := newTestShiftService()
+	ctx := roleContext("user1", domain.RoleManager)
+
+	shift := &domain.Shift{ProjectID: "p1", Name: " Night ", StartTime: "20:00:00", EndTime: "6:00", GraceBeforeMinutes: 60, GraceAfterMinutes: 120}
+	err := svc.CreateShift(ctx, "user1", shift)
+
+	assert.NoError(t, err)
+	if assert.Len(t, repo.created, 1) {
+		created := repo.created[0]
+		assert.NotEmpty(t, created.ID)
+		assert.Equal(t, "s1", created.SiteID)
+		assert.Equal(t, "user1", created.UserID)
+		assert.Equal(t, "Night", created.Name)
+		assert.Equal(t, "20:00", created.StartTime)
+		assert.Equal(t, "06:00", created.EndTime)
+		assert.Equal(t, domain.StatusActive, created.Status)
+	}
+}
+
+func TestShiftService_CreateShift_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		shift domain.Shift
+	}{
+		{"no site or project", domain.Shift{Name: "Day", StartTime: "08:00", EndTime: "17:00"}},
+		{"bad start time", domain.Shift{SiteID: "s1", Name: "Day", StartTime: "8am", EndTime: "17:00"}},
+		{"zero length", domain.Shift{SiteID: "s1", Name: "Day", StartTime: "08:00", EndTime: "08:00"}},
+		{"grace too long", domain.Shift{SiteID: "s1", Name: "Day", StartTime: "08:00", EndTime: "17:00", GraceAfterMinutes: 600}},
+		{"project on another site", domain.Shift{SiteID: "s2", ProjectID: "p1", Name: "Day", StartTime: "08:00", EndTime: "17:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestShiftService()
+			shift := tt.shift
+			err := svc.CreateShift(roleContext("user1", domain.RoleManager), "user1", &shift)
+			assert.ErrorIs(t, err, apperrors.ErrValidation)
+			assert.Empty(t, repo.created)
+		})
+	}
+}
+
+func TestShiftService_CreateShift_RequiresSitesWrite(t *testing.T) {
+	svc, repo, _ := newTestShiftService()
+
+	err := svc.CreateShift(roleContext("user1", domain.RoleViewer), "user1", &domain.Shift{SiteID: "s1", Name: "Day", StartTime: "08:00", EndTime: "17:00"})
+
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	assert.Empty(t, repo.created)
+}
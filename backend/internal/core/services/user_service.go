@@ -4,23 +4,28 @@ import (
 	"context"
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
 )
 
 type UserService struct {
-	repo            ports.UserRepository
-	analytics       ports.AnalyticsService
-	defaultPassword string
+	repo      ports.UserRepository
+	members   ports.MemberRepository
+	analytics ports.AnalyticsService
+	passwords PasswordConfig
 }
 
-func NewUserService(repo ports.UserRepository, analytics ports.AnalyticsService, defaultPassword string) ports.UserService {
+func NewUserService(repo ports.UserRepository, members ports.MemberRepository, analytics ports.AnalyticsService, passwords PasswordConfig) ports.UserService {
 	return &UserService{
-		repo:            repo,
-		analytics:       analytics,
-		defaultPassword: defaultPassword,
+		repo:      repo,
+		members:   members,
+		analytics: analytics,
+		passwords: passwords,
 	}
 }
 
@@ -33,10 +38,18 @@ func (s *UserService) ListUsers(ctx context.Context) ([]domain.User, error) {
 }
 
 func (s *UserService) CreateUser(ctx context.Context, user *domain.User, password string) error {
+	if user.UserType == domain.UserTypeVendor {
+		if err := requireGlobalScope(ctx, "create vendor organisations"); err != nil {
+			return err
+		}
+	}
+
 	// If no password provided, use the global default password to avoid hardcoding specific user credentials
 	finalPassword := password
 	if finalPassword == "" {
-		finalPassword = s.defaultPassword
+		finalPassword = s.passwords.DefaultPassword
+	} else if err := s.passwords.validatePassword(user.Username, password); err != nil {
+		return err
 	}
 
 	if finalPassword != "" {
@@ -58,11 +71,39 @@ func (s *UserService) CreateUser(ctx context.Context, user *domain.User, passwor
         user.BridgeStatus = "active"
     }
 
-	err := s.repo.Create(ctx, user)
-	if err == nil {
-		s.analytics.LogActivity(ctx, user.ID, "User Registered", "user", user.ID, fmt.Sprintf("New user account created for %s", user.Name))
+	// The organisation's first login shares its ID and manages the other members. It is stored with the
+	// organisation, so a failure leaves neither behind.
+	var primary *domain.Member
+	if user.Username != "" {
+		primary = &domain.Member{
+			Username:     user.Username,
+			Name:         user.Name,
+			Email:        user.ContactEmail,
+			Role:         domain.RoleManager,
+			Status:       domain.StatusActive,
+			PasswordHash: user.PasswordHash,
+			// The default or vendor-chosen password is replaced at first login
+			MustChangePassword: true,
+		}
 	}
-	return err
+	if err := s.repo.Create(ctx, user, primary); err != nil {
+		return err
+	}
+
+	// A vendor limited to some organisations keeps the ones it creates in its scope
+	if scope, ok := ports.GetScope(ctx); ok && scope.Kind == domain.ScopeTenants {
+		vendorID := ports.GetUserID(ctx)
+		tenants, err := s.repo.ListVendorTenants(ctx, vendorID)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.SetVendorTenants(ctx, vendorID, append(tenants, user.ID)); err != nil {
+			return fmt.Errorf("failed to add organisation to vendor scope: %w", err)
+		}
+	}
+
+	s.analytics.LogActivity(ctx, user.ID, "User Registered", "user", user.ID, fmt.Sprintf("New user account created for %s", user.Name))
+	return nil
 }
 
 func (s *UserService) UpdateUser(ctx context.Context, id string, payload map[string]interface{}) error {
@@ -77,7 +118,12 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, payload map[str
 	// Manual patching from map - matching frontend keys and JSON tags
 	if name, ok := payload["user_name"].(string); ok { user.Name = name }
 	if username, ok := payload["username"].(string); ok { user.Username = username }
-	if uType, ok := payload["user_type"].(string); ok { user.UserType = uType }
+	if uType, ok := payload["user_type"].(string); ok && uType != user.UserType {
+		if err := requireGlobalScope(ctx, "change organisation types"); err != nil {
+			return err
+		}
+		user.UserType = uType
+	}
 	if email, ok := payload["email"].(string); ok { user.ContactEmail = email }
 	if phone, ok := payload["phone"].(string); ok { user.ContactPhone = phone }
 	if status, ok := payload["status"].(string); ok { user.Status = status }
@@ -91,19 +137,47 @@ func (s *UserService) UpdateUser(ctx context.Context, id string, payload map[str
 	if bridgeAuth, ok := payload["bridge_auth_token"].(string); ok { user.BridgeAuthToken = &bridgeAuth }
 	if bridgeStat, ok := payload["bridge_status"].(string); ok { user.BridgeStatus = bridgeStat }
 
+	passwordSet := false
 	if pwd, ok := payload["password"].(string); ok && pwd != "" {
+		if err := s.passwords.validatePassword(user.Username, pwd); err != nil {
+			return err
+		}
 		hash, err := bcrypt.GenerateFromPassword([]byte(pwd), bcrypt.DefaultCost)
 		if err != nil {
 			return fmt.Errorf("failed to hash password: %w", err)
 		}
 		user.PasswordHash = string(hash)
+		passwordSet = true
 	}
 
-	err = s.repo.Update(ctx, user)
-	if err == nil {
-		s.analytics.LogActivity(ctx, id, "User Updated", "user", id, "User profile and/or bridge configuration modified")
+	if err := s.repo.Update(ctx, user); err != nil {
+		return err
 	}
-	return err
+	if err := s.syncPrimaryMember(ctx, user, passwordSet); err != nil {
+		return err
+	}
+
+	s.analytics.LogActivity(ctx, id, "User Updated", "user", id, "User profile and/or bridge configuration modified")
+	return nil
+}
+
+// syncPrimaryMember keeps the organisation's primary login in step with credentials edited on the organisation.
+// The login's password is only replaced when the vendor set a new one, which must be replaced at the next
+// login; users.password_hash is not kept current when members change their own password.
+func (s *UserService) syncPrimaryMember(ctx context.Context, user *domain.User, passwordSet bool) error {
+	primary, err := s.members.Get(ctx, user.ID)
+	if err != nil || primary == nil {
+		return err
+	}
+	primary.Username = user.Username
+	if passwordSet {
+		primary.PasswordHash = user.PasswordHash
+		now := time.Now()
+		primary.PasswordChangedAt = &now
+		primary.MustChangePassword = true
+	}
+	primary.Email = user.ContactEmail
+	return s.members.Update(ctx, primary)
 }
 
 func (s *UserService) DeleteUser(ctx context.Context, id string) error {
@@ -114,6 +188,73 @@ func (s *UserService) DeleteUser(ctx context.Context, id string) error {
 	return err
 }
 
+// GetVendorTenants returns the organisations a vendor supports. An empty list means it sees every organisation.
+func (s *UserService) GetVendorTenants(ctx context.Context, vendorID string) ([]string, error) {
+	if _, err := s.getVendor(ctx, vendorID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVendorTenants(ctx, vendorID)
+}
+
+// SetVendorTenants limits a vendor to the given tenant organisations, or restores its global scope when
+// tenantIDs is empty. Only a global vendor may change scopes, and never its own.
+func (s *UserService) SetVendorTenants(ctx context.Context, vendorID string, tenantIDs []string) error {
+	if err := requireGlobalScope(ctx, "change vendor scopes"); err != nil {
+		return err
+	}
+	if vendorID == ports.GetUserID(ctx) {
+		return apperrors.NewValidationError("you cannot change your own organisation's scope")
+	}
+	if _, err := s.getVendor(ctx, vendorID); err != nil {
+		return err
+	}
+	for _, id := range tenantIDs {
+		tenant, err := s.repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if tenant == nil {
+			return apperrors.NewNotFound("user", id)
+		}
+		if tenant.UserType == domain.UserTypeVendor {
+			return apperrors.NewValidationError(fmt.Sprintf("%s is a vendor organisation, not a tenant", id))
+		}
+	}
+
+	if err := s.repo.SetVendorTenants(ctx, vendorID, tenantIDs); err != nil {
+		return err
+	}
+
+	details := "Vendor scope set to all organisations"
+	if len(tenantIDs) > 0 {
+		details = fmt.Sprintf("Vendor scope limited to %d organisations: %s", len(tenantIDs), strings.Join(tenantIDs, ", "))
+	}
+	s.analytics.LogActivity(ctx, vendorID, "Vendor Scope Changed", "user", vendorID, details)
+	return nil
+}
+
+func (s *UserService) getVendor(ctx context.Context, vendorID string) (*domain.User, error) {
+	vendor, err := s.repo.Get(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+	if vendor == nil {
+		return nil, apperrors.NewNotFound("user", vendorID)
+	}
+	if vendor.UserType != domain.UserTypeVendor {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("%s is not a vendor organisation", vendorID))
+	}
+	return vendor, nil
+}
+
+// requireGlobalScope denies callers that are not allowed to see every organisation.
+func requireGlobalScope(ctx context.Context, action string) error {
+	if scope, _ := ports.GetScope(ctx); !scope.IsGlobal() {
+		return apperrors.NewPermissionDenied("a global vendor scope is required to " + action)
+	}
+	return nil
+}
+
 func generateSecureToken(length int) string {
 	b := make([]byte, length)
 	if _, err := rand.Read(b); err != nil {
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"cpd-nexus/internal/core/domain"
+	"cpd-nexus/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// userTestRepo keeps organisations in a map; the other UserRepository methods are not used
+type userTestRepo struct {
+	ports.UserRepository
+	users map[string]*domain.User
+}
+
+func (r *userTestRepo) Get(ctx context.Context, id string) (*domain.User, error) {
+	u := *r.users[id]
+	return &u, nil
+}
+
+func (r *userTestRepo) Update(ctx context.Context, u *domain.User) error {
+	r.users[u.ID] = u
+	return nil
+}
+
+func TestUserService_UpdateUser_KeepsMemberPassword(t *testing.T) {
+	repo := &userTestRepo{users: map[string]*domain.User{
+		"tenant-1": {ID: "tenant-1", Name: "Acme", Username: "acme", PasswordHash: "stale-hash", Status: domain.StatusActive},
+	}}
+	members := new(MockMemberRepository)
+	primary := &domain.Member{ID: "tenant-1", UserID: "tenant-1", Username: "acme", PasswordHash: "changed-hash"}
+	members.On("Get", mock.Anything, "tenant-1").Return(primary, nil)
+	members.On("Update", mock.Anything, mock.Anything).Return(nil)
+	analytics := new(MockAnalyticsService)
+	analytics.On("LogActivity", mock.Anything, "tenant-1", "User Updated", "user", "tenant-1", mock.Anything).Return(nil)
+	svc := NewUserService(repo, members, analytics, PasswordConfig{})
+
+	// Editing the organisation leaves the password the member chose in place
+	require.NoError(t, svc.UpdateUser(context.Background(), "tenant-1", map[string]interface{}{"user_name": "Acme Builders"}))
+	assert.Equal(t, "changed-hash", primary.PasswordHash)
+	assert.False(t, primary.MustChangePassword)
+
+	// A password set by the vendor replaces it and must be rotated
+	require.NoError(t, svc.UpdateUser(context.Background(), "tenant-1", map[string]interface{}{"password": "Vendor-Chosen-1"}))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(primary.PasswordHash), []byte("Vendor-Chosen-1")))
+	assert.True(t, primary.MustChangePassword)
+	assert.NotNil(t, primary.PasswordChangedAt)
+}
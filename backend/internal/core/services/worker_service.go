@@ -24,8 +24,6 @@ func (s *WorkerService) GetWorker(ctx context.Context, userID, id string) (*doma
 	if userID == "" && !ports.IsVendor(ctx) {
 		return nil, apperrors.NewPermissionDenied("user_id scope required")
 	}
-	// Inject isVendor into context so repo can check it without importing middleware
-	ctx = context.WithValue(ctx, ports.IsVendorKey, ports.IsVendor(ctx))
 	worker, err := s.repo.Get(ctx, userID, id)
 	if err != nil {
 		return nil, err
@@ -34,8 +32,6 @@ func (s *WorkerService) GetWorker(ctx context.Context, userID, id string) (*doma
 }
 
 func (s *WorkerService) ListWorkers(ctx context.Context, userID, siteID string) ([]domain.Worker, error) {
-	// Inject isVendor into context so repo can check it without importing middleware
-	ctx = context.WithValue(ctx, ports.IsVendorKey, ports.IsVendor(ctx))
 	return s.repo.List(ctx, userID, siteID)
 }
 
@@ -162,7 +158,11 @@ func (s *WorkerService) UpdateWorker(ctx context.Context, userID, id string, req
 	if req.PersonIDNo != nil {
 		existing.PersonIDNo = *req.PersonIDNo
 	}
-	if req.UserID != nil {
+	if req.UserID != nil && *req.UserID != existing.UserID {
+		// A worker may only move to an organisation the caller also manages
+		if err := ports.AuthorizeTenant(ctx, *req.UserID); err != nil {
+			return err
+		}
 		existing.UserID = *req.UserID
 	}
 	if req.PersonIDAndWorkPassType != nil {
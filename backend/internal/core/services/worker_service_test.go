@@ -4,6 +4,7 @@ import (
 	"context"
 	"cpd-nexus/internal/core/domain"
 	"cpd-nexus/internal/core/ports"
+	"cpd-nexus/internal/pkg/apperrors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -162,3 +163,17 @@ func TestWorkerService_UpdateWorker_SyncTrigger(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 	mockAnalytics.AssertExpectations(t)
 }
+
+func TestWorkerService_UpdateWorker_RejectsOwnerOutsideScope(t *testing.T) {
+	mockRepo := new(MockWorkerRepository)
+	svc := NewWorkerService(mockRepo, new(MockAnalyticsService))
+	ctx := context.WithValue(context.Background(), ports.UserIDKey, "user1")
+	ctx = context.WithValue(ctx, ports.ScopeKey, domain.TenantScope("user1"))
+
+	mockRepo.On("Get", ctx, "user1", "w1").Return(&domain.Worker{ID: "w1", UserID: "user1", Name: "John"}, nil)
+
+	newOwner := "user2"
+	err := svc.UpdateWorker(ctx, "user1", "w1", &domain.UpdateWorkerRequest{UserID: &newOwner})
+	assert.ErrorIs(t, err, apperrors.ErrPermissionDenied)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
@@ -3,12 +3,15 @@ package apperrors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 type AppError struct {
 	Code    int
 	Message string
 	Err     error
+	// RetryAfter tells rate-limited callers when to try again
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
@@ -28,6 +31,8 @@ var (
 	ErrValidation       = errors.New("validation error")
 	ErrInternal         = errors.New("internal server error")
 	ErrConflict         = errors.New("resource conflict")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrTooManyRequests  = errors.New("too many requests")
 )
 
 func NewNotFound(resource string, id string) error {
@@ -53,3 +58,28 @@ func NewValidationError(msg string) error {
 		Err:     ErrValidation,
 	}
 }
+
+func NewConflict(msg string) error {
+	return &AppError{
+		Code:    409,
+		Message: msg,
+		Err:     ErrConflict,
+	}
+}
+
+func NewUnauthorized(msg string) error {
+	return &AppError{
+		Code:    401,
+		Message: msg,
+		Err:     ErrUnauthorized,
+	}
+}
+
+func NewTooManyRequests(msg string, retryAfter time.Duration) error {
+	return &AppError{
+		Code:       429,
+		Message:    msg,
+		Err:        ErrTooManyRequests,
+		RetryAfter: retryAfter,
+	}
+}
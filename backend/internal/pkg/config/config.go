@@ -2,9 +2,14 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strings"
 	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/timeutil"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,12 +24,55 @@ type Config struct {
 	IngressURL string
 	PitstopURL string
 
+	// BusinessTimezone is the zone attendance dates, submission months and schedules are reckoned in
+	BusinessTimezone string
+	BusinessLocation *time.Location
+
 	JWTSecret      string
 	AllowedOrigins string
 
 	DefaultUserPassword string
 
+	PasswordMinLength       int
+	PasswordRequireUpper    bool
+	PasswordRequireLower    bool
+	PasswordRequireDigit    bool
+	PasswordRequireSymbol   bool
+	PasswordResetTTLMinutes int
+
+	LoginMaxAccountFailures   int
+	LoginMaxIPFailures        int
+	LoginFailureWindowMinutes int
+	LoginDelayBaseSeconds     int
+	LoginDelayMaxSeconds      int
+	LoginLockoutMinutes       int
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header names the client
+	TrustedProxies []*net.IPNet
+
+	MFARequiredUserTypes []string
+	MFAIssuer            string
+	MFASecretKey         string
+
+	OIDCRedirectURL  string
+	OIDCPostLoginURL string
+
+	AccessTokenTTLMinutes int
+	RefreshTokenTTLHours  int
+
+	ImpersonationTTLMinutes    int
+	ImpersonationMaxTTLMinutes int
+
 	WorkerIntervalMinutes int
+
+	BridgePingIntervalSeconds int
+	BridgePongTimeoutSeconds  int
+
+	DeviceOfflineAfterSeconds int
+
+	SubmissionMaxAttempts          int
+	SubmissionRetryBaseMinutes     int
+	SubmissionRetryIntervalMinutes int
 }
 
 func LoadConfig() *Config {
@@ -44,9 +92,47 @@ func LoadConfig() *Config {
 		JWTSecret:      getEnvRequired("JWT_SECRET"),
 		AllowedOrigins: getEnv("ALLOWED_ORIGINS", ""),
 
+		BusinessTimezone: getEnv("BUSINESS_TIMEZONE", timeutil.DefaultBusinessTimezone),
+
 		DefaultUserPassword: getEnv("DEFAULT_USER_PASSWORD", "Nexus@2026!ChangeMe"),
 
+		PasswordMinLength:       getEnvInt("PASSWORD_MIN_LENGTH", 10),
+		PasswordRequireUpper:    getEnvBool("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:    getEnvBool("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:    getEnvBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol:   getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordResetTTLMinutes: getEnvInt("PASSWORD_RESET_TTL_MINUTES", 60),
+
+		LoginMaxAccountFailures:   getEnvInt("LOGIN_MAX_ACCOUNT_FAILURES", 5),
+		LoginMaxIPFailures:        getEnvInt("LOGIN_MAX_IP_FAILURES", 20),
+		LoginFailureWindowMinutes: getEnvInt("LOGIN_FAILURE_WINDOW_MINUTES", 15),
+		LoginDelayBaseSeconds:     getEnvInt("LOGIN_DELAY_BASE_SECONDS", 1),
+		LoginDelayMaxSeconds:      getEnvInt("LOGIN_DELAY_MAX_SECONDS", 30),
+		LoginLockoutMinutes:       getEnvInt("LOGIN_LOCKOUT_MINUTES", 15),
+
+		MFARequiredUserTypes: getEnvList("MFA_REQUIRED_USER_TYPES", "vendor"),
+		MFAIssuer:            getEnv("MFA_ISSUER", "CPD-Nexus"),
+		MFASecretKey:         getEnv("MFA_SECRET_KEY", ""),
+
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:3000/api/auth/oidc/callback"),
+		OIDCPostLoginURL: getEnv("OIDC_POST_LOGIN_URL", "/"),
+
+		AccessTokenTTLMinutes: getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 120),
+		RefreshTokenTTLHours:  getEnvInt("REFRESH_TOKEN_TTL_HOURS", 168),
+
+		ImpersonationTTLMinutes:    getEnvInt("IMPERSONATION_TTL_MINUTES", 30),
+		ImpersonationMaxTTLMinutes: getEnvInt("IMPERSONATION_MAX_TTL_MINUTES", 240),
+
 		WorkerIntervalMinutes: getEnvInt("WORKER_INTERVAL_MINUTES", 5),
+
+		BridgePingIntervalSeconds: getEnvInt("BRIDGE_PING_INTERVAL_SECONDS", 30),
+		BridgePongTimeoutSeconds:  getEnvInt("BRIDGE_PONG_TIMEOUT_SECONDS", 75),
+
+		DeviceOfflineAfterSeconds: getEnvInt("DEVICE_OFFLINE_AFTER_SECONDS", 300),
+
+		SubmissionMaxAttempts:          getEnvInt("SUBMISSION_MAX_ATTEMPTS", 6),
+		SubmissionRetryBaseMinutes:     getEnvInt("SUBMISSION_RETRY_BASE_MINUTES", 5),
+		SubmissionRetryIntervalMinutes: getEnvInt("SUBMISSION_RETRY_INTERVAL_MINUTES", 5),
 	}
 
 	// Enforce strong JWT secret (#11)
@@ -54,8 +140,26 @@ func LoadConfig() *Config {
 		logger.Fatalf("[CONFIG] FATAL: JWT_SECRET must be at least 32 characters for production security.")
 	}
 
-	cfg.DBDSN = fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true&multiStatements=true",
-		cfg.DBUser, cfg.DBPass, cfg.DBHost, cfg.DBName)
+	loc, err := timeutil.LoadBusinessLocation(cfg.BusinessTimezone)
+	if err != nil {
+		logger.Fatalf("[CONFIG] FATAL: BUSINESS_TIMEZONE: %v", err)
+	}
+	cfg.BusinessLocation = loc
+
+	for _, entry := range getEnvList("TRUSTED_PROXIES", "") {
+		network, err := parseProxy(entry)
+		if err != nil {
+			logger.Fatalf("[CONFIG] FATAL: TRUSTED_PROXIES: %v", err)
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, network)
+	}
+
+	// DATETIME values are read and written as business wall-clock time, and the session time_zone
+	// makes NOW(), DATE() and TIMESTAMP columns agree with it. The zone has a fixed offset (see
+	// timeutil.LoadBusinessLocation), so the offset taken now holds for the life of the process.
+	cfg.DBDSN = fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true&multiStatements=true&loc=%s&time_zone=%s",
+		cfg.DBUser, cfg.DBPass, cfg.DBHost, cfg.DBName,
+		url.QueryEscape(loc.String()), url.QueryEscape("'"+timeutil.MySQLOffset(loc)+"'"))
 
 	return cfg
 }
@@ -88,3 +192,44 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return val
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	str := getEnv(key, "")
+	if str == "" {
+		return fallback
+	}
+	val, err := strconv.ParseBool(str)
+	if err != nil {
+		logger.Infof("Warning: Invalid value for %s: %v. Using fallback: %t", key, err, fallback)
+		return fallback
+	}
+	return val
+}
+
+// getEnvList splits a comma-separated env var; an empty value yields an empty list.
+func getEnvList(key, fallback string) []string {
+	var list []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseProxy reads a TRUSTED_PROXIES entry: a CIDR range or a single IP address.
+func parseProxy(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, network, err := net.ParseCIDR(entry)
+		return network, err
+	}
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", entry)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
@@ -0,0 +1,422 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cpd-nexus/internal/pkg/logger"
+)
+
+const (
+	lockName    = "cpd_nexus_schema_migrations"
+	lockTimeout = 10 // seconds
+
+	createTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version bigint NOT NULL,
+    name varchar(255) NOT NULL,
+    checksum char(64) NOT NULL,
+    dirty tinyint(1) NOT NULL DEFAULT 0,
+    execution_ms int NOT NULL DEFAULT 0,
+    applied_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (version)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4`
+)
+
+var (
+	// ErrDirty is returned when a previous migration failed part-way and needs manual attention
+	ErrDirty = errors.New("database is dirty")
+	// ErrDestructive is returned when a migration would drop data and Force is not set
+	ErrDestructive = errors.New("destructive migration requires -force")
+	// ErrChecksumMismatch is returned when an applied migration file was edited afterwards
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+)
+
+// Migration states reported by Status
+const (
+	StatePending  = "pending"
+	StateApplied  = "applied"
+	StateModified = "modified" // applied, but the up script changed since
+	StateDirty    = "dirty"    // failed part-way
+	StateMissing  = "missing"  // recorded as applied, but the file no longer exists
+)
+
+// Record is a row of schema_migrations
+type Record struct {
+	Version     int64
+	Name        string
+	Checksum    string
+	Dirty       bool
+	ExecutionMs int
+	AppliedAt   time.Time
+}
+
+// Status describes one migration version, from the files, the database or both
+type Status struct {
+	Version   int64
+	Name      string
+	State     string
+	AppliedAt *time.Time
+}
+
+// Options controls the safety checks
+type Options struct {
+	// Force allows destructive migrations, ignores checksum drift and retries dirty migrations
+	Force bool
+	// Logf receives progress messages; defaults to logger.Infof
+	Logf func(format string, v ...interface{})
+}
+
+// Migrator applies migrations to a MySQL database. The DSN must enable multiStatements.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+	opts       Options
+}
+
+// New creates a Migrator for the given migrations (as returned by Load)
+func New(db *sql.DB, migrations []Migration, opts Options) *Migrator {
+	if opts.Logf == nil {
+		opts.Logf = logger.Infof
+	}
+	return &Migrator{db: db, migrations: migrations, opts: opts}
+}
+
+// Status lists every known version in ascending order
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var out []Status
+	err := m.withSession(ctx, func(conn *sql.Conn, records map[int64]Record) error {
+		out = buildStatus(m.migrations, records)
+		return nil
+	})
+	return out, err
+}
+
+// Up applies pending migrations in version order. limit <= 0 applies all of them.
+// Returns the number of migrations applied.
+func (m *Migrator) Up(ctx context.Context, limit int) (int, error) {
+	applied := 0
+	err := m.withSession(ctx, func(conn *sql.Conn, records map[int64]Record) error {
+		plan, err := planUp(m.migrations, records, limit, m.opts.Force)
+		if err != nil {
+			return err
+		}
+		if len(plan) == 0 {
+			m.opts.Logf("[Migrate] No pending migrations")
+			return nil
+		}
+		for _, mig := range plan {
+			_, retry := records[mig.Version]
+			if err := m.apply(ctx, conn, mig, retry); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the most recently applied migrations, newest first. steps <= 0 reverts one.
+// Returns the number of migrations reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withSession(ctx, func(conn *sql.Conn, records map[int64]Record) error {
+		plan, err := planDown(m.migrations, records, steps, m.opts.Force)
+		if err != nil {
+			return err
+		}
+		if len(plan) == 0 {
+			m.opts.Logf("[Migrate] Nothing to revert")
+			return nil
+		}
+		for _, mig := range plan {
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Redo reverts the latest applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withSession(ctx, func(conn *sql.Conn, records map[int64]Record) error {
+		plan, err := planDown(m.migrations, records, 1, m.opts.Force)
+		if err != nil {
+			return err
+		}
+		if len(plan) == 0 {
+			return fmt.Errorf("no applied migration to redo")
+		}
+		mig := plan[0]
+		if found := Destructive(mig.Up); len(found) > 0 && !m.opts.Force {
+			return fmt.Errorf("%w: %s up contains %s", ErrDestructive, mig, strings.Join(found, ", "))
+		}
+		if err := m.revert(ctx, conn, mig); err != nil {
+			return err
+		}
+		return m.apply(ctx, conn, mig, false)
+	})
+}
+
+// Baseline records every migration up to and including version as applied without running it.
+// Use it once on databases created before schema_migrations existed.
+func (m *Migrator) Baseline(ctx context.Context, version int64) (int, error) {
+	marked := 0
+	err := m.withSession(ctx, func(conn *sql.Conn, records map[int64]Record) error {
+		for _, mig := range m.migrations {
+			if mig.Version > version {
+				break
+			}
+			if _, ok := records[mig.Version]; ok {
+				continue
+			}
+			if _, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, checksum, dirty, execution_ms) VALUES (?, ?, ?, 0, 0)",
+				mig.Version, mig.Name, mig.Checksum); err != nil {
+				return fmt.Errorf("failed to baseline %s: %w", mig, err)
+			}
+			m.opts.Logf("[Migrate] Baselined %s", mig)
+			marked++
+		}
+		return nil
+	})
+	return marked, err
+}
+
+// withSession pins a connection, takes the advisory lock so concurrent runners cannot interleave,
+// and loads the applied records
+func (m *Migrator) withSession(ctx context.Context, fn func(conn *sql.Conn, records map[int64]Record) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, lockTimeout).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	if locked.Int64 != 1 {
+		return fmt.Errorf("another migration is running (lock %s is held)", lockName)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+	if _, err := conn.ExecContext(ctx, createTableSQL); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	records, err := loadRecords(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, records)
+}
+
+func loadRecords(ctx context.Context, conn *sql.Conn) (map[int64]Record, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, dirty, execution_ms, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	records := make(map[int64]Record)
+	for rows.Next() {
+		var r Record
+		if err := rows.Scan(&r.Version, &r.Name, &r.Checksum, &r.Dirty, &r.ExecutionMs, &r.AppliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		records[r.Version] = r
+	}
+	return records, rows.Err()
+}
+
+// apply runs an up script. The version is recorded as dirty first and cleared in the same
+// transaction as the script, so a failure that MySQL could not roll back (DDL commits
+// implicitly) leaves the version dirty for an operator to inspect.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, retry bool) error {
+	m.opts.Logf("[Migrate] Applying %s", mig)
+
+	if retry {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version); err != nil {
+			return fmt.Errorf("failed to clear dirty record for %s: %w", mig, err)
+		}
+	}
+	if _, err := conn.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, checksum, dirty) VALUES (?, ?, ?, 1)",
+		mig.Version, mig.Name, mig.Checksum); err != nil {
+		return fmt.Errorf("failed to record %s: %w", mig, err)
+	}
+
+	start := time.Now()
+	err := m.runInTx(ctx, conn, mig.Up, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"UPDATE schema_migrations SET dirty = 0, execution_ms = ?, applied_at = CURRENT_TIMESTAMP WHERE version = ?",
+			time.Since(start).Milliseconds(), mig.Version)
+		return err
+	})
+	if err != nil {
+		if !hasDDL(mig.Up) {
+			// Nothing was committed, so the version can simply be retried
+			conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+			return fmt.Errorf("migration %s failed and was rolled back: %w", mig, err)
+		}
+		return fmt.Errorf("migration %s failed and is marked dirty; check the schema, then rerun with -force: %w", mig, err)
+	}
+
+	m.opts.Logf("[Migrate] Applied %s (%s)", mig, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// revert runs a down script and removes the version record in the same transaction
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	m.opts.Logf("[Migrate] Reverting %s", mig)
+
+	if _, err := conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = 1 WHERE version = ?", mig.Version); err != nil {
+		return fmt.Errorf("failed to mark %s dirty: %w", mig, err)
+	}
+
+	err := m.runInTx(ctx, conn, mig.Down, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+		return err
+	})
+	if err != nil {
+		if !hasDDL(mig.Down) {
+			conn.ExecContext(ctx, "UPDATE schema_migrations SET dirty = 0 WHERE version = ?", mig.Version)
+			return fmt.Errorf("reverting %s failed and was rolled back: %w", mig, err)
+		}
+		return fmt.Errorf("reverting %s failed and it is marked dirty; check the schema, then rerun with -force: %w", mig, err)
+	}
+
+	m.opts.Logf("[Migrate] Reverted %s", mig)
+	return nil
+}
+
+func (m *Migrator) runInTx(ctx context.Context, conn *sql.Conn, script string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := record(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update schema_migrations: %w", err)
+	}
+	return tx.Commit()
+}
+
+// planUp selects the migrations to apply and enforces the safety checks before anything runs
+func planUp(migrations []Migration, records map[int64]Record, limit int, force bool) ([]Migration, error) {
+	var drifted []string
+	for _, mig := range migrations {
+		r, ok := records[mig.Version]
+		if !ok {
+			continue
+		}
+		if r.Dirty && !force {
+			return nil, fmt.Errorf("%w: %s failed part-way; check the schema, then rerun with -force", ErrDirty, mig)
+		}
+		if !r.Dirty && r.Checksum != mig.Checksum {
+			drifted = append(drifted, mig.String())
+		}
+	}
+	if len(drifted) > 0 && !force {
+		return nil, fmt.Errorf("%w: %s (add a new migration instead of editing an applied one)", ErrChecksumMismatch, strings.Join(drifted, ", "))
+	}
+
+	var plan []Migration
+	for _, mig := range migrations {
+		if r, ok := records[mig.Version]; ok && !r.Dirty {
+			continue
+		}
+		if found := Destructive(mig.Up); len(found) > 0 && !force {
+			return nil, fmt.Errorf("%w: %s contains %s", ErrDestructive, mig, strings.Join(found, ", "))
+		}
+		plan = append(plan, mig)
+		if limit > 0 && len(plan) == limit {
+			break
+		}
+	}
+	return plan, nil
+}
+
+// planDown selects the latest applied migrations to revert, newest first
+func planDown(migrations []Migration, records map[int64]Record, steps int, force bool) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	byVersion := make(map[int64]Migration, len(migrations))
+	for _, mig := range migrations {
+		byVersion[mig.Version] = mig
+	}
+
+	versions := make([]int64, 0, len(records))
+	for v := range records {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+	var plan []Migration
+	for _, v := range versions {
+		if len(plan) == steps {
+			break
+		}
+		r := records[v]
+		mig, ok := byVersion[v]
+		if !ok {
+			return nil, fmt.Errorf("migration %03d_%s is applied but its file is missing", v, r.Name)
+		}
+		if r.Dirty && !force {
+			return nil, fmt.Errorf("%w: %s failed part-way; check the schema, then rerun with -force", ErrDirty, mig)
+		}
+		if mig.Down == "" {
+			return nil, fmt.Errorf("migration %s has no down script", mig)
+		}
+		if found := Destructive(mig.Down); len(found) > 0 && !force {
+			return nil, fmt.Errorf("%w: reverting %s runs %s", ErrDestructive, mig, strings.Join(found, ", "))
+		}
+		plan = append(plan, mig)
+	}
+	return plan, nil
+}
+
+func buildStatus(migrations []Migration, records map[int64]Record) []Status {
+	seen := make(map[int64]bool, len(migrations))
+	var out []Status
+	for _, mig := range migrations {
+		seen[mig.Version] = true
+		st := Status{Version: mig.Version, Name: mig.Name, State: StatePending}
+		if r, ok := records[mig.Version]; ok {
+			appliedAt := r.AppliedAt
+			st.AppliedAt = &appliedAt
+			switch {
+			case r.Dirty:
+				st.State = StateDirty
+			case r.Checksum != mig.Checksum:
+				st.State = StateModified
+			default:
+				st.State = StateApplied
+			}
+		}
+		out = append(out, st)
+	}
+	for v, r := range records {
+		if !seen[v] {
+			appliedAt := r.AppliedAt
+			out = append(out, Status{Version: v, Name: r.Name, State: StateMissing, AppliedAt: &appliedAt})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out
+}
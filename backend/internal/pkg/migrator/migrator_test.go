@@ -0,0 +1,205 @@
+package migrator
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_col.up.sql":   {Data: []byte("ALTER TABLE t ADD COLUMN c int;")},
+		"002_add_col.down.sql": {Data: []byte("ALTER TABLE t DROP COLUMN c;")},
+		"001_init.up.sql":      {Data: []byte("CREATE TABLE t (id int);\r\n")},
+		"init_data.sql":        {Data: []byte("TRUNCATE TABLE t;")},
+		"migrate.go":           {Data: []byte("package main")},
+	}
+
+	migrations, err := Load(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "init", migrations[0].Name)
+	assert.Equal(t, "CREATE TABLE t (id int);\n", migrations[0].Up)
+	assert.Empty(t, migrations[0].Down)
+	assert.Len(t, migrations[0].Checksum, 64)
+
+	assert.Equal(t, "002_add_col", migrations[1].String())
+	assert.Equal(t, "ALTER TABLE t DROP COLUMN c;", migrations[1].Down)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"unpaired legacy name", fstest.MapFS{"001_init.sql": {Data: []byte("x")}}},
+		{"duplicate version", fstest.MapFS{
+			"001_a.up.sql": {Data: []byte("x")},
+			"001_b.up.sql": {Data: []byte("y")},
+		}},
+		{"down without up", fstest.MapFS{"003_x.down.sql": {Data: []byte("x")}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestLoad_RepositoryMigrations guards the real migrate/ directory: every file must pair up and
+// no up script may be destructive. 013 is the one exception: it deletes duplicate attendance after
+// copying it to attendance_dedupe_backup, so databases past it never need -force to upgrade.
+func TestLoad_RepositoryMigrations(t *testing.T) {
+	migrations, err := Load(os.DirFS("../../../migrate"))
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	backedUp := map[int64]bool{13: true}
+	for _, m := range migrations {
+		if backedUp[m.Version] {
+			assert.NotEmpty(t, Destructive(m.Up), "%s up script is no longer destructive", m)
+		} else {
+			assert.Empty(t, Destructive(m.Up), "%s up script is destructive", m)
+		}
+		assert.NotEmpty(t, m.Down, "%s has no down script", m)
+	}
+}
+
+func TestDestructive(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   bool
+	}{
+		{"create table", "CREATE TABLE IF NOT EXISTS `users` (id int);", false},
+		{"drop table", "DROP TABLE IF EXISTS `users`;", true},
+		{"drop database", "drop database cpd;", true},
+		{"drop column", "ALTER TABLE a DROP COLUMN `next_retry_at`;", true},
+		{"drop bare column", "ALTER TABLE a DROP `next_retry_at`;", true},
+		{"truncate", "TRUNCATE TABLE attendance;", true},
+		{"delete", "DELETE FROM `attendance` WHERE time_in IS NULL;", true},
+		{"delete with alias", "DELETE a FROM `attendance` a JOIN b ON a.id = b.id;", true},
+		{"delete multi-table", "DELETE LOW_PRIORITY a, b FROM a JOIN b ON a.id = b.id;", true},
+		{"on delete cascade", "ALTER TABLE a ADD CONSTRAINT fk_a FOREIGN KEY (b) REFERENCES b (id) ON DELETE CASCADE;", false},
+		{"on delete set null", "CREATE TABLE a (b int, FOREIGN KEY (b) REFERENCES b (id) ON DELETE SET NULL);", false},
+		{"drop index", "ALTER TABLE a DROP KEY `uk_x`, DROP INDEX idx_y;", false},
+		{"drop foreign key", "ALTER TABLE a DROP FOREIGN KEY fk_a;", false},
+		{"commented out", "-- DROP TABLE users;\n/* TRUNCATE t; */\nSELECT 1;", false},
+		{"explicit marker", "-- migrate:destructive\nUPDATE a SET b = NULL;", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, len(Destructive(tt.script)) > 0)
+		})
+	}
+}
+
+func testMigrations() []Migration {
+	mk := func(v int64, name, up, down string) Migration {
+		return Migration{Version: v, Name: name, Up: up, Down: down, Checksum: checksum(up)}
+	}
+	return []Migration{
+		mk(1, "users", "CREATE TABLE users (id int);", "DROP TABLE users;"),
+		mk(2, "index", "ALTER TABLE users ADD KEY k (id);", "ALTER TABLE users DROP KEY k;"),
+		mk(3, "cleanup", "ALTER TABLE users DROP COLUMN legacy;", ""),
+	}
+}
+
+func applied(ms []Migration, versions ...int64) map[int64]Record {
+	records := make(map[int64]Record)
+	for _, m := range ms {
+		for _, v := range versions {
+			if m.Version == v {
+				records[v] = Record{Version: v, Name: m.Name, Checksum: m.Checksum}
+			}
+		}
+	}
+	return records
+}
+
+func versions(ms []Migration) []int64 {
+	var out []int64
+	for _, m := range ms {
+		out = append(out, m.Version)
+	}
+	return out
+}
+
+func TestPlanUp(t *testing.T) {
+	ms := testMigrations()
+
+	plan, err := planUp(ms, applied(ms), 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2}, versions(plan))
+
+	_, err = planUp(ms, applied(ms, 1, 2), 0, false)
+	assert.True(t, errors.Is(err, ErrDestructive))
+
+	plan, err = planUp(ms, applied(ms, 1, 2), 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{3}, versions(plan))
+
+	drifted := applied(ms, 1)
+	r := drifted[1]
+	r.Checksum = "edited"
+	drifted[1] = r
+	_, err = planUp(ms, drifted, 0, false)
+	assert.True(t, errors.Is(err, ErrChecksumMismatch))
+
+	dirty := applied(ms, 1, 2)
+	r = dirty[2]
+	r.Dirty = true
+	dirty[2] = r
+	_, err = planUp(ms, dirty, 0, false)
+	assert.True(t, errors.Is(err, ErrDirty))
+	plan, err = planUp(ms, dirty, 0, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, versions(plan), "forced run retries the dirty migration")
+}
+
+func TestPlanDown(t *testing.T) {
+	ms := testMigrations()
+
+	plan, err := planDown(ms, applied(ms, 1, 2), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, versions(plan))
+
+	_, err = planDown(ms, applied(ms, 1, 2), 2, false)
+	assert.True(t, errors.Is(err, ErrDestructive), "dropping users needs -force")
+
+	plan, err = planDown(ms, applied(ms, 1, 2), 5, true)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, versions(plan))
+
+	_, err = planDown(ms, applied(ms, 1, 2, 3), 1, true)
+	assert.ErrorContains(t, err, "no down script")
+
+	missing := applied(ms, 1)
+	missing[9] = Record{Version: 9, Name: "gone"}
+	_, err = planDown(ms, missing, 1, true)
+	assert.ErrorContains(t, err, "file is missing")
+}
+
+func TestBuildStatus(t *testing.T) {
+	ms := testMigrations()
+	records := applied(ms, 1, 2)
+	r := records[2]
+	r.Checksum = "edited"
+	records[2] = r
+	records[7] = Record{Version: 7, Name: "removed"}
+
+	st := buildStatus(ms, records)
+	var states []string
+	for _, s := range st {
+		states = append(states, s.State)
+	}
+	assert.Equal(t, []string{StateApplied, StateModified, StatePending, StateMissing}, states)
+	assert.Nil(t, st[2].AppliedAt)
+}
@@ -0,0 +1,148 @@
+// Package migrator applies versioned SQL migrations and records them in schema_migrations.
+//
+// Migrations are pairs of files named NNN_name.up.sql and NNN_name.down.sql. Applied versions
+// are stored with a checksum of their up script, so edits to an applied migration are detected,
+// and a dirty flag, so a migration that failed half-way is not silently skipped.
+package migrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([A-Za-z0-9_\-]+)\.(up|down)\.sql$`)
+	versionedFile   = regexp.MustCompile(`^\d+_.*\.sql$`)
+
+	dropPattern     = regexp.MustCompile("(?i)\\bDROP\\s+(?:IF\\s+EXISTS\\s+)?`?(\\w+)`?")
+	truncatePattern = regexp.MustCompile(`(?i)\bTRUNCATE\b`)
+	deletePattern   = regexp.MustCompile("(?i)\\bDELETE\\s+(?:(?:LOW_PRIORITY|QUICK|IGNORE)\\s+)*(?:[\\w`.]+(?:\\s*,\\s*[\\w`.]+)*\\s+)?FROM\\s+`?[\\w.]+`?")
+	ddlPattern      = regexp.MustCompile(`(?i)\b(CREATE|ALTER|DROP|RENAME|TRUNCATE)\b`)
+
+	lineComment  = regexp.MustCompile(`(?m)(--|#)[^\n]*$`)
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+)
+
+// destructiveMarker flags a migration as destructive when the patterns below cannot see it
+const destructiveMarker = "-- migrate:destructive"
+
+// nonDestructiveDrops are DROP targets that remove no data
+var nonDestructiveDrops = map[string]bool{
+	"KEY": true, "INDEX": true, "PRIMARY": true, "FOREIGN": true, "CONSTRAINT": true,
+	"CHECK": true, "DEFAULT": true, "VIEW": true, "TRIGGER": true, "PROCEDURE": true,
+	"FUNCTION": true, "EVENT": true,
+}
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string // empty when the migration cannot be reverted
+	Checksum string // sha256 of Up
+}
+
+// String returns the migration's file stem, e.g. 017_attendance_submission_retry
+func (m Migration) String() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// Load reads NNN_name.up.sql / NNN_name.down.sql pairs from the root of fsys, sorted by version.
+// Files without a numeric prefix (seed data, the runner itself) are ignored.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		if e.IsDir() || !versionedFile.MatchString(e.Name()) {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(e.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s must be named NNN_name.up.sql or NNN_name.down.sql", e.Name())
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		name, direction := match[2], match[3]
+
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", e.Name(), err)
+		}
+		body := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, m.Name, name)
+		}
+
+		if direction == "up" {
+			m.Up = body
+			m.Checksum = checksum(body)
+		} else {
+			m.Down = body
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has a down script but no up script", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Destructive lists the statements in script that can lose data: dropped tables, databases or
+// columns, TRUNCATE, DELETE, or an explicit "-- migrate:destructive" marker. Index and constraint
+// drops are allowed.
+func Destructive(script string) []string {
+	var found []string
+	if strings.Contains(script, destructiveMarker) {
+		found = append(found, "marked "+destructiveMarker)
+	}
+
+	code := stripComments(script)
+	for _, m := range dropPattern.FindAllStringSubmatch(code, -1) {
+		if !nonDestructiveDrops[strings.ToUpper(m[1])] {
+			found = append(found, strings.Join(strings.Fields(m[0]), " "))
+		}
+	}
+	for _, m := range truncatePattern.FindAllString(code, -1) {
+		found = append(found, strings.ToUpper(m))
+	}
+	for _, m := range deletePattern.FindAllString(code, -1) {
+		found = append(found, strings.Join(strings.Fields(m), " "))
+	}
+	return found
+}
+
+// hasDDL reports whether script contains statements MySQL commits implicitly, which a
+// transaction rollback cannot undo.
+func hasDDL(script string) bool {
+	return ddlPattern.MatchString(stripComments(script))
+}
+
+func stripComments(script string) string {
+	script = blockComment.ReplaceAllString(script, " ")
+	return lineComment.ReplaceAllString(script, "")
+}
+
+func checksum(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,299 @@
+// Package spreadsheet reads the first sheet of an uploaded CSV or XLSX file as rows of cell text.
+// XLSX files are read with the standard library: formulas give their cached value and styles are
+// ignored, so date cells come back as serial numbers for SerialTime to convert.
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxRows bounds the rows read from a sheet, counting blank ones.
+	MaxRows = 100000
+	// MaxColumns bounds the columns of a sheet.
+	MaxColumns = 256
+	// maxPartSize bounds the uncompressed size of a single XLSX part.
+	maxPartSize = 64 << 20
+)
+
+// ErrUnsupported is returned for files that are neither CSV nor XLSX.
+var ErrUnsupported = errors.New("unsupported file type; upload a .csv or .xlsx file")
+
+// Sheet holds the cell text of a sheet. Rows[i] is row i+1 of the sheet, so callers can report
+// problems by the row number the user sees; blank rows are empty.
+type Sheet struct {
+	Rows     [][]string
+	date1904 bool
+}
+
+// Read reads the first sheet of a CSV or XLSX file, chosen by the extension of filename.
+func Read(filename string, r io.Reader) (*Sheet, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return readCSV(r)
+	case ".xlsx":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return readXLSX(data)
+	default:
+		return nil, ErrUnsupported
+	}
+}
+
+// SerialTime converts a spreadsheet date serial ("46083.5" is 2026-03-02 12:00) to wall-clock time
+// in loc. It reports false when value is not a serial.
+func (s *Sheet) SerialTime(value string, loc *time.Location) (time.Time, bool) {
+	serial, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	// 2958466 is 10000-01-01
+	if err != nil || serial < 0 || serial >= 2958466 {
+		return time.Time{}, false
+	}
+	days := int(serial)
+	seconds := int((serial-float64(days))*86400 + 0.5)
+	if s.date1904 {
+		return time.Date(1904, 1, 1+days, 0, 0, seconds, 0, loc), true
+	}
+	// Serial 1 is 1900-01-01, counting the 1900-02-29 that Lotus believed in
+	return time.Date(1899, 12, 30+days, 0, 0, seconds, 0, loc), true
+}
+
+func readCSV(r io.Reader) (*Sheet, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	sheet := &Sheet{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		// Blank lines are skipped by the reader; keep them so rows match the file's line numbers
+		n, _ := reader.FieldPos(0)
+		if n > MaxRows {
+			return nil, fmt.Errorf("sheet has more than %d rows", MaxRows)
+		}
+		if len(record) > MaxColumns {
+			return nil, fmt.Errorf("row %d has more than %d columns", n, MaxColumns)
+		}
+		if n == 1 {
+			// Spreadsheet programs save CSV with a byte order mark
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		for len(sheet.Rows) < n-1 {
+			sheet.Rows = append(sheet.Rows, nil)
+		}
+		sheet.Rows = append(sheet.Rows, record)
+	}
+	return sheet, nil
+}
+
+type xlsxWorkbook struct {
+	WorkbookPr struct {
+		Date1904 string `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String joins the runs of rich text.
+func (t xlsxText) String() string {
+	if len(t.R) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readXLSX(data []byte) (*Sheet, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid XLSX file: %w", err)
+	}
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		parts[f.Name] = f
+	}
+
+	var workbook xlsxWorkbook
+	if err := decodePart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, errors.New("invalid XLSX file: workbook has no sheets")
+	}
+	sheetPath, err := sheetPart(parts, workbook.Sheets[0].RelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var shared xlsxSharedStrings
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(parts, "xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+	var worksheet xlsxWorksheet
+	if err := decodePart(parts, sheetPath, &worksheet); err != nil {
+		return nil, err
+	}
+
+	sheet := &Sheet{date1904: workbook.WorkbookPr.Date1904 == "1" || workbook.WorkbookPr.Date1904 == "true"}
+	for _, row := range worksheet.Rows {
+		// Rows without a number follow the previous one
+		n := row.R
+		if n == 0 {
+			n = len(sheet.Rows) + 1
+		}
+		if n < len(sheet.Rows)+1 {
+			return nil, fmt.Errorf("invalid XLSX file: row %d is out of order", n)
+		}
+		if n > MaxRows {
+			return nil, fmt.Errorf("sheet has more than %d rows", MaxRows)
+		}
+		for len(sheet.Rows) < n {
+			sheet.Rows = append(sheet.Rows, nil)
+		}
+
+		var cells []string
+		for _, c := range row.Cells {
+			col := len(cells)
+			if c.Ref != "" {
+				if col, err = columnIndex(c.Ref); err != nil {
+					return nil, err
+				}
+			}
+			if col >= MaxColumns {
+				return nil, fmt.Errorf("row %d has more than %d columns", n, MaxColumns)
+			}
+			for len(cells) <= col {
+				cells = append(cells, "")
+			}
+
+			switch c.Type {
+			case "s":
+				i, err := strconv.Atoi(strings.TrimSpace(c.Value))
+				if err != nil || i < 0 || i >= len(shared.Items) {
+					return nil, fmt.Errorf("invalid XLSX file: cell %s refers to a missing shared string", c.Ref)
+				}
+				cells[col] = shared.Items[i].String()
+			case "inlineStr":
+				cells[col] = c.Inline.String()
+			default:
+				cells[col] = c.Value
+			}
+		}
+		sheet.Rows[n-1] = cells
+	}
+	return sheet, nil
+}
+
+// sheetPart resolves the part holding the sheet with relationship relID, falling back to the
+// conventional name when the workbook relationships are missing.
+func sheetPart(parts map[string]*zip.File, relID string) (string, error) {
+	var rels xlsxRelationships
+	if _, ok := parts["xl/_rels/workbook.xml.rels"]; ok {
+		if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &rels); err != nil {
+			return "", err
+		}
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID != relID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	if _, ok := parts["xl/worksheets/sheet1.xml"]; ok {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+	return "", errors.New("invalid XLSX file: first sheet not found")
+}
+
+func decodePart(parts map[string]*zip.File, name string, v interface{}) error {
+	f, ok := parts[name]
+	if !ok {
+		return fmt.Errorf("invalid XLSX file: %s is missing", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("invalid XLSX file: %w", err)
+	}
+	defer rc.Close()
+
+	limited := &io.LimitedReader{R: rc, N: maxPartSize + 1}
+	if err := xml.NewDecoder(limited).Decode(v); err != nil {
+		if limited.N <= 0 {
+			return fmt.Errorf("invalid XLSX file: %s is too large", name)
+		}
+		return fmt.Errorf("invalid XLSX file: %s: %w", name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the 0-based column of a cell reference such as "C7".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+		if col > MaxColumns {
+			return 0, fmt.Errorf("cell %s is beyond the first %d columns", ref, MaxColumns)
+		}
+	}
+	if i == 0 {
+		return 0, fmt.Errorf("invalid XLSX file: bad cell reference %q", ref)
+	}
+	return col - 1, nil
+}
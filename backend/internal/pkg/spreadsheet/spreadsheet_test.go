@@ -0,0 +1,153 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// buildXLSX zips the given parts into an XLSX file.
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const testWorkbook = `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Timesheet" sheetId="1" r:id="rId3"/><sheet name="Notes" sheetId="2" r:id="rId4"/></sheets>
+</workbook>`
+
+const testRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/timesheet.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/notes.xml"/>
+</Relationships>`
+
+func TestRead_XLSX(t *testing.T) {
+	data := buildXLSX(t, map[string]string{
+		"xl/workbook.xml":            testWorkbook,
+		"xl/_rels/workbook.xml.rels": testRels,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>worker_id</t></si><si><t>time_in</t></si><si><r><t>W-</t></r><r><t>001</t></r></si></sst>`,
+		// Row 2 is blank and B4 is skipped, as spreadsheet programs write them
+		"xl/worksheets/timesheet.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="B3"><v>46083.333333333336</v></c></row>
+<row r="4"><c r="A4" t="inlineStr"><is><t>W-002</t></is></c><c r="C4" t="str"><f>A4</f><v>W-002</v></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/notes.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>not this sheet</t></is></c></row></sheetData></worksheet>`,
+	})
+
+	sheet, err := Read("Timesheet.XLSX", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := [][]string{
+		{"worker_id", "time_in"},
+		nil,
+		{"W-001", "46083.333333333336"},
+		{"W-002", "", "W-002"},
+	}
+	if !reflect.DeepEqual(sheet.Rows, want) {
+		t.Errorf("Rows = %q, want %q", sheet.Rows, want)
+	}
+
+	sgt := time.FixedZone("SGT", 8*60*60)
+	got, ok := sheet.SerialTime(sheet.Rows[2][1], sgt)
+	if !ok || !got.Equal(time.Date(2026, 3, 2, 8, 0, 0, 0, sgt)) {
+		t.Errorf("SerialTime = %v, %v; want 2026-03-02 08:00 SGT", got, ok)
+	}
+}
+
+func TestRead_XLSXInvalid(t *testing.T) {
+	tests := map[string][]byte{
+		"not a zip":     []byte("worker_id,time_in"),
+		"no workbook":   buildXLSX(t, map[string]string{"xl/worksheets/sheet1.xml": "<worksheet/>"}),
+		"no sheets":     buildXLSX(t, map[string]string{"xl/workbook.xml": "<workbook><sheets/></workbook>"}),
+		"missing sheet": buildXLSX(t, map[string]string{"xl/workbook.xml": testWorkbook, "xl/_rels/workbook.xml.rels": testRels}),
+		"bad shared string": buildXLSX(t, map[string]string{
+			"xl/workbook.xml":             testWorkbook,
+			"xl/_rels/workbook.xml.rels":  testRels,
+			"xl/worksheets/timesheet.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>7</v></c></row></sheetData></worksheet>`,
+		}),
+		"too many columns": buildXLSX(t, map[string]string{
+			"xl/workbook.xml":             testWorkbook,
+			"xl/_rels/workbook.xml.rels":  testRels,
+			"xl/worksheets/timesheet.xml": `<worksheet><sheetData><row r="1"><c r="XFD1"><v>1</v></c></row></sheetData></worksheet>`,
+		}),
+	}
+	for name, data := range tests {
+		if _, err := Read("upload.xlsx", bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRead_CSV(t *testing.T) {
+	input := "\ufeffworker_id,time_in\n\nW-001, 2026-03-02 08:00\n\"W-002\",\"line\nbreak\"\nW-003\n"
+
+	sheet, err := Read("timesheet.csv", strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	// Rows stay on their line numbers despite the blank line and the quoted line break
+	want := [][]string{
+		{"worker_id", "time_in"},
+		nil,
+		{"W-001", "2026-03-02 08:00"},
+		{"W-002", "line\nbreak"},
+		nil,
+		{"W-003"},
+	}
+	if !reflect.DeepEqual(sheet.Rows, want) {
+		t.Errorf("Rows = %q, want %q", sheet.Rows, want)
+	}
+}
+
+func TestRead_Unsupported(t *testing.T) {
+	if _, err := Read("timesheet.xls", strings.NewReader("")); err != ErrUnsupported {
+		t.Errorf("err = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestSheet_SerialTime(t *testing.T) {
+	loc := time.UTC
+	tests := []struct {
+		value    string
+		date1904 bool
+		want     time.Time
+		ok       bool
+	}{
+		{"46083", false, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), true},
+		{"46083.75", false, time.Date(2026, 3, 2, 18, 0, 0, 0, loc), true},
+		// A time without a date
+		{"0.5", false, time.Date(1899, 12, 30, 12, 0, 0, 0, loc), true},
+		{"44621", true, time.Date(2026, 3, 2, 0, 0, 0, 0, loc), true},
+		{"08:00", false, time.Time{}, false},
+		{"-1", false, time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := (&Sheet{date1904: tt.date1904}).SerialTime(tt.value, loc)
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("SerialTime(%q, 1904=%v) = %v, %v; want %v, %v", tt.value, tt.date1904, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package timeutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultBusinessTimezone is the zone attendance dates, submission months and daily schedules are
+// reckoned in unless BUSINESS_TIMEZONE says otherwise.
+const DefaultBusinessTimezone = "Asia/Singapore"
+
+var businessLocation atomic.Pointer[time.Location]
+
+func init() {
+	loc, err := time.LoadLocation(DefaultBusinessTimezone)
+	if err != nil {
+		// No tz database on the host; Singapore has not observed DST since 1982
+		loc = time.FixedZone("SGT", 8*60*60)
+	}
+	businessLocation.Store(loc)
+}
+
+// LoadBusinessLocation resolves a timezone name such as "Asia/Singapore"; empty means the default.
+// Zones whose UTC offset changes during the year are refused: MySQL gets the zone as a fixed offset
+// (see MySQLOffset), which would be an hour out for half of the year.
+func LoadBusinessLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultBusinessTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+	if err := checkFixedOffset(loc, time.Now().Year()); err != nil {
+		return nil, err
+	}
+	return loc, nil
+}
+
+// checkFixedOffset returns an error when loc's UTC offset is not the same all through year.
+func checkFixedOffset(loc *time.Location, year int) error {
+	_, offset := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	for month := time.February; month <= time.December; month++ {
+		if _, o := time.Date(year, month, 1, 0, 0, 0, 0, loc).Zone(); o != offset {
+			return fmt.Errorf("timezone %q observes daylight saving time; use a zone with a fixed UTC offset", loc)
+		}
+	}
+	return nil
+}
+
+// SetBusinessLocation sets the process-wide business timezone. It is called once at startup.
+func SetBusinessLocation(loc *time.Location) {
+	if loc != nil {
+		businessLocation.Store(loc)
+	}
+}
+
+// BusinessLocation returns the process-wide business timezone.
+func BusinessLocation() *time.Location {
+	return businessLocation.Load()
+}
+
+// BusinessNow returns the current time in the business timezone.
+func BusinessNow() time.Time {
+	return time.Now().In(BusinessLocation())
+}
+
+// InBusiness returns t in the business timezone.
+func InBusiness(t time.Time) time.Time {
+	return t.In(BusinessLocation())
+}
+
+// BusinessDate returns the calendar date (YYYY-MM-DD) of t in the business timezone.
+func BusinessDate(t time.Time) string {
+	return InBusiness(t).Format("2006-01-02")
+}
+
+// ParseBusinessTime parses a timestamp from a device or bridge. RFC3339 values keep their own offset;
+// zone-less values ("2006-01-02T15:04:05" or "2006-01-02 15:04:05") are wall-clock time in the
+// business timezone. The result is in the business timezone either way.
+func ParseBusinessTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return InBusiness(t), nil
+	}
+	var err error
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		var t time.Time
+		if t, err = time.ParseInLocation(layout, value, BusinessLocation()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// MySQLOffset returns the business timezone's current UTC offset ("+08:00") for MySQL's time_zone
+// session variable, which needs no tz tables in that form. LoadBusinessLocation only accepts zones
+// whose offset does not change, so the offset computed at startup stays right.
+func MySQLOffset(loc *time.Location) string {
+	return time.Now().In(loc).Format("-07:00")
+}
@@ -0,0 +1,58 @@
+package timeutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBusinessTime(t *testing.T) {
+	sgt := time.FixedZone("SGT", 8*60*60)
+	prev := BusinessLocation()
+	SetBusinessLocation(sgt)
+	defer SetBusinessLocation(prev)
+
+	tests := []struct {
+		value string
+		want  string // RFC3339 in the business timezone
+		date  string
+	}{
+		// An explicit offset is honoured; late evening UTC is already the next day in Singapore
+		{"2026-03-01T16:30:00Z", "2026-03-02T00:30:00+08:00", "2026-03-02"},
+		{"2026-03-01T23:59:59+08:00", "2026-03-01T23:59:59+08:00", "2026-03-01"},
+		// Zone-less values are business wall-clock time, not UTC
+		{"2026-03-02T00:30:00", "2026-03-02T00:30:00+08:00", "2026-03-02"},
+		{"2026-03-01 23:59:59", "2026-03-01T23:59:59+08:00", "2026-03-01"},
+	}
+	for _, tt := range tests {
+		got, err := ParseBusinessTime(tt.value)
+		if err != nil {
+			t.Errorf("ParseBusinessTime(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if got.Format(time.RFC3339) != tt.want || BusinessDate(got) != tt.date {
+			t.Errorf("ParseBusinessTime(%q) = %s (date %s); want %s (date %s)", tt.value, got.Format(time.RFC3339), BusinessDate(got), tt.want, tt.date)
+		}
+	}
+
+	if _, err := ParseBusinessTime("yesterday"); err == nil {
+		t.Error("ParseBusinessTime accepted a non-time")
+	}
+}
+
+func TestLoadBusinessLocation(t *testing.T) {
+	if loc, err := LoadBusinessLocation(""); err != nil || loc.String() != DefaultBusinessTimezone {
+		t.Skipf("tz database not available: %v", err)
+	}
+	if _, err := LoadBusinessLocation("Mars/Olympus_Mons"); err == nil {
+		t.Error("LoadBusinessLocation accepted an unknown zone")
+	}
+	if _, err := LoadBusinessLocation("Europe/London"); err == nil {
+		t.Error("LoadBusinessLocation accepted a zone that observes daylight saving time")
+	}
+	if _, err := LoadBusinessLocation("UTC"); err != nil {
+		t.Errorf("LoadBusinessLocation(UTC): %v", err)
+	}
+	if got := MySQLOffset(time.FixedZone("SGT", 8*60*60)); got != "+08:00" {
+		t.Errorf("MySQLOffset = %s; want +08:00", got)
+	}
+}
@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `users`;
-
 CREATE TABLE IF NOT EXISTS `users` (
     `user_id` varchar(50) NOT NULL,
     `user_name` varchar(255) NOT NULL,
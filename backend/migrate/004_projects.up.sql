@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `projects`;
-
 CREATE TABLE `projects` (
     `project_id` varchar(50) NOT NULL,
     `user_id` varchar(50) DEFAULT NULL,
@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `devices`;
-
 CREATE TABLE IF NOT EXISTS `devices` (
     `device_id` varchar(50) NOT NULL,
     `sn` varchar(100) NOT NULL,
@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `attendance`;
-
 CREATE TABLE IF NOT EXISTS `attendance` (
     `attendance_id` char(36) NOT NULL,
     `device_id` varchar(50) NOT NULL,
@@ -0,0 +1,5 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+DROP TABLE IF EXISTS `system_settings`;
+
+SET FOREIGN_KEY_CHECKS = 1;
@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `system_settings`;
-
 CREATE TABLE IF NOT EXISTS `system_settings` (
     `id` int NOT NULL DEFAULT '1',
     `attendance_sync_time` TIME NOT NULL DEFAULT '23:00:00',
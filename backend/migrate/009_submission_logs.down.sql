@@ -0,0 +1,5 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+DROP TABLE IF EXISTS `submission_logs`;
+
+SET FOREIGN_KEY_CHECKS = 1;
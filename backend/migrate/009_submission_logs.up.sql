@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `submission_logs`;
-
 CREATE TABLE IF NOT EXISTS `submission_logs` (
     `log_id` int NOT NULL AUTO_INCREMENT,
     `data_element_id` varchar(100) NOT NULL,
@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `pitstop_authorisations`;
-
 CREATE TABLE IF NOT EXISTS `pitstop_authorisations` (
     `pitstop_auth_id` varchar(50) NOT NULL,
     `dataset_id` varchar(50) NOT NULL,
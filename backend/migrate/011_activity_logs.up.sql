@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `activity_logs`;
-
 CREATE TABLE IF NOT EXISTS `activity_logs` (
     `id` int NOT NULL AUTO_INCREMENT,
     `user_id` varchar(255) NOT NULL,
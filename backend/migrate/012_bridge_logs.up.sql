@@ -1,7 +1,5 @@
 SET FOREIGN_KEY_CHECKS = 0;
 
-DROP TABLE IF EXISTS `bridge_logs`;
-
 CREATE TABLE IF NOT EXISTS `bridge_logs` (
     `id` int NOT NULL AUTO_INCREMENT,
     `user_id` varchar(255) NOT NULL,
@@ -0,0 +1,3 @@
+-- Duplicates collapsed by the up migration are not restored; they remain in attendance_dedupe_backup.
+ALTER TABLE `attendance`
+    DROP KEY `uk_attendance_natural`;
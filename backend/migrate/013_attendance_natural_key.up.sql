@@ -0,0 +1,38 @@
+-- Natural-key deduplication for bridge attendance.
+-- A record is identified by (worker, time_in, source device/bridge) so that
+-- re-fetching the same window upserts instead of inserting duplicates.
+
+-- Collapse existing duplicates. Each group keeps its submitted row first, then a submitting one,
+-- then the earliest attendance_id. The removed rows are copied to attendance_dedupe_backup with
+-- the id of the row that replaced them, since the down migration cannot restore them.
+CREATE TABLE IF NOT EXISTS `attendance_dedupe_backup` AS
+SELECT r.keep_id AS kept_attendance_id, a.*
+FROM `attendance` a
+JOIN (
+    SELECT attendance_id,
+        FIRST_VALUE(attendance_id) OVER (
+            PARTITION BY worker_id, time_in, device_id
+            ORDER BY CASE status WHEN 'submitted' THEN 0 WHEN 'submitting' THEN 1 ELSE 2 END, attendance_id
+        ) AS keep_id
+    FROM `attendance`
+    WHERE time_in IS NOT NULL
+) r ON a.attendance_id = r.attendance_id
+WHERE a.attendance_id <> r.keep_id;
+
+-- Carry the latest time_out over, except onto rows CPD already has.
+UPDATE `attendance` a
+JOIN (
+    SELECT kept_attendance_id, MAX(time_out) AS max_out
+    FROM `attendance_dedupe_backup`
+    GROUP BY kept_attendance_id
+) d ON a.attendance_id = d.kept_attendance_id
+SET a.time_out = d.max_out
+WHERE a.status <> 'submitted'
+    AND d.max_out IS NOT NULL
+    AND (a.time_out IS NULL OR a.time_out < d.max_out);
+
+DELETE a FROM `attendance` a
+JOIN `attendance_dedupe_backup` b ON a.attendance_id = b.attendance_id;
+
+ALTER TABLE `attendance`
+    ADD UNIQUE KEY `uk_attendance_natural` (`worker_id`, `time_in`, `device_id`);
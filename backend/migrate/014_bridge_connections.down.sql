@@ -0,0 +1,5 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+DROP TABLE IF EXISTS `bridge_connections`;
+
+SET FOREIGN_KEY_CHECKS = 1;
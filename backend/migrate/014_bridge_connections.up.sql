@@ -0,0 +1,15 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+CREATE TABLE IF NOT EXISTS `bridge_connections` (
+    `user_id` varchar(50) NOT NULL,
+    `status` varchar(20) NOT NULL DEFAULT 'disconnected',
+    `remote_addr` varchar(255) DEFAULT NULL,
+    `connected_at` datetime DEFAULT NULL,
+    `last_seen` datetime DEFAULT NULL,
+    `disconnected_at` datetime DEFAULT NULL,
+    `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`),
+    CONSTRAINT `fk_bridge_connections_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`user_id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+SET FOREIGN_KEY_CHECKS = 1;
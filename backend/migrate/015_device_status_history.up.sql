@@ -0,0 +1,15 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+CREATE TABLE IF NOT EXISTS `device_status_history` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `device_id` varchar(50) NOT NULL,
+    `from_status` varchar(20) DEFAULT NULL,
+    `to_status` varchar(20) NOT NULL,
+    `reason` varchar(50) NOT NULL,
+    `changed_at` datetime NOT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_device_changed` (`device_id`, `changed_at`),
+    CONSTRAINT `fk_device_status_history_device` FOREIGN KEY (`device_id`) REFERENCES `devices` (`device_id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+SET FOREIGN_KEY_CHECKS = 1;
@@ -0,0 +1,28 @@
+SET FOREIGN_KEY_CHECKS = 0;
+
+CREATE TABLE IF NOT EXISTS `bridge_outbox` (
+    `id` bigint NOT NULL AUTO_INCREMENT,
+    `user_id` varchar(50) NOT NULL,
+    `action` varchar(100) NOT NULL,
+    `request_id` varchar(100) NOT NULL,
+    `worker_id` varchar(50) DEFAULT NULL,
+    `payload` json DEFAULT NULL,
+    `status` enum(
+        'pending',
+        'sent',
+        'failed',
+        'expired'
+    ) NOT NULL DEFAULT 'pending',
+    `attempts` int NOT NULL DEFAULT '0',
+    `last_error` text DEFAULT NULL,
+    `expires_at` datetime NOT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    `sent_at` datetime DEFAULT NULL,
+    PRIMARY KEY (`id`),
+    KEY `idx_outbox_queue` (`user_id`, `status`, `id`),
+    KEY `idx_outbox_worker` (`user_id`, `action`, `worker_id`, `status`),
+    CONSTRAINT `fk_bridge_outbox_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`user_id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+SET FOREIGN_KEY_CHECKS = 1;
@@ -0,0 +1,11 @@
+-- Dead-lettered rows fall back to failed so the narrower enum can be applied.
+UPDATE `attendance` SET `status` = 'failed' WHERE `status` = 'dead_letter';
+
+ALTER TABLE `attendance`
+    DROP KEY `idx_attendance_retry`,
+    DROP COLUMN `next_retry_at`,
+    MODIFY `status` enum(
+        'pending',
+        'submitted',
+        'failed'
+    ) NOT NULL DEFAULT 'pending';
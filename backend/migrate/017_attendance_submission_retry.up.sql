@@ -0,0 +1,11 @@
+-- Retry bookkeeping for CPD submissions: transient failures wait for next_retry_at,
+-- permanent or exhausted ones are parked in dead_letter until an admin requeues them.
+ALTER TABLE `attendance`
+    MODIFY `status` enum(
+        'pending',
+        'submitted',
+        'failed',
+        'dead_letter'
+    ) NOT NULL DEFAULT 'pending',
+    ADD COLUMN `next_retry_at` datetime DEFAULT NULL AFTER `retry_count`,
+    ADD KEY `idx_attendance_retry` (`status`, `next_retry_at`);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS `user_site_assignments`;
+
+ALTER TABLE `users` DROP COLUMN `role`;
@@ -0,0 +1,19 @@
+-- Per-login roles. Existing tenant accounts keep full access as managers.
+ALTER TABLE `users`
+    ADD COLUMN `role` enum(
+        'manager',
+        'pic',
+        'viewer',
+        'worker'
+    ) NOT NULL DEFAULT 'manager' AFTER `user_type`;
+
+-- Sites a PIC may edit attendance for.
+CREATE TABLE IF NOT EXISTS `user_site_assignments` (
+    `user_id` varchar(50) NOT NULL,
+    `site_id` varchar(50) NOT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`user_id`, `site_id`),
+    KEY `idx_usa_site` (`site_id`),
+    CONSTRAINT `fk_usa_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`user_id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_usa_site` FOREIGN KEY (`site_id`) REFERENCES `sites` (`site_id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,7 @@
+ALTER TABLE `activity_logs`
+    DROP KEY `idx_activity_member`,
+    DROP COLUMN `member_id`;
+
+DROP TABLE IF EXISTS `member_site_assignments`;
+
+DROP TABLE IF EXISTS `members`;
@@ -0,0 +1,50 @@
+-- Individual logins of a tenant organisation. The users row remains the organisation and keeps
+-- owning all tenant data; users.username, users.password_hash, users.role and
+-- user_site_assignments are superseded by the tables below.
+CREATE TABLE IF NOT EXISTS `members` (
+    `member_id` varchar(50) NOT NULL,
+    `user_id` varchar(50) NOT NULL COMMENT 'Owning organisation',
+    `username` varchar(255) NOT NULL,
+    `password_hash` varchar(255) DEFAULT NULL,
+    `name` varchar(255) NOT NULL,
+    `email` varchar(255) DEFAULT NULL,
+    `role` enum(
+        'manager',
+        'pic',
+        'viewer',
+        'worker'
+    ) NOT NULL DEFAULT 'viewer',
+    `status` enum('active', 'inactive') NOT NULL DEFAULT 'active',
+    `last_login_at` datetime DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`member_id`),
+    UNIQUE KEY `uk_members_username` (`username`),
+    KEY `idx_members_user` (`user_id`),
+    CONSTRAINT `fk_members_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`user_id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+CREATE TABLE IF NOT EXISTS `member_site_assignments` (
+    `member_id` varchar(50) NOT NULL,
+    `site_id` varchar(50) NOT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`member_id`, `site_id`),
+    KEY `idx_msa_site` (`site_id`),
+    CONSTRAINT `fk_msa_member` FOREIGN KEY (`member_id`) REFERENCES `members` (`member_id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_msa_site` FOREIGN KEY (`site_id`) REFERENCES `sites` (`site_id`) ON DELETE CASCADE
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- Every existing login becomes its organisation's primary member, sharing the organisation's ID
+-- so tokens issued before this migration still resolve.
+INSERT IGNORE INTO `members` (`member_id`, `user_id`, `username`, `password_hash`, `name`, `email`, `role`, `status`)
+SELECT `user_id`, `user_id`, `username`, `password_hash`, `user_name`, `contact_email`, `role`, `status`
+FROM `users`
+WHERE `username` IS NOT NULL;
+
+INSERT IGNORE INTO `member_site_assignments` (`member_id`, `site_id`)
+SELECT `user_id`, `site_id` FROM `user_site_assignments`;
+
+-- Audit entries record the individual member alongside the owning organisation.
+ALTER TABLE `activity_logs`
+    ADD COLUMN `member_id` varchar(50) DEFAULT NULL AFTER `user_id`,
+    ADD KEY `idx_activity_member` (`member_id`);
@@ -0,0 +1,35 @@
+-- Refresh tokens (stored as SHA-256 hashes). Rotation links sessions of one login into a family;
+-- replaying a rotated token revokes the whole family.
+CREATE TABLE IF NOT EXISTS `auth_sessions` (
+    `session_id` varchar(50) NOT NULL,
+    `family_id` varchar(50) NOT NULL,
+    `member_id` varchar(50) NOT NULL,
+    `user_id` varchar(50) NOT NULL,
+    `token_hash` char(64) NOT NULL,
+    `access_jti` varchar(50) NOT NULL COMMENT 'jti of the access token issued with this refresh token',
+    `access_expires_at` datetime NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `status` enum('active', 'rotated', 'revoked') NOT NULL DEFAULT 'active',
+    `replaced_by` varchar(50) DEFAULT NULL,
+    `ip_address` varchar(45) DEFAULT NULL,
+    `user_agent` varchar(255) DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `revoked_at` datetime DEFAULT NULL,
+    `revoked_reason` varchar(255) DEFAULT NULL,
+    PRIMARY KEY (`session_id`),
+    UNIQUE KEY `uk_auth_sessions_token` (`token_hash`),
+    KEY `idx_auth_sessions_family` (`family_id`),
+    KEY `idx_auth_sessions_member` (`member_id`, `status`),
+    KEY `idx_auth_sessions_user` (`user_id`, `status`),
+    KEY `idx_auth_sessions_expiry` (`expires_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- Access tokens revoked before they expire, checked by jti on every request.
+CREATE TABLE IF NOT EXISTS `revoked_tokens` (
+    `jti` varchar(50) NOT NULL,
+    `member_id` varchar(50) DEFAULT NULL,
+    `expires_at` datetime NOT NULL,
+    `revoked_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`jti`),
+    KEY `idx_revoked_tokens_expiry` (`expires_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `password_reset_tokens`;
+
+ALTER TABLE `members`
+    DROP COLUMN `password_changed_at`,
+    DROP COLUMN `must_change_password`;
@@ -0,0 +1,15 @@
+-- Forced password rotation and administrator-issued one-time reset tokens.
+ALTER TABLE `members`
+    ADD COLUMN `must_change_password` tinyint(1) NOT NULL DEFAULT 0 AFTER `password_hash`,
+    ADD COLUMN `password_changed_at` datetime DEFAULT NULL AFTER `must_change_password`;
+
+CREATE TABLE IF NOT EXISTS `password_reset_tokens` (
+    `token_hash` char(64) NOT NULL,
+    `member_id` varchar(50) NOT NULL,
+    `created_by` varchar(50) DEFAULT NULL COMMENT 'member_id of the administrator who issued it',
+    `expires_at` datetime NOT NULL,
+    `used_at` datetime DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`token_hash`),
+    KEY `idx_password_reset_member` (`member_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,10 @@
+-- Failed-login counters per account (lower-cased username) and per source IP.
+CREATE TABLE IF NOT EXISTS `login_throttles` (
+    `scope` enum('account', 'ip') NOT NULL,
+    `throttle_key` varchar(191) NOT NULL,
+    `failures` int NOT NULL DEFAULT 0,
+    `last_failure_at` datetime NOT NULL,
+    `locked_until` datetime DEFAULT NULL,
+    PRIMARY KEY (`scope`, `throttle_key`),
+    KEY `idx_login_throttles_locked` (`locked_until`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
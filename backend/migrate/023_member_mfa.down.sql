@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `member_recovery_codes`;
+
+ALTER TABLE `members`
+    DROP COLUMN `mfa_enrolled_at`,
+    DROP COLUMN `mfa_last_step`,
+    DROP COLUMN `mfa_secret`,
+    DROP COLUMN `mfa_enabled`;
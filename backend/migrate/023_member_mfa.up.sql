@@ -0,0 +1,15 @@
+-- TOTP multi-factor authentication. mfa_secret is AES-GCM sealed; it is kept with mfa_enabled = 0
+-- while enrolment awaits its first code. mfa_last_step stops a code from being used twice.
+ALTER TABLE `members`
+    ADD COLUMN `mfa_enabled` tinyint(1) NOT NULL DEFAULT 0 AFTER `password_changed_at`,
+    ADD COLUMN `mfa_secret` varchar(255) DEFAULT NULL AFTER `mfa_enabled`,
+    ADD COLUMN `mfa_last_step` bigint DEFAULT NULL AFTER `mfa_secret`,
+    ADD COLUMN `mfa_enrolled_at` datetime DEFAULT NULL AFTER `mfa_last_step`;
+
+CREATE TABLE IF NOT EXISTS `member_recovery_codes` (
+    `member_id` varchar(50) NOT NULL,
+    `code_hash` char(64) NOT NULL,
+    `used_at` datetime DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`member_id`, `code_hash`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,19 @@
+-- Machine-to-machine API keys of tenant organisations (stored as SHA-256 hashes).
+-- scopes is a comma-separated list of permissions, e.g. 'attendance:read,workers:write'.
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    `key_id` varchar(50) NOT NULL,
+    `user_id` varchar(50) NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `key_prefix` varchar(20) NOT NULL COMMENT 'first characters of the key, shown in listings',
+    `key_hash` char(64) NOT NULL,
+    `scopes` varchar(1000) NOT NULL,
+    `expires_at` datetime DEFAULT NULL,
+    `last_used_at` datetime DEFAULT NULL,
+    `last_used_ip` varchar(45) DEFAULT NULL,
+    `created_by` varchar(50) DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `revoked_at` datetime DEFAULT NULL,
+    PRIMARY KEY (`key_id`),
+    UNIQUE KEY `uk_api_keys_hash` (`key_hash`),
+    KEY `idx_api_keys_user` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
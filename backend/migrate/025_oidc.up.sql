@@ -0,0 +1,41 @@
+-- OpenID Connect single sign-on. Each organisation can register identity providers; client_secret
+-- is AES-GCM sealed. scopes and email_domains are comma-separated, claim_mapping is JSON.
+CREATE TABLE IF NOT EXISTS `oidc_providers` (
+    `provider_id` varchar(50) NOT NULL,
+    `user_id` varchar(50) NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `issuer` varchar(255) NOT NULL,
+    `client_id` varchar(255) NOT NULL,
+    `client_secret` varchar(512) DEFAULT NULL,
+    `scopes` varchar(500) NOT NULL,
+    `email_domains` varchar(1000) NOT NULL DEFAULT '',
+    `claim_mapping` json NOT NULL,
+    `auto_provision` tinyint(1) NOT NULL DEFAULT 0,
+    `default_role` varchar(20) NOT NULL DEFAULT 'viewer',
+    `enabled` tinyint(1) NOT NULL DEFAULT 1,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`provider_id`),
+    KEY `idx_oidc_providers_user` (`user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- Pending logins, keyed by the SHA-256 of the state parameter and deleted when the callback arrives.
+CREATE TABLE IF NOT EXISTS `oidc_login_states` (
+    `state_hash` char(64) NOT NULL,
+    `provider_id` varchar(50) NOT NULL,
+    `code_verifier` varchar(128) NOT NULL,
+    `nonce` varchar(128) NOT NULL,
+    `return_to` varchar(500) NOT NULL DEFAULT '',
+    `expires_at` datetime NOT NULL,
+    PRIMARY KEY (`state_hash`),
+    KEY `idx_oidc_login_states_expires` (`expires_at`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- Links an IdP subject to the member it signs in as.
+CREATE TABLE IF NOT EXISTS `oidc_identities` (
+    `provider_id` varchar(50) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `member_id` varchar(50) NOT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`provider_id`, `subject`),
+    KEY `idx_oidc_identities_member` (`member_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,9 @@
+-- Organisations a vendor account supports. A vendor with no rows here sees every organisation
+-- (global scope); one with rows sees only those organisations and itself.
+CREATE TABLE IF NOT EXISTS `vendor_tenants` (
+    `vendor_user_id` varchar(50) NOT NULL,
+    `tenant_user_id` varchar(50) NOT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`vendor_user_id`, `tenant_user_id`),
+    KEY `idx_vendor_tenants_tenant` (`tenant_user_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,6 @@
+ALTER TABLE `activity_logs`
+    DROP KEY `idx_activity_impersonation`,
+    DROP COLUMN `impersonation_id`,
+    DROP COLUMN `impersonator_user_id`;
+
+DROP TABLE IF EXISTS `impersonation_sessions`;
@@ -0,0 +1,25 @@
+-- Time-limited "act as tenant" sessions of vendor support staff. The access token issued for a
+-- session carries its impersonation_id; ending or expiring the row invalidates the token.
+CREATE TABLE IF NOT EXISTS `impersonation_sessions` (
+    `impersonation_id` varchar(50) NOT NULL,
+    `actor_user_id` varchar(50) NOT NULL COMMENT 'vendor organisation of the support member',
+    `actor_member_id` varchar(50) NOT NULL,
+    `actor_username` varchar(100) DEFAULT NULL,
+    `tenant_user_id` varchar(50) NOT NULL COMMENT 'organisation being impersonated',
+    `reason` varchar(500) NOT NULL,
+    `read_only` tinyint(1) NOT NULL DEFAULT 1,
+    `expires_at` datetime NOT NULL,
+    `ip_address` varchar(45) DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `ended_at` datetime DEFAULT NULL,
+    PRIMARY KEY (`impersonation_id`),
+    KEY `idx_impersonation_tenant` (`tenant_user_id`, `created_at`),
+    KEY `idx_impersonation_actor` (`actor_member_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- Actions taken while impersonating are logged in the tenant's feed (user_id) by the vendor's
+-- member (member_id); these columns keep the vendor organisation and the session alongside.
+ALTER TABLE `activity_logs`
+    ADD COLUMN `impersonator_user_id` varchar(50) DEFAULT NULL AFTER `member_id`,
+    ADD COLUMN `impersonation_id` varchar(50) DEFAULT NULL AFTER `impersonator_user_id`,
+    ADD KEY `idx_activity_impersonation` (`impersonation_id`);
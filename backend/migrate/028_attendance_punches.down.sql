@@ -0,0 +1,6 @@
+ALTER TABLE `system_settings`
+    DROP COLUMN `max_session_hours`,
+    DROP COLUMN `punch_debounce_seconds`,
+    DROP COLUMN `attendance_pairing_mode`;
+
+DROP TABLE IF EXISTS `attendance_punches`;
@@ -0,0 +1,35 @@
+-- Raw punch events as scanned at the devices. Attendance rows are derived from these by the
+-- pairing engine, so they can be re-derived whenever the pairing rules change.
+CREATE TABLE IF NOT EXISTS `attendance_punches` (
+    `punch_id` bigint NOT NULL AUTO_INCREMENT,
+    `worker_id` varchar(50) NOT NULL,
+    `site_id` varchar(50) DEFAULT NULL,
+    `user_id` varchar(50) DEFAULT NULL,
+    `device_sn` varchar(50) NOT NULL,
+    `direction` enum('in', 'out', 'unknown') NOT NULL DEFAULT 'unknown',
+    `punched_at` timestamp NOT NULL,
+    `source` varchar(20) NOT NULL DEFAULT 'device' COMMENT 'device, or bridge_record for punches split from an aggregated record',
+    `raw_payload` json DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`punch_id`),
+    UNIQUE KEY `uk_punch_natural` (`worker_id`, `punched_at`, `device_sn`),
+    KEY `idx_punch_user_time` (`user_id`, `punched_at`),
+    CONSTRAINT `attendance_punches_ibfk_1` FOREIGN KEY (`worker_id`) REFERENCES `workers` (`worker_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- Rebuild the punches behind existing aggregated rows so their days can be re-derived too.
+INSERT IGNORE INTO `attendance_punches` (worker_id, site_id, user_id, device_sn, direction, punched_at, source)
+SELECT worker_id, site_id, user_id, device_id, 'in', time_in, 'bridge_record'
+FROM `attendance`
+WHERE time_in IS NOT NULL;
+
+INSERT IGNORE INTO `attendance_punches` (worker_id, site_id, user_id, device_sn, direction, punched_at, source)
+SELECT worker_id, site_id, user_id, device_id, 'out', time_out, 'bridge_record'
+FROM `attendance`
+WHERE time_out IS NOT NULL;
+
+-- Pairing rules used to derive attendance sessions from punches.
+ALTER TABLE `system_settings`
+    ADD COLUMN `attendance_pairing_mode` varchar(30) NOT NULL DEFAULT 'first_in_last_out' COMMENT 'first_in_last_out or paired',
+    ADD COLUMN `punch_debounce_seconds` int NOT NULL DEFAULT '60' COMMENT 'Repeat scans in the same direction within this window are ignored',
+    ADD COLUMN `max_session_hours` int NOT NULL DEFAULT '16' COMMENT 'An open session is abandoned after this many hours without an out punch';
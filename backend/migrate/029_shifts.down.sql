@@ -0,0 +1,4 @@
+ALTER TABLE `attendance`
+    DROP COLUMN `open_until`;
+
+DROP TABLE IF EXISTS `shifts`;
@@ -0,0 +1,25 @@
+-- Shift definitions per site, or per project (which then overrides its site). Punches inside a
+-- shift's window count towards the business date the shift starts on.
+CREATE TABLE IF NOT EXISTS `shifts` (
+    `shift_id` varchar(50) NOT NULL,
+    `user_id` varchar(50) NOT NULL,
+    `site_id` varchar(50) DEFAULT NULL,
+    `project_id` varchar(50) DEFAULT NULL,
+    `name` varchar(100) NOT NULL,
+    `start_time` TIME NOT NULL,
+    `end_time` TIME NOT NULL COMMENT 'Not after start_time means the shift ends the next day',
+    `grace_before_minutes` int NOT NULL DEFAULT '60',
+    `grace_after_minutes` int NOT NULL DEFAULT '120',
+    `status` varchar(20) NOT NULL DEFAULT 'active',
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    PRIMARY KEY (`shift_id`),
+    KEY `idx_shifts_user` (`user_id`),
+    KEY `idx_shifts_site` (`site_id`),
+    KEY `idx_shifts_project` (`project_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- A session still waiting for its time_out is held back from CPD submission until open_until,
+-- the end of its shift window (or max_session_hours after time_in without a shift).
+ALTER TABLE `attendance`
+    ADD COLUMN `open_until` datetime DEFAULT NULL AFTER `time_out`;
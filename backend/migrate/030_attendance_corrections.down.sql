@@ -0,0 +1,10 @@
+ALTER TABLE `attendance`
+    DROP COLUMN `revision`;
+
+DROP TRIGGER IF EXISTS `trg_attendance_revisions_no_delete`;
+
+DROP TRIGGER IF EXISTS `trg_attendance_revisions_no_update`;
+
+DROP TABLE IF EXISTS `attendance_revisions`;
+
+DROP TABLE IF EXISTS `attendance_corrections`;
@@ -0,0 +1,57 @@
+-- Attendance corrections: a reason with the original and proposed times, approved or rejected by
+-- someone other than the requester. Corrections of submitted records are amendments.
+CREATE TABLE IF NOT EXISTS `attendance_corrections` (
+    `correction_id` varchar(50) NOT NULL,
+    `attendance_id` varchar(50) NOT NULL,
+    `user_id` varchar(50) NOT NULL,
+    `site_id` varchar(50) NOT NULL,
+    `worker_id` varchar(50) NOT NULL,
+    `kind` enum('correction', 'amendment') NOT NULL,
+    `reason` varchar(500) NOT NULL,
+    `original_time_in` timestamp NULL DEFAULT NULL,
+    `original_time_out` timestamp NULL DEFAULT NULL,
+    `proposed_time_in` timestamp NULL DEFAULT NULL,
+    `proposed_time_out` timestamp NULL DEFAULT NULL,
+    `status` enum('pending', 'approved', 'rejected') NOT NULL DEFAULT 'pending',
+    `requested_by` varchar(100) NOT NULL,
+    `requested_by_name` varchar(255) DEFAULT NULL,
+    `reviewed_by` varchar(100) DEFAULT NULL,
+    `reviewed_by_name` varchar(255) DEFAULT NULL,
+    `review_note` varchar(500) DEFAULT NULL,
+    `reviewed_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`correction_id`),
+    KEY `idx_corrections_attendance` (`attendance_id`, `status`),
+    KEY `idx_corrections_user_status` (`user_id`, `status`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- One row per applied correction. The history is append-only.
+CREATE TABLE IF NOT EXISTS `attendance_revisions` (
+    `revision_id` bigint NOT NULL AUTO_INCREMENT,
+    `attendance_id` varchar(50) NOT NULL,
+    `revision` int NOT NULL,
+    `correction_id` varchar(50) NOT NULL,
+    `kind` enum('correction', 'amendment') NOT NULL,
+    `previous_time_in` timestamp NULL DEFAULT NULL,
+    `previous_time_out` timestamp NULL DEFAULT NULL,
+    `time_in` timestamp NULL DEFAULT NULL,
+    `time_out` timestamp NULL DEFAULT NULL,
+    `previous_status` varchar(20) NOT NULL,
+    `reason` varchar(500) NOT NULL,
+    `requested_by` varchar(100) NOT NULL,
+    `approved_by` varchar(100) NOT NULL,
+    `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (`revision_id`),
+    UNIQUE KEY `uk_revision` (`attendance_id`, `revision`),
+    KEY `idx_revisions_correction` (`correction_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+CREATE TRIGGER `trg_attendance_revisions_no_update` BEFORE UPDATE ON `attendance_revisions`
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'attendance revisions are immutable';
+
+CREATE TRIGGER `trg_attendance_revisions_no_delete` BEFORE DELETE ON `attendance_revisions`
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'attendance revisions are immutable';
+
+-- Number of approved corrections. A corrected record is no longer re-derived from punches.
+ALTER TABLE `attendance`
+    ADD COLUMN `revision` int NOT NULL DEFAULT 0 AFTER `status`;
@@ -0,0 +1,7 @@
+ALTER TABLE `system_settings`
+    DROP COLUMN `manual_attendance_policy`;
+
+ALTER TABLE `attendance`
+    DROP COLUMN `entered_by_name`,
+    DROP COLUMN `entered_by`,
+    DROP COLUMN `source`;
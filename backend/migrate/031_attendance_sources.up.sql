@@ -0,0 +1,10 @@
+-- Where an attendance record came from: derived from device punches, entered by hand or imported
+-- from a timesheet, and who entered it.
+ALTER TABLE `attendance`
+    ADD COLUMN `source` enum('device', 'manual', 'import') NOT NULL DEFAULT 'device' AFTER `direction`,
+    ADD COLUMN `entered_by` varchar(100) DEFAULT NULL AFTER `source`,
+    ADD COLUMN `entered_by_name` varchar(255) DEFAULT NULL AFTER `entered_by`;
+
+-- How attendance not recorded by a device is submitted to CPD.
+ALTER TABLE `system_settings`
+    ADD COLUMN `manual_attendance_policy` varchar(20) NOT NULL DEFAULT 'flag' COMMENT 'submit, flag or exclude attendance entered manually or imported';
@@ -0,0 +1,12 @@
+-- Claimed rows go back to the queue they were claimed from so the narrower enum can be applied.
+UPDATE `attendance`
+SET `status` = IF(COALESCE(`retry_count`, 0) > 0, 'failed', 'pending'), `next_retry_at` = NULL
+WHERE `status` = 'submitting';
+
+ALTER TABLE `attendance`
+    MODIFY `status` enum(
+        'pending',
+        'submitted',
+        'failed',
+        'dead_letter'
+    ) NOT NULL DEFAULT 'pending';
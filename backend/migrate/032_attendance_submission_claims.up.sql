@@ -0,0 +1,10 @@
+-- A submission run claims the rows it is about to send by marking them submitting; next_retry_at
+-- holds the end of the claim so rows of a run that died become due again.
+ALTER TABLE `attendance`
+    MODIFY `status` enum(
+        'pending',
+        'submitting',
+        'submitted',
+        'failed',
+        'dead_letter'
+    ) NOT NULL DEFAULT 'pending';
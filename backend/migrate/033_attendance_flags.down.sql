@@ -0,0 +1,2 @@
+ALTER TABLE `attendance`
+    DROP COLUMN `flagged`;
@@ -0,0 +1,4 @@
+-- Set when a record entered manually or imported was submitted under the "flag" manual attendance
+-- policy. The CPD payload has no field for it, so it is kept on the record.
+ALTER TABLE `attendance`
+    ADD COLUMN `flagged` tinyint(1) NOT NULL DEFAULT 0 AFTER `entered_by_name`;
@@ -2,6 +2,8 @@ SET FOREIGN_KEY_CHECKS = 0;
 
 TRUNCATE TABLE attendance;
 
+TRUNCATE TABLE attendance_punches;
+
 TRUNCATE TABLE devices;
 
 TRUNCATE TABLE workers;
@@ -10,6 +12,36 @@ TRUNCATE TABLE sites;
 
 TRUNCATE TABLE users;
 
+TRUNCATE TABLE members;
+
+TRUNCATE TABLE auth_sessions;
+
+TRUNCATE TABLE revoked_tokens;
+
+TRUNCATE TABLE password_reset_tokens;
+
+TRUNCATE TABLE login_throttles;
+
+TRUNCATE TABLE member_recovery_codes;
+
+TRUNCATE TABLE api_keys;
+
+TRUNCATE TABLE oidc_providers;
+
+TRUNCATE TABLE oidc_login_states;
+
+TRUNCATE TABLE oidc_identities;
+
+TRUNCATE TABLE vendor_tenants;
+
+TRUNCATE TABLE impersonation_sessions;
+
+TRUNCATE TABLE shifts;
+
+TRUNCATE TABLE attendance_corrections;
+
+TRUNCATE TABLE attendance_revisions;
+
 TRUNCATE TABLE projects;
 
 -- ======================
@@ -53,6 +85,29 @@ VALUES (
         NOW()
     );
 
+-- Primary login of the vendor organisation (shares its ID)
+INSERT INTO
+    members (
+        member_id,
+        user_id,
+        username,
+        password_hash,
+        name,
+        email,
+        role,
+        status
+    )
+VALUES (
+        'Owner_001',
+        'Owner_001',
+        'came_admin',
+        '$2a$10$YGl2KZOrJ8oAtuyu5l59JuLCAeHZMfm15blSCSLwGAkfIU04c.F6G',
+        'CA M&E Account',
+        'admin@came.com',
+        'manager',
+        'active'
+    );
+
 -- ======================
 -- System Settings
 -- ======================
@@ -63,7 +118,11 @@ INSERT INTO
         cpd_submission_time,
         max_payload_size_kb,
         max_workers_per_request,
-        max_requests_per_minute
+        max_requests_per_minute,
+        attendance_pairing_mode,
+        punch_debounce_seconds,
+        max_session_hours,
+        manual_attendance_policy
     )
 VALUES (
         1,
@@ -71,13 +130,21 @@ VALUES (
         '09:00:00',
         256,
         100,
-        150
+        150,
+        'first_in_last_out',
+        60,
+        16,
+        'flag'
     )
 ON DUPLICATE KEY UPDATE
     attendance_sync_time = '23:00:00',
     cpd_submission_time = '09:00:00',
     max_payload_size_kb = 256,
     max_workers_per_request = 100,
-    max_requests_per_minute = 150;
+    max_requests_per_minute = 150,
+    attendance_pairing_mode = 'first_in_last_out',
+    punch_debounce_seconds = 60,
+    max_session_hours = 16,
+    manual_attendance_policy = 'flag';
 
 SET FOREIGN_KEY_CHECKS = 1;
\ No newline at end of file
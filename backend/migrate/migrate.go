@@ -1,61 +1,151 @@
-package main // change to main so it can be executable
+// Command migrate applies the versioned schema migrations in this directory.
+//
+//	go run ./migrate status            list migrations and whether they are applied
+//	go run ./migrate up [n]            apply pending migrations (all, or the next n)
+//	go run ./migrate down [n]          revert the latest n applied migrations (default 1)
+//	go run ./migrate redo              revert and re-apply the latest migration
+//	go run ./migrate baseline <ver>    mark migrations up to <ver> as applied without running them
+//
+// Migrations that drop tables or columns, and every down that does, are refused unless -force is set.
+package main
 
 import (
+	"context"
 	"database/sql"
+	"embed"
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"log"
-	"path/filepath"
-	"sort"
+	"io/fs"
+	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"cpd-nexus/internal/pkg/config"
+	"cpd-nexus/internal/pkg/logger"
+	"cpd-nexus/internal/pkg/migrator"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
-// RunMigrations reads all .sql files in folder and executes them in order
-func RunMigrations(db *sql.DB, folder string) error {
-	files, err := filepath.Glob(folder + "/*.sql")
+//go:embed [0-9]*.sql
+var embedded embed.FS
+
+func main() {
+	dir := flag.String("dir", "", "read migrations from this directory instead of the ones built into the binary")
+	force := flag.Bool("force", false, "allow destructive migrations, ignore checksum drift and retry dirty migrations")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var source fs.FS = embedded
+	if *dir != "" {
+		source = os.DirFS(*dir)
+	}
+	migrations, err := migrator.Load(source)
 	if err != nil {
-		return err
+		logger.Fatalf("%v", err)
 	}
 
-	sort.Strings(files) // ensure correct order
+	cfg := config.LoadConfig()
+	dsn, err := migrationDSN(cfg.DBDSN)
+	if err != nil {
+		logger.Fatalf("Invalid DB_DSN: %v", err)
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		logger.Fatalf("Failed to connect to DB: %v", err)
+	}
+	defer db.Close()
 
-	for _, file := range files {
-		log.Printf("Processing file: %s", file)
-		sqlBytes, err := ioutil.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
-		}
+	if err := db.Ping(); err != nil {
+		logger.Fatalf("DB ping failed: %v", err)
+	}
+
+	ctx := context.Background()
+	m := migrator.New(db, migrations, migrator.Options{Force: *force})
 
-		_, err = db.Exec(string(sqlBytes))
-		if err != nil {
-			return fmt.Errorf("failed to exec %s: %w", file, err)
+	cmd, arg := flag.Arg(0), flag.Arg(1)
+	switch cmd {
+	case "status":
+		err = printStatus(ctx, m)
+	case "up":
+		var n int
+		if n, err = optionalInt(arg, 0); err == nil {
+			n, err = m.Up(ctx, n)
+			logger.Infof("[Migrate] %d migration(s) applied", n)
 		}
-		log.Printf("Migrated: %s\n", file)
+	case "down":
+		var n int
+		if n, err = optionalInt(arg, 1); err == nil {
+			n, err = m.Down(ctx, n)
+			logger.Infof("[Migrate] %d migration(s) reverted", n)
+		}
+	case "redo":
+		err = m.Redo(ctx)
+	case "baseline":
+		var version int64
+		if version, err = strconv.ParseInt(arg, 10, 64); err != nil {
+			err = fmt.Errorf("baseline requires a version number")
+		} else {
+			var n int
+			n, err = m.Baseline(ctx, version)
+			logger.Infof("[Migrate] %d migration(s) baselined", n)
+		}
+	default:
+		usage()
+		os.Exit(2)
 	}
 
-	return nil
+	if err != nil {
+		logger.Fatalf("Migration failed: %v", err)
+	}
 }
 
-func main() {
-	cfg := config.LoadConfig()
-
-	db, err := sql.Open("mysql", cfg.DBDSN)
+func printStatus(ctx context.Context, m *migrator.Migrator) error {
+	statuses, err := m.Status(ctx)
 	if err != nil {
-		log.Fatal("Failed to connect to DB:", err)
+		return err
 	}
-	defer db.Close()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, appliedAt)
+	}
+	return w.Flush()
+}
 
-	if err := db.Ping(); err != nil {
-		log.Fatal("DB ping failed:", err)
+// migrationDSN enables the driver options the runner relies on: whole-file scripts need
+// multiStatements and schema_migrations.applied_at needs parseTime.
+func migrationDSN(dsn string) (string, error) {
+	c, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
 	}
+	c.MultiStatements = true
+	c.ParseTime = true
+	return c.FormatDSN(), nil
+}
 
-	// Run migrations
-	if err := RunMigrations(db, "./"); err != nil { // ./ assuming you're in migrate folder
-		log.Fatal("Migration failed:", err)
+func optionalInt(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
 	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid count %q", s)
+	}
+	return n, nil
+}
 
-	log.Println("All migrations ran successfully!")
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: migrate [-dir path] [-force] status | up [n] | down [n] | redo | baseline <version>\n")
+	flag.PrintDefaults()
 }
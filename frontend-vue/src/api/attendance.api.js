@@ -11,9 +11,26 @@ export const attendanceApi = {
     getAttendance: (params) => http.get('/attendance', { params }),
 
     /**
-     * Update an attendance record
+     * Request a correction of an attendance record; it applies once approved
+     * @param {Object} data - { reason, time_in, time_out }
      */
-    updateAttendance: (id, data) => http.put(`/attendance/${id}`, data),
+    requestCorrection: (id, data) => http.post(`/attendance/${id}/corrections`, data),
+
+    /**
+     * Fetch corrections, e.g. { status: 'pending' } for the review queue
+     */
+    getCorrections: (params) => http.get('/attendance/corrections', { params }),
+
+    /**
+     * Approve or reject a pending correction
+     */
+    approveCorrection: (id, note) => http.post(`/attendance/corrections/${id}/approve`, { note }),
+    rejectCorrection: (id, note) => http.post(`/attendance/corrections/${id}/reject`, { note }),
+
+    /**
+     * Fetch the revision history of an attendance record
+     */
+    getRevisions: (id) => http.get(`/attendance/${id}/revisions`),
 
     /**
      * Fetch a single attendance record by ID
@@ -68,7 +68,11 @@ export const liveApi = {
     getActivityLog: analyticsApi.getActivityLog,
     getDetailedAnalytics: analyticsApi.getDetailedAnalytics,
     getAttendance: attendanceApi.getAttendance,
-    updateAttendance: attendanceApi.updateAttendance,
+    requestCorrection: attendanceApi.requestCorrection,
+    getCorrections: attendanceApi.getCorrections,
+    approveCorrection: attendanceApi.approveCorrection,
+    rejectCorrection: attendanceApi.rejectCorrection,
+    getRevisions: attendanceApi.getRevisions,
 
     // --- System Settings ---
     getSettings: settingsApi.getSettings,